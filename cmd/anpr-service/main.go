@@ -3,23 +3,54 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// Часовые пояса камер не должны зависеть от tzdata в образе
+	_ "time/tzdata"
 
+	"google.golang.org/grpc"
+
+	"anpr-service/internal/archive"
 	"anpr-service/internal/auth"
+	"anpr-service/internal/capture"
 	"anpr-service/internal/config"
 	"anpr-service/internal/db"
+	"anpr-service/internal/grpcapi"
 	httphandler "anpr-service/internal/http"
 	"anpr-service/internal/http/middleware"
 	"anpr-service/internal/logger"
+	"anpr-service/internal/metrics"
 	"anpr-service/internal/repository"
+	"anpr-service/internal/roles"
 	"anpr-service/internal/service"
+	"anpr-service/internal/tracing"
 )
 
 func main() {
+	// replay, simulate и roles-stub не требуют конфигурации сервиса и подключения к БД
+	if len(os.Args) > 1 {
+		var run func([]string) error
+		switch os.Args[1] {
+		case "replay":
+			run = runReplay
+		case "simulate":
+			run = runSimulate
+		case "roles-stub":
+			run = runRolesStub
+		}
+		if run != nil {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
@@ -28,60 +59,163 @@ func main() {
 
 	appLogger := logger.New(cfg.Environment)
 
+	// Подкоманды CLI; без аргументов запускается HTTP-сервер
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(cfg, appLogger, os.Args[2:]); err != nil {
+				appLogger.Fatal().Err(err).Msg("migrate failed")
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to init tracing")
+	}
+
 	database, err := db.New(cfg, appLogger)
 	if err != nil {
 		appLogger.Fatal().Err(err).Msg("failed to connect database")
 	}
 
+	if err := tracing.InstrumentGorm(database); err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to instrument database")
+	}
+	if err := metrics.InstrumentGorm(database); err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to instrument database")
+	}
+	if sqlDB, err := database.DB(); err == nil {
+		if err := metrics.RegisterDBStats(sqlDB); err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to register db stats collector")
+		}
+	}
+
 	anprRepo := repository.NewANPRRepository(database)
-	anprService := service.NewANPRService(anprRepo, appLogger)
+	auditRepo := repository.NewAuditRepository(database)
+	retentionRepo := repository.NewRetentionRepository(database)
+	archiveRepo := repository.NewArchiveRepository(database)
+	partitionRepo := repository.NewPartitionRepository(database)
+	exportRepo := repository.NewExportRepository(database)
+	cameraRepo := repository.NewCameraRepository(database)
+	anomalyRepo := repository.NewAnomalyRepository(database)
+	vehicleRepo := repository.NewVehicleRepository(database)
+	reviewRepo := repository.NewReviewRepository(database)
+	correctionRepo := repository.NewCorrectionRepository(database)
+
+	archiveStore, err := archive.NewStore(cfg.Archive)
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to init archive store")
+	}
+
+	var captureStore *capture.Store
+	if cfg.Capture.Enabled {
+		captureStore, err = capture.NewStore(cfg.Capture)
+		if err != nil {
+			appLogger.Fatal().Err(err).Msg("failed to init capture store")
+		}
+		appLogger.Warn().
+			Str("dir", cfg.Capture.Dir).
+			Strs("cameras", cfg.Capture.Cameras).
+			Str("mode", cfg.Capture.Mode).
+			Msg("raw ingest capture enabled")
+	}
+
+	cameraService := service.NewCameraService(cameraRepo, cfg.Confidence, cfg.Clock, appLogger)
+	anomalyService := service.NewAnomalyService(anomalyRepo, cameraService, cfg.Anomaly, appLogger)
+	// API аномалий и пары проездов без номера доступны всегда, проверка при приёме - только
+	// при ANOMALY_DETECTION_ENABLED
+	var anomalyDetector *service.AnomalyService
+	if cfg.Anomaly.Enabled {
+		anomalyDetector = anomalyService
+	}
+	reviewService := service.NewReviewService(reviewRepo, anprRepo, cfg.Review, appLogger)
+	archiveService := service.NewArchiveService(archiveRepo, archiveStore, cfg.Archive, appLogger)
+	// Архивация перед удалением: политики хранения, удаление секций и ручная очистка
+	var deleteArchiver *service.ArchiveService
+	if cfg.Archive.BeforeDelete {
+		deleteArchiver = archiveService
+	} else {
+		appLogger.Warn().Msg("ARCHIVE_BEFORE_DELETE is disabled: events are deleted without archiving")
+	}
+	anprService := service.NewANPRService(anprRepo, cameraService, anomalyDetector, anomalyService, reviewService, deleteArchiver, appLogger)
+	correctionService := service.NewCorrectionService(correctionRepo, anprRepo, appLogger)
+	auditService := service.NewAuditService(auditRepo, appLogger)
+	var rolesClient *roles.Client
+	if cfg.VehicleSync.Enabled {
+		rolesClient = roles.NewClient(cfg.VehicleSync.RolesURL, cfg.VehicleSync.RolesToken, cfg.VehicleSync.PageSize, cfg.VehicleSync.Timeout)
+	}
+	vehicleService := service.NewVehicleService(vehicleRepo, rolesClient, cfg.VehicleSync, appLogger)
+	exportService := service.NewExportService(anprRepo, exportRepo, archiveStore, cfg.Export, cfg.Archive.TempDir, appLogger)
+
+	partitionService := service.NewPartitionService(partitionRepo, deleteArchiver, cfg.Partition, appLogger)
+	retentionService := service.NewRetentionService(retentionRepo, deleteArchiver, partitionService, cfg.Retention, appLogger)
 
 	tokenParser := auth.NewParser(cfg.Auth.AccessSecret)
 
-	handler := httphandler.NewHandler(anprService, cfg, appLogger)
+	handler := httphandler.NewHandler(anprService, auditService, retentionService, archiveService, partitionService, exportService, cameraService, anomalyService, vehicleService, reviewService, correctionService, captureStore, cfg, appLogger)
 	authMiddleware := middleware.Auth(tokenParser)
 	router := httphandler.NewRouter(handler, authMiddleware, cfg.Environment, database)
 
 	addr := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port)
 	appLogger.Info().Str("addr", addr).Msg("starting ANPR service")
 
+	tlsConfig, err := serverTLSConfig(cfg.HTTP)
+	if err != nil {
+		appLogger.Fatal().Err(err).Msg("failed to configure TLS")
+	}
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: router,
+		Addr:      addr,
+		Handler:   router,
+		TLSConfig: tlsConfig,
 	}
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := srv.ListenAndServe
+		if tlsConfig != nil {
+			serve = func() error { return srv.ListenAndServeTLS(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile) }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			appLogger.Error().Err(err).Msg("failed to start server")
 			os.Exit(1)
 		}
 	}()
 
-	// Запускаем фоновую задачу для очистки старых событий (каждые 6 часов)
-	go func() {
-		ticker := time.NewTicker(6 * time.Hour)
-		defer ticker.Stop()
-
-		// Выполняем первую очистку через 1 минуту после запуска
-		time.Sleep(1 * time.Minute)
-		if deleted, err := anprService.CleanupOldEvents(context.Background(), 3); err == nil {
-			appLogger.Info().Int64("deleted", deleted).Msg("initial cleanup completed")
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.GRPC.Port)
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			appLogger.Fatal().Err(err).Str("addr", grpcAddr).Msg("failed to listen grpc")
 		}
-
-		for range ticker.C {
-			if deleted, err := anprService.CleanupOldEvents(context.Background(), 3); err != nil {
-				appLogger.Error().Err(err).Msg("periodic cleanup failed")
-			} else if deleted > 0 {
-				appLogger.Info().Int64("deleted", deleted).Msg("periodic cleanup completed")
+		grpcServer = grpcapi.NewGRPCServer(grpcapi.NewServer(anprService, cameraService, cfg, appLogger), tokenParser)
+		appLogger.Info().Str("addr", grpcAddr).Msg("starting gRPC server")
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				appLogger.Error().Err(err).Msg("grpc server stopped")
 			}
-		}
-	}()
+		}()
+	}
+
+	// Фоновые задачи: создание будущих секций, применение политик хранения и выгрузки событий
+	// (см. PARTITION_*, RETENTION_* и EXPORT_* в конфигурации)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go partitionService.Start(backgroundCtx)
+	go retentionService.Start(backgroundCtx)
+	go exportService.Start(backgroundCtx)
+	go vehicleService.Start(backgroundCtx)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	appLogger.Info().Msg("shutting down server")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -89,6 +223,22 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		appLogger.Error().Err(err).Msg("server forced to shutdown")
 	}
+	if grpcServer != nil {
+		// WatchEvents держит потоки бесконечно, поэтому мягкую остановку ограничиваем тем же таймаутом
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.Error().Err(err).Msg("failed to flush traces")
+	}
 
 	appLogger.Info().Msg("server exited")
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/db"
+)
+
+// runMigrate выполняет подкоманду `migrate up|down [N]|status|backfill-confidence [batch]`
+func runMigrate(cfg *config.Config, log zerolog.Logger, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [N]|status|backfill-confidence [batch]")
+	}
+
+	database, err := db.Open(cfg, log)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	migrator := db.NewMigrator(database, log)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info().Ints("versions", applied).Msg("migrations applied")
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Info().Ints("versions", reverted).Msg("migrations rolled back")
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	case "backfill-confidence":
+		batch := 1000
+		if len(args) > 1 {
+			batch, err = strconv.Atoi(args[1])
+			if err != nil || batch < 1 {
+				return fmt.Errorf("invalid batch size: %s", args[1])
+			}
+		}
+		updated, err := db.BackfillConfidence(ctx, database, batch, log)
+		if err != nil {
+			return err
+		}
+		log.Info().Int64("updated", updated).Msg("confidence backfill finished")
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+	return nil
+}
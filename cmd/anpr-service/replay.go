@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"anpr-service/internal/capture"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/simulator"
+)
+
+// Заголовки, которые не переносятся при повторной отправке
+var replaySkipHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Connection":        true,
+	"Transfer-Encoding": true,
+	// Сохранённая подпись отклоняется как повтор, ключ API в записи скрыт
+	"X-Signature":           true,
+	"X-Signature-Timestamp": true,
+	"X-Api-Key":             true,
+}
+
+// runReplay выполняет подкоманду `replay`: повторно отправляет сохранённые запросы
+// в работающий сервис (-target) или прогоняет их через парсер камеры (-parse)
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of a running instance")
+	parseOnly := fs.Bool("parse", false, "run captures through the vendor parser instead of posting them")
+	delay := fs.Duration("delay", 0, "pause between requests")
+	timezone := fs.String("timezone", "Asia/Almaty", "camera time zone for timestamps without offset (-parse)")
+	apiKey := fs.String("api-key", "", "camera API key sent as X-API-Key instead of the redacted one")
+	hmacSecret := fs.String("hmac-secret", "", "re-sign request bodies like an edge relay (one of INGEST_HMAC_SECRETS)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: replay [-target URL [-api-key K] [-hmac-secret S] | -parse [-timezone TZ]] [-delay D] <capture file or dir>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no capture files given")
+	}
+
+	loc, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	files, err := expandCaptures(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	failed := 0
+	for i, file := range files {
+		if i > 0 && *delay > 0 {
+			time.Sleep(*delay)
+		}
+		var err error
+		if *parseOnly {
+			err = replayParse(file, loc)
+		} else {
+			err = replayPost(client, *target, file, *apiKey, *hmacSecret)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d captures failed", failed, len(files))
+	}
+	return nil
+}
+
+func expandCaptures(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		dirFiles, err := capture.List(p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+func replayPost(client *http.Client, target, file, apiKey, hmacSecret string) error {
+	captured, body, err := capture.Load(file)
+	if err != nil {
+		return err
+	}
+
+	// Скрытый при записи ключ из строки запроса только помешал бы проверке
+	query := captured.URL.Query()
+	query.Del("api_key")
+	uri := *captured.URL
+	uri.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(captured.Method, strings.TrimRight(target, "/")+uri.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range captured.Header {
+		if replaySkipHeaders[name] || strings.HasPrefix(name, "X-Capture-") {
+			continue
+		}
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	simulator.Authenticate(req, body, apiKey, hmacSecret)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	fmt.Printf("%s -> %d %s\n", file, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	return nil
+}
+
+func replayParse(file string, loc *time.Location) error {
+	captured, body, err := capture.Load(file)
+	if err != nil {
+		return err
+	}
+
+	var payload anpr.EventPayload
+	var timeErr error
+	var kind string
+	switch {
+	case strings.HasSuffix(captured.URL.Path, "/anpr/hikvision"):
+		if err := captured.ParseMultipartForm(32 << 20); err != nil {
+			return fmt.Errorf("parse multipart: %w", err)
+		}
+		xmlPayload, err := hikvision.ExtractXMLPayload(captured.MultipartForm)
+		if err != nil {
+			return fmt.Errorf("extract xml: %w", err)
+		}
+		event, err := hikvision.Parse(xmlPayload)
+		if err != nil {
+			return fmt.Errorf("parse xml: %w", err)
+		}
+		kind = event.Kind()
+		payload, timeErr = event.ToEventPayload(nil, loc)
+		payload.RawPayload = nil
+	case strings.HasSuffix(captured.URL.Path, "/anpr/events"):
+		raw := body
+		if strings.Contains(captured.Header.Get("Content-Type"), "multipart/form-data") {
+			if err := captured.ParseMultipartForm(32 << 20); err != nil {
+				return fmt.Errorf("parse multipart: %w", err)
+			}
+			values := captured.MultipartForm.Value["event"]
+			if len(values) == 0 {
+				return errors.New("event field not found in multipart form")
+			}
+			raw = []byte(values[0])
+		}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return fmt.Errorf("parse json: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported capture path: %s", captured.URL.Path)
+	}
+
+	out := struct {
+		File    string            `json:"file"`
+		Camera  string            `json:"captured_camera,omitempty"`
+		Status  string            `json:"captured_status,omitempty"`
+		Kind    string            `json:"kind,omitempty"`
+		Payload anpr.EventPayload `json:"payload"`
+		// TimeError - время события не разобрано; приём взял бы время получения
+		TimeError string `json:"time_error,omitempty"`
+	}{
+		File:    file,
+		Camera:  captured.Header.Get(capture.HeaderCamera),
+		Status:  captured.Header.Get(capture.HeaderStatus),
+		Kind:    kind,
+		Payload: payload,
+	}
+	if timeErr != nil {
+		out.TimeError = timeErr.Error()
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
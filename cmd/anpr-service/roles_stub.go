@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"anpr-service/internal/roles"
+)
+
+// runRolesStub выполняет подкоманду `roles-stub`: локальная замена API ТС сервиса roles
+// для разработки синхронизации реестра (VEHICLE_SYNC_ENABLED + ROLES_API_URL)
+func runRolesStub(args []string) error {
+	fs := flag.NewFlagSet("roles-stub", flag.ContinueOnError)
+	addr := fs.String("addr", ":8091", "listen address")
+	file := fs.String("file", "vehicles.json", "JSON array of vehicles to serve; re-read on every request")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: roles-stub [-addr ADDR] [-file vehicles.json]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := os.Stat(*file); err != nil {
+		return fmt.Errorf("vehicles file: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "serving %s on %s%s\n", *file, *addr, roles.VehiclesPath)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           roles.NewStubHandler(*file),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"anpr-service/internal/simulator"
+)
+
+// runSimulate выполняет подкоманду `simulate`: эмулирует N камер Hikvision и печатает отчёт
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of a running instance")
+	cameras := fs.Int("cameras", 4, "number of emulated cameras (even - entry, odd - exit)")
+	rate := fs.Float64("rate", 1, "events per second per camera")
+	duration := fs.Duration("duration", time.Minute, "how long to generate traffic")
+	poolSize := fs.Int("pool", 200, "number of generated regular plates")
+	platesFile := fs.String("plates", "", "file with regular plates, one per line (overrides -pool)")
+	blacklist := fs.String("blacklist", "", "comma-separated blacklisted plates to mix in")
+	whitelist := fs.String("whitelist", "", "comma-separated whitelisted plates to mix in")
+	listShare := fs.Float64("list-share", 0.1, "share of passages using blacklisted/whitelisted plates")
+	misread := fs.Float64("misread", 0.05, "probability of a misread character")
+	duplicates := fs.Float64("duplicates", 0.05, "probability of a duplicate burst")
+	burst := fs.Int("burst", 3, "copies in a duplicate burst")
+	exitShare := fs.Float64("exit", 0.8, "share of entries followed by an exit on the paired camera")
+	dwell := fs.Duration("dwell", 30*time.Second, "mean time between entry and exit")
+	concurrency := fs.Int("concurrency", 32, "max in-flight requests")
+	pictures := fs.Int("pictures", 2, "JPEG pictures per event")
+	pictureSize := fs.String("picture-size", "640x360", "JPEG dimensions WxH")
+	timeout := fs.Duration("timeout", 10*time.Second, "per-request timeout")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "random seed")
+	apiKey := fs.String("api-key", "", "camera API key sent as X-API-Key")
+	hmacSecret := fs.String("hmac-secret", "", "sign request bodies like an edge relay (one of INGEST_HMAC_SECRETS)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+
+	var regular []string
+	if *platesFile != "" {
+		var err error
+		if regular, err = readPlates(*platesFile); err != nil {
+			return err
+		}
+	} else {
+		regular = simulator.GeneratePlates(rng, *poolSize)
+	}
+	listed := append(splitPlates(*blacklist), splitPlates(*whitelist)...)
+
+	var width, height int
+	if _, err := fmt.Sscanf(*pictureSize, "%dx%d", &width, &height); err != nil || width < 1 || height < 1 {
+		return fmt.Errorf("invalid picture size: %s", *pictureSize)
+	}
+	pics := make(map[string][]byte, *pictures)
+	for i := 0; i < *pictures; i++ {
+		jpg, err := simulator.NoiseJPEG(rng, width, height)
+		if err != nil {
+			return fmt.Errorf("generate jpeg: %w", err)
+		}
+		name := "detectionPicture.jpg"
+		if i == 0 {
+			name = "licensePlatePicture.jpg"
+		} else if i > 1 {
+			name = fmt.Sprintf("detectionPicture%d.jpg", i)
+		}
+		pics[name] = jpg
+	}
+
+	cfg := simulator.Config{
+		Target:   *target,
+		Cameras:  *cameras,
+		Rate:     *rate,
+		Duration: *duration,
+		Pool: simulator.PlatePool{
+			Regular:   regular,
+			Listed:    listed,
+			ListShare: *listShare,
+		},
+		MisreadProbability:   *misread,
+		DuplicateProbability: *duplicates,
+		BurstSize:            *burst,
+		ExitProbability:      *exitShare,
+		Dwell:                *dwell,
+		Concurrency:          *concurrency,
+		Pictures:             pics,
+		Seed:                 *seed,
+		APIKey:               *apiKey,
+		HMACSecret:           *hmacSecret,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "simulating %d cameras at %.2f ev/s each for %s against %s (seed %d)\n",
+		cfg.Cameras, cfg.Rate, cfg.Duration, cfg.Target, *seed)
+
+	report, err := simulator.Run(ctx, cfg, &http.Client{Timeout: *timeout})
+	if err != nil {
+		return err
+	}
+	printReport(report)
+
+	if report.Sent > 0 && report.Succeeded == 0 {
+		return errors.New("all requests failed")
+	}
+	return nil
+}
+
+func printReport(r *simulator.Report) {
+	fmt.Printf("elapsed:      %s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Printf("sent:         %d (%.1f req/s)\n", r.Sent, r.Throughput)
+	fmt.Printf("succeeded:    %d\n", r.Succeeded)
+	fmt.Printf("failed:       %d\n", r.Failed)
+	fmt.Printf("misreads:     %d\n", r.Misreads)
+	fmt.Printf("dup bursts:   %d\n", r.Duplicates)
+	fmt.Printf("exits:        %d\n", r.Exits)
+	fmt.Printf("latency:      p50=%s p90=%s p95=%s p99=%s max=%s\n",
+		r.Latency["p50"], r.Latency["p90"], r.Latency["p95"], r.Latency["p99"], r.Latency["max"])
+
+	statuses := make([]int, 0, len(r.Statuses))
+	for s := range r.Statuses {
+		statuses = append(statuses, s)
+	}
+	sort.Ints(statuses)
+	for _, s := range statuses {
+		fmt.Printf("status %d:   %d\n", s, r.Statuses[s])
+	}
+	for kind, n := range r.Errors {
+		fmt.Printf("error:        %d x %s\n", n, kind)
+	}
+}
+
+func readPlates(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var plates []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			plates = append(plates, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(plates) == 0 {
+		return nil, fmt.Errorf("no plates in %s", path)
+	}
+	return plates, nil
+}
+
+func splitPlates(value string) []string {
+	var plates []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			plates = append(plates, p)
+		}
+	}
+	return plates
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"anpr-service/internal/config"
+)
+
+// serverTLSConfig - TLS HTTP-сервера; nil - сервер работает без TLS. С HTTP_TLS_CLIENT_CA_FILE
+// клиентский сертификат проверяется, если предъявлен: камеры с сертификатом подтверждаются mTLS,
+// остальные клиенты (API с JWT, камеры с ключом) подключаются без него.
+func serverTLSConfig(cfg config.HTTPConfig) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caPEM, err := os.ReadFile(cfg.TLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("client CA file contains no PEM certificates")
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	return tlsConfig, nil
+}
@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	FormatNDJSONGzip = "ndjson.gz"
+
+	ManifestVersion = 1
+)
+
+// Manifest описывает архивный файл и хранится рядом с ним
+type Manifest struct {
+	Version      int        `json:"version"`
+	ArchiveID    string     `json:"archive_id"`
+	Format       string     `json:"format"`
+	DataKey      string     `json:"data_key"`
+	Reason       string     `json:"reason"`
+	RangeFrom    *time.Time `json:"range_from,omitempty"`
+	RangeTo      *time.Time `json:"range_to,omitempty"`
+	MinEventTime *time.Time `json:"min_event_time,omitempty"`
+	MaxEventTime *time.Time `json:"max_event_time,omitempty"`
+	EventCount   int64      `json:"event_count"`
+	SizeBytes    int64      `json:"size_bytes"`
+	SHA256       string     `json:"sha256"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Writer пишет записи в gzip-сжатый NDJSON во временный файл,
+// попутно считая количество строк и контрольную сумму сжатых данных.
+type Writer struct {
+	file  *os.File
+	hash  hash.Hash
+	gz    *gzip.Writer
+	buf   *bufio.Writer
+	enc   *json.Encoder
+	count int64
+	size  int64
+}
+
+func NewWriter(tempDir string) (*Writer, error) {
+	file, err := os.CreateTemp(tempDir, "anpr-archive-*.ndjson.gz")
+	if err != nil {
+		return nil, fmt.Errorf("create archive temp file: %w", err)
+	}
+	h := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(file, h))
+	buf := bufio.NewWriterSize(gz, 256<<10)
+	return &Writer{
+		file: file,
+		hash: h,
+		gz:   gz,
+		buf:  buf,
+		enc:  json.NewEncoder(buf),
+	}, nil
+}
+
+func (w *Writer) Write(record interface{}) error {
+	if err := w.enc.Encode(record); err != nil {
+		return fmt.Errorf("encode archive record: %w", err)
+	}
+	w.count++
+	return nil
+}
+
+func (w *Writer) Count() int64 {
+	return w.count
+}
+
+// Close завершает сжатие и возвращает контрольную сумму и размер файла
+func (w *Writer) Close() (string, int64, error) {
+	if err := w.buf.Flush(); err != nil {
+		return "", 0, err
+	}
+	if err := w.gz.Close(); err != nil {
+		return "", 0, err
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	w.size = info.Size()
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(w.hash.Sum(nil)), w.size, nil
+}
+
+// File возвращает временный файл; после Close он открыт и перемотан в начало
+func (w *Writer) File() *os.File {
+	return w.file
+}
+
+// Remove закрывает и удаляет временный файл
+func (w *Writer) Remove() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// Reader читает NDJSON архив и проверяет контрольную сумму после чтения всех записей
+type Reader struct {
+	hash     hash.Hash
+	expected string
+	gz       *gzip.Reader
+	dec      *json.Decoder
+	src      io.Reader
+}
+
+func NewReader(r io.Reader, expectedSHA256 string) (*Reader, error) {
+	h := sha256.New()
+	src := io.TeeReader(r, h)
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, fmt.Errorf("open archive gzip: %w", err)
+	}
+	return &Reader{
+		hash:     h,
+		expected: expectedSHA256,
+		gz:       gz,
+		dec:      json.NewDecoder(gz),
+		src:      src,
+	}, nil
+}
+
+// Next декодирует следующую запись; по окончании возвращает io.EOF
+// или ошибку, если контрольная сумма не совпала.
+func (r *Reader) Next(record interface{}) error {
+	err := r.dec.Decode(record)
+	if err == io.EOF {
+		// Дочитываем хвост gzip-потока, чтобы хэш покрыл весь файл
+		if _, err := io.Copy(io.Discard, r.src); err != nil {
+			return err
+		}
+		if r.expected != "" && hex.EncodeToString(r.hash.Sum(nil)) != r.expected {
+			return fmt.Errorf("archive checksum mismatch")
+		}
+		return io.EOF
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testRecord struct {
+	ID    int    `json:"id"`
+	Plate string `json:"plate"`
+}
+
+func TestWriterReaderRoundTrip(t *testing.T) {
+	w, err := NewWriter(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	defer w.Remove()
+
+	for i, plate := range []string{"123ABC02", "777KZ01", "001AAA05"} {
+		if err := w.Write(testRecord{ID: i, Plate: plate}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	checksum, size, err := w.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if size == 0 || checksum == "" {
+		t.Fatalf("expected non-empty archive, got size=%d checksum=%q", size, checksum)
+	}
+
+	data, err := io.ReadAll(w.File())
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(data), checksum)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	var got []testRecord
+	for {
+		var rec testRecord
+		err := r.Next(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, rec)
+	}
+	if len(got) != 3 || got[1].Plate != "777KZ01" {
+		t.Fatalf("unexpected records: %+v", got)
+	}
+
+	r, err = NewReader(bytes.NewReader(data), "deadbeef")
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	for {
+		var rec testRecord
+		err = r.Next(&rec)
+		if err != nil {
+			break
+		}
+	}
+	if errors.Is(err, io.EOF) || err == nil {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
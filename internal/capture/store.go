@@ -0,0 +1,221 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/utils"
+)
+
+const (
+	ModeAll    = "all"
+	ModeFailed = "failed"
+
+	fileExt = ".http"
+
+	// Служебные заголовки, которые добавляются к сохранённому запросу
+	HeaderCamera    = "X-Capture-Camera"
+	HeaderRemote    = "X-Capture-Remote"
+	HeaderStatus    = "X-Capture-Status"
+	HeaderTime      = "X-Capture-Time"
+	HeaderTruncated = "X-Capture-Truncated"
+)
+
+// Заголовки с секретами не сохраняются на диск
+var redactedHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+
+// redactedParams - параметры строки запроса с секретами (ключ API камеры)
+var redactedParams = []string{"api_key"}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Meta - сведения о запросе, известные после его обработки
+type Meta struct {
+	CameraID   string
+	RemoteAddr string
+	Status     int
+	Truncated  bool
+	Time       time.Time
+}
+
+// Store пишет входящие запросы в формате HTTP/1.1 в директорию, удаляя самые старые
+// файлы при превышении MaxFiles или MaxBytes
+type Store struct {
+	dir      string
+	mode     string
+	cameras  map[string]bool
+	maxFiles int
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+func NewStore(cfg config.CaptureConfig) (*Store, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create capture dir: %w", err)
+	}
+	cameras := make(map[string]bool, len(cfg.Cameras))
+	for _, c := range cfg.Cameras {
+		if c = strings.TrimSpace(c); c != "" {
+			cameras[c] = true
+		}
+	}
+	return &Store{
+		dir:      cfg.Dir,
+		mode:     cfg.Mode,
+		cameras:  cameras,
+		maxFiles: cfg.MaxFiles,
+		maxBytes: cfg.MaxBytes,
+	}, nil
+}
+
+// ShouldCapture проверяет фильтр камер (camera_id или IP клиента) и режим all/failed
+func (s *Store) ShouldCapture(cameraID, clientIP string, status int) bool {
+	if s.mode == ModeFailed && status < http.StatusBadRequest {
+		return false
+	}
+	if len(s.cameras) == 0 {
+		return true
+	}
+	return s.cameras[cameraID] || s.cameras[clientIP]
+}
+
+// Save сохраняет запрос целиком: строка запроса, заголовки и тело
+func (s *Store) Save(r *http.Request, body []byte, meta Meta) (string, error) {
+	if meta.Time.IsZero() {
+		meta.Time = time.Now()
+	}
+
+	var buf bytes.Buffer
+	target := *r.URL
+	target.RawQuery = utils.RedactQuery(target.RawQuery, redactedParams...)
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", r.Method, target.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", r.Host)
+
+	header := r.Header.Clone()
+	for _, h := range redactedHeaders {
+		if header.Get(h) != "" {
+			header.Set(h, "REDACTED")
+		}
+	}
+	header.Del("Content-Length")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set(HeaderCamera, meta.CameraID)
+	header.Set(HeaderRemote, meta.RemoteAddr)
+	header.Set(HeaderStatus, strconv.Itoa(meta.Status))
+	header.Set(HeaderTime, meta.Time.UTC().Format(time.RFC3339Nano))
+	if meta.Truncated {
+		header.Set(HeaderTruncated, "true")
+	}
+	if err := header.Write(&buf); err != nil {
+		return "", fmt.Errorf("write headers: %w", err)
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	camera := meta.CameraID
+	if camera == "" {
+		camera = "unknown"
+	}
+	// Имя начинается с времени UTC, поэтому лексикографический порядок совпадает с хронологическим
+	name := fmt.Sprintf("%s_%s_%s%s",
+		meta.Time.UTC().Format("20060102T150405.000000000Z"),
+		unsafeChars.ReplaceAllString(camera, "_"),
+		uuid.NewString()[:8],
+		fileExt,
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o640); err != nil {
+		return "", fmt.Errorf("write capture: %w", err)
+	}
+	if err := s.rotate(); err != nil {
+		return path, fmt.Errorf("rotate captures: %w", err)
+	}
+	return path, nil
+}
+
+func (s *Store) rotate() error {
+	files, err := List(s.dir)
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		sizes[i] = info.Size()
+		total += sizes[i]
+	}
+
+	for i := 0; i < len(files); i++ {
+		remaining := len(files) - i
+		overFiles := s.maxFiles > 0 && remaining > s.maxFiles
+		overBytes := s.maxBytes > 0 && total > s.maxBytes && remaining > 1
+		if !overFiles && !overBytes {
+			break
+		}
+		if err := os.Remove(files[i]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= sizes[i]
+	}
+	return nil
+}
+
+// List возвращает сохранённые запросы в директории от старых к новым
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileExt) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Load читает сохранённый запрос; тело возвращается отдельно и уже прочитано
+func Load(path string) (*http.Request, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	req, err := http.ReadRequest(bufio.NewReader(f))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse captured request: %w", err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read captured body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return req, body, nil
+}
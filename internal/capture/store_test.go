@@ -0,0 +1,86 @@
+package capture
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"anpr-service/internal/config"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	store, err := NewStore(config.CaptureConfig{Dir: t.TempDir(), Mode: ModeAll})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := "--b\r\nContent-Disposition: form-data; name=\"anpr.xml\"\r\n\r\n<palteTypeByGAT>1</palteTypeByGAT>\r\n--b--\r\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anpr/hikvision?camera_id=cam-1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	path, err := store.Save(req, []byte(body), Meta{CameraID: "cam-1", Status: 400})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, loadedBody, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Method != http.MethodPost || loaded.URL.RequestURI() != "/api/v1/anpr/hikvision?camera_id=cam-1" {
+		t.Fatalf("unexpected request line: %s %s", loaded.Method, loaded.URL.RequestURI())
+	}
+	if string(loadedBody) != body {
+		t.Fatalf("body mismatch: %q", loadedBody)
+	}
+	if got := loaded.Header.Get("Authorization"); got != "REDACTED" {
+		t.Fatalf("authorization must be redacted, got %q", got)
+	}
+	if loaded.Header.Get(HeaderCamera) != "cam-1" || loaded.Header.Get(HeaderStatus) != "400" {
+		t.Fatalf("capture meta headers missing: %v", loaded.Header)
+	}
+}
+
+func TestRotateKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(config.CaptureConfig{Dir: dir, Mode: ModeAll, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/anpr/events", nil)
+		path, err := store.Save(req, []byte("{}"), Meta{CameraID: "cam", Time: base.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	files, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != paths[2] || files[1] != paths[3] {
+		t.Fatalf("expected two newest captures, got %v", files)
+	}
+}
+
+func TestShouldCapture(t *testing.T) {
+	store := &Store{mode: ModeFailed, cameras: map[string]bool{"cam-1": true, "10.0.0.5": true}}
+
+	if store.ShouldCapture("cam-1", "", http.StatusCreated) {
+		t.Fatal("failed mode must skip successful requests")
+	}
+	if !store.ShouldCapture("", "10.0.0.5", http.StatusBadRequest) {
+		t.Fatal("camera must match by client IP")
+	}
+	if store.ShouldCapture("cam-2", "10.0.0.6", http.StatusBadRequest) {
+		t.Fatal("unlisted camera must not be captured")
+	}
+}
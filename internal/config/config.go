@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -10,6 +12,21 @@ import (
 type HTTPConfig struct {
 	Host string
 	Port int
+	// TrustedProxies - адреса и подсети прокси, которым доверяется X-Forwarded-For;
+	// пусто - адрес клиента берётся из соединения
+	TrustedProxies []string
+	// TLS сервера; TLSClientCAFile - CA клиентских сертификатов камер (mTLS), сертификат необязателен
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+}
+
+type GRPCConfig struct {
+	Enabled bool
+	Port    int
+
+	// IngestRoles - роли JWT, которым разрешён приём событий ProcessEvent (сервисные учётные записи)
+	IngestRoles []string
 }
 
 type DBConfig struct {
@@ -17,6 +34,8 @@ type DBConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// AutoMigrate - применять миграции при старте сервиса
+	AutoMigrate bool
 }
 
 type AuthConfig struct {
@@ -30,12 +49,174 @@ type CameraConfig struct {
 	HikConnect string
 }
 
+type RetentionConfig struct {
+	Enabled               bool
+	Interval              time.Duration
+	InitialDelay          time.Duration
+	DefaultEventDays      int
+	DefaultRawPayloadDays int
+	DefaultSnapshotDays   int
+}
+
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+type ArchiveConfig struct {
+	// BeforeDelete - архивировать события перед любым удалением: политиками хранения,
+	// удалением секций и ручной очисткой
+	BeforeDelete bool
+	Store        string
+	LocalDir     string
+	TempDir      string
+	Prefix       string
+	S3           S3Config
+}
+
+type PartitionConfig struct {
+	Enabled bool
+	// Interval - размер секции: day или month
+	Interval string
+	// Premake - сколько будущих периодов создавать заранее
+	Premake       int
+	CheckInterval time.Duration
+	// ExpiredAction - что делать с секцией за сроком хранения: drop или detach
+	ExpiredAction string
+}
+
+type TracingConfig struct {
+	// Exporter - none, otlp или stdout
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	SampleRatio  float64
+	ServiceName  string
+}
+
+type LoggingConfig struct {
+	// IngestSampleEvery - писать access-лог для каждого N-го успешного запроса приёма событий
+	IngestSampleEvery int
+}
+
+type CaptureConfig struct {
+	Enabled bool
+	Dir     string
+	// Cameras - camera_id или IP камер, запросы которых сохраняются; пусто - все
+	Cameras []string
+	// Mode - all (все запросы) или failed (только отклонённые)
+	Mode     string
+	MaxFiles int
+	MaxBytes int64
+}
+
+type ExportConfig struct {
+	// Prefix - префикс ключей файлов фоновых выгрузок в хранилище архивов
+	Prefix        string
+	MaxConcurrent int
+	// TTL - сколько хранится готовый файл выгрузки
+	TTL        time.Duration
+	JobTimeout time.Duration
+	// SyncMaxRows - максимум событий для прямой выгрузки; больше - только фоновой задачей
+	SyncMaxRows int64
+}
+
+type AnomalyConfig struct {
+	Enabled bool
+	// MinObservations - сколько раз атрибут должен наблюдаться у номера, чтобы профилю можно было доверять
+	MinObservations int64
+	// DominantShare - доля самого частого значения атрибута, начиная с которой оно считается устойчивым
+	DominantShare float64
+	// MinMismatches - сколько атрибутов должно противоречить профилю для аномалии
+	MinMismatches int
+	// MaxSpeedKMH - скорость между камерами, выше которой перемещение считается невозможным
+	MaxSpeedKMH float64
+	// MinDistanceMeters - расстояние между камерами, ниже которого перемещение не проверяется
+	MinDistanceMeters float64
+	// TravelWindow - насколько далеко в прошлом искать предыдущее событие номера
+	TravelWindow time.Duration
+}
+
+type VehicleSyncConfig struct {
+	// Enabled - периодически сверять зеркало ТС с сервисом roles
+	Enabled    bool
+	RolesURL   string
+	RolesToken string
+	Interval   time.Duration
+	PageSize   int
+	Timeout    time.Duration
+	// MaxRemoveShare - доля зеркала, которую плановая сверка может удалить за раз;
+	// защищает whitelist от пустого или усечённого ответа roles; 0 - без ограничения
+	MaxRemoveShare float64
+}
+
+type ReviewConfig struct {
+	// DefaultEnabled - ставить номера вне списков на проверку на полигонах без своей политики
+	DefaultEnabled bool
+}
+
+// ConfidenceConfig - пороги уверенности (0–1) для камер без своих порогов
+type ConfidenceConfig struct {
+	// ReviewBelow - прочтения ниже порога принимаются, но помечаются на проверку
+	ReviewBelow float64
+	// UnverifiedBelow - прочтения ниже порога сохраняются без сверки со списками
+	UnverifiedBelow float64
+	// MatchCandidates - сколько альтернативных прочтений сверяется со списками; 0 - только основное
+	MatchCandidates int
+}
+
+// ClockConfig - время событий камер и их связь с сервером
+type ClockConfig struct {
+	// DefaultTimezone - часовой пояс (IANA) времени без смещения для камер без своего пояса
+	DefaultTimezone string
+	// SkewThreshold - расхождение часов камеры с сервером, начиная с которого камера считается сбитой
+	SkewThreshold time.Duration
+	// OfflineAfter - камера без heartbeat и событий дольше этого срока считается недоступной
+	OfflineAfter time.Duration
+}
+
+// IngestConfig - ограничения публичного приёма событий
+type IngestConfig struct {
+	// MaxBodyBytes - максимальный размер тела запроса приёма; больше - 413 до разбора
+	MaxBodyBytes int64
+	// IPRate и IPBurst - запросов в секунду и запас token bucket на адрес клиента; 0 - без ограничения
+	IPRate  float64
+	IPBurst int
+	// CameraRate и CameraBurst - то же на camera_id
+	CameraRate  float64
+	CameraBurst int
+	// AuthPolicy - что делать с событием без подтверждённого источника: off (принять как есть),
+	// untrusted (сохранить без сверки со списками) или reject (401)
+	AuthPolicy string
+	// HMACSecrets - секреты подписи тел пограничных ретрансляторов; несколько - на время ротации
+	HMACSecrets []string
+	// SignatureMaxAge - насколько подпись может быть старше времени получения
+	SignatureMaxAge time.Duration
+}
+
 type Config struct {
 	Environment              string
 	HTTP                     HTTPConfig
+	GRPC                     GRPCConfig
 	DB                       DBConfig
 	Auth                     AuthConfig
 	Camera                   CameraConfig
+	Retention                RetentionConfig
+	Archive                  ArchiveConfig
+	Partition                PartitionConfig
+	Tracing                  TracingConfig
+	Logging                  LoggingConfig
+	Capture                  CaptureConfig
+	Export                   ExportConfig
+	Anomaly                  AnomalyConfig
+	VehicleSync              VehicleSyncConfig
+	Review                   ReviewConfig
+	Confidence               ConfidenceConfig
+	Clock                    ClockConfig
+	Ingest                   IngestConfig
 	EnableSnowVolumeAnalysis bool
 }
 
@@ -50,19 +231,87 @@ func Load() (*Config, error) {
 
 	v.AutomaticEnv()
 
+	v.SetDefault("GRPC_ENABLED", true)
+	v.SetDefault("GRPC_PORT", 9090)
+	v.SetDefault("GRPC_INGEST_ROLES", "AKIMAT_ADMIN")
+	v.SetDefault("DB_AUTO_MIGRATE", true)
+	v.SetDefault("RETENTION_ENABLED", true)
+	v.SetDefault("RETENTION_INTERVAL", 6*time.Hour)
+	v.SetDefault("RETENTION_INITIAL_DELAY", time.Minute)
+	v.SetDefault("RETENTION_DEFAULT_EVENT_DAYS", 3)
+	v.SetDefault("ARCHIVE_BEFORE_DELETE", true)
+	v.SetDefault("ARCHIVE_STORE", "local")
+	v.SetDefault("ARCHIVE_LOCAL_DIR", "./data/archive")
+	v.SetDefault("ARCHIVE_PREFIX", "anpr-events")
+	v.SetDefault("PARTITION_MANAGER_ENABLED", true)
+	v.SetDefault("PARTITION_INTERVAL", "day")
+	v.SetDefault("PARTITION_PREMAKE", 7)
+	v.SetDefault("PARTITION_CHECK_INTERVAL", time.Hour)
+	v.SetDefault("PARTITION_EXPIRED_ACTION", "drop")
+	v.SetDefault("LOG_INGEST_SAMPLE_EVERY", 1)
+	v.SetDefault("CAPTURE_DIR", "./data/capture")
+	v.SetDefault("CAPTURE_MODE", "all")
+	v.SetDefault("CAPTURE_MAX_FILES", 1000)
+	v.SetDefault("CAPTURE_MAX_BYTES", 512<<20)
+	v.SetDefault("EXPORT_PREFIX", "anpr-exports")
+	v.SetDefault("EXPORT_MAX_CONCURRENT", 2)
+	v.SetDefault("EXPORT_TTL", 24*time.Hour)
+	v.SetDefault("EXPORT_JOB_TIMEOUT", time.Hour)
+	v.SetDefault("EXPORT_SYNC_MAX_ROWS", 100000)
+	v.SetDefault("ANOMALY_DETECTION_ENABLED", true)
+	v.SetDefault("ANOMALY_MIN_OBSERVATIONS", 5)
+	v.SetDefault("ANOMALY_DOMINANT_SHARE", 0.8)
+	v.SetDefault("ANOMALY_MIN_MISMATCHES", 2)
+	v.SetDefault("ANOMALY_MAX_SPEED_KMH", 150.0)
+	v.SetDefault("ANOMALY_MIN_DISTANCE_METERS", 1000.0)
+	v.SetDefault("ANOMALY_TRAVEL_WINDOW", 6*time.Hour)
+	v.SetDefault("CONFIDENCE_REVIEW_BELOW", 0.8)
+	v.SetDefault("CONFIDENCE_UNVERIFIED_BELOW", 0.5)
+	v.SetDefault("CONFIDENCE_MATCH_CANDIDATES", 3)
+	v.SetDefault("CAMERA_DEFAULT_TIMEZONE", "Asia/Almaty")
+	v.SetDefault("CAMERA_CLOCK_SKEW_THRESHOLD", 2*time.Minute)
+	v.SetDefault("CAMERA_OFFLINE_AFTER", 5*time.Minute)
+	v.SetDefault("INGEST_MAX_BODY_BYTES", 8<<20)
+	v.SetDefault("INGEST_IP_RATE", 20)
+	v.SetDefault("INGEST_IP_BURST", 40)
+	v.SetDefault("INGEST_CAMERA_RATE", 5)
+	v.SetDefault("INGEST_CAMERA_BURST", 20)
+	v.SetDefault("INGEST_AUTH_POLICY", "off")
+	v.SetDefault("INGEST_SIGNATURE_MAX_AGE", 5*time.Minute)
+	v.SetDefault("VEHICLE_SYNC_INTERVAL", 10*time.Minute)
+	v.SetDefault("VEHICLE_SYNC_PAGE_SIZE", 500)
+	v.SetDefault("VEHICLE_SYNC_TIMEOUT", 30*time.Second)
+	v.SetDefault("VEHICLE_SYNC_MAX_REMOVE_SHARE", 0.5)
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4318")
+	v.SetDefault("TRACING_OTLP_INSECURE", true)
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("TRACING_SERVICE_NAME", "anpr-service")
+
 	_ = v.ReadInConfig()
 
 	cfg := &Config{
 		Environment: v.GetString("APP_ENV"),
 		HTTP: HTTPConfig{
-			Host: v.GetString("HTTP_HOST"),
-			Port: v.GetInt("HTTP_PORT"),
+			Host:            v.GetString("HTTP_HOST"),
+			Port:            v.GetInt("HTTP_PORT"),
+			TrustedProxies:  splitList(v.GetString("HTTP_TRUSTED_PROXIES")),
+			TLSCertFile:     v.GetString("HTTP_TLS_CERT_FILE"),
+			TLSKeyFile:      v.GetString("HTTP_TLS_KEY_FILE"),
+			TLSClientCAFile: v.GetString("HTTP_TLS_CLIENT_CA_FILE"),
+		},
+		GRPC: GRPCConfig{
+			Enabled: v.GetBool("GRPC_ENABLED"),
+			Port:    v.GetInt("GRPC_PORT"),
+
+			IngestRoles: splitList(v.GetString("GRPC_INGEST_ROLES")),
 		},
 		DB: DBConfig{
 			DSN:             v.GetString("DB_DSN"),
 			MaxOpenConns:    v.GetInt("DB_MAX_OPEN_CONNS"),
 			MaxIdleConns:    v.GetInt("DB_MAX_IDLE_CONNS"),
 			ConnMaxLifetime: v.GetDuration("DB_CONN_MAX_LIFETIME"),
+			AutoMigrate:     v.GetBool("DB_AUTO_MIGRATE"),
 		},
 		Auth: AuthConfig{
 			AccessSecret: v.GetString("JWT_ACCESS_SECRET"),
@@ -73,6 +322,101 @@ func Load() (*Config, error) {
 			Model:      v.GetString("CAMERA_MODEL"),
 			HikConnect: v.GetString("HIK_CONNECT_DOMAIN"),
 		},
+		Retention: RetentionConfig{
+			Enabled:               v.GetBool("RETENTION_ENABLED"),
+			Interval:              v.GetDuration("RETENTION_INTERVAL"),
+			InitialDelay:          v.GetDuration("RETENTION_INITIAL_DELAY"),
+			DefaultEventDays:      v.GetInt("RETENTION_DEFAULT_EVENT_DAYS"),
+			DefaultRawPayloadDays: v.GetInt("RETENTION_DEFAULT_RAW_PAYLOAD_DAYS"),
+			DefaultSnapshotDays:   v.GetInt("RETENTION_DEFAULT_SNAPSHOT_DAYS"),
+		},
+		Archive: ArchiveConfig{
+			BeforeDelete: v.GetBool("ARCHIVE_BEFORE_DELETE"),
+			Store:        v.GetString("ARCHIVE_STORE"),
+			LocalDir:     v.GetString("ARCHIVE_LOCAL_DIR"),
+			TempDir:      v.GetString("ARCHIVE_TEMP_DIR"),
+			Prefix:       v.GetString("ARCHIVE_PREFIX"),
+			S3: S3Config{
+				Endpoint:  v.GetString("ARCHIVE_S3_ENDPOINT"),
+				Bucket:    v.GetString("ARCHIVE_S3_BUCKET"),
+				Region:    v.GetString("ARCHIVE_S3_REGION"),
+				AccessKey: v.GetString("ARCHIVE_S3_ACCESS_KEY"),
+				SecretKey: v.GetString("ARCHIVE_S3_SECRET_KEY"),
+			},
+		},
+		Partition: PartitionConfig{
+			Enabled:       v.GetBool("PARTITION_MANAGER_ENABLED"),
+			Interval:      v.GetString("PARTITION_INTERVAL"),
+			Premake:       v.GetInt("PARTITION_PREMAKE"),
+			CheckInterval: v.GetDuration("PARTITION_CHECK_INTERVAL"),
+			ExpiredAction: v.GetString("PARTITION_EXPIRED_ACTION"),
+		},
+		Tracing: TracingConfig{
+			Exporter:     v.GetString("TRACING_EXPORTER"),
+			OTLPEndpoint: v.GetString("TRACING_OTLP_ENDPOINT"),
+			OTLPInsecure: v.GetBool("TRACING_OTLP_INSECURE"),
+			SampleRatio:  v.GetFloat64("TRACING_SAMPLE_RATIO"),
+			ServiceName:  v.GetString("TRACING_SERVICE_NAME"),
+		},
+		Logging: LoggingConfig{
+			IngestSampleEvery: v.GetInt("LOG_INGEST_SAMPLE_EVERY"),
+		},
+		Capture: CaptureConfig{
+			Enabled:  v.GetBool("CAPTURE_ENABLED"),
+			Dir:      v.GetString("CAPTURE_DIR"),
+			Cameras:  splitList(v.GetString("CAPTURE_CAMERAS")),
+			Mode:     v.GetString("CAPTURE_MODE"),
+			MaxFiles: v.GetInt("CAPTURE_MAX_FILES"),
+			MaxBytes: v.GetInt64("CAPTURE_MAX_BYTES"),
+		},
+		Export: ExportConfig{
+			Prefix:        v.GetString("EXPORT_PREFIX"),
+			MaxConcurrent: v.GetInt("EXPORT_MAX_CONCURRENT"),
+			TTL:           v.GetDuration("EXPORT_TTL"),
+			JobTimeout:    v.GetDuration("EXPORT_JOB_TIMEOUT"),
+			SyncMaxRows:   v.GetInt64("EXPORT_SYNC_MAX_ROWS"),
+		},
+		Anomaly: AnomalyConfig{
+			Enabled:           v.GetBool("ANOMALY_DETECTION_ENABLED"),
+			MinObservations:   v.GetInt64("ANOMALY_MIN_OBSERVATIONS"),
+			DominantShare:     v.GetFloat64("ANOMALY_DOMINANT_SHARE"),
+			MinMismatches:     v.GetInt("ANOMALY_MIN_MISMATCHES"),
+			MaxSpeedKMH:       v.GetFloat64("ANOMALY_MAX_SPEED_KMH"),
+			MinDistanceMeters: v.GetFloat64("ANOMALY_MIN_DISTANCE_METERS"),
+			TravelWindow:      v.GetDuration("ANOMALY_TRAVEL_WINDOW"),
+		},
+		VehicleSync: VehicleSyncConfig{
+			Enabled:        v.GetBool("VEHICLE_SYNC_ENABLED"),
+			RolesURL:       v.GetString("ROLES_API_URL"),
+			RolesToken:     v.GetString("ROLES_API_TOKEN"),
+			Interval:       v.GetDuration("VEHICLE_SYNC_INTERVAL"),
+			PageSize:       v.GetInt("VEHICLE_SYNC_PAGE_SIZE"),
+			Timeout:        v.GetDuration("VEHICLE_SYNC_TIMEOUT"),
+			MaxRemoveShare: v.GetFloat64("VEHICLE_SYNC_MAX_REMOVE_SHARE"),
+		},
+		Review: ReviewConfig{
+			DefaultEnabled: v.GetBool("REVIEW_UNKNOWN_DEFAULT_ENABLED"),
+		},
+		Confidence: ConfidenceConfig{
+			ReviewBelow:     v.GetFloat64("CONFIDENCE_REVIEW_BELOW"),
+			UnverifiedBelow: v.GetFloat64("CONFIDENCE_UNVERIFIED_BELOW"),
+			MatchCandidates: v.GetInt("CONFIDENCE_MATCH_CANDIDATES"),
+		},
+		Clock: ClockConfig{
+			DefaultTimezone: v.GetString("CAMERA_DEFAULT_TIMEZONE"),
+			SkewThreshold:   v.GetDuration("CAMERA_CLOCK_SKEW_THRESHOLD"),
+			OfflineAfter:    v.GetDuration("CAMERA_OFFLINE_AFTER"),
+		},
+		Ingest: IngestConfig{
+			MaxBodyBytes:    v.GetInt64("INGEST_MAX_BODY_BYTES"),
+			IPRate:          v.GetFloat64("INGEST_IP_RATE"),
+			IPBurst:         v.GetInt("INGEST_IP_BURST"),
+			CameraRate:      v.GetFloat64("INGEST_CAMERA_RATE"),
+			CameraBurst:     v.GetInt("INGEST_CAMERA_BURST"),
+			AuthPolicy:      v.GetString("INGEST_AUTH_POLICY"),
+			HMACSecrets:     splitList(v.GetString("INGEST_HMAC_SECRETS")),
+			SignatureMaxAge: v.GetDuration("INGEST_SIGNATURE_MAX_AGE"),
+		},
 		EnableSnowVolumeAnalysis: v.GetBool("ENABLE_SNOW_VOLUME_ANALYSIS"),
 	}
 
@@ -112,6 +456,138 @@ func validate(cfg *Config) error {
 	if cfg.Auth.AccessSecret == "" {
 		return fmt.Errorf("JWT_ACCESS_SECRET is required")
 	}
+	if cfg.Retention.Enabled && cfg.Retention.Interval <= 0 {
+		return fmt.Errorf("RETENTION_INTERVAL must be positive")
+	}
+	if err := validateArchive(cfg.Archive); err != nil {
+		return err
+	}
+	if cfg.Partition.Interval != "day" && cfg.Partition.Interval != "month" {
+		return fmt.Errorf("PARTITION_INTERVAL must be day or month")
+	}
+	if cfg.Partition.ExpiredAction != "drop" && cfg.Partition.ExpiredAction != "detach" {
+		return fmt.Errorf("PARTITION_EXPIRED_ACTION must be drop or detach")
+	}
+	if cfg.Partition.Enabled && cfg.Partition.CheckInterval <= 0 {
+		return fmt.Errorf("PARTITION_CHECK_INTERVAL must be positive")
+	}
+	if cfg.Logging.IngestSampleEvery < 1 {
+		return fmt.Errorf("LOG_INGEST_SAMPLE_EVERY must be >= 1")
+	}
+	if cfg.Capture.Mode != "all" && cfg.Capture.Mode != "failed" {
+		return fmt.Errorf("CAPTURE_MODE must be all or failed")
+	}
+	if cfg.Export.MaxConcurrent < 1 {
+		return fmt.Errorf("EXPORT_MAX_CONCURRENT must be >= 1")
+	}
+	if cfg.Export.TTL <= 0 || cfg.Export.JobTimeout <= 0 {
+		return fmt.Errorf("EXPORT_TTL and EXPORT_JOB_TIMEOUT must be positive")
+	}
+	if cfg.Anomaly.DominantShare <= 0.5 || cfg.Anomaly.DominantShare > 1 {
+		return fmt.Errorf("ANOMALY_DOMINANT_SHARE must be in (0.5, 1]")
+	}
+	if cfg.Anomaly.MinMismatches < 1 {
+		return fmt.Errorf("ANOMALY_MIN_MISMATCHES must be >= 1")
+	}
+	if cfg.Anomaly.MaxSpeedKMH <= 0 {
+		return fmt.Errorf("ANOMALY_MAX_SPEED_KMH must be positive")
+	}
+	if cfg.Confidence.ReviewBelow < 0 || cfg.Confidence.ReviewBelow > 1 ||
+		cfg.Confidence.UnverifiedBelow < 0 || cfg.Confidence.UnverifiedBelow > 1 {
+		return fmt.Errorf("CONFIDENCE_REVIEW_BELOW and CONFIDENCE_UNVERIFIED_BELOW must be between 0 and 1")
+	}
+	if cfg.Confidence.UnverifiedBelow > cfg.Confidence.ReviewBelow {
+		return fmt.Errorf("CONFIDENCE_UNVERIFIED_BELOW must not exceed CONFIDENCE_REVIEW_BELOW")
+	}
+	if cfg.Confidence.MatchCandidates < 0 || cfg.Confidence.MatchCandidates > 10 {
+		return fmt.Errorf("CONFIDENCE_MATCH_CANDIDATES must be between 0 and 10")
+	}
+	if _, err := time.LoadLocation(cfg.Clock.DefaultTimezone); err != nil {
+		return fmt.Errorf("CAMERA_DEFAULT_TIMEZONE is not a valid IANA time zone: %w", err)
+	}
+	if cfg.Clock.SkewThreshold <= 0 {
+		return fmt.Errorf("CAMERA_CLOCK_SKEW_THRESHOLD must be positive")
+	}
+	if cfg.Clock.OfflineAfter <= 0 {
+		return fmt.Errorf("CAMERA_OFFLINE_AFTER must be positive")
+	}
+	if cfg.Ingest.MaxBodyBytes <= 0 {
+		return fmt.Errorf("INGEST_MAX_BODY_BYTES must be positive")
+	}
+	if cfg.Ingest.IPRate < 0 || cfg.Ingest.CameraRate < 0 {
+		return fmt.Errorf("INGEST_IP_RATE and INGEST_CAMERA_RATE must not be negative")
+	}
+	if (cfg.Ingest.IPRate > 0 && cfg.Ingest.IPBurst < 1) || (cfg.Ingest.CameraRate > 0 && cfg.Ingest.CameraBurst < 1) {
+		return fmt.Errorf("INGEST_IP_BURST and INGEST_CAMERA_BURST must be >= 1")
+	}
+	switch cfg.Ingest.AuthPolicy {
+	case "off", "untrusted", "reject":
+	default:
+		return fmt.Errorf("INGEST_AUTH_POLICY must be off, untrusted or reject")
+	}
+	if cfg.Ingest.SignatureMaxAge <= 0 {
+		return fmt.Errorf("INGEST_SIGNATURE_MAX_AGE must be positive")
+	}
+	if (cfg.HTTP.TLSCertFile == "") != (cfg.HTTP.TLSKeyFile == "") {
+		return fmt.Errorf("HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+	}
+	if cfg.HTTP.TLSClientCAFile != "" && cfg.HTTP.TLSCertFile == "" {
+		return fmt.Errorf("HTTP_TLS_CLIENT_CA_FILE requires HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE")
+	}
+	for _, proxy := range cfg.HTTP.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				return fmt.Errorf("HTTP_TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+	if cfg.VehicleSync.Enabled {
+		if cfg.VehicleSync.RolesURL == "" {
+			return fmt.Errorf("ROLES_API_URL is required when VEHICLE_SYNC_ENABLED is set")
+		}
+		if cfg.VehicleSync.Interval <= 0 || cfg.VehicleSync.PageSize < 1 {
+			return fmt.Errorf("VEHICLE_SYNC_INTERVAL and VEHICLE_SYNC_PAGE_SIZE must be positive")
+		}
+	}
+	if cfg.VehicleSync.MaxRemoveShare < 0 || cfg.VehicleSync.MaxRemoveShare > 1 {
+		return fmt.Errorf("VEHICLE_SYNC_MAX_REMOVE_SHARE must be between 0 and 1")
+	}
+	switch cfg.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		return fmt.Errorf("TRACING_EXPORTER must be none, otlp or stdout")
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
 	return nil
 }
 
+// splitList разбирает список через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validateArchive проверяет хранилище архивов: при ARCHIVE_BEFORE_DELETE без него
+// события удалялись бы без архива
+func validateArchive(cfg ArchiveConfig) error {
+	switch strings.ToLower(cfg.Store) {
+	case "", "local":
+		if cfg.BeforeDelete && cfg.LocalDir == "" {
+			return fmt.Errorf("ARCHIVE_BEFORE_DELETE requires ARCHIVE_LOCAL_DIR")
+		}
+	case "s3":
+		if cfg.BeforeDelete && (cfg.S3.Endpoint == "" || cfg.S3.Bucket == "") {
+			return fmt.Errorf("ARCHIVE_BEFORE_DELETE requires ARCHIVE_S3_ENDPOINT and ARCHIVE_S3_BUCKET")
+		}
+	default:
+		return fmt.Errorf("ARCHIVE_STORE must be local or s3")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/db/dbtest"
+)
+
+// Работает с PostgreSQL (TEST_DB_DSN): триггер миграции 2 делает журнал аудита неизменяемым
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	database := dbtest.Open(t)
+
+	id := uuid.New()
+	if err := database.Exec(`INSERT INTO anpr_audit_log (id, action, status) VALUES (?, 'test_action', 'success')`, id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.Exec(`UPDATE anpr_audit_log SET status = 'failure' WHERE id = ?`, id).Error; err == nil {
+		t.Error("UPDATE of an audit entry must be rejected")
+	}
+	if err := database.Exec(`DELETE FROM anpr_audit_log WHERE id = ?`, id).Error; err == nil {
+		t.Error("DELETE of an audit entry must be rejected")
+	}
+
+	var status string
+	if err := database.Raw(`SELECT status FROM anpr_audit_log WHERE id = ?`, id).Scan(&status).Error; err != nil || status != "success" {
+		t.Errorf("entry changed: status %q, %v", status, err)
+	}
+}
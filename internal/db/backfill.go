@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// hikvisionSource - событие принято из XML Hikvision: confidenceLevel в raw_payload.anpr.
+// До версии 12 он сохранялся как есть, в процентах, в том числе значения 0–1 (1 - это 1%).
+const hikvisionSource = `raw_payload->'anpr'->>'confidence_level' IS NOT NULL`
+
+// BackfillConfidence переводит уверенность событий, созданных до миграции 12, в долю 0–1.
+// Строки выбираются по источнику, а не по значению: у событий Hikvision (по raw_payload, а если
+// raw_payload уже очищен - по камере, присылавшей события Hikvision) делится на 100 любое значение,
+// у событий JSON и gRPC - только значения больше 1, как при приёме. Работает пакетами по batchSize
+// строк в порядке (event_time, id); каждый пакет вместе с курсором в anpr_confidence_backfill -
+// отдельная короткая транзакция, поэтому шаг можно выполнять на работающей базе и прерывать:
+// повторный запуск продолжит с курсора, а после завершения ничего не меняет. Возвращает число
+// исправленных строк за этот запуск.
+func BackfillConfidence(ctx context.Context, db *gorm.DB, batchSize int, log zerolog.Logger) (int64, error) {
+	type eventKey struct {
+		ID        uuid.UUID
+		EventTime time.Time
+	}
+	var state struct {
+		Cutoff          time.Time
+		CursorEventTime *time.Time
+		CursorID        *uuid.UUID
+		FinishedAt      *time.Time
+	}
+	err := db.WithContext(ctx).Table("anpr_confidence_backfill").Where("id = 1").Take(&state).Error
+	if err != nil {
+		return 0, fmt.Errorf("read confidence backfill state (is migration 12 applied?): %w", err)
+	}
+	if state.FinishedAt != nil {
+		log.Info().Time("finished_at", *state.FinishedAt).Msg("confidence backfill already finished")
+		return 0, nil
+	}
+
+	var hikvisionCameras []string
+	err = db.WithContext(ctx).Table("anpr_events").
+		Where("created_at < ? AND "+hikvisionSource, state.Cutoff).
+		Distinct("camera_id").Pluck("camera_id", &hikvisionCameras).Error
+	if err != nil {
+		return 0, fmt.Errorf("find hikvision cameras: %w", err)
+	}
+	// Пустой IN в PostgreSQL недопустим
+	if len(hikvisionCameras) == 0 {
+		hikvisionCameras = []string{""}
+	}
+
+	var cursor *eventKey
+	if state.CursorEventTime != nil && state.CursorID != nil {
+		cursor = &eventKey{ID: *state.CursorID, EventTime: *state.CursorEventTime}
+	}
+
+	var total int64
+	for {
+		query := db.WithContext(ctx).Table("anpr_events").
+			Select("id, event_time").
+			Where("created_at < ? AND confidence IS NOT NULL", state.Cutoff)
+		if cursor != nil {
+			query = query.Where("(event_time, id) > (?, ?)", cursor.EventTime, cursor.ID)
+		}
+		var batch []eventKey
+		err := query.Order("event_time, id").Limit(batchSize).Scan(&batch).Error
+		if err != nil {
+			return total, fmt.Errorf("select confidence batch: %w", err)
+		}
+		if len(batch) == 0 {
+			err := db.WithContext(ctx).Exec(`UPDATE anpr_confidence_backfill SET finished_at = NOW() WHERE id = 1`).Error
+			if err != nil {
+				return total, fmt.Errorf("finish confidence backfill: %w", err)
+			}
+			return total, nil
+		}
+
+		ids := make([]uuid.UUID, len(batch))
+		for i, key := range batch {
+			ids[i] = key.ID
+		}
+		first, last := batch[0], batch[len(batch)-1]
+		var updated int64
+		err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Exec(`
+				UPDATE anpr_events SET confidence = LEAST(GREATEST(confidence / 100, 0), 1)
+				WHERE id IN ? AND event_time BETWEEN ? AND ?
+					AND (`+hikvisionSource+` OR camera_id IN ? OR confidence > 1)`,
+				ids, first.EventTime, last.EventTime, hikvisionCameras)
+			if result.Error != nil {
+				return fmt.Errorf("update confidence batch: %w", result.Error)
+			}
+			updated = result.RowsAffected
+			return tx.Exec(`
+				UPDATE anpr_confidence_backfill
+				SET cursor_event_time = ?, cursor_id = ?, updated = updated + ?
+				WHERE id = 1`, last.EventTime, last.ID, updated).Error
+		})
+		if err != nil {
+			return total, err
+		}
+		total += updated
+		cursor = &last
+		log.Info().Int64("updated", total).Time("event_time", last.EventTime).Msg("confidence backfill progress")
+	}
+}
@@ -0,0 +1,71 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/db"
+	"anpr-service/internal/db/dbtest"
+)
+
+// Работает с PostgreSQL (TEST_DB_DSN). Сбрасывает ход шага, поэтому повторно проходит
+// события прошлых запусков - данные тестовой БД других тестов от уверенности не зависят.
+func TestBackfillConfidenceBySource(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+	before := time.Now().Add(-time.Hour)
+
+	hikvisionCamera := "hik-" + uuid.NewString()
+	jsonCamera := "json-" + uuid.NewString()
+	insert := func(cameraID string, confidence float64, rawPayload string) uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		err := database.Exec(`
+			INSERT INTO anpr_events (id, camera_id, raw_plate, normalized_plate, confidence, event_time, raw_payload, created_at)
+			VALUES (?, ?, 'X', 'X', ?, ?, ?::jsonb, ?)`,
+			id, cameraID, confidence, before, rawPayload, before).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	hikvisionPercent := `{"anpr": {"confidence_level": 1}}`
+	lowHikvision := insert(hikvisionCamera, 1, hikvisionPercent)
+	clearedHikvision := insert(hikvisionCamera, 0.5, `null`)
+	jsonFraction := insert(jsonCamera, 0.5, `{}`)
+	jsonPercent := insert(jsonCamera, 87, `{}`)
+
+	if err := database.Exec(`UPDATE anpr_confidence_backfill
+		SET cutoff = NOW(), cursor_event_time = NULL, cursor_id = NULL, finished_at = NULL WHERE id = 1`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.BackfillConfidence(ctx, database, 2, zerolog.Nop()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uuid.UUID]float64{lowHikvision: 0.01, clearedHikvision: 0.005, jsonFraction: 0.5, jsonPercent: 0.87}
+	check := func() {
+		t.Helper()
+		for id, expected := range want {
+			var confidence float64
+			if err := database.Raw(`SELECT confidence FROM anpr_events WHERE id = ?`, id).Scan(&confidence).Error; err != nil {
+				t.Fatal(err)
+			}
+			if confidence != expected {
+				t.Errorf("event %s: confidence %v, want %v", id, confidence, expected)
+			}
+		}
+	}
+	check()
+
+	// Повторный запуск после завершения ничего не делит второй раз
+	updated, err := db.BackfillConfidence(ctx, database, 2, zerolog.Nop())
+	if err != nil || updated != 0 {
+		t.Errorf("rerun updated %d, %v; want 0", updated, err)
+	}
+	check()
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,19 +12,33 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 
 	"anpr-service/internal/config"
+	"anpr-service/internal/logger"
 )
 
+// New подключается к БД и, если включено DB_AUTO_MIGRATE, применяет миграции
 func New(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
+	database, err := Open(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DB.AutoMigrate {
+		if _, err := NewMigrator(database, log).Up(context.Background()); err != nil {
+			return nil, fmt.Errorf("run migrations: %w", err)
+		}
+	}
+
+	return database, nil
+}
+
+// Open подключается к БД без применения миграций
+func Open(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
 	dbCfg := cfg.DB
-	gormLog := gormlogger.New(
-		zerologWriter{logger: log},
-		gormlogger.Config{
-			SlowThreshold:             time.Second,
-			Colorful:                  false,
-			IgnoreRecordNotFoundError: true,
-			LogLevel:                  selectLogLevel(cfg.Environment),
-		},
-	)
+	gormLog := &gormLogger{
+		base:          log,
+		level:         selectLogLevel(cfg.Environment),
+		slowThreshold: time.Second,
+	}
 
 	database, err := gorm.Open(postgres.Open(dbCfg.DSN), &gorm.Config{
 		Logger: gormLog,
@@ -47,10 +62,6 @@ func New(cfg *config.Config, log zerolog.Logger) (*gorm.DB, error) {
 		sqlDB.SetConnMaxLifetime(dbCfg.ConnMaxLifetime)
 	}
 
-	if err := runMigrations(database); err != nil {
-		return nil, fmt.Errorf("run migrations: %w", err)
-	}
-
 	return database, nil
 }
 
@@ -65,11 +76,53 @@ func selectLogLevel(env string) gormlogger.LogLevel {
 	return gormlogger.Warn
 }
 
-type zerologWriter struct {
-	logger zerolog.Logger
+// gormLogger пишет SQL-логи через логгер запроса из контекста (request_id, camera_id, plate)
+type gormLogger struct {
+	base          zerolog.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
 }
 
-func (w zerologWriter) Printf(msg string, args ...interface{}) {
-	w.logger.Info().Msgf(msg, args...)
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
 }
 
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx, l.base).Info().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx, l.base).Warn().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx, l.base).Error().Msgf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := logger.FromContext(ctx, l.base)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Error().Err(err).Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msg("sql error")
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.Warn().Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msg("slow sql")
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		log.Debug().Dur("elapsed", elapsed).Int64("rows", rows).Str("sql", sql).Msg("sql")
+	}
+}
@@ -0,0 +1,42 @@
+// Package dbtest подключает интеграционные тесты к PostgreSQL из TEST_DB_DSN.
+// Без переменной тесты пропускаются.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/db"
+)
+
+// DSNEnv - переменная с DSN тестовой БД; данные тестов в ней не удаляются
+const DSNEnv = "TEST_DB_DSN"
+
+// Open подключается к тестовой БД и применяет миграции. Тесты работают с уникальными
+// номерами и идентификаторами, поэтому БД можно не очищать между запусками.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	database, err := db.Open(&config.Config{DB: config.DBConfig{DSN: dsn}}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if _, err := db.NewMigrator(database, zerolog.Nop()).Up(context.Background()); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return database
+}
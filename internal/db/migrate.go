@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// migrationLockKey - ключ advisory lock, чтобы одновременно стартующие реплики
+// не применяли миграции параллельно
+const migrationLockKey int64 = 0x616e70725f6d6967 // "anpr_mig"
+
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// Checksum считается по тексту Up-миграции
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	for _, stmt := range m.Up {
+		h.Write([]byte(strings.TrimSpace(stmt)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	Checksum  string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Dirty - контрольная сумма применённой версии не совпадает с кодом
+	Dirty bool `json:"dirty"`
+	// Unknown - версия есть в базе, но отсутствует в бинарнике (база новее)
+	Unknown bool `json:"unknown"`
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	log        zerolog.Logger
+}
+
+func NewMigrator(db *gorm.DB, log zerolog.Logger) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Migrator{db: db, migrations: sorted, log: log}
+}
+
+// Up применяет все неприменённые миграции, каждую в своей транзакции
+func (m *Migrator) Up(ctx context.Context) ([]int, error) {
+	var applied []int
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			start := time.Now()
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := execAll(tx, mig.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   mig.Version,
+					Name:      mig.Name,
+					Checksum:  mig.Checksum(),
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			m.log.Info().
+				Int("version", mig.Version).
+				Str("name", mig.Name).
+				Dur("duration", time.Since(start)).
+				Msg("migration applied")
+			applied = append(applied, mig.Version)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down откатывает последние steps применённых миграций
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be >= 1")
+	}
+
+	var reverted []int
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := execAll(tx, mig.Down); err != nil {
+					return err
+				}
+				return tx.Where("version = ?", mig.Version).Delete(&schemaMigration{}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			m.log.Warn().Int("version", mig.Version).Str("name", mig.Name).Msg("migration rolled back")
+			reverted = append(reverted, mig.Version)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn := m.db.WithContext(ctx)
+	if err := ensureMigrationsTable(conn); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if rec, ok := done[mig.Version]; ok {
+			appliedAt := rec.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &appliedAt
+			st.Dirty = rec.Checksum != mig.Checksum()
+		}
+		statuses = append(statuses, st)
+	}
+	for version, rec := range done {
+		if known[version] {
+			continue
+		}
+		appliedAt := rec.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      rec.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// withLock выполняет fn на одном выделенном соединении под advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				m.log.Error().Err(err).Msg("failed to release migration lock")
+			}
+		}()
+
+		if err := ensureMigrationsTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) applied(conn *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	done := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// verify проверяет, что применённые версии не были изменены задним числом
+func (m *Migrator) verify(done map[int]schemaMigration) error {
+	known := make(map[int]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if rec, ok := done[mig.Version]; ok && rec.Checksum != mig.Checksum() {
+			return fmt.Errorf("%w: version %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+		}
+	}
+	for version := range done {
+		if !known[version] {
+			// База новее бинарника (например, при откате релиза) - не падаем, но предупреждаем
+			m.log.Warn().Int("version", version).Msg("database has migration unknown to this binary")
+		}
+	}
+	return nil
+}
+
+func ensureMigrationsTable(conn *gorm.DB) error {
+	err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		checksum    TEXT NOT NULL,
+		applied_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func execAll(tx *gorm.DB, statements []string) error {
+	for i, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
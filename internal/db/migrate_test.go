@@ -0,0 +1,32 @@
+package db
+
+import "testing"
+
+func TestMigrationsOrdered(t *testing.T) {
+	prev := 0
+	for _, m := range migrations {
+		if m.Version <= prev {
+			t.Fatalf("migration %d_%s: version must be greater than %d", m.Version, m.Name, prev)
+		}
+		if m.Name == "" || len(m.Up) == 0 {
+			t.Fatalf("migration %d: name and up statements are required", m.Version)
+		}
+		if len(m.Down) == 0 {
+			t.Fatalf("migration %d_%s: down statements are required", m.Version, m.Name)
+		}
+		prev = m.Version
+	}
+}
+
+func TestMigrationChecksumStable(t *testing.T) {
+	m := Migration{Version: 1, Name: "x", Up: []string{"CREATE TABLE a (id INT)", "  SELECT 1  "}}
+	same := Migration{Version: 1, Name: "x", Up: []string{"CREATE TABLE a (id INT)", "SELECT 1"}}
+	changed := Migration{Version: 1, Name: "x", Up: []string{"CREATE TABLE a (id BIGINT)", "SELECT 1"}}
+
+	if m.Checksum() != same.Checksum() {
+		t.Fatal("checksum must ignore surrounding whitespace")
+	}
+	if m.Checksum() == changed.Checksum() {
+		t.Fatal("checksum must change when statement changes")
+	}
+}
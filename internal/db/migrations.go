@@ -1,202 +1,919 @@
 package db
 
-import (
-	"fmt"
-
-	"gorm.io/gorm"
-)
-
-var migrationStatements = []string{
-	`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`,
-
-	// Таблица plates - хранит все уникальные номера с нормализацией
-	// Связь с vehicles через normalized (логическая связь через vehicles.plate_number)
-	`CREATE TABLE IF NOT EXISTS anpr_plates (
-		id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		number          TEXT NOT NULL,
-		normalized      TEXT NOT NULL,
-		country         TEXT,
-		region          TEXT,
-		created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_plates_normalized ON anpr_plates(normalized);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_plates_number ON anpr_plates(number);`,
-
-	// Таблица anpr_events - события распознавания номеров
-	// camera_id может быть UUID (если камера из основной БД) или TEXT (внешний ID камеры)
-	`CREATE TABLE IF NOT EXISTS anpr_events (
-		id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		plate_id        UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
-		camera_id       TEXT NOT NULL,
-		camera_uuid     UUID,
-		polygon_id      UUID,
-		camera_model    TEXT,
-		direction       TEXT,
-		lane            INT,
-		raw_plate       TEXT NOT NULL,
-		normalized_plate TEXT NOT NULL,
-		confidence      NUMERIC(5,2),
-		vehicle_color   TEXT,
-		vehicle_type    TEXT,
-		vehicle_brand   TEXT,
-		vehicle_model   TEXT,
-		vehicle_country TEXT,
-		vehicle_plate_color TEXT,
-		vehicle_speed   NUMERIC(7,2),
-		snapshot_url    TEXT,
-		event_time      TIMESTAMPTZ NOT NULL,
-		raw_payload     JSONB,
-		created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);`,
-	// Добавляем столбец camera_uuid, если его нет (для существующих таблиц)
-	`DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'camera_uuid') THEN
-			ALTER TABLE anpr_events ADD COLUMN camera_uuid UUID;
-		END IF;
-	END
-	$$;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;`,
-	// Добавляем столбец polygon_id, если его нет (для существующих таблиц)
-	`DO $$
-	BEGIN
-		IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
-			WHERE table_name = 'anpr_events' AND column_name = 'polygon_id') THEN
-			ALTER TABLE anpr_events ADD COLUMN polygon_id UUID;
-		END IF;
-	END
-	$$;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_brand TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_model TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_country TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_plate_color TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_speed NUMERIC(7,2);`,
-	// Поля для данных о снеге
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_event_time TIMESTAMPTZ;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_camera_id TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_percentage NUMERIC(5,2);`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_confidence NUMERIC(5,2);`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_direction_ai TEXT;`,
-	`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS matched_snow BOOLEAN DEFAULT FALSE;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_snow_event_time ON anpr_events(snow_event_time) WHERE snow_event_time IS NOT NULL;`,
-
-	// Таблица lists - списки номеров (whitelist/blacklist)
-	`CREATE TABLE IF NOT EXISTS anpr_lists (
-		id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-		name        TEXT NOT NULL,
-		type        TEXT NOT NULL,
-		description TEXT,
-		created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
-	);`,
-	`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_lists_name ON anpr_lists(name);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_lists_type ON anpr_lists(type);`,
-
-	// Таблица list_items - связи номеров со списками
-	`CREATE TABLE IF NOT EXISTS anpr_list_items (
-		list_id     UUID REFERENCES anpr_lists(id) ON DELETE CASCADE,
-		plate_id    UUID REFERENCES anpr_plates(id) ON DELETE CASCADE,
-		note        TEXT,
-		created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
-		PRIMARY KEY (list_id, plate_id)
-	);`,
-	`CREATE INDEX IF NOT EXISTS idx_anpr_list_items_plate_id ON anpr_list_items(plate_id);`,
-
-	// Создание дефолтных списков
-	`DO $$
-	DECLARE
-		whitelist_id UUID;
-		blacklist_id UUID;
-	BEGIN
-		-- Создаем default_whitelist если его нет
-		IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_whitelist') THEN
-			INSERT INTO anpr_lists (id, name, type, description) 
-			VALUES (uuid_generate_v4(), 'default_whitelist', 'WHITELIST', 'Default whitelist - автоматически добавляются номера из vehicles')
-			RETURNING id INTO whitelist_id;
-		END IF;
-		
-		-- Создаем default_blacklist если его нет
-		IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_blacklist') THEN
-			INSERT INTO anpr_lists (id, name, type, description) 
-			VALUES (uuid_generate_v4(), 'default_blacklist', 'BLACKLIST', 'Default blacklist')
-			RETURNING id INTO blacklist_id;
-		END IF;
-	END
-	$$;`,
-
-	// Функция для нормализации номера (аналогична Go функции)
-	// Используется в триггерах для автоматической синхронизации
-	`CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
-	RETURNS TEXT AS $$
-	BEGIN
-		-- Удаляем все пробелы, дефисы и приводим к верхнему регистру
-		RETURN UPPER(REGEXP_REPLACE(plate_text, '[^A-Z0-9]', '', 'g'));
-	END;
-	$$ LANGUAGE plpgsql IMMUTABLE;`,
-
-	// Функция для автоматического добавления номера в whitelist при создании vehicle
-	// Вызывается извне (через API или триггер в основной БД, если нужно)
-	`CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
-	RETURNS UUID AS $$
-	DECLARE
-		normalized_plate TEXT;
-		plate_uuid UUID;
-		whitelist_uuid UUID;
-	BEGIN
-		-- Нормализуем номер
-		normalized_plate := normalize_plate_number(vehicle_plate_number);
-		
-		IF normalized_plate = '' THEN
-			RETURN NULL;
-		END IF;
-		
-		-- Получаем или создаем plate
-		SELECT id INTO plate_uuid
-		FROM anpr_plates
-		WHERE normalized = normalized_plate;
-		
-		IF plate_uuid IS NULL THEN
-			INSERT INTO anpr_plates (number, normalized)
-			VALUES (vehicle_plate_number, normalized_plate)
-			RETURNING id INTO plate_uuid;
-		END IF;
-		
-		-- Получаем ID whitelist
-		SELECT id INTO whitelist_uuid
-		FROM anpr_lists
-		WHERE name = 'default_whitelist' AND type = 'WHITELIST'
-		LIMIT 1;
-		
-		IF whitelist_uuid IS NULL THEN
-			-- Создаем whitelist если его нет
-			INSERT INTO anpr_lists (name, type, description)
-			VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
-			RETURNING id INTO whitelist_uuid;
-		END IF;
-		
-		-- Добавляем номер в whitelist (если еще не добавлен)
-		INSERT INTO anpr_list_items (list_id, plate_id, note)
-		VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles')
-		ON CONFLICT (list_id, plate_id) DO NOTHING;
-		
-		RETURN plate_uuid;
-	END;
-	$$ LANGUAGE plpgsql;`,
-
-	// Индекс для быстрого поиска по normalized_plate в anpr_events
-	`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);`,
-}
+// migrations - упорядоченный список версий схемы. Уже применённые версии менять нельзя:
+// их контрольные суммы хранятся в schema_migrations и сверяются при старте.
+// Первые версии намеренно идемпотентны (IF NOT EXISTS), чтобы базы, созданные
+// до появления schema_migrations, принимали их без ошибок.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up: []string{
+			`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`,
+
+			// Таблица plates - хранит все уникальные номера с нормализацией
+			// Связь с vehicles через normalized (логическая связь через vehicles.plate_number)
+			`CREATE TABLE IF NOT EXISTS anpr_plates (
+				id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				number          TEXT NOT NULL,
+				normalized      TEXT NOT NULL,
+				country         TEXT,
+				region          TEXT,
+				created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_plates_normalized ON anpr_plates(normalized);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_plates_number ON anpr_plates(number);`,
+
+			// Таблица anpr_events - события распознавания номеров
+			// camera_id может быть UUID (если камера из основной БД) или TEXT (внешний ID камеры)
+			`CREATE TABLE IF NOT EXISTS anpr_events (
+				id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				plate_id        UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+				camera_id       TEXT NOT NULL,
+				camera_uuid     UUID,
+				polygon_id      UUID,
+				camera_model    TEXT,
+				direction       TEXT,
+				lane            INT,
+				raw_plate       TEXT NOT NULL,
+				normalized_plate TEXT NOT NULL,
+				confidence      NUMERIC(5,2),
+				vehicle_color   TEXT,
+				vehicle_type    TEXT,
+				vehicle_brand   TEXT,
+				vehicle_model   TEXT,
+				vehicle_country TEXT,
+				vehicle_plate_color TEXT,
+				vehicle_speed   NUMERIC(7,2),
+				snapshot_url    TEXT,
+				event_time      TIMESTAMPTZ NOT NULL,
+				raw_payload     JSONB,
+				created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);`,
+			// Добавляем столбец camera_uuid, если его нет (для существующих таблиц)
+			`DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					WHERE table_name = 'anpr_events' AND column_name = 'camera_uuid') THEN
+					ALTER TABLE anpr_events ADD COLUMN camera_uuid UUID;
+				END IF;
+			END
+			$$;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;`,
+			// Добавляем столбец polygon_id, если его нет (для существующих таблиц)
+			`DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+					WHERE table_name = 'anpr_events' AND column_name = 'polygon_id') THEN
+					ALTER TABLE anpr_events ADD COLUMN polygon_id UUID;
+				END IF;
+			END
+			$$;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_brand TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_model TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_country TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_plate_color TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS vehicle_speed NUMERIC(7,2);`,
+			// Поля для данных о снеге
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_event_time TIMESTAMPTZ;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_camera_id TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_percentage NUMERIC(5,2);`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_volume_confidence NUMERIC(5,2);`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS snow_direction_ai TEXT;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS matched_snow BOOLEAN DEFAULT FALSE;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_snow_event_time ON anpr_events(snow_event_time) WHERE snow_event_time IS NOT NULL;`,
+
+			// Таблица lists - списки номеров (whitelist/blacklist)
+			`CREATE TABLE IF NOT EXISTS anpr_lists (
+				id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				name        TEXT NOT NULL,
+				type        TEXT NOT NULL,
+				description TEXT,
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS ux_anpr_lists_name ON anpr_lists(name);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_lists_type ON anpr_lists(type);`,
+
+			// Таблица list_items - связи номеров со списками
+			`CREATE TABLE IF NOT EXISTS anpr_list_items (
+				list_id     UUID REFERENCES anpr_lists(id) ON DELETE CASCADE,
+				plate_id    UUID REFERENCES anpr_plates(id) ON DELETE CASCADE,
+				note        TEXT,
+				created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+				PRIMARY KEY (list_id, plate_id)
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_list_items_plate_id ON anpr_list_items(plate_id);`,
+
+			// Создание дефолтных списков
+			`DO $$
+			DECLARE
+				whitelist_id UUID;
+				blacklist_id UUID;
+			BEGIN
+				-- Создаем default_whitelist если его нет
+				IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_whitelist') THEN
+					INSERT INTO anpr_lists (id, name, type, description) 
+					VALUES (uuid_generate_v4(), 'default_whitelist', 'WHITELIST', 'Default whitelist - автоматически добавляются номера из vehicles')
+					RETURNING id INTO whitelist_id;
+				END IF;
+
+				-- Создаем default_blacklist если его нет
+				IF NOT EXISTS (SELECT 1 FROM anpr_lists WHERE name = 'default_blacklist') THEN
+					INSERT INTO anpr_lists (id, name, type, description) 
+					VALUES (uuid_generate_v4(), 'default_blacklist', 'BLACKLIST', 'Default blacklist')
+					RETURNING id INTO blacklist_id;
+				END IF;
+			END
+			$$;`,
+
+			// Функция для нормализации номера (аналогична Go функции)
+			// Используется в триггерах для автоматической синхронизации
+			`CREATE OR REPLACE FUNCTION normalize_plate_number(plate_text TEXT)
+			RETURNS TEXT AS $$
+			BEGIN
+				-- Удаляем все пробелы, дефисы и приводим к верхнему регистру
+				RETURN UPPER(REGEXP_REPLACE(plate_text, '[^A-Z0-9]', '', 'g'));
+			END;
+			$$ LANGUAGE plpgsql IMMUTABLE;`,
+
+			// Функция для автоматического добавления номера в whitelist при создании vehicle
+			// Вызывается извне (через API или триггер в основной БД, если нужно)
+			`CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
+			RETURNS UUID AS $$
+			DECLARE
+				normalized_plate TEXT;
+				plate_uuid UUID;
+				whitelist_uuid UUID;
+			BEGIN
+				-- Нормализуем номер
+				normalized_plate := normalize_plate_number(vehicle_plate_number);
+
+				IF normalized_plate = '' THEN
+					RETURN NULL;
+				END IF;
+
+				-- Получаем или создаем plate
+				SELECT id INTO plate_uuid
+				FROM anpr_plates
+				WHERE normalized = normalized_plate;
+
+				IF plate_uuid IS NULL THEN
+					INSERT INTO anpr_plates (number, normalized)
+					VALUES (vehicle_plate_number, normalized_plate)
+					RETURNING id INTO plate_uuid;
+				END IF;
+
+				-- Получаем ID whitelist
+				SELECT id INTO whitelist_uuid
+				FROM anpr_lists
+				WHERE name = 'default_whitelist' AND type = 'WHITELIST'
+				LIMIT 1;
+
+				IF whitelist_uuid IS NULL THEN
+					-- Создаем whitelist если его нет
+					INSERT INTO anpr_lists (name, type, description)
+					VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
+					RETURNING id INTO whitelist_uuid;
+				END IF;
+
+				-- Добавляем номер в whitelist (если еще не добавлен)
+				INSERT INTO anpr_list_items (list_id, plate_id, note)
+				VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles')
+				ON CONFLICT (list_id, plate_id) DO NOTHING;
+
+				RETURN plate_uuid;
+			END;
+			$$ LANGUAGE plpgsql;`,
+
+			// Индекс для быстрого поиска по normalized_plate в anpr_events
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);`,
+		},
+		Down: []string{
+			`DROP FUNCTION IF EXISTS anpr_sync_vehicle_to_whitelist(TEXT);`,
+			`DROP FUNCTION IF EXISTS normalize_plate_number(TEXT);`,
+			`DROP TABLE IF EXISTS anpr_list_items;`,
+			`DROP TABLE IF EXISTS anpr_lists;`,
+			`DROP TABLE IF EXISTS anpr_events;`,
+			`DROP TABLE IF EXISTS anpr_plates;`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "audit_log",
+		Up: []string{
+			// Журнал административных действий (append-only)
+			`CREATE TABLE IF NOT EXISTS anpr_audit_log (
+				id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				actor_id      UUID,
+				actor_org_id  UUID,
+				actor_role    TEXT,
+				action        TEXT NOT NULL,
+				target        TEXT,
+				params        JSONB,
+				result        JSONB,
+				status        TEXT NOT NULL,
+				error         TEXT,
+				client_ip     TEXT,
+				created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_audit_log_created_at ON anpr_audit_log(created_at DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_audit_log_actor_id ON anpr_audit_log(actor_id, created_at DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_audit_log_action ON anpr_audit_log(action, created_at DESC);`,
+			// Запрещаем UPDATE/DELETE на уровне БД, чтобы журнал нельзя было переписать в обход сервиса
+			`CREATE OR REPLACE FUNCTION anpr_audit_log_immutable()
+			RETURNS TRIGGER AS $$
+			BEGIN
+				RAISE EXCEPTION 'anpr_audit_log is append-only';
+			END;
+			$$ LANGUAGE plpgsql;`,
+			`DO $$
+			BEGIN
+				IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_anpr_audit_log_immutable') THEN
+					CREATE TRIGGER trg_anpr_audit_log_immutable
+					BEFORE UPDATE OR DELETE ON anpr_audit_log
+					FOR EACH ROW EXECUTE FUNCTION anpr_audit_log_immutable();
+				END IF;
+			END
+			$$;`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_audit_log;`,
+			`DROP FUNCTION IF EXISTS anpr_audit_log_immutable();`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "retention",
+		Up: []string{
+			// Политики хранения событий, сырых payload'ов и снимков
+			`CREATE TABLE IF NOT EXISTS anpr_retention_policies (
+				id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				name         TEXT NOT NULL,
+				target       TEXT NOT NULL CHECK (target IN ('events', 'raw_payloads', 'snapshots')),
+				camera_id    TEXT,
+				polygon_id   UUID,
+				list_type    TEXT,
+				retain_days  INT NOT NULL CHECK (retain_days > 0),
+				priority     INT NOT NULL DEFAULT 0,
+				enabled      BOOLEAN NOT NULL DEFAULT TRUE,
+				description  TEXT,
+				created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+				updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE TABLE IF NOT EXISTS anpr_retention_runs (
+				id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				trigger      TEXT NOT NULL,
+				dry_run      BOOLEAN NOT NULL,
+				status       TEXT NOT NULL,
+				results      JSONB,
+				error        TEXT,
+				started_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+				finished_at  TIMESTAMPTZ
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_retention_runs_started_at ON anpr_retention_runs(started_at DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_id_time ON anpr_events(camera_id, event_time);`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_camera_id_time;`,
+			`DROP TABLE IF EXISTS anpr_retention_runs;`,
+			`DROP TABLE IF EXISTS anpr_retention_policies;`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "archives",
+		Up: []string{
+			// Реестр архивов событий в холодном хранилище
+			`CREATE TABLE IF NOT EXISTS anpr_archives (
+				id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				reason          TEXT NOT NULL,
+				format          TEXT NOT NULL,
+				store           TEXT NOT NULL,
+				data_key        TEXT NOT NULL,
+				manifest_key    TEXT NOT NULL,
+				range_from      TIMESTAMPTZ,
+				range_to        TIMESTAMPTZ,
+				min_event_time  TIMESTAMPTZ,
+				max_event_time  TIMESTAMPTZ,
+				event_count     BIGINT NOT NULL,
+				size_bytes      BIGINT NOT NULL,
+				sha256          TEXT NOT NULL,
+				created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+				restored_at     TIMESTAMPTZ
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_archives_event_range ON anpr_archives(min_event_time, max_event_time);`,
+			// События, восстановленные из архива (только для чтения)
+			`CREATE TABLE IF NOT EXISTS anpr_events_restored (
+				id                UUID PRIMARY KEY,
+				archive_id        UUID NOT NULL REFERENCES anpr_archives(id),
+				plate_id          UUID,
+				camera_id         TEXT NOT NULL,
+				normalized_plate  TEXT NOT NULL,
+				event_time        TIMESTAMPTZ NOT NULL,
+				data              JSONB NOT NULL,
+				restored_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_restored_plate_time ON anpr_events_restored(normalized_plate, event_time DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_restored_event_time ON anpr_events_restored(event_time);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_events_restored;`,
+			`DROP TABLE IF EXISTS anpr_archives;`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "partition_events",
+		Up: []string{
+			// Перевод anpr_events на секционирование по event_time.
+			// Существующая таблица не копируется: она подключается как секция
+			// [MINVALUE, граница), а дальнейшие секции создаёт PartitionService.
+			`DO $$
+			DECLARE
+				bound TIMESTAMPTZ;
+				idx RECORD;
+			BEGIN
+				IF (SELECT relkind FROM pg_class WHERE oid = to_regclass('anpr_events')) IS DISTINCT FROM 'r' THEN
+					RETURN;
+				END IF;
+
+				SELECT GREATEST(
+					date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + interval '1 day',
+					date_trunc('day', max(event_time) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + interval '1 day'
+				) INTO bound FROM anpr_events;
+
+				ALTER TABLE anpr_events RENAME TO anpr_events_legacy;
+				FOR idx IN SELECT indexname FROM pg_indexes
+					WHERE tablename = 'anpr_events_legacy' AND indexname LIKE 'idx_anpr_events_%'
+				LOOP
+					EXECUTE format('ALTER INDEX %I RENAME TO %I', idx.indexname,
+						replace(idx.indexname, 'idx_anpr_events_', 'idx_anpr_events_legacy_'));
+				END LOOP;
+
+				ALTER TABLE anpr_events_legacy DROP CONSTRAINT IF EXISTS anpr_events_pkey;
+				ALTER TABLE anpr_events_legacy ADD CONSTRAINT anpr_events_legacy_pkey PRIMARY KEY (id, event_time);
+				EXECUTE format('ALTER TABLE anpr_events_legacy ADD CONSTRAINT anpr_events_legacy_bound CHECK (event_time IS NOT NULL AND event_time < %L)', bound);
+
+				CREATE TABLE anpr_events (LIKE anpr_events_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (event_time);
+				ALTER TABLE anpr_events ADD PRIMARY KEY (id, event_time);
+				ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_plate_id_fkey
+					FOREIGN KEY (plate_id) REFERENCES anpr_plates(id) ON DELETE SET NULL;
+				EXECUTE format('ALTER TABLE anpr_events ATTACH PARTITION anpr_events_legacy FOR VALUES FROM (MINVALUE) TO (%L)', bound);
+				CREATE TABLE anpr_events_default PARTITION OF anpr_events DEFAULT;
+			END
+			$$;`,
+			// Индексы на секционированной таблице (наследуются всеми секциями)
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_snow_event_time ON anpr_events(snow_event_time) WHERE snow_event_time IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_id_time ON anpr_events(camera_id, event_time);`,
+		},
+		Down: []string{
+			// Обратно в обычную таблицу: данные всех секций копируются
+			`DO $$
+			BEGIN
+				IF (SELECT relkind FROM pg_class WHERE oid = to_regclass('anpr_events')) IS DISTINCT FROM 'p' THEN
+					RETURN;
+				END IF;
+
+				ALTER TABLE anpr_events RENAME TO anpr_events_partitioned;
+				CREATE TABLE anpr_events (LIKE anpr_events_partitioned INCLUDING DEFAULTS);
+				INSERT INTO anpr_events SELECT * FROM anpr_events_partitioned;
+				DROP TABLE anpr_events_partitioned CASCADE;
+
+				ALTER TABLE anpr_events ADD PRIMARY KEY (id);
+				ALTER TABLE anpr_events ADD CONSTRAINT anpr_events_plate_id_fkey
+					FOREIGN KEY (plate_id) REFERENCES anpr_plates(id) ON DELETE SET NULL;
+			END
+			$$;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_plate_id ON anpr_events(plate_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time ON anpr_events(event_time);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate ON anpr_events(normalized_plate);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_uuid ON anpr_events(camera_uuid) WHERE camera_uuid IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_polygon_id ON anpr_events(polygon_id) WHERE polygon_id IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_matched_snow ON anpr_events(matched_snow) WHERE matched_snow = TRUE;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_snow_event_time ON anpr_events(snow_event_time) WHERE snow_event_time IS NOT NULL;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_time ON anpr_events(normalized_plate, event_time DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_camera_id_time ON anpr_events(camera_id, event_time);`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "events_query_indexes",
+		Up: []string{
+			// Keyset-пагинация GET /events по (event_time, id)
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_event_time_id ON anpr_events(event_time DESC, id DESC);`,
+			// Фильтр по префиксу номера (LIKE 'ABC%')
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_normalized_plate_prefix ON anpr_events(normalized_plate text_pattern_ops);`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_normalized_plate_prefix;`,
+			`DROP INDEX IF EXISTS idx_anpr_events_event_time_id;`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "export_jobs",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS anpr_export_jobs (
+				id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				status TEXT NOT NULL DEFAULT 'pending',
+				format TEXT NOT NULL,
+				query JSONB NOT NULL DEFAULT '{}'::jsonb,
+				include_snapshots BOOLEAN NOT NULL DEFAULT FALSE,
+				store TEXT,
+				data_key TEXT,
+				row_count BIGINT NOT NULL DEFAULT 0,
+				size_bytes BIGINT NOT NULL DEFAULT 0,
+				error TEXT,
+				created_by UUID,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				started_at TIMESTAMPTZ,
+				finished_at TIMESTAMPTZ,
+				expires_at TIMESTAMPTZ
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_export_jobs_status_created ON anpr_export_jobs(status, created_at);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_export_jobs;`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "cameras_and_anomalies",
+		Up: []string{
+			// Реестр камер: координаты нужны для проверки невозможного перемещения
+			`CREATE TABLE IF NOT EXISTS anpr_cameras (
+				camera_id  TEXT PRIMARY KEY,
+				name       TEXT,
+				latitude   DOUBLE PRECISION,
+				longitude  DOUBLE PRECISION,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			// Профиль номера: распределение наблюдённых значений атрибутов ТС
+			`CREATE TABLE IF NOT EXISTS anpr_plate_profiles (
+				plate_id  UUID NOT NULL REFERENCES anpr_plates(id) ON DELETE CASCADE,
+				attribute TEXT NOT NULL,
+				value     TEXT NOT NULL,
+				count     BIGINT NOT NULL,
+				last_seen TIMESTAMPTZ NOT NULL,
+				PRIMARY KEY (plate_id, attribute, value)
+			);`,
+			// Первичное заполнение профилей по истории событий
+			`INSERT INTO anpr_plate_profiles (plate_id, attribute, value, count, last_seen)
+			SELECT e.plate_id, v.attribute, LOWER(TRIM(v.value)), COUNT(*), MAX(e.event_time)
+			FROM anpr_events e
+			CROSS JOIN LATERAL (VALUES
+				('vehicle_type', e.vehicle_type),
+				('vehicle_color', e.vehicle_color),
+				('vehicle_brand', e.vehicle_brand),
+				('vehicle_model', e.vehicle_model)
+			) AS v(attribute, value)
+			WHERE e.plate_id IS NOT NULL AND v.value IS NOT NULL AND TRIM(v.value) <> ''
+			  AND LOWER(TRIM(v.value)) NOT IN ('unknown', 'other')
+			GROUP BY e.plate_id, v.attribute, LOWER(TRIM(v.value))
+			ON CONFLICT DO NOTHING;`,
+			`CREATE TABLE IF NOT EXISTS anpr_anomalies (
+				id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				type             TEXT NOT NULL,
+				status           TEXT NOT NULL DEFAULT 'open',
+				plate_id         UUID NOT NULL,
+				normalized_plate TEXT NOT NULL,
+				event_id         UUID NOT NULL,
+				related_event_id UUID,
+				camera_id        TEXT NOT NULL,
+				event_time       TIMESTAMPTZ NOT NULL,
+				score            DOUBLE PRECISION NOT NULL,
+				details          JSONB NOT NULL DEFAULT '{}'::jsonb,
+				resolved_by      UUID,
+				resolved_at      TIMESTAMPTZ,
+				resolution_note  TEXT,
+				created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_anomalies_event_time ON anpr_anomalies(event_time DESC);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_anomalies_status_type ON anpr_anomalies(status, type);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_anomalies_plate_id ON anpr_anomalies(plate_id);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_anomalies;`,
+			`DROP TABLE IF EXISTS anpr_plate_profiles;`,
+			`DROP TABLE IF EXISTS anpr_cameras;`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "vehicle_registry",
+		Up: []string{
+			// source - кто добавил номер в список: vehicles - синхронизация с реестром ТС, NULL - вручную
+			`ALTER TABLE anpr_list_items ADD COLUMN IF NOT EXISTS source TEXT;`,
+			`UPDATE anpr_list_items SET source = 'vehicles' WHERE note = 'Автоматически добавлен из vehicles';`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_list_items_source ON anpr_list_items(source) WHERE source IS NOT NULL;`,
+			`CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
+			RETURNS UUID AS $$
+			DECLARE
+				normalized_plate TEXT;
+				plate_uuid UUID;
+				whitelist_uuid UUID;
+			BEGIN
+				-- Нормализуем номер
+				normalized_plate := normalize_plate_number(vehicle_plate_number);
+
+				IF normalized_plate = '' THEN
+					RETURN NULL;
+				END IF;
+
+				-- Получаем или создаем plate
+				SELECT id INTO plate_uuid
+				FROM anpr_plates
+				WHERE normalized = normalized_plate;
+
+				IF plate_uuid IS NULL THEN
+					INSERT INTO anpr_plates (number, normalized)
+					VALUES (vehicle_plate_number, normalized_plate)
+					RETURNING id INTO plate_uuid;
+				END IF;
+
+				-- Получаем ID whitelist
+				SELECT id INTO whitelist_uuid
+				FROM anpr_lists
+				WHERE name = 'default_whitelist' AND type = 'WHITELIST'
+				LIMIT 1;
+
+				IF whitelist_uuid IS NULL THEN
+					-- Создаем whitelist если его нет
+					INSERT INTO anpr_lists (name, type, description)
+					VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
+					RETURNING id INTO whitelist_uuid;
+				END IF;
+
+				-- Добавляем номер в whitelist (если еще не добавлен)
+				INSERT INTO anpr_list_items (list_id, plate_id, note, source)
+				VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles', 'vehicles')
+				ON CONFLICT (list_id, plate_id) DO NOTHING;
+
+				RETURN plate_uuid;
+			END;
+			$$ LANGUAGE plpgsql;`,
+			// Зеркало таблицы vehicles сервиса roles
+			`CREATE TABLE IF NOT EXISTS anpr_vehicles (
+				vehicle_id        UUID PRIMARY KEY,
+				org_id            UUID NOT NULL,
+				contractor_id     UUID,
+				contractor_name   TEXT,
+				plate_number      TEXT NOT NULL,
+				normalized_plate  TEXT NOT NULL,
+				plate_id          UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+				is_active         BOOLEAN NOT NULL DEFAULT TRUE,
+				source_updated_at TIMESTAMPTZ,
+				created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_vehicles_plate_id ON anpr_vehicles(plate_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_vehicles_org_id ON anpr_vehicles(org_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_vehicles_contractor_id ON anpr_vehicles(contractor_id) WHERE contractor_id IS NOT NULL;`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_vehicles;`,
+			`CREATE OR REPLACE FUNCTION anpr_sync_vehicle_to_whitelist(vehicle_plate_number TEXT)
+			RETURNS UUID AS $$
+			DECLARE
+				normalized_plate TEXT;
+				plate_uuid UUID;
+				whitelist_uuid UUID;
+			BEGIN
+				-- Нормализуем номер
+				normalized_plate := normalize_plate_number(vehicle_plate_number);
+
+				IF normalized_plate = '' THEN
+					RETURN NULL;
+				END IF;
+
+				-- Получаем или создаем plate
+				SELECT id INTO plate_uuid
+				FROM anpr_plates
+				WHERE normalized = normalized_plate;
+
+				IF plate_uuid IS NULL THEN
+					INSERT INTO anpr_plates (number, normalized)
+					VALUES (vehicle_plate_number, normalized_plate)
+					RETURNING id INTO plate_uuid;
+				END IF;
+
+				-- Получаем ID whitelist
+				SELECT id INTO whitelist_uuid
+				FROM anpr_lists
+				WHERE name = 'default_whitelist' AND type = 'WHITELIST'
+				LIMIT 1;
+
+				IF whitelist_uuid IS NULL THEN
+					-- Создаем whitelist если его нет
+					INSERT INTO anpr_lists (name, type, description)
+					VALUES ('default_whitelist', 'WHITELIST', 'Default whitelist')
+					RETURNING id INTO whitelist_uuid;
+				END IF;
+
+				-- Добавляем номер в whitelist (если еще не добавлен)
+				INSERT INTO anpr_list_items (list_id, plate_id, note)
+				VALUES (whitelist_uuid, plate_uuid, 'Автоматически добавлен из vehicles')
+				ON CONFLICT (list_id, plate_id) DO NOTHING;
 
-func runMigrations(db *gorm.DB) error {
-	for i, stmt := range migrationStatements {
-		if err := db.Exec(stmt).Error; err != nil {
-			return fmt.Errorf("migration %d failed: %w", i+1, err)
-		}
-	}
-	return nil
+				RETURN plate_uuid;
+			END;
+			$$ LANGUAGE plpgsql;`,
+			`DROP INDEX IF EXISTS idx_anpr_list_items_source;`,
+			`ALTER TABLE anpr_list_items DROP COLUMN IF EXISTS source;`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "unknown_vehicle_review",
+		Up: []string{
+			// Полигон камеры: события камеры относятся к нему
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS polygon_id UUID;`,
+			// Политика полигона: ставить ли номера вне списков в очередь проверки;
+			// direction - только события этого направления (NULL - любые)
+			`CREATE TABLE IF NOT EXISTS anpr_review_policies (
+				polygon_id UUID PRIMARY KEY,
+				enabled    BOOLEAN NOT NULL DEFAULT TRUE,
+				direction  TEXT,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			// Очередь проверки неизвестных ТС: одна открытая запись на номер и полигон
+			`CREATE TABLE IF NOT EXISTS anpr_review_items (
+				id                 UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				status             TEXT NOT NULL DEFAULT 'pending',
+				plate_id           UUID NOT NULL REFERENCES anpr_plates(id) ON DELETE CASCADE,
+				normalized_plate   TEXT NOT NULL,
+				polygon_id         UUID NOT NULL,
+				camera_id          TEXT NOT NULL,
+				first_event_id     UUID NOT NULL,
+				last_event_id      UUID NOT NULL,
+				first_seen         TIMESTAMPTZ NOT NULL,
+				last_seen          TIMESTAMPTZ NOT NULL,
+				occurrences        INTEGER NOT NULL DEFAULT 1,
+				suggested_plate_id UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+				list_id            UUID REFERENCES anpr_lists(id) ON DELETE SET NULL,
+				corrected_plate_id UUID REFERENCES anpr_plates(id) ON DELETE SET NULL,
+				resolved_by        UUID,
+				resolved_at        TIMESTAMPTZ,
+				resolution_note    TEXT,
+				created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_anpr_review_items_pending ON anpr_review_items(plate_id, polygon_id) WHERE status = 'pending';`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_review_items_status_last_seen ON anpr_review_items(status, last_seen DESC);`,
+			// Данные для нечёткой коррекции: какой номер на самом деле стоял за прочитанной строкой.
+			// Строка, совпадающая с нормализованным номером plate_id, - подтверждение, что номер настоящий.
+			`CREATE TABLE IF NOT EXISTS anpr_plate_corrections (
+				read_plate TEXT NOT NULL,
+				plate_id   UUID NOT NULL REFERENCES anpr_plates(id) ON DELETE CASCADE,
+				count      INTEGER NOT NULL DEFAULT 1,
+				last_used  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (read_plate, plate_id)
+			);`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_plate_corrections;`,
+			`DROP TABLE IF EXISTS anpr_review_items;`,
+			`DROP TABLE IF EXISTS anpr_review_policies;`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS polygon_id;`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "plate_corrections",
+		Up: []string{
+			// Исправление номера оператором: raw_plate не меняется, plate_id и normalized_plate
+			// указывают на настоящий номер, original_plate_id - на номер до первого исправления
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS original_plate_id UUID;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS corrected_by UUID;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS corrected_at TIMESTAMPTZ;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS correction_reason TEXT;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_original_plate_id ON anpr_events(original_plate_id) WHERE original_plate_id IS NOT NULL;`,
+			// Слияния номеров: ошибочный номер удаляется, поэтому его строка сохраняется здесь.
+			// Ссылок на anpr_plates нет: последующее слияние целевого номера не стирает историю
+			`CREATE TABLE IF NOT EXISTS anpr_plate_merges (
+				id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				source_plate_id   UUID NOT NULL,
+				source_plate      TEXT NOT NULL,
+				target_plate_id   UUID NOT NULL,
+				target_plate      TEXT NOT NULL,
+				events_moved      BIGINT NOT NULL DEFAULT 0,
+				memberships_moved BIGINT NOT NULL DEFAULT 0,
+				merged_by         UUID,
+				reason            TEXT NOT NULL,
+				created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_plate_merges_target ON anpr_plate_merges(target_plate_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_plate_merges_source ON anpr_plate_merges(source_plate_id);`,
+			// Номера, производные данные которых (поездки, отчёты) нужно пересчитать после исправления
+			// или слияния. Пишется в транзакции изменения; потребители забирают и подтверждают записи
+			`CREATE TABLE IF NOT EXISTS anpr_plate_recomputes (
+				id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+				plate_id         UUID NOT NULL,
+				normalized_plate TEXT NOT NULL,
+				reason           TEXT NOT NULL,
+				created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				processed_at     TIMESTAMPTZ
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_plate_recomputes_pending ON anpr_plate_recomputes(created_at) WHERE processed_at IS NULL;`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS anpr_plate_recomputes;`,
+			`DROP TABLE IF EXISTS anpr_plate_merges;`,
+			`DROP INDEX IF EXISTS idx_anpr_events_original_plate_id;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS correction_reason;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS corrected_at;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS corrected_by;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS original_plate_id;`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "confidence_policy",
+		Up: []string{
+			// Пороги уверенности камеры (шкала 0–1); NULL - значения по умолчанию из конфигурации
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS confidence_review_below NUMERIC(5,4);`,
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS confidence_unverified_below NUMERIC(5,4);`,
+			// Уверенность хранится долей 0–1. Снятие ограничения NUMERIC не переписывает таблицу;
+			// старые значения в процентах переводит отдельный пакетный шаг `migrate backfill-confidence`
+			`ALTER TABLE anpr_events ALTER COLUMN confidence TYPE NUMERIC;`,
+			// Ход этого шага: cutoff - момент обновления (события, созданные раньше, хранят старую шкалу),
+			// курсор (event_time, id) - до какого события шаг дошёл, чтобы ни одна строка не делилась дважды
+			`CREATE TABLE IF NOT EXISTS anpr_confidence_backfill (
+				id                INT PRIMARY KEY,
+				cutoff            TIMESTAMPTZ NOT NULL,
+				cursor_event_time TIMESTAMPTZ,
+				cursor_id         UUID,
+				updated           BIGINT NOT NULL DEFAULT 0,
+				finished_at       TIMESTAMPTZ
+			);`,
+			`INSERT INTO anpr_confidence_backfill (id, cutoff) VALUES (1, NOW()) ON CONFLICT DO NOTHING;`,
+			// Исход политики уверенности: accepted, flagged (на проверку) или unverified (без сверки со списками)
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS read_status TEXT NOT NULL DEFAULT 'accepted';`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS verified_by UUID;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_read_status ON anpr_events(read_status, event_time DESC) WHERE read_status <> 'accepted';`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_read_status;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS verified_at;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS verified_by;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS read_status;`,
+			`DROP TABLE IF EXISTS anpr_confidence_backfill;`,
+			`ALTER TABLE anpr_events ALTER COLUMN confidence TYPE NUMERIC(5,2);`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS confidence_unverified_below;`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS confidence_review_below;`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "event_clock",
+		Up: []string{
+			// Часовой пояс камеры (IANA) для времени без смещения; NULL - CAMERA_DEFAULT_TIMEZONE
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS timezone TEXT;`,
+			// Время получения сервером и расхождение часов камеры с ним;
+			// time_source = 'server' - камера не передала разборчивое время
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS clock_skew_seconds DOUBLE PRECISION;`,
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS time_source TEXT NOT NULL DEFAULT 'camera';`,
+		},
+		Down: []string{
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS time_source;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS clock_skew_seconds;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS received_at;`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS timezone;`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "camera_liveness",
+		Up: []string{
+			// Последняя связь с камерой, в том числе незарегистрированной: heartbeat (videoloss inactive)
+			// и любое другое уведомление; last_address - адрес, с которого оно пришло
+			`CREATE TABLE IF NOT EXISTS anpr_camera_liveness (
+				camera_id         TEXT PRIMARY KEY,
+				last_heartbeat_at TIMESTAMPTZ,
+				last_event_at     TIMESTAMPTZ,
+				last_address      TEXT,
+				updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);`,
+			// Проезды без номера хранятся без plate_id со статусом plateless
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_plateless ON anpr_events(camera_id, event_time DESC) WHERE read_status = 'plateless';`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_plateless;`,
+			`DROP TABLE IF EXISTS anpr_camera_liveness;`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "plateless_pairing",
+		Up: []string{
+			// Предыдущий проезд того же ТС без номера, найденный по совпадению признаков на другой камере
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS paired_event_id UUID;`,
+			// Проезды без номера ищутся по idx_anpr_events_plateless (версия 14); по этому индексу
+			// проверяется, не стал ли проезд уже парой другого
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_paired ON anpr_events(paired_event_id) WHERE paired_event_id IS NOT NULL;`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_paired;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS paired_event_id;`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "plate_candidates",
+		Up: []string{
+			// Альтернативные прочтения номера движком распознавания: [{"plate": "...", "confidence": 0.7}]
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS plate_candidates JSONB;`,
+		},
+		Down: []string{
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS plate_candidates;`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "camera_allowed_ips",
+		Up: []string{
+			// Адреса и подсети, с которых принимаются события камеры: ["10.0.5.0/24", "192.168.1.64"]
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS allowed_ips JSONB;`,
+		},
+		Down: []string{
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS allowed_ips;`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "camera_auth",
+		Up: []string{
+			// SHA-256 ключа API камеры (сам ключ не хранится) и CN клиентского сертификата камеры
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS api_key_hash TEXT;`,
+			`ALTER TABLE anpr_cameras ADD COLUMN IF NOT EXISTS client_cert_cn TEXT;`,
+			// Чем подтверждён источник события: api_key, signature, mtls или untrusted; NULL - не проверялся
+			`ALTER TABLE anpr_events ADD COLUMN IF NOT EXISTS auth TEXT;`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_events_untrusted ON anpr_events(event_time DESC) WHERE auth = 'untrusted';`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_anpr_events_untrusted;`,
+			`ALTER TABLE anpr_events DROP COLUMN IF EXISTS auth;`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS client_cert_cn;`,
+			`ALTER TABLE anpr_cameras DROP COLUMN IF EXISTS api_key_hash;`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "retention_event_links",
+		Up: []string{
+			// Совпадения события со списками на момент приёма: политики хранения по list_type смотрят
+			// сюда, а не на текущее членство номера. Ссылки на списки нет - удаление списка не стирает факт совпадения
+			`CREATE TABLE IF NOT EXISTS anpr_event_list_hits (
+				event_id       UUID NOT NULL,
+				list_id        UUID NOT NULL,
+				list_type      TEXT NOT NULL,
+				matched_plate  TEXT NOT NULL,
+				candidate_rank INT NOT NULL DEFAULT 0,
+				created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (event_id, list_id)
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_event_list_hits_type ON anpr_event_list_hits(list_type, event_id);`,
+			// Для уже принятых событий момент приёма не восстановить - берётся текущее членство номера
+			`INSERT INTO anpr_event_list_hits (event_id, list_id, list_type, matched_plate)
+			SELECT e.id, l.id, l.type, e.normalized_plate
+			FROM anpr_list_items li
+			JOIN anpr_lists l ON l.id = li.list_id
+			JOIN anpr_events e ON e.plate_id = li.plate_id
+			WHERE e.read_status NOT IN ('unverified', 'plateless') AND e.auth IS DISTINCT FROM 'untrusted'
+			ON CONFLICT DO NOTHING;`,
+			// События, которые внешняя система поездок связала с поездкой (POST /api/v1/trips/:trip_id/events)
+			`CREATE TABLE IF NOT EXISTS anpr_event_trip_links (
+				event_id  UUID NOT NULL,
+				trip_id   TEXT NOT NULL,
+				linked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (event_id, trip_id)
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_anpr_event_trip_links_trip ON anpr_event_trip_links(trip_id);`,
+			// trip_linked - критерий связи с поездкой; retain_until - до этой даты (конец сезона) политика
+			// ничего не удаляет, после неё действует retain_days
+			`ALTER TABLE anpr_retention_policies ADD COLUMN IF NOT EXISTS trip_linked BOOLEAN;`,
+			`ALTER TABLE anpr_retention_policies ADD COLUMN IF NOT EXISTS retain_until TIMESTAMPTZ;`,
+		},
+		Down: []string{
+			`ALTER TABLE anpr_retention_policies DROP COLUMN IF EXISTS retain_until;`,
+			`ALTER TABLE anpr_retention_policies DROP COLUMN IF EXISTS trip_linked;`,
+			`DROP TABLE IF EXISTS anpr_event_trip_links;`,
+			`DROP TABLE IF EXISTS anpr_event_list_hits;`,
+		},
+	},
 }
@@ -16,6 +16,12 @@ type VehicleInfo struct {
 	Speed      *float64 `json:"speed,omitempty"`
 }
 
+// PlateCandidate - альтернативное прочтение номера движком распознавания
+type PlateCandidate struct {
+	Plate      string  `json:"plate"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
 type EventPayload struct {
 	CameraID    string                 `json:"camera_id"`
 	CameraModel string                 `json:"camera_model,omitempty"`
@@ -34,24 +40,81 @@ type EventPayload struct {
 	SnowVolumeConfidence *float64   `json:"snow_volume_confidence,omitempty"`
 	SnowDirectionAI      string     `json:"snow_direction_ai,omitempty"`
 	MatchedSnow          bool       `json:"matched_snow,omitempty"`
+	// Candidates - альтернативные прочтения по убыванию уверенности; без Plate основным
+	// прочтением становится лучший кандидат
+	Candidates []PlateCandidate `json:"candidates,omitempty"`
+	// ReceivedAt - время получения запроса сервером; TimeSource - откуда взято EventTime.
+	// Заполняются приёмом, а не клиентом.
+	ReceivedAt time.Time `json:"-"`
+	TimeSource string    `json:"-"`
+	// Auth - чем приём подтвердил источник события (Auth*); пусто - проверка отключена
+	Auth string `json:"-"`
 }
 
+// Источник времени события
+const (
+	// TimeSourceCamera - время из события камеры
+	TimeSourceCamera = "camera"
+	// TimeSourceServer - камера не передала разборчивое время, взято время получения
+	TimeSourceServer = "server"
+)
+
+// Подтверждение источника события при приёме
+const (
+	// AuthAPIKey - ключ камеры в заголовке X-API-Key или параметре api_key
+	AuthAPIKey = "api_key"
+	// AuthSignature - тело подписано HMAC пограничным ретранслятором
+	AuthSignature = "signature"
+	// AuthMTLS - клиентский сертификат камеры
+	AuthMTLS = "mtls"
+	// AuthUntrusted - источник не подтверждён: событие только сохраняется, без сверки со списками
+	AuthUntrusted = "untrusted"
+)
+
+// Исход политики уверенности камеры для прочитанного номера
+const (
+	ReadStatusAccepted = "accepted"
+	// ReadStatusFlagged - номер принят и сверен со списками, но требует проверки оператором
+	ReadStatusFlagged = "flagged"
+	// ReadStatusUnverified - номер сохранён без сверки со списками и проверок аномалий
+	ReadStatusUnverified = "unverified"
+	// ReadStatusPlateless - проезд ТС без прочитанного номера: сохраняются камера, время, признаки и снимок
+	ReadStatusPlateless = "plateless"
+)
+
 type Event struct {
 	ID      uuid.UUID
 	PlateID uuid.UUID
+	// PolygonID - полигон камеры из реестра камер
+	PolygonID *uuid.UUID
 	EventPayload
 	NormalizedPlate string
+	ReadStatus      string
+	// ClockSkew - насколько часы камеры опережают время получения (секунды); nil - время не от камеры
+	ClockSkew *float64
+	// PairedEventID - предыдущий проезд того же ТС без номера, найденный по признакам ТС
+	PairedEventID *uuid.UUID
 }
 
 type ListHit struct {
 	ListID   uuid.UUID `json:"list_id"`
 	ListName string    `json:"list_name"`
 	ListType string    `json:"list_type"`
+	// MatchedPlate - номер, совпавший со списком; CandidateRank - 0 для основного прочтения,
+	// иначе позиция альтернативного прочтения (с 1)
+	MatchedPlate  string `json:"matched_plate,omitempty"`
+	CandidateRank int    `json:"candidate_rank"`
 }
 
 type ProcessResult struct {
 	EventID uuid.UUID `json:"event_id"`
 	PlateID uuid.UUID `json:"plate_id"`
 	Plate   string    `json:"plate"`
-	Hits    []ListHit `json:"hits"`
+	// ReadStatus - accepted, flagged, unverified или plateless
+	ReadStatus string    `json:"read_status"`
+	Hits       []ListHit `json:"hits"`
+	// Anomalies - типы аномалий, найденных для события (attribute_mismatch, impossible_travel)
+	Anomalies []string `json:"anomalies,omitempty"`
+	// ReviewItemID - запись очереди проверки, если номер вне списков поставлен на проверку
+	ReviewItemID *uuid.UUID `json:"review_item_id,omitempty"`
 }
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatNDJSON = "ndjson"
+)
+
+// Column - колонка табличного экспорта
+type Column struct {
+	Name string
+	// Numeric - значение пишется в XLSX числом, а не строкой
+	Numeric bool
+}
+
+// Writer пишет строки в выходной поток по одной, не накапливая их в памяти.
+// values используются для CSV/XLSX, object - для NDJSON.
+type Writer interface {
+	WriteHeader(columns []Column) error
+	WriteRow(values []string, object interface{}) error
+	Close() error
+}
+
+// NewWriter создаёт writer для формата
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// Valid проверяет название формата
+func Valid(format string) bool {
+	return format == FormatCSV || format == FormatXLSX || format == FormatNDJSON
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func (c *csvWriter) WriteHeader(columns []Column) error {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	return c.w.Write(names)
+}
+
+func (c *csvWriter) WriteRow(values []string, _ interface{}) error {
+	if err := c.w.Write(values); err != nil {
+		return err
+	}
+	// Периодически сбрасываем буфер, чтобы клиент получал данные по мере выгрузки
+	c.rows++
+	if c.rows%500 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteHeader([]Column) error {
+	return nil
+}
+
+func (n *ndjsonWriter) WriteRow(_ []string, object interface{}) error {
+	return n.enc.Encode(object)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
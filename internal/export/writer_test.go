@@ -0,0 +1,77 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCellRef(t *testing.T) {
+	cases := map[int]string{0: "A1", 25: "Z1", 26: "AA1", 27: "AB1", 701: "ZZ1", 702: "AAA1"}
+	for col, want := range cases {
+		if got := cellRef(col, 1); got != want {
+			t.Errorf("cellRef(%d) = %s, want %s", col, got, want)
+		}
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader([]Column{{Name: "plate"}, {Name: "confidence", Numeric: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"123<ABC>02", "0.95"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+	if !strings.Contains(sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">123&lt;ABC&gt;02</t></is></c>`) {
+		t.Fatalf("escaped string cell missing: %s", sheet)
+	}
+	if !strings.Contains(sheet, `<c r="B2"><v>0.95</v></c>`) {
+		t.Fatalf("numeric cell missing: %s", sheet)
+	}
+	if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Fatal("sheet not closed")
+	}
+}
+
+func TestCSVAndNDJSON(t *testing.T) {
+	var csvBuf, jsonBuf bytes.Buffer
+	cw, _ := NewWriter(FormatCSV, &csvBuf)
+	jw, _ := NewWriter(FormatNDJSON, &jsonBuf)
+	for _, w := range []Writer{cw, jw} {
+		_ = w.WriteHeader([]Column{{Name: "plate"}})
+		_ = w.WriteRow([]string{"A,B"}, map[string]string{"plate": "A,B"})
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if csvBuf.String() != "plate\n\"A,B\"\n" {
+		t.Fatalf("unexpected csv: %q", csvBuf.String())
+	}
+	if jsonBuf.String() != "{\"plate\":\"A,B\"}\n" {
+		t.Fatalf("unexpected ndjson: %q", jsonBuf.String())
+	}
+}
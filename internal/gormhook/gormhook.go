@@ -0,0 +1,38 @@
+package gormhook
+
+import (
+	"gorm.io/gorm"
+)
+
+// Register регистрирует callbacks gorm вокруг каждой операции (create, query, update, delete,
+// row, raw) под именами <prefix>:before_<operation> и <prefix>:after_<operation>
+func Register(db *gorm.DB, prefix string, before, after func(operation string, tx *gorm.DB)) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		operation := h.operation
+		if err := h.before(prefix+":before_"+operation, func(tx *gorm.DB) {
+			before(operation, tx)
+		}); err != nil {
+			return err
+		}
+		if err := h.after(prefix+":after_"+operation, func(tx *gorm.DB) {
+			after(operation, tx)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
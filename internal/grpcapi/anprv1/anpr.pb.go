@@ -0,0 +1,1222 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: anpr/v1/anpr.proto
+
+package anprv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Vehicle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Color         string                 `protobuf:"bytes,1,opt,name=color,proto3" json:"color,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Brand         string                 `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"`
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`
+	Country       string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	PlateColor    string                 `protobuf:"bytes,6,opt,name=plate_color,json=plateColor,proto3" json:"plate_color,omitempty"`
+	Speed         *float64               `protobuf:"fixed64,7,opt,name=speed,proto3,oneof" json:"speed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vehicle) Reset() {
+	*x = Vehicle{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vehicle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vehicle) ProtoMessage() {}
+
+func (x *Vehicle) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vehicle.ProtoReflect.Descriptor instead.
+func (*Vehicle) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Vehicle) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Vehicle) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Vehicle) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Vehicle) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Vehicle) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Vehicle) GetPlateColor() string {
+	if x != nil {
+		return x.PlateColor
+	}
+	return ""
+}
+
+func (x *Vehicle) GetSpeed() float64 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+type PlateCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plate         string                 `protobuf:"bytes,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlateCandidate) Reset() {
+	*x = PlateCandidate{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlateCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlateCandidate) ProtoMessage() {}
+
+func (x *PlateCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlateCandidate.ProtoReflect.Descriptor instead.
+func (*PlateCandidate) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlateCandidate) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+func (x *PlateCandidate) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+type ProcessEventRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	CameraId    string                 `protobuf:"bytes,1,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	CameraModel string                 `protobuf:"bytes,2,opt,name=camera_model,json=cameraModel,proto3" json:"camera_model,omitempty"`
+	Plate       string                 `protobuf:"bytes,3,opt,name=plate,proto3" json:"plate,omitempty"`
+	Confidence  float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Direction   string                 `protobuf:"bytes,5,opt,name=direction,proto3" json:"direction,omitempty"`
+	Lane        int32                  `protobuf:"varint,6,opt,name=lane,proto3" json:"lane,omitempty"`
+	EventTime   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	Vehicle     *Vehicle               `protobuf:"bytes,8,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	SnapshotUrl string                 `protobuf:"bytes,9,opt,name=snapshot_url,json=snapshotUrl,proto3" json:"snapshot_url,omitempty"`
+	// candidates - альтернативные прочтения номера (ранжированный список движка распознавания)
+	Candidates    []*PlateCandidate `protobuf:"bytes,10,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessEventRequest) Reset() {
+	*x = ProcessEventRequest{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessEventRequest) ProtoMessage() {}
+
+func (x *ProcessEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessEventRequest.ProtoReflect.Descriptor instead.
+func (*ProcessEventRequest) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessEventRequest) GetCameraId() string {
+	if x != nil {
+		return x.CameraId
+	}
+	return ""
+}
+
+func (x *ProcessEventRequest) GetCameraModel() string {
+	if x != nil {
+		return x.CameraModel
+	}
+	return ""
+}
+
+func (x *ProcessEventRequest) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+func (x *ProcessEventRequest) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *ProcessEventRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *ProcessEventRequest) GetLane() int32 {
+	if x != nil {
+		return x.Lane
+	}
+	return 0
+}
+
+func (x *ProcessEventRequest) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+func (x *ProcessEventRequest) GetVehicle() *Vehicle {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *ProcessEventRequest) GetSnapshotUrl() string {
+	if x != nil {
+		return x.SnapshotUrl
+	}
+	return ""
+}
+
+func (x *ProcessEventRequest) GetCandidates() []*PlateCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+type ListHit struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ListId   string                 `protobuf:"bytes,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	ListName string                 `protobuf:"bytes,2,opt,name=list_name,json=listName,proto3" json:"list_name,omitempty"`
+	ListType string                 `protobuf:"bytes,3,opt,name=list_type,json=listType,proto3" json:"list_type,omitempty"`
+	// matched_plate - номер, совпавший со списком; candidate_rank - 0 для основного прочтения,
+	// иначе позиция альтернативного прочтения (с 1)
+	MatchedPlate  string `protobuf:"bytes,4,opt,name=matched_plate,json=matchedPlate,proto3" json:"matched_plate,omitempty"`
+	CandidateRank int32  `protobuf:"varint,5,opt,name=candidate_rank,json=candidateRank,proto3" json:"candidate_rank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHit) Reset() {
+	*x = ListHit{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHit) ProtoMessage() {}
+
+func (x *ListHit) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHit.ProtoReflect.Descriptor instead.
+func (*ListHit) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListHit) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+func (x *ListHit) GetListName() string {
+	if x != nil {
+		return x.ListName
+	}
+	return ""
+}
+
+func (x *ListHit) GetListType() string {
+	if x != nil {
+		return x.ListType
+	}
+	return ""
+}
+
+func (x *ListHit) GetMatchedPlate() string {
+	if x != nil {
+		return x.MatchedPlate
+	}
+	return ""
+}
+
+func (x *ListHit) GetCandidateRank() int32 {
+	if x != nil {
+		return x.CandidateRank
+	}
+	return 0
+}
+
+type ProcessEventResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	EventId string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	PlateId string                 `protobuf:"bytes,2,opt,name=plate_id,json=plateId,proto3" json:"plate_id,omitempty"`
+	Plate   string                 `protobuf:"bytes,3,opt,name=plate,proto3" json:"plate,omitempty"`
+	Hits    []*ListHit             `protobuf:"bytes,4,rep,name=hits,proto3" json:"hits,omitempty"`
+	// read_status - accepted, flagged, unverified или plateless
+	ReadStatus string   `protobuf:"bytes,5,opt,name=read_status,json=readStatus,proto3" json:"read_status,omitempty"`
+	Anomalies  []string `protobuf:"bytes,6,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	// review_item_id - запись очереди проверки, если номер поставлен на проверку
+	ReviewItemId  string `protobuf:"bytes,7,opt,name=review_item_id,json=reviewItemId,proto3" json:"review_item_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessEventResponse) Reset() {
+	*x = ProcessEventResponse{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessEventResponse) ProtoMessage() {}
+
+func (x *ProcessEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessEventResponse.ProtoReflect.Descriptor instead.
+func (*ProcessEventResponse) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProcessEventResponse) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ProcessEventResponse) GetPlateId() string {
+	if x != nil {
+		return x.PlateId
+	}
+	return ""
+}
+
+func (x *ProcessEventResponse) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+func (x *ProcessEventResponse) GetHits() []*ListHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *ProcessEventResponse) GetReadStatus() string {
+	if x != nil {
+		return x.ReadStatus
+	}
+	return ""
+}
+
+func (x *ProcessEventResponse) GetAnomalies() []string {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+func (x *ProcessEventResponse) GetReviewItemId() string {
+	if x != nil {
+		return x.ReviewItemId
+	}
+	return ""
+}
+
+type Plate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number        string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`
+	Normalized    string                 `protobuf:"bytes,3,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	LastEventTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_event_time,json=lastEventTime,proto3" json:"last_event_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Plate) Reset() {
+	*x = Plate{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plate) ProtoMessage() {}
+
+func (x *Plate) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plate.ProtoReflect.Descriptor instead.
+func (*Plate) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Plate) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Plate) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *Plate) GetNormalized() string {
+	if x != nil {
+		return x.Normalized
+	}
+	return ""
+}
+
+func (x *Plate) GetLastEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastEventTime
+	}
+	return nil
+}
+
+type FindPlatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plate         string                 `protobuf:"bytes,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindPlatesRequest) Reset() {
+	*x = FindPlatesRequest{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindPlatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindPlatesRequest) ProtoMessage() {}
+
+func (x *FindPlatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindPlatesRequest.ProtoReflect.Descriptor instead.
+func (*FindPlatesRequest) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FindPlatesRequest) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+type FindPlatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plates        []*Plate               `protobuf:"bytes,1,rep,name=plates,proto3" json:"plates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindPlatesResponse) Reset() {
+	*x = FindPlatesResponse{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindPlatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindPlatesResponse) ProtoMessage() {}
+
+func (x *FindPlatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindPlatesResponse.ProtoReflect.Descriptor instead.
+func (*FindPlatesResponse) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FindPlatesResponse) GetPlates() []*Plate {
+	if x != nil {
+		return x.Plates
+	}
+	return nil
+}
+
+type FindEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Plate string                 `protobuf:"bytes,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	From  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// limit - максимум событий в потоке, 0 - без ограничения
+	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindEventsRequest) Reset() {
+	*x = FindEventsRequest{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindEventsRequest) ProtoMessage() {}
+
+func (x *FindEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindEventsRequest.ProtoReflect.Descriptor instead.
+func (*FindEventsRequest) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FindEventsRequest) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+func (x *FindEventsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *FindEventsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *FindEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Event struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PlateId         string                 `protobuf:"bytes,2,opt,name=plate_id,json=plateId,proto3" json:"plate_id,omitempty"`
+	CameraId        string                 `protobuf:"bytes,3,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	CameraModel     string                 `protobuf:"bytes,4,opt,name=camera_model,json=cameraModel,proto3" json:"camera_model,omitempty"`
+	Direction       string                 `protobuf:"bytes,5,opt,name=direction,proto3" json:"direction,omitempty"`
+	Lane            int32                  `protobuf:"varint,6,opt,name=lane,proto3" json:"lane,omitempty"`
+	RawPlate        string                 `protobuf:"bytes,7,opt,name=raw_plate,json=rawPlate,proto3" json:"raw_plate,omitempty"`
+	NormalizedPlate string                 `protobuf:"bytes,8,opt,name=normalized_plate,json=normalizedPlate,proto3" json:"normalized_plate,omitempty"`
+	Confidence      *float64               `protobuf:"fixed64,9,opt,name=confidence,proto3,oneof" json:"confidence,omitempty"`
+	Vehicle         *Vehicle               `protobuf:"bytes,10,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	SnapshotUrl     string                 `protobuf:"bytes,11,opt,name=snapshot_url,json=snapshotUrl,proto3" json:"snapshot_url,omitempty"`
+	EventTime       *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	// read_status - исход политики уверенности: accepted, flagged, unverified или plateless
+	ReadStatus string            `protobuf:"bytes,13,opt,name=read_status,json=readStatus,proto3" json:"read_status,omitempty"`
+	Candidates []*PlateCandidate `protobuf:"bytes,14,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	// auth - чем подтверждён источник: api_key, signature, mtls, untrusted; пусто - не проверялся
+	Auth string `protobuf:"bytes,15,opt,name=auth,proto3" json:"auth,omitempty"`
+	// time_source - источник event_time: camera или server
+	TimeSource string `protobuf:"bytes,16,opt,name=time_source,json=timeSource,proto3" json:"time_source,omitempty"`
+	// paired_event_id - предыдущий проезд того же ТС без номера (только для plateless)
+	PairedEventId string `protobuf:"bytes,17,opt,name=paired_event_id,json=pairedEventId,proto3" json:"paired_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetPlateId() string {
+	if x != nil {
+		return x.PlateId
+	}
+	return ""
+}
+
+func (x *Event) GetCameraId() string {
+	if x != nil {
+		return x.CameraId
+	}
+	return ""
+}
+
+func (x *Event) GetCameraModel() string {
+	if x != nil {
+		return x.CameraModel
+	}
+	return ""
+}
+
+func (x *Event) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *Event) GetLane() int32 {
+	if x != nil {
+		return x.Lane
+	}
+	return 0
+}
+
+func (x *Event) GetRawPlate() string {
+	if x != nil {
+		return x.RawPlate
+	}
+	return ""
+}
+
+func (x *Event) GetNormalizedPlate() string {
+	if x != nil {
+		return x.NormalizedPlate
+	}
+	return ""
+}
+
+func (x *Event) GetConfidence() float64 {
+	if x != nil && x.Confidence != nil {
+		return *x.Confidence
+	}
+	return 0
+}
+
+func (x *Event) GetVehicle() *Vehicle {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *Event) GetSnapshotUrl() string {
+	if x != nil {
+		return x.SnapshotUrl
+	}
+	return ""
+}
+
+func (x *Event) GetEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EventTime
+	}
+	return nil
+}
+
+func (x *Event) GetReadStatus() string {
+	if x != nil {
+		return x.ReadStatus
+	}
+	return ""
+}
+
+func (x *Event) GetCandidates() []*PlateCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *Event) GetAuth() string {
+	if x != nil {
+		return x.Auth
+	}
+	return ""
+}
+
+func (x *Event) GetTimeSource() string {
+	if x != nil {
+		return x.TimeSource
+	}
+	return ""
+}
+
+func (x *Event) GetPairedEventId() string {
+	if x != nil {
+		return x.PairedEventId
+	}
+	return ""
+}
+
+type CheckPlateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plate         string                 `protobuf:"bytes,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckPlateRequest) Reset() {
+	*x = CheckPlateRequest{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckPlateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPlateRequest) ProtoMessage() {}
+
+func (x *CheckPlateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPlateRequest.ProtoReflect.Descriptor instead.
+func (*CheckPlateRequest) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CheckPlateRequest) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+type CheckPlateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plate         string                 `protobuf:"bytes,1,opt,name=plate,proto3" json:"plate,omitempty"`
+	Known         bool                   `protobuf:"varint,2,opt,name=known,proto3" json:"known,omitempty"`
+	PlateId       string                 `protobuf:"bytes,3,opt,name=plate_id,json=plateId,proto3" json:"plate_id,omitempty"`
+	Hits          []*ListHit             `protobuf:"bytes,4,rep,name=hits,proto3" json:"hits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckPlateResponse) Reset() {
+	*x = CheckPlateResponse{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckPlateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckPlateResponse) ProtoMessage() {}
+
+func (x *CheckPlateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckPlateResponse.ProtoReflect.Descriptor instead.
+func (*CheckPlateResponse) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CheckPlateResponse) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+func (x *CheckPlateResponse) GetKnown() bool {
+	if x != nil {
+		return x.Known
+	}
+	return false
+}
+
+func (x *CheckPlateResponse) GetPlateId() string {
+	if x != nil {
+		return x.PlateId
+	}
+	return ""
+}
+
+func (x *CheckPlateResponse) GetHits() []*ListHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Необязательные фильтры
+	CameraId      string `protobuf:"bytes,1,opt,name=camera_id,json=cameraId,proto3" json:"camera_id,omitempty"`
+	Plate         string `protobuf:"bytes,2,opt,name=plate,proto3" json:"plate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_anpr_v1_anpr_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_anpr_v1_anpr_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_anpr_v1_anpr_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchEventsRequest) GetCameraId() string {
+	if x != nil {
+		return x.CameraId
+	}
+	return ""
+}
+
+func (x *WatchEventsRequest) GetPlate() string {
+	if x != nil {
+		return x.Plate
+	}
+	return ""
+}
+
+var File_anpr_v1_anpr_proto protoreflect.FileDescriptor
+
+const file_anpr_v1_anpr_proto_rawDesc = "" +
+	"\n" +
+	"\x12anpr/v1/anpr.proto\x12\aanpr.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbf\x01\n" +
+	"\aVehicle\x12\x14\n" +
+	"\x05color\x18\x01 \x01(\tR\x05color\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\x12\x1f\n" +
+	"\vplate_color\x18\x06 \x01(\tR\n" +
+	"plateColor\x12\x19\n" +
+	"\x05speed\x18\a \x01(\x01H\x00R\x05speed\x88\x01\x01B\b\n" +
+	"\x06_speed\"F\n" +
+	"\x0ePlateCandidate\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\tR\x05plate\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\"\x80\x03\n" +
+	"\x13ProcessEventRequest\x12\x1b\n" +
+	"\tcamera_id\x18\x01 \x01(\tR\bcameraId\x12!\n" +
+	"\fcamera_model\x18\x02 \x01(\tR\vcameraModel\x12\x14\n" +
+	"\x05plate\x18\x03 \x01(\tR\x05plate\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12\x1c\n" +
+	"\tdirection\x18\x05 \x01(\tR\tdirection\x12\x12\n" +
+	"\x04lane\x18\x06 \x01(\x05R\x04lane\x129\n" +
+	"\n" +
+	"event_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\teventTime\x12*\n" +
+	"\avehicle\x18\b \x01(\v2\x10.anpr.v1.VehicleR\avehicle\x12!\n" +
+	"\fsnapshot_url\x18\t \x01(\tR\vsnapshotUrl\x127\n" +
+	"\n" +
+	"candidates\x18\n" +
+	" \x03(\v2\x17.anpr.v1.PlateCandidateR\n" +
+	"candidates\"\xa8\x01\n" +
+	"\aListHit\x12\x17\n" +
+	"\alist_id\x18\x01 \x01(\tR\x06listId\x12\x1b\n" +
+	"\tlist_name\x18\x02 \x01(\tR\blistName\x12\x1b\n" +
+	"\tlist_type\x18\x03 \x01(\tR\blistType\x12#\n" +
+	"\rmatched_plate\x18\x04 \x01(\tR\fmatchedPlate\x12%\n" +
+	"\x0ecandidate_rank\x18\x05 \x01(\x05R\rcandidateRank\"\xed\x01\n" +
+	"\x14ProcessEventResponse\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x19\n" +
+	"\bplate_id\x18\x02 \x01(\tR\aplateId\x12\x14\n" +
+	"\x05plate\x18\x03 \x01(\tR\x05plate\x12$\n" +
+	"\x04hits\x18\x04 \x03(\v2\x10.anpr.v1.ListHitR\x04hits\x12\x1f\n" +
+	"\vread_status\x18\x05 \x01(\tR\n" +
+	"readStatus\x12\x1c\n" +
+	"\tanomalies\x18\x06 \x03(\tR\tanomalies\x12$\n" +
+	"\x0ereview_item_id\x18\a \x01(\tR\freviewItemId\"\x93\x01\n" +
+	"\x05Plate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\tR\x06number\x12\x1e\n" +
+	"\n" +
+	"normalized\x18\x03 \x01(\tR\n" +
+	"normalized\x12B\n" +
+	"\x0flast_event_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rlastEventTime\")\n" +
+	"\x11FindPlatesRequest\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\tR\x05plate\"<\n" +
+	"\x12FindPlatesResponse\x12&\n" +
+	"\x06plates\x18\x01 \x03(\v2\x0e.anpr.v1.PlateR\x06plates\"\x9b\x01\n" +
+	"\x11FindEventsRequest\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\tR\x05plate\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\xe1\x04\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bplate_id\x18\x02 \x01(\tR\aplateId\x12\x1b\n" +
+	"\tcamera_id\x18\x03 \x01(\tR\bcameraId\x12!\n" +
+	"\fcamera_model\x18\x04 \x01(\tR\vcameraModel\x12\x1c\n" +
+	"\tdirection\x18\x05 \x01(\tR\tdirection\x12\x12\n" +
+	"\x04lane\x18\x06 \x01(\x05R\x04lane\x12\x1b\n" +
+	"\traw_plate\x18\a \x01(\tR\brawPlate\x12)\n" +
+	"\x10normalized_plate\x18\b \x01(\tR\x0fnormalizedPlate\x12#\n" +
+	"\n" +
+	"confidence\x18\t \x01(\x01H\x00R\n" +
+	"confidence\x88\x01\x01\x12*\n" +
+	"\avehicle\x18\n" +
+	" \x01(\v2\x10.anpr.v1.VehicleR\avehicle\x12!\n" +
+	"\fsnapshot_url\x18\v \x01(\tR\vsnapshotUrl\x129\n" +
+	"\n" +
+	"event_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\teventTime\x12\x1f\n" +
+	"\vread_status\x18\r \x01(\tR\n" +
+	"readStatus\x127\n" +
+	"\n" +
+	"candidates\x18\x0e \x03(\v2\x17.anpr.v1.PlateCandidateR\n" +
+	"candidates\x12\x12\n" +
+	"\x04auth\x18\x0f \x01(\tR\x04auth\x12\x1f\n" +
+	"\vtime_source\x18\x10 \x01(\tR\n" +
+	"timeSource\x12&\n" +
+	"\x0fpaired_event_id\x18\x11 \x01(\tR\rpairedEventIdB\r\n" +
+	"\v_confidence\")\n" +
+	"\x11CheckPlateRequest\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\tR\x05plate\"\x81\x01\n" +
+	"\x12CheckPlateResponse\x12\x14\n" +
+	"\x05plate\x18\x01 \x01(\tR\x05plate\x12\x14\n" +
+	"\x05known\x18\x02 \x01(\bR\x05known\x12\x19\n" +
+	"\bplate_id\x18\x03 \x01(\tR\aplateId\x12$\n" +
+	"\x04hits\x18\x04 \x03(\v2\x10.anpr.v1.ListHitR\x04hits\"G\n" +
+	"\x12WatchEventsRequest\x12\x1b\n" +
+	"\tcamera_id\x18\x01 \x01(\tR\bcameraId\x12\x14\n" +
+	"\x05plate\x18\x02 \x01(\tR\x05plate2\xe2\x02\n" +
+	"\vANPRService\x12K\n" +
+	"\fProcessEvent\x12\x1c.anpr.v1.ProcessEventRequest\x1a\x1d.anpr.v1.ProcessEventResponse\x12E\n" +
+	"\n" +
+	"FindPlates\x12\x1a.anpr.v1.FindPlatesRequest\x1a\x1b.anpr.v1.FindPlatesResponse\x12:\n" +
+	"\n" +
+	"FindEvents\x12\x1a.anpr.v1.FindEventsRequest\x1a\x0e.anpr.v1.Event0\x01\x12E\n" +
+	"\n" +
+	"CheckPlate\x12\x1a.anpr.v1.CheckPlateRequest\x1a\x1b.anpr.v1.CheckPlateResponse\x12<\n" +
+	"\vWatchEvents\x12\x1b.anpr.v1.WatchEventsRequest\x1a\x0e.anpr.v1.Event0\x01B-Z+anpr-service/internal/grpcapi/anprv1;anprv1b\x06proto3"
+
+var (
+	file_anpr_v1_anpr_proto_rawDescOnce sync.Once
+	file_anpr_v1_anpr_proto_rawDescData []byte
+)
+
+func file_anpr_v1_anpr_proto_rawDescGZIP() []byte {
+	file_anpr_v1_anpr_proto_rawDescOnce.Do(func() {
+		file_anpr_v1_anpr_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_anpr_v1_anpr_proto_rawDesc), len(file_anpr_v1_anpr_proto_rawDesc)))
+	})
+	return file_anpr_v1_anpr_proto_rawDescData
+}
+
+var file_anpr_v1_anpr_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_anpr_v1_anpr_proto_goTypes = []any{
+	(*Vehicle)(nil),               // 0: anpr.v1.Vehicle
+	(*PlateCandidate)(nil),        // 1: anpr.v1.PlateCandidate
+	(*ProcessEventRequest)(nil),   // 2: anpr.v1.ProcessEventRequest
+	(*ListHit)(nil),               // 3: anpr.v1.ListHit
+	(*ProcessEventResponse)(nil),  // 4: anpr.v1.ProcessEventResponse
+	(*Plate)(nil),                 // 5: anpr.v1.Plate
+	(*FindPlatesRequest)(nil),     // 6: anpr.v1.FindPlatesRequest
+	(*FindPlatesResponse)(nil),    // 7: anpr.v1.FindPlatesResponse
+	(*FindEventsRequest)(nil),     // 8: anpr.v1.FindEventsRequest
+	(*Event)(nil),                 // 9: anpr.v1.Event
+	(*CheckPlateRequest)(nil),     // 10: anpr.v1.CheckPlateRequest
+	(*CheckPlateResponse)(nil),    // 11: anpr.v1.CheckPlateResponse
+	(*WatchEventsRequest)(nil),    // 12: anpr.v1.WatchEventsRequest
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_anpr_v1_anpr_proto_depIdxs = []int32{
+	13, // 0: anpr.v1.ProcessEventRequest.event_time:type_name -> google.protobuf.Timestamp
+	0,  // 1: anpr.v1.ProcessEventRequest.vehicle:type_name -> anpr.v1.Vehicle
+	1,  // 2: anpr.v1.ProcessEventRequest.candidates:type_name -> anpr.v1.PlateCandidate
+	3,  // 3: anpr.v1.ProcessEventResponse.hits:type_name -> anpr.v1.ListHit
+	13, // 4: anpr.v1.Plate.last_event_time:type_name -> google.protobuf.Timestamp
+	5,  // 5: anpr.v1.FindPlatesResponse.plates:type_name -> anpr.v1.Plate
+	13, // 6: anpr.v1.FindEventsRequest.from:type_name -> google.protobuf.Timestamp
+	13, // 7: anpr.v1.FindEventsRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 8: anpr.v1.Event.vehicle:type_name -> anpr.v1.Vehicle
+	13, // 9: anpr.v1.Event.event_time:type_name -> google.protobuf.Timestamp
+	1,  // 10: anpr.v1.Event.candidates:type_name -> anpr.v1.PlateCandidate
+	3,  // 11: anpr.v1.CheckPlateResponse.hits:type_name -> anpr.v1.ListHit
+	2,  // 12: anpr.v1.ANPRService.ProcessEvent:input_type -> anpr.v1.ProcessEventRequest
+	6,  // 13: anpr.v1.ANPRService.FindPlates:input_type -> anpr.v1.FindPlatesRequest
+	8,  // 14: anpr.v1.ANPRService.FindEvents:input_type -> anpr.v1.FindEventsRequest
+	10, // 15: anpr.v1.ANPRService.CheckPlate:input_type -> anpr.v1.CheckPlateRequest
+	12, // 16: anpr.v1.ANPRService.WatchEvents:input_type -> anpr.v1.WatchEventsRequest
+	4,  // 17: anpr.v1.ANPRService.ProcessEvent:output_type -> anpr.v1.ProcessEventResponse
+	7,  // 18: anpr.v1.ANPRService.FindPlates:output_type -> anpr.v1.FindPlatesResponse
+	9,  // 19: anpr.v1.ANPRService.FindEvents:output_type -> anpr.v1.Event
+	11, // 20: anpr.v1.ANPRService.CheckPlate:output_type -> anpr.v1.CheckPlateResponse
+	9,  // 21: anpr.v1.ANPRService.WatchEvents:output_type -> anpr.v1.Event
+	17, // [17:22] is the sub-list for method output_type
+	12, // [12:17] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_anpr_v1_anpr_proto_init() }
+func file_anpr_v1_anpr_proto_init() {
+	if File_anpr_v1_anpr_proto != nil {
+		return
+	}
+	file_anpr_v1_anpr_proto_msgTypes[0].OneofWrappers = []any{}
+	file_anpr_v1_anpr_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_anpr_v1_anpr_proto_rawDesc), len(file_anpr_v1_anpr_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_anpr_v1_anpr_proto_goTypes,
+		DependencyIndexes: file_anpr_v1_anpr_proto_depIdxs,
+		MessageInfos:      file_anpr_v1_anpr_proto_msgTypes,
+	}.Build()
+	File_anpr_v1_anpr_proto = out.File
+	file_anpr_v1_anpr_proto_goTypes = nil
+	file_anpr_v1_anpr_proto_depIdxs = nil
+}
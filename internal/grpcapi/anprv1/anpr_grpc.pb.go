@@ -0,0 +1,300 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: anpr/v1/anpr.proto
+
+package anprv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ANPRService_ProcessEvent_FullMethodName = "/anpr.v1.ANPRService/ProcessEvent"
+	ANPRService_FindPlates_FullMethodName   = "/anpr.v1.ANPRService/FindPlates"
+	ANPRService_FindEvents_FullMethodName   = "/anpr.v1.ANPRService/FindEvents"
+	ANPRService_CheckPlate_FullMethodName   = "/anpr.v1.ANPRService/CheckPlate"
+	ANPRService_WatchEvents_FullMethodName  = "/anpr.v1.ANPRService/WatchEvents"
+)
+
+// ANPRServiceClient is the client API for ANPRService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ANPRService - gRPC-зеркало REST API сервиса.
+// Все методы требуют JWT в metadata: authorization: Bearer <token>.
+type ANPRServiceClient interface {
+	// ProcessEvent принимает событие распознавания (аналог POST /api/v1/anpr/events)
+	ProcessEvent(ctx context.Context, in *ProcessEventRequest, opts ...grpc.CallOption) (*ProcessEventResponse, error)
+	// FindPlates ищет номера (аналог GET /api/v1/plates)
+	FindPlates(ctx context.Context, in *FindPlatesRequest, opts ...grpc.CallOption) (*FindPlatesResponse, error)
+	// FindEvents отдаёт события потоком, без ограничения REST в 100 записей
+	FindEvents(ctx context.Context, in *FindEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// CheckPlate проверяет номер по спискам без создания события
+	CheckPlate(ctx context.Context, in *CheckPlateRequest, opts ...grpc.CallOption) (*CheckPlateResponse, error)
+	// WatchEvents отдаёт новые события по мере их приёма этим экземпляром сервиса.
+	// События других экземпляров не видны, пропущенные при переподключении не повторяются,
+	// а не успевшие в буфер медленного клиента отбрасываются.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type aNPRServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewANPRServiceClient(cc grpc.ClientConnInterface) ANPRServiceClient {
+	return &aNPRServiceClient{cc}
+}
+
+func (c *aNPRServiceClient) ProcessEvent(ctx context.Context, in *ProcessEventRequest, opts ...grpc.CallOption) (*ProcessEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessEventResponse)
+	err := c.cc.Invoke(ctx, ANPRService_ProcessEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aNPRServiceClient) FindPlates(ctx context.Context, in *FindPlatesRequest, opts ...grpc.CallOption) (*FindPlatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindPlatesResponse)
+	err := c.cc.Invoke(ctx, ANPRService_FindPlates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aNPRServiceClient) FindEvents(ctx context.Context, in *FindEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ANPRService_ServiceDesc.Streams[0], ANPRService_FindEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FindEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ANPRService_FindEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *aNPRServiceClient) CheckPlate(ctx context.Context, in *CheckPlateRequest, opts ...grpc.CallOption) (*CheckPlateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckPlateResponse)
+	err := c.cc.Invoke(ctx, ANPRService_CheckPlate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aNPRServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ANPRService_ServiceDesc.Streams[1], ANPRService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ANPRService_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+// ANPRServiceServer is the server API for ANPRService service.
+// All implementations must embed UnimplementedANPRServiceServer
+// for forward compatibility.
+//
+// ANPRService - gRPC-зеркало REST API сервиса.
+// Все методы требуют JWT в metadata: authorization: Bearer <token>.
+type ANPRServiceServer interface {
+	// ProcessEvent принимает событие распознавания (аналог POST /api/v1/anpr/events)
+	ProcessEvent(context.Context, *ProcessEventRequest) (*ProcessEventResponse, error)
+	// FindPlates ищет номера (аналог GET /api/v1/plates)
+	FindPlates(context.Context, *FindPlatesRequest) (*FindPlatesResponse, error)
+	// FindEvents отдаёт события потоком, без ограничения REST в 100 записей
+	FindEvents(*FindEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// CheckPlate проверяет номер по спискам без создания события
+	CheckPlate(context.Context, *CheckPlateRequest) (*CheckPlateResponse, error)
+	// WatchEvents отдаёт новые события по мере их приёма этим экземпляром сервиса.
+	// События других экземпляров не видны, пропущенные при переподключении не повторяются,
+	// а не успевшие в буфер медленного клиента отбрасываются.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedANPRServiceServer()
+}
+
+// UnimplementedANPRServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedANPRServiceServer struct{}
+
+func (UnimplementedANPRServiceServer) ProcessEvent(context.Context, *ProcessEventRequest) (*ProcessEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessEvent not implemented")
+}
+func (UnimplementedANPRServiceServer) FindPlates(context.Context, *FindPlatesRequest) (*FindPlatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindPlates not implemented")
+}
+func (UnimplementedANPRServiceServer) FindEvents(*FindEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method FindEvents not implemented")
+}
+func (UnimplementedANPRServiceServer) CheckPlate(context.Context, *CheckPlateRequest) (*CheckPlateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPlate not implemented")
+}
+func (UnimplementedANPRServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedANPRServiceServer) mustEmbedUnimplementedANPRServiceServer() {}
+func (UnimplementedANPRServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeANPRServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ANPRServiceServer will
+// result in compilation errors.
+type UnsafeANPRServiceServer interface {
+	mustEmbedUnimplementedANPRServiceServer()
+}
+
+func RegisterANPRServiceServer(s grpc.ServiceRegistrar, srv ANPRServiceServer) {
+	// If the following call pancis, it indicates UnimplementedANPRServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ANPRService_ServiceDesc, srv)
+}
+
+func _ANPRService_ProcessEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ANPRServiceServer).ProcessEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ANPRService_ProcessEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ANPRServiceServer).ProcessEvent(ctx, req.(*ProcessEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ANPRService_FindPlates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindPlatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ANPRServiceServer).FindPlates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ANPRService_FindPlates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ANPRServiceServer).FindPlates(ctx, req.(*FindPlatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ANPRService_FindEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ANPRServiceServer).FindEvents(m, &grpc.GenericServerStream[FindEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ANPRService_FindEventsServer = grpc.ServerStreamingServer[Event]
+
+func _ANPRService_CheckPlate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPlateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ANPRServiceServer).CheckPlate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ANPRService_CheckPlate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ANPRServiceServer).CheckPlate(ctx, req.(*CheckPlateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ANPRService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ANPRServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ANPRService_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+// ANPRService_ServiceDesc is the grpc.ServiceDesc for ANPRService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ANPRService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "anpr.v1.ANPRService",
+	HandlerType: (*ANPRServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessEvent",
+			Handler:    _ANPRService_ProcessEvent_Handler,
+		},
+		{
+			MethodName: "FindPlates",
+			Handler:    _ANPRService_FindPlates_Handler,
+		},
+		{
+			MethodName: "CheckPlate",
+			Handler:    _ANPRService_CheckPlate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FindEvents",
+			Handler:       _ANPRService_FindEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _ANPRService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "anpr/v1/anpr.proto",
+}
@@ -0,0 +1,4 @@
+package grpcapi
+
+// Код в anprv1 генерируется из api/proto/anpr/v1/anpr.proto
+//go:generate protoc -I ../../api/proto --go_out=../.. --go_opt=module=anpr-service --go-grpc_out=../.. --go-grpc_opt=module=anpr-service anpr/v1/anpr.proto
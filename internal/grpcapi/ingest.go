@@ -0,0 +1,125 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/service"
+)
+
+// apiKeyKey - ключ API камеры в metadata, как заголовок X-API-Key REST-приёма
+const apiKeyKey = "x-api-key"
+
+const (
+	authPolicyUntrusted = "untrusted"
+	authPolicyReject    = "reject"
+)
+
+// cameraService - методы CameraService, которыми приём проверяет источник события
+type cameraService interface {
+	Lookup(ctx context.Context, cameraID string) (*repository.Camera, error)
+	AddressAllowed(ctx context.Context, cameraID, clientIP string) (bool, error)
+	AuthenticateAPIKey(ctx context.Context, cameraID, key string) (bool, error)
+}
+
+// authorizeIngest пропускает к ProcessEvent только роли из GRPC_INGEST_ROLES: JWT пользователя
+// не подтверждает, что событие пришло от камеры
+func (s *Server) authorizeIngest(ctx context.Context) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || !slices.Contains(s.config.GRPC.IngestRoles, string(principal.Role)) {
+		return status.Error(codes.PermissionDenied, "role is not allowed to submit events")
+	}
+	return nil
+}
+
+// authenticateCamera подтверждает источник события ключом API камеры из metadata x-api-key;
+// без ключа действует INGEST_AUTH_POLICY, как для REST. Возвращает значение anpr.EventPayload.Auth.
+func (s *Server) authenticateCamera(ctx context.Context, cameraID string) (string, error) {
+	// Событие без camera_id отклонит приём; его нечем сопоставить с ключом
+	if cameraID == "" {
+		return "", nil
+	}
+
+	if key := incomingAPIKey(ctx); key != "" {
+		ok, err := s.cameras.AuthenticateAPIKey(ctx, cameraID, key)
+		if err != nil {
+			metrics.Reject(cameraID, metrics.RejectDBError)
+			return "", err
+		}
+		if !ok {
+			metrics.Reject(cameraID, metrics.RejectInvalidCredentials)
+			return "", status.Error(codes.Unauthenticated, "invalid api key")
+		}
+		metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), anpr.AuthAPIKey).Inc()
+		return anpr.AuthAPIKey, nil
+	}
+
+	switch s.config.Ingest.AuthPolicy {
+	case authPolicyReject:
+		metrics.Reject(cameraID, metrics.RejectUnauthenticated)
+		return "", status.Error(codes.Unauthenticated, "camera authentication required")
+	case authPolicyUntrusted:
+		metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), anpr.AuthUntrusted).Inc()
+		return anpr.AuthUntrusted, nil
+	}
+	metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), metrics.AuthNone).Inc()
+	return "", nil
+}
+
+// admitCamera проверяет адрес клиента по allowlist камеры и лимит запросов камеры,
+// как admitCamera REST-приёма. Вызывается после authenticateCamera.
+func (s *Server) admitCamera(ctx context.Context, cameraID, auth string) error {
+	if cameraID == "" {
+		return nil
+	}
+	camera, err := s.cameras.Lookup(ctx, cameraID)
+	if err != nil {
+		metrics.Reject(cameraID, metrics.RejectDBError)
+		return err
+	}
+	allowed, err := s.cameras.AddressAllowed(ctx, cameraID, peerIP(ctx))
+	if err != nil {
+		metrics.Reject(cameraID, metrics.RejectDBError)
+		return err
+	}
+	if !allowed {
+		metrics.Reject(cameraID, metrics.RejectAddressNotAllowed)
+		return status.Error(codes.PermissionDenied, "address is not allowed for this camera")
+	}
+	if ok, _ := s.cameraLimiter.Allow(service.CameraBucket(cameraID, auth, camera != nil)); !ok {
+		metrics.Reject(cameraID, metrics.RejectCameraRateLimited)
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+func incomingAPIKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(apiKeyKey); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// peerIP - адрес клиента gRPC без порта; пусто - адрес неизвестен
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,125 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"anpr-service/internal/auth"
+	"anpr-service/internal/logger"
+	"anpr-service/internal/model"
+)
+
+const (
+	authorizationKey = "authorization"
+	requestIDKey     = "x-request-id"
+	bearerPrefix     = "bearer "
+)
+
+type principalKey struct{}
+
+// PrincipalFromContext возвращает пользователя, аутентифицированного интерсептором
+func PrincipalFromContext(ctx context.Context) (model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(model.Principal)
+	return principal, ok
+}
+
+// authenticate проверяет JWT из metadata тем же auth.Parser, что и REST
+func authenticate(ctx context.Context, parser *auth.Parser) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata missing")
+	}
+	raw := values[0]
+	if len(raw) <= len(bearerPrefix) || !strings.EqualFold(raw[:len(bearerPrefix)], bearerPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+
+	claims, err := parser.Parse(strings.TrimSpace(raw[len(bearerPrefix):]))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return context.WithValue(ctx, principalKey{}, model.Principal{
+		UserID:   claims.UserID,
+		OrgID:    claims.OrgID,
+		Role:     claims.Role,
+		DriverID: claims.DriverID,
+	}), nil
+}
+
+// withRequestLogger кладёт в контекст логгер с request_id (из x-request-id или новым)
+func withRequestLogger(ctx context.Context, base zerolog.Logger, method string) (context.Context, zerolog.Logger) {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 && len(values[0]) <= 128 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	log := base.With().Str("request_id", requestID).Str("grpc_method", method).Logger()
+	return logger.WithContext(ctx, log), log
+}
+
+func logCall(log zerolog.Logger, start time.Time, err error) {
+	code := status.Code(err)
+	event := log.Info()
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		event = log.Error().Err(err)
+	default:
+		event = log.Warn().Err(err)
+	}
+	event.Str("code", code.String()).Dur("latency", time.Since(start)).Msg("grpc request")
+}
+
+func unaryInterceptor(parser *auth.Parser, base zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		ctx, log := withRequestLogger(ctx, base, info.FullMethod)
+		defer func() { logCall(log, start, err) }()
+
+		ctx, err = authenticate(ctx, parser)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// wrappedStream подменяет контекст потока
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func streamInterceptor(parser *auth.Parser, base zerolog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx, log := withRequestLogger(ss.Context(), base, info.FullMethod)
+		defer func() { logCall(log, start, err) }()
+
+		ctx, err = authenticate(ctx, parser)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
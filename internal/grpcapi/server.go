@@ -0,0 +1,322 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"anpr-service/internal/auth"
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/grpcapi/anprv1"
+	"anpr-service/internal/logger"
+	"anpr-service/internal/ratelimit"
+	"anpr-service/internal/service"
+	"anpr-service/internal/utils"
+)
+
+const (
+	// findEventsPage - размер страницы при потоковой выдаче FindEvents (максимум ANPRService.FindEvents)
+	findEventsPage = 100
+	watchBuffer    = 256
+)
+
+// eventService - методы ANPRService, на которых построен gRPC API
+type eventService interface {
+	ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (*anpr.ProcessResult, error)
+	FindPlates(ctx context.Context, plateQuery string) ([]service.PlateInfo, error)
+	FindEventsPage(ctx context.Context, q service.EventQuery) (*service.EventPage, error)
+	CheckPlate(ctx context.Context, plateQuery string) (*service.PlateCheck, error)
+	Events() *service.EventBus
+}
+
+// Server реализует anprv1.ANPRServiceServer поверх ANPRService
+type Server struct {
+	anprv1.UnimplementedANPRServiceServer
+
+	anprService eventService
+	// cameras и cameraLimiter - проверка источника событий ProcessEvent (allowlist, ключ API,
+	// INGEST_CAMERA_RATE), как у REST-приёма; лимит ведётся отдельно от HTTP
+	cameras       cameraService
+	cameraLimiter *ratelimit.Limiter
+	config        *config.Config
+	log           zerolog.Logger
+}
+
+func NewServer(anprService *service.ANPRService, cameraService *service.CameraService, cfg *config.Config, log zerolog.Logger) *Server {
+	return &Server{
+		anprService:   anprService,
+		cameras:       cameraService,
+		cameraLimiter: ratelimit.New(cfg.Ingest.CameraRate, cfg.Ingest.CameraBurst),
+		config:        cfg,
+		log:           log,
+	}
+}
+
+// NewGRPCServer создаёт grpc.Server с JWT-аутентификацией, логированием и трассировкой
+func NewGRPCServer(srv *Server, parser *auth.Parser) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptor(parser, srv.log)),
+		grpc.ChainStreamInterceptor(streamInterceptor(parser, srv.log)),
+	)
+	anprv1.RegisterANPRServiceServer(server, srv)
+	return server
+}
+
+// ProcessEvent принимает событие от сервисной учётной записи (GRPC_INGEST_ROLES). Источник
+// подтверждается ключом API камеры в metadata x-api-key, без него действует INGEST_AUTH_POLICY.
+func (s *Server) ProcessEvent(ctx context.Context, req *anprv1.ProcessEventRequest) (*anprv1.ProcessEventResponse, error) {
+	if err := s.authorizeIngest(ctx); err != nil {
+		return nil, err
+	}
+	auth, err := s.authenticateCamera(ctx, req.GetCameraId())
+	if err == nil {
+		err = s.admitCamera(ctx, req.GetCameraId(), auth)
+	}
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+
+	payload := anpr.EventPayload{
+		CameraID:    req.GetCameraId(),
+		CameraModel: req.GetCameraModel(),
+		Plate:       req.GetPlate(),
+		Confidence:  req.GetConfidence(),
+		Direction:   req.GetDirection(),
+		Lane:        int(req.GetLane()),
+		SnapshotURL: req.GetSnapshotUrl(),
+		ReceivedAt:  time.Now(),
+		Auth:        auth,
+	}
+	if req.GetEventTime() != nil {
+		payload.EventTime = req.GetEventTime().AsTime()
+	} else {
+		payload.EventTime = payload.ReceivedAt
+		payload.TimeSource = anpr.TimeSourceServer
+	}
+	if v := req.GetVehicle(); v != nil {
+		payload.Vehicle = anpr.VehicleInfo{
+			Color:      v.GetColor(),
+			Type:       v.GetType(),
+			Brand:      v.GetBrand(),
+			Model:      v.GetModel(),
+			Country:    v.GetCountry(),
+			PlateColor: v.GetPlateColor(),
+			Speed:      v.Speed,
+		}
+	}
+	for _, c := range req.GetCandidates() {
+		payload.Candidates = append(payload.Candidates, anpr.PlateCandidate{Plate: c.GetPlate(), Confidence: c.GetConfidence()})
+	}
+
+	result, err := s.anprService.ProcessIncomingEvent(ctx, payload, s.config.Camera.Model)
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+
+	resp := &anprv1.ProcessEventResponse{
+		EventId:    result.EventID.String(),
+		Plate:      result.Plate,
+		Hits:       toListHits(result.Hits),
+		ReadStatus: result.ReadStatus,
+		Anomalies:  result.Anomalies,
+	}
+	// Проезд без номера не привязан к номеру
+	if result.PlateID != uuid.Nil {
+		resp.PlateId = result.PlateID.String()
+	}
+	if result.ReviewItemID != nil {
+		resp.ReviewItemId = result.ReviewItemID.String()
+	}
+	return resp, nil
+}
+
+func (s *Server) FindPlates(ctx context.Context, req *anprv1.FindPlatesRequest) (*anprv1.FindPlatesResponse, error) {
+	plates, err := s.anprService.FindPlates(ctx, req.GetPlate())
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+
+	resp := &anprv1.FindPlatesResponse{Plates: make([]*anprv1.Plate, 0, len(plates))}
+	for _, p := range plates {
+		plate := &anprv1.Plate{Id: p.ID, Number: p.Number, Normalized: p.Normalized}
+		if p.LastEventTime != nil {
+			plate.LastEventTime = timestamppb.New(*p.LastEventTime)
+		}
+		resp.Plates = append(resp.Plates, plate)
+	}
+	return resp, nil
+}
+
+// FindEvents отдаёт события постранично по курсору, пока не исчерпаны результаты или limit
+func (s *Server) FindEvents(req *anprv1.FindEventsRequest, stream grpc.ServerStreamingServer[anprv1.Event]) error {
+	ctx := stream.Context()
+
+	query := service.EventQuery{Limit: findEventsPage}
+	if req.GetPlate() != "" {
+		value := req.GetPlate()
+		query.Plate = &value
+	}
+	if req.GetFrom() != nil {
+		value := req.GetFrom().AsTime().Format(time.RFC3339Nano)
+		query.From = &value
+	}
+	if req.GetTo() != nil {
+		value := req.GetTo().AsTime().Format(time.RFC3339Nano)
+		query.To = &value
+	}
+	limit := int(req.GetLimit())
+
+	sent := 0
+	for {
+		page, err := s.anprService.FindEventsPage(ctx, query)
+		if err != nil {
+			return s.toStatus(ctx, err)
+		}
+		for _, e := range page.Events {
+			if limit > 0 && sent >= limit {
+				return nil
+			}
+			if err := stream.Send(toEvent(e)); err != nil {
+				return err
+			}
+			sent++
+		}
+		if page.NextCursor == nil {
+			return nil
+		}
+		query.Cursor = *page.NextCursor
+	}
+}
+
+func (s *Server) CheckPlate(ctx context.Context, req *anprv1.CheckPlateRequest) (*anprv1.CheckPlateResponse, error) {
+	check, err := s.anprService.CheckPlate(ctx, req.GetPlate())
+	if err != nil {
+		return nil, s.toStatus(ctx, err)
+	}
+
+	resp := &anprv1.CheckPlateResponse{
+		Plate: check.Plate,
+		Known: check.Known,
+		Hits:  toListHits(check.Hits),
+	}
+	if check.PlateID != nil {
+		resp.PlateId = *check.PlateID
+	}
+	return resp, nil
+}
+
+// WatchEvents отдаёт события, принятые этим экземпляром после подписки. События других
+// экземпляров сюда не попадают: шина событий живёт в памяти процесса.
+func (s *Server) WatchEvents(req *anprv1.WatchEventsRequest, stream grpc.ServerStreamingServer[anprv1.Event]) error {
+	ctx := stream.Context()
+	plate := ""
+	if req.GetPlate() != "" {
+		plate = utils.NormalizePlate(req.GetPlate())
+	}
+
+	events, unsubscribe := s.anprService.Events().Subscribe(watchBuffer)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if req.GetCameraId() != "" && e.CameraID != req.GetCameraId() {
+				continue
+			}
+			if plate != "" && e.NormalizedPlate != plate {
+				continue
+			}
+			if err := stream.Send(toEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) toStatus(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		logger.FromContext(ctx, s.log).Error().Err(err).Msg("grpc handler error")
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toListHits(hits []anpr.ListHit) []*anprv1.ListHit {
+	result := make([]*anprv1.ListHit, 0, len(hits))
+	for _, h := range hits {
+		result = append(result, &anprv1.ListHit{
+			ListId:        h.ListID.String(),
+			ListName:      h.ListName,
+			ListType:      h.ListType,
+			MatchedPlate:  h.MatchedPlate,
+			CandidateRank: int32(h.CandidateRank),
+		})
+	}
+	return result
+}
+
+func toEvent(e service.EventInfo) *anprv1.Event {
+	event := &anprv1.Event{
+		Id:              e.ID,
+		CameraId:        e.CameraID,
+		RawPlate:        e.RawPlate,
+		NormalizedPlate: e.NormalizedPlate,
+		Confidence:      e.Confidence,
+		EventTime:       timestamppb.New(e.EventTime),
+		Vehicle: &anprv1.Vehicle{
+			Color:      deref(e.VehicleColor),
+			Type:       deref(e.VehicleType),
+			Brand:      deref(e.VehicleBrand),
+			Model:      deref(e.VehicleModel),
+			Country:    deref(e.VehicleCountry),
+			PlateColor: deref(e.VehiclePlateColor),
+			Speed:      e.VehicleSpeed,
+		},
+		PlateId:       deref(e.PlateID),
+		CameraModel:   deref(e.CameraModel),
+		Direction:     deref(e.Direction),
+		SnapshotUrl:   deref(e.SnapshotURL),
+		ReadStatus:    e.ReadStatus,
+		Auth:          deref(e.Auth),
+		TimeSource:    e.TimeSource,
+		PairedEventId: deref(e.PairedEventID),
+	}
+	if e.Lane != nil {
+		event.Lane = int32(*e.Lane)
+	}
+	for _, c := range e.Candidates {
+		event.Candidates = append(event.Candidates, &anprv1.PlateCandidate{Plate: c.Plate, Confidence: c.Confidence})
+	}
+	return event
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
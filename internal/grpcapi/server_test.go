@@ -0,0 +1,328 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"anpr-service/internal/auth"
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/grpcapi/anprv1"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/service"
+)
+
+const testSecret = "test-secret"
+
+// fakeEvents подменяет ANPRService: запоминает принятые события и публикует их в шину
+type fakeEvents struct {
+	bus *service.EventBus
+
+	mu       sync.Mutex
+	payloads []anpr.EventPayload
+}
+
+func (f *fakeEvents) ProcessIncomingEvent(_ context.Context, payload anpr.EventPayload, _ string) (*anpr.ProcessResult, error) {
+	if payload.CameraID == "" {
+		return nil, fmt.Errorf("%w: camera_id is required", service.ErrInvalidInput)
+	}
+	f.mu.Lock()
+	f.payloads = append(f.payloads, payload)
+	f.mu.Unlock()
+
+	reviewID := uuid.New()
+	return &anpr.ProcessResult{
+		EventID:    uuid.New(),
+		PlateID:    uuid.New(),
+		Plate:      payload.Plate,
+		ReadStatus: anpr.ReadStatusFlagged,
+		Hits: []anpr.ListHit{{
+			ListID:        uuid.New(),
+			ListName:      "stolen",
+			ListType:      "BLACKLIST",
+			MatchedPlate:  payload.Candidates[0].Plate,
+			CandidateRank: 1,
+		}},
+		Anomalies:    []string{"impossible_travel"},
+		ReviewItemID: &reviewID,
+	}, nil
+}
+
+func (f *fakeEvents) FindPlates(context.Context, string) ([]service.PlateInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeEvents) FindEventsPage(context.Context, service.EventQuery) (*service.EventPage, error) {
+	return &service.EventPage{}, nil
+}
+
+func (f *fakeEvents) CheckPlate(context.Context, string) (*service.PlateCheck, error) {
+	return nil, service.ErrNotFound
+}
+
+func (f *fakeEvents) Events() *service.EventBus {
+	return f.bus
+}
+
+// fakeCameras - реестр камер в памяти: зарегистрированные камеры и их ключи API
+type fakeCameras struct {
+	apiKeys map[string]string
+}
+
+func (f *fakeCameras) Lookup(_ context.Context, cameraID string) (*repository.Camera, error) {
+	if _, ok := f.apiKeys[cameraID]; !ok {
+		return nil, nil
+	}
+	return &repository.Camera{CameraID: cameraID}, nil
+}
+
+func (f *fakeCameras) AddressAllowed(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeCameras) AuthenticateAPIKey(_ context.Context, cameraID, key string) (bool, error) {
+	expected, ok := f.apiKeys[cameraID]
+	return ok && expected == key, nil
+}
+
+func testConfig(authPolicy string) *config.Config {
+	return &config.Config{
+		GRPC:   config.GRPCConfig{IngestRoles: []string{string(model.UserRoleAkimatAdmin)}},
+		Ingest: config.IngestConfig{AuthPolicy: authPolicy},
+	}
+}
+
+func startServer(t *testing.T, svc eventService) anprv1.ANPRServiceClient {
+	t.Helper()
+	return startServerWith(t, svc, testConfig("off"))
+}
+
+func startServerWith(t *testing.T, svc eventService, cfg *config.Config) anprv1.ANPRServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := &Server{
+		anprService: svc,
+		cameras:     &fakeCameras{apiKeys: map[string]string{"cam-1": "camera-key"}},
+		config:      cfg,
+		log:         zerolog.Nop(),
+	}
+	server := NewGRPCServer(srv, auth.NewParser(testSecret))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return anprv1.NewANPRServiceClient(conn)
+}
+
+func authorized(t *testing.T, ctx context.Context) context.Context {
+	t.Helper()
+	return authorizedAs(t, ctx, model.UserRoleAkimatAdmin)
+}
+
+func authorizedAs(t *testing.T, ctx context.Context, role model.UserRole) context.Context {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		UserID: uuid.New(),
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+}
+
+func TestProcessEvent(t *testing.T) {
+	svc := &fakeEvents{bus: service.NewEventBus()}
+	client := startServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &anprv1.ProcessEventRequest{
+		CameraId:   "cam-1",
+		Plate:      "123ABC02",
+		Confidence: 0.7,
+		Candidates: []*anprv1.PlateCandidate{{Plate: "123ABC03", Confidence: 0.6}},
+	}
+	if _, err := client.ProcessEvent(ctx, req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("without token: expected Unauthenticated, got %v", err)
+	}
+
+	resp, err := client.ProcessEvent(authorized(t, ctx), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetReadStatus() != anpr.ReadStatusFlagged || resp.GetReviewItemId() == "" || len(resp.GetAnomalies()) != 1 {
+		t.Errorf("unexpected response: %v", resp)
+	}
+	if len(resp.GetHits()) != 1 || resp.GetHits()[0].GetMatchedPlate() != "123ABC03" || resp.GetHits()[0].GetCandidateRank() != 1 {
+		t.Errorf("unexpected hits: %v", resp.GetHits())
+	}
+
+	svc.mu.Lock()
+	payload := svc.payloads[0]
+	svc.mu.Unlock()
+	if len(payload.Candidates) != 1 || payload.Candidates[0].Plate != "123ABC03" || payload.Candidates[0].Confidence != 0.6 {
+		t.Errorf("candidates not passed to the service: %+v", payload.Candidates)
+	}
+	if payload.TimeSource != anpr.TimeSourceServer || payload.EventTime.IsZero() {
+		t.Errorf("event without event_time must use server time: %+v", payload)
+	}
+}
+
+func TestProcessEventCameraAuthentication(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &anprv1.ProcessEventRequest{
+		CameraId:   "cam-1",
+		Plate:      "123ABC02",
+		Confidence: 0.9,
+		Candidates: []*anprv1.PlateCandidate{{Plate: "123ABC02", Confidence: 0.9}},
+	}
+
+	t.Run("reject without key", func(t *testing.T) {
+		svc := &fakeEvents{bus: service.NewEventBus()}
+		client := startServerWith(t, svc, testConfig(authPolicyReject))
+
+		resp, err := client.ProcessEvent(authorized(t, ctx), req)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated, got %v", err)
+		}
+		if len(resp.GetHits()) != 0 || len(svc.payloads) != 0 {
+			t.Errorf("unauthenticated source must not reach the service: hits %v, payloads %d", resp.GetHits(), len(svc.payloads))
+		}
+	})
+
+	t.Run("reject with wrong key", func(t *testing.T) {
+		svc := &fakeEvents{bus: service.NewEventBus()}
+		client := startServerWith(t, svc, testConfig(authPolicyReject))
+
+		_, err := client.ProcessEvent(metadata.AppendToOutgoingContext(authorized(t, ctx), apiKeyKey, "stolen"), req)
+		if status.Code(err) != codes.Unauthenticated || len(svc.payloads) != 0 {
+			t.Fatalf("expected Unauthenticated without processing, got %v", err)
+		}
+	})
+
+	t.Run("reject with camera key", func(t *testing.T) {
+		svc := &fakeEvents{bus: service.NewEventBus()}
+		client := startServerWith(t, svc, testConfig(authPolicyReject))
+
+		if _, err := client.ProcessEvent(metadata.AppendToOutgoingContext(authorized(t, ctx), apiKeyKey, "camera-key"), req); err != nil {
+			t.Fatal(err)
+		}
+		if len(svc.payloads) != 1 || svc.payloads[0].Auth != anpr.AuthAPIKey {
+			t.Errorf("payloads = %+v, want one with auth api_key", svc.payloads)
+		}
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		svc := &fakeEvents{bus: service.NewEventBus()}
+		client := startServerWith(t, svc, testConfig(authPolicyUntrusted))
+
+		if _, err := client.ProcessEvent(authorized(t, ctx), req); err != nil {
+			t.Fatal(err)
+		}
+		if len(svc.payloads) != 1 || svc.payloads[0].Auth != anpr.AuthUntrusted {
+			t.Errorf("payloads = %+v, want one with auth untrusted", svc.payloads)
+		}
+	})
+
+	t.Run("driver role", func(t *testing.T) {
+		svc := &fakeEvents{bus: service.NewEventBus()}
+		client := startServerWith(t, svc, testConfig("off"))
+
+		_, err := client.ProcessEvent(authorizedAs(t, ctx, model.UserRoleDriver), req)
+		if status.Code(err) != codes.PermissionDenied || len(svc.payloads) != 0 {
+			t.Fatalf("expected PermissionDenied without processing, got %v", err)
+		}
+	})
+}
+
+func TestProcessEventInvalidInput(t *testing.T) {
+	client := startServer(t, &fakeEvents{bus: service.NewEventBus()})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.ProcessEvent(authorized(t, ctx), &anprv1.ProcessEventRequest{Plate: "123ABC02"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	svc := &fakeEvents{bus: service.NewEventBus()}
+	client := startServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchEvents(authorized(t, ctx), &anprv1.WatchEventsRequest{CameraId: "cam-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := anpr.AuthAPIKey
+	paired := uuid.NewString()
+	// Подписка на шину появляется асинхронно, поэтому события публикуются, пока клиент не получит первое
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			svc.bus.Publish(service.EventInfo{ID: "other", CameraID: "cam-2"})
+			svc.bus.Publish(service.EventInfo{
+				ID:            "watched",
+				CameraID:      "cam-1",
+				ReadStatus:    anpr.ReadStatusPlateless,
+				TimeSource:    anpr.TimeSourceCamera,
+				Auth:          &auth,
+				PairedEventID: &paired,
+				Candidates:    []anpr.PlateCandidate{{Plate: "123ABC03", Confidence: 0.5}},
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetId() != "watched" {
+		t.Fatalf("camera filter not applied: got event %q", event.GetId())
+	}
+	if event.GetReadStatus() != anpr.ReadStatusPlateless || event.GetAuth() != anpr.AuthAPIKey ||
+		event.GetTimeSource() != anpr.TimeSourceCamera || event.GetPairedEventId() != paired {
+		t.Errorf("unexpected event: %v", event)
+	}
+	if len(event.GetCandidates()) != 1 || event.GetCandidates()[0].GetPlate() != "123ABC03" {
+		t.Errorf("unexpected candidates: %v", event.GetCandidates())
+	}
+}
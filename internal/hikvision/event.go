@@ -0,0 +1,403 @@
+package hikvision
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/utils"
+)
+
+// ErrMissingTime - в уведомлении нет dateTime
+var ErrMissingTime = errors.New("dateTime is missing")
+
+// ErrMissingEventType - в уведомлении нет eventType, и его нельзя классифицировать
+var ErrMissingEventType = errors.New("eventType is missing")
+
+// Вид уведомления камеры: определяет, как его обрабатывает приём
+const (
+	// KindPlateRead - проезд с распознанным номером
+	KindPlateRead = "plate_read"
+	// KindPlateless - проезд ТС, номер которого не прочитан
+	KindPlateless = "plateless"
+	// KindHeartbeat - периодический сигнал камеры (videoloss в состоянии inactive)
+	KindHeartbeat = "heartbeat"
+	// KindOther - прочие тревоги (VMD, TFS, настоящая потеря видео и т.п.): подтверждаются и считаются
+	KindOther = "other"
+)
+
+// Известные значения eventType; остальные в метриках сводятся к other
+const (
+	EventTypeANPR             = "anpr"
+	EventTypeVehicleDetection = "vehicledetection"
+	EventTypeVMD              = "vmd"
+	EventTypeVideoLoss        = "videoloss"
+	EventTypeTFS              = "tfs"
+	EventTypeOther            = "other"
+)
+
+// noPlateValues - строки, которыми камеры обозначают непрочитанный номер
+var noPlateValues = map[string]bool{
+	"":        true,
+	"unknown": true,
+	"noplate": true,
+	"无车牌":     true,
+}
+
+// Parse строго разбирает XML EventNotificationAlert: корневой элемент, корректный XML без
+// содержимого после корня и непустой eventType обязательны
+func Parse(payload []byte) (*Event, error) {
+	event := &Event{}
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	decoder.Strict = true
+	if err := decoder.Decode(event); err != nil {
+		return nil, fmt.Errorf("decode EventNotificationAlert: %w", err)
+	}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode EventNotificationAlert: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("unexpected text after EventNotificationAlert")
+			}
+		default:
+			return nil, errors.New("unexpected content after EventNotificationAlert")
+		}
+	}
+	if strings.TrimSpace(event.EventType) == "" {
+		return nil, ErrMissingEventType
+	}
+	return event, nil
+}
+
+// ExtractXMLPayload ищет XML события среди файлов и полей multipart-формы
+func ExtractXMLPayload(form *multipart.Form) ([]byte, error) {
+	if form == nil {
+		return nil, errors.New("empty form")
+	}
+
+	for _, files := range form.File {
+		for _, fh := range files {
+			if isXMLFile(fh) {
+				file, err := fh.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer file.Close()
+				return io.ReadAll(file)
+			}
+		}
+	}
+
+	for key, values := range form.Value {
+		if strings.Contains(strings.ToLower(key), "xml") && len(values) > 0 {
+			return []byte(values[0]), nil
+		}
+	}
+
+	return nil, errors.New("xml file not found")
+}
+
+func isXMLFile(fh *multipart.FileHeader) bool {
+	filename := strings.ToLower(fh.Filename)
+	if strings.HasSuffix(filename, ".xml") {
+		return true
+	}
+	contentType := strings.ToLower(fh.Header.Get("Content-Type"))
+	return strings.Contains(contentType, "xml")
+}
+
+// Event - уведомление EventNotificationAlert от камеры Hikvision
+type Event struct {
+	XMLName          xml.Name `xml:"EventNotificationAlert"`
+	EventType        string   `xml:"eventType" json:"event_type"`
+	EventDescription string   `xml:"eventDescription" json:"event_description"`
+	EventState       string   `xml:"eventState" json:"event_state"`
+	ActivePostCount  string   `xml:"activePostCount" json:"active_post_count"`
+	DateTime         string   `xml:"dateTime" json:"date_time"`
+	ChannelID        string   `xml:"channelID" json:"channel_id"`
+	DeviceID         string   `xml:"deviceID" json:"device_id"`
+	DeviceName       string   `xml:"deviceName" json:"device_name"`
+	IPAddress        string   `xml:"ipAddress" json:"ip_address"`
+	PortNo           string   `xml:"portNo" json:"port_no"`
+	ProtocolType     string   `xml:"protocolType" json:"protocol_type"`
+	ANPR             struct {
+		LicensePlate    string  `xml:"licensePlate" json:"license_plate"`
+		ConfidenceLevel float64 `xml:"confidenceLevel" json:"confidence_level"`
+		VehicleType     string  `xml:"vehicleType" json:"vehicle_type"`
+		VehicleColor    string  `xml:"vehicleColor" json:"vehicle_color"`
+		Color           string  `xml:"color" json:"color"`
+		PlateColor      string  `xml:"plateColor" json:"plate_color"`
+		Country         string  `xml:"country" json:"country"`
+		Brand           string  `xml:"brand" json:"brand"`
+		Direction       string  `xml:"direction" json:"direction"`
+		LaneNo          string  `xml:"laneNo" json:"lane_no"`
+		Speed           string  `xml:"speed" json:"speed"`
+		// CandidatePlates - альтернативные прочтения, если движок камеры их передаёт
+		CandidatePlates []CandidatePlate `xml:"candidatePlateList>candidatePlate" json:"candidate_plates,omitempty"`
+	} `xml:"ANPR" json:"anpr"`
+	VehicleInfo struct {
+		Type             string `xml:"vehicleType" json:"vehicle_type"`
+		Color            string `xml:"color" json:"color"`
+		VehicleColor     string `xml:"vehicleColor" json:"vehicle_color"`
+		Brand            string `xml:"brand" json:"brand"`
+		VehicleLogoRecog string `xml:"vehicleLogoRecog" json:"vehicle_logo_recog"`
+		Model            string `xml:"vehicleModel" json:"vehicle_model"`
+		VehileModel      string `xml:"vehileModel" json:"vehile_model"`
+		PlateColor       string `xml:"plateColor" json:"plate_color"`
+		Country          string `xml:"country" json:"country"`
+		Speed            string `xml:"speed" json:"speed"`
+	} `xml:"vehicleInfo" json:"vehicle_info"`
+	VehicleGATInfo struct {
+		VehicleTypeByGAT string `xml:"vehicleTypeByGAT" json:"vehicle_type_by_gat"`
+		ColorByGAT       string `xml:"colorByGAT" json:"color_by_gat"`
+		PlateTypeByGAT   string `xml:"palteTypeByGAT" json:"plate_type_by_gat"`
+		PlateColorByGAT  string `xml:"plateColorByGAT" json:"plate_color_by_gat"`
+	} `xml:"VehicleGATInfo" json:"vehicle_gat_info"`
+	PicInfo struct {
+		StoragePath string   `xml:"ftpPath" json:"ftp_path"`
+		FilePath    string   `xml:"filePath" json:"file_path"`
+		FilePaths   []string `xml:"filePathList>filePath" json:"file_path_list"`
+	} `xml:"picInfo" json:"pic_info"`
+}
+
+// CandidatePlate - альтернативное прочтение номера в candidatePlateList
+type CandidatePlate struct {
+	LicensePlate    string  `xml:"licensePlate" json:"license_plate"`
+	ConfidenceLevel float64 `xml:"confidenceLevel" json:"confidence_level"`
+}
+
+// NormalizedEventType - eventType в нижнем регистре; неизвестные типы - other
+func (e *Event) NormalizedEventType() string {
+	eventType := strings.ToLower(strings.TrimSpace(e.EventType))
+	switch eventType {
+	case EventTypeANPR, EventTypeVehicleDetection, EventTypeVMD, EventTypeVideoLoss, EventTypeTFS:
+		return eventType
+	}
+	return EventTypeOther
+}
+
+// Kind классифицирует уведомление. ANPR и vehicleDetection - проезды: с номером или без;
+// videoloss в состоянии inactive - heartbeat; всё остальное - прочие тревоги.
+func (e *Event) Kind() string {
+	switch e.NormalizedEventType() {
+	case EventTypeANPR, EventTypeVehicleDetection:
+		if e.HasPlate() || len(e.Candidates()) > 0 {
+			return KindPlateRead
+		}
+		return KindPlateless
+	case EventTypeVideoLoss:
+		if strings.EqualFold(strings.TrimSpace(e.EventState), "inactive") {
+			return KindHeartbeat
+		}
+	}
+	return KindOther
+}
+
+// HasPlate - камера прочитала номер
+func (e *Event) HasPlate() bool {
+	return !noPlateValues[strings.ToLower(strings.TrimSpace(e.ANPR.LicensePlate))]
+}
+
+// Candidates - альтернативные прочтения в порядке камеры, без обозначений непрочитанного номера
+func (e *Event) Candidates() []anpr.PlateCandidate {
+	var candidates []anpr.PlateCandidate
+	for _, c := range e.ANPR.CandidatePlates {
+		plate := strings.TrimSpace(c.LicensePlate)
+		if noPlateValues[strings.ToLower(plate)] {
+			continue
+		}
+		candidates = append(candidates, anpr.PlateCandidate{Plate: plate, Confidence: utils.ConfidenceFromPercent(c.ConfidenceLevel)})
+	}
+	return candidates
+}
+
+// CameraID - идентификатор камеры из уведомления: канал, иначе устройство
+func (e *Event) CameraID() string {
+	return firstNonEmpty(e.ChannelID, e.DeviceID)
+}
+
+// ToEventPayload собирает EventPayload из уведомления. Время без смещения считается
+// местным временем камеры в loc. Ошибка означает, что время события отсутствует или
+// не разобрано: EventTime тогда нулевое, остальные поля заполнены.
+func (e *Event) ToEventPayload(rawXML []byte, loc *time.Location) (anpr.EventPayload, error) {
+	eventTime, timeErr := ParseTime(e.DateTime, loc)
+	lane := parseLane(e.ANPR.LaneNo)
+
+	// Цвет: ПРИОРИТЕТ - текстовые значения из vehicleInfo, НЕ используем GAT коды если есть текст
+	// GAT коды (H, C и т.д.) - это числовые коды, не читаемые названия
+	vehicleColor := firstNonEmpty(
+		e.VehicleInfo.Color,        // "blue", "white" - текстовое значение (ПРИОРИТЕТ)
+		e.VehicleInfo.VehicleColor, // альтернативное поле в vehicleInfo
+		e.ANPR.VehicleColor,        // из ANPR секции (если есть)
+		e.ANPR.Color,               // альтернативное поле в ANPR
+	)
+	// НЕ используем GAT коды - они нечитаемые (H, C и т.д.)
+	// Если текстового значения нет, оставляем пустым
+
+	// Тип: сначала из ANPR, потом из GAT, потом из vehicleInfo
+	vehicleType := firstNonEmpty(
+		e.ANPR.VehicleType,
+		e.VehicleGATInfo.VehicleTypeByGAT,
+		e.VehicleInfo.Type,
+	)
+	vehiclePlateColor := firstNonEmpty(
+		e.ANPR.PlateColor,
+		e.VehicleGATInfo.PlateColorByGAT,
+		e.VehicleInfo.PlateColor,
+	)
+	vehicleCountry := firstNonEmpty(e.ANPR.Country, e.VehicleInfo.Country)
+
+	// Бренд: сначала текстовое значение, потом ID из vehicleLogoRecog
+	vehicleBrand := firstNonEmpty(e.VehicleInfo.Brand, e.ANPR.Brand)
+	// Если текстового значения нет, но есть ID логотипа, сохраняем ID
+	if vehicleBrand == "" && e.VehicleInfo.VehicleLogoRecog != "" && e.VehicleInfo.VehicleLogoRecog != "0" {
+		vehicleBrand = "brand_id:" + e.VehicleInfo.VehicleLogoRecog
+	}
+
+	// Модель: сначала текстовое значение, потом ID из vehileModel
+	vehicleModel := firstNonEmpty(e.VehicleInfo.Model, e.VehicleInfo.VehileModel)
+	// Если текстового значения нет, но есть ID модели, сохраняем ID (игнорируем "0")
+	if vehicleModel == "" || vehicleModel == "0" {
+		// Если есть другой ID модели, используем его
+		if e.VehicleInfo.VehileModel != "" && e.VehicleInfo.VehileModel != "0" {
+			vehicleModel = "model_id:" + e.VehicleInfo.VehileModel
+		} else {
+			vehicleModel = ""
+		}
+	}
+	speedPtr := parseOptionalFloat(firstNonEmpty(e.VehicleInfo.Speed, e.ANPR.Speed))
+
+	cameraModel := firstNonEmpty(e.DeviceName, e.DeviceID)
+	snapshotURL := firstNonEmpty(e.PicInfo.StoragePath, e.PicInfo.FilePath)
+	if snapshotURL == "" && len(e.PicInfo.FilePaths) > 0 {
+		snapshotURL = e.PicInfo.FilePaths[0]
+	}
+
+	var plate string
+	if e.HasPlate() {
+		plate = strings.TrimSpace(e.ANPR.LicensePlate)
+	}
+
+	rawPayload := map[string]interface{}{
+		"event_type":        e.EventType,
+		"event_description": e.EventDescription,
+		"event_state":       e.EventState,
+		"date_time":         e.DateTime,
+		"device_id":         e.DeviceID,
+		"device_name":       e.DeviceName,
+		"channel_id":        e.ChannelID,
+		"ip_address":        e.IPAddress,
+		"port_no":           e.PortNo,
+		"protocol_type":     e.ProtocolType,
+		"anpr":              e.ANPR,
+		"vehicle_info":      e.VehicleInfo,
+		"vehicle_gat_info":  e.VehicleGATInfo,
+	}
+	if len(rawXML) > 0 {
+		rawPayload["xml"] = string(rawXML)
+	}
+
+	return anpr.EventPayload{
+		CameraID:    e.CameraID(),
+		CameraModel: cameraModel,
+		Plate:       plate,
+		Candidates:  e.Candidates(),
+		Confidence:  utils.ConfidenceFromPercent(e.ANPR.ConfidenceLevel),
+		Direction:   e.ANPR.Direction,
+		Lane:        lane,
+		EventTime:   eventTime,
+		Vehicle: anpr.VehicleInfo{
+			Color:      vehicleColor,
+			Type:       vehicleType,
+			Brand:      vehicleBrand,
+			Model:      vehicleModel,
+			Country:    vehicleCountry,
+			PlateColor: vehiclePlateColor,
+			Speed:      speedPtr,
+		},
+		SnapshotURL: snapshotURL,
+		RawPayload:  rawPayload,
+	}, timeErr
+}
+
+// Форматы dateTime со смещением; дробная часть секунд разбирается без отдельного формата
+var offsetTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+}
+
+// Форматы dateTime без смещения: местное время камеры
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ParseTime разбирает dateTime камеры. Время со смещением берётся как есть, время без
+// смещения - в часовом поясе камеры loc (nil - UTC).
+func ParseTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, ErrMissingTime
+	}
+	for _, layout := range offsetTimeLayouts {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts, nil
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range localTimeLayouts {
+		if ts, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid dateTime %q", value)
+}
+
+func parseLane(value string) int {
+	if value == "" {
+		return 0
+	}
+	lane, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return lane
+}
+
+func parseOptionalFloat(value string) *float64 {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		return &f
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
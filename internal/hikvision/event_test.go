@@ -0,0 +1,49 @@
+package hikvision
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseTime(t *testing.T) {
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2025, 1, 21, 6, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input string
+		loc   *time.Location
+		want  time.Time
+	}{
+		{name: "rfc3339 offset", input: "2025-01-21T12:34:56+06:00", loc: time.UTC, want: want},
+		{name: "utc", input: "2025-01-21T06:34:56Z", loc: almaty, want: want},
+		{name: "offset without colon", input: "2025-01-21T12:34:56+0600", loc: time.UTC, want: want},
+		{name: "fractional seconds", input: "2025-01-21T12:34:56.250+06:00", loc: time.UTC, want: want.Add(250 * time.Millisecond)},
+		{name: "local time in camera zone", input: "2025-01-21T11:34:56", loc: almaty, want: want},
+		{name: "local time with space", input: "2025-01-21 11:34:56", loc: almaty, want: want},
+		{name: "local time without zone", input: "2025-01-21 06:34:56", loc: nil, want: want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.input, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTime(" ", almaty); !errors.Is(err, ErrMissingTime) {
+		t.Errorf("empty dateTime: expected ErrMissingTime, got %v", err)
+	}
+	if _, err := ParseTime("21.01.2025 12:34", almaty); err == nil || errors.Is(err, ErrMissingTime) {
+		t.Errorf("garbage dateTime: expected parse error, got %v", err)
+	}
+}
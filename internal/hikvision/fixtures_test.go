@@ -0,0 +1,129 @@
+package hikvision
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		file      string
+		kind      string
+		eventType string
+		cameraID  string
+		plate     string
+	}{
+		{file: "anpr.xml", kind: KindPlateRead, eventType: EventTypeANPR, cameraID: "1", plate: "123ABC02"},
+		{file: "vehicle_detection_plate.xml", kind: KindPlateRead, eventType: EventTypeVehicleDetection, cameraID: "2", plate: "777XYZ01"},
+		{file: "vehicle_detection_noplate.xml", kind: KindPlateless, eventType: EventTypeVehicleDetection, cameraID: "2"},
+		{file: "anpr_noplate.xml", kind: KindPlateless, eventType: EventTypeANPR, cameraID: "1"},
+		{file: "anpr_candidates.xml", kind: KindPlateRead, eventType: EventTypeANPR, cameraID: "1"},
+		{file: "heartbeat.xml", kind: KindHeartbeat, eventType: EventTypeVideoLoss, cameraID: "1"},
+		{file: "videoloss.xml", kind: KindOther, eventType: EventTypeVideoLoss, cameraID: "1"},
+		{file: "vmd.xml", kind: KindOther, eventType: EventTypeVMD, cameraID: "1"},
+		{file: "tfs.xml", kind: KindOther, eventType: EventTypeTFS, cameraID: "2"},
+		{file: "unknown_type.xml", kind: KindOther, eventType: EventTypeOther, cameraID: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw := readFixture(t, tt.file)
+			event, err := Parse(raw)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := event.Kind(); got != tt.kind {
+				t.Errorf("Kind() = %q, want %q", got, tt.kind)
+			}
+			if got := event.NormalizedEventType(); got != tt.eventType {
+				t.Errorf("NormalizedEventType() = %q, want %q", got, tt.eventType)
+			}
+
+			payload, err := event.ToEventPayload(raw, time.UTC)
+			if err != nil {
+				t.Fatalf("ToEventPayload: %v", err)
+			}
+			if payload.CameraID != tt.cameraID {
+				t.Errorf("camera id = %q, want %q", payload.CameraID, tt.cameraID)
+			}
+			if payload.Plate != tt.plate {
+				t.Errorf("plate = %q, want %q", payload.Plate, tt.plate)
+			}
+		})
+	}
+}
+
+func TestPlatelessPassageKeepsAttributes(t *testing.T) {
+	raw := readFixture(t, "vehicle_detection_noplate.xml")
+	event, err := Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := event.ToEventPayload(raw, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 1, 21, 6, 36, 0, 0, time.UTC); !payload.EventTime.Equal(want) {
+		t.Errorf("event time = %v, want %v", payload.EventTime, want)
+	}
+	if payload.Vehicle.Color != "black" || payload.Vehicle.Type != "SUV" {
+		t.Errorf("vehicle = %+v, want black SUV", payload.Vehicle)
+	}
+	if payload.Direction != "reverse" {
+		t.Errorf("direction = %q, want reverse", payload.Direction)
+	}
+	if payload.SnapshotURL == "" {
+		t.Error("snapshot is lost")
+	}
+}
+
+func TestCandidatePlates(t *testing.T) {
+	raw := readFixture(t, "anpr_candidates.xml")
+	event, err := Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := event.ToEventPayload(raw, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Candidates) != 2 {
+		t.Fatalf("candidates = %+v, want 2 without unknown", payload.Candidates)
+	}
+	if c := payload.Candidates[0]; c.Plate != "123ABC02" || c.Confidence != 0.71 {
+		t.Errorf("first candidate = %+v", c)
+	}
+	if c := payload.Candidates[1]; c.Plate != "123A8C02" || c.Confidence != 0.64 {
+		t.Errorf("second candidate = %+v", c)
+	}
+}
+
+func TestParseRejectsInvalidXML(t *testing.T) {
+	for _, file := range []string{
+		"invalid_root.xml",
+		"invalid_trailing.xml",
+		"invalid_unclosed.xml",
+		"invalid_confidence.xml",
+	} {
+		t.Run(file, func(t *testing.T) {
+			if _, err := Parse(readFixture(t, file)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := Parse(readFixture(t, "invalid_missing_type.xml")); !errors.Is(err, ErrMissingEventType) {
+		t.Errorf("missing eventType: expected ErrMissingEventType, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+type resolveAnomalyRequest struct {
+	Status string  `json:"status" binding:"required"`
+	Note   *string `json:"note"`
+}
+
+func (h *Handler) listAnomalies(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	anomalies, err := h.anomalyService.FindAnomalies(c.Request.Context(), service.AnomalyQuery{
+		Type:     optionalQuery(c, "type"),
+		Status:   optionalQuery(c, "status"),
+		Plate:    optionalQuery(c, "plate"),
+		PlateID:  optionalQuery(c, "plate_id"),
+		CameraID: optionalQuery(c, "camera_id"),
+		From:     optionalQuery(c, "from"),
+		To:       optionalQuery(c, "to"),
+	}, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(anomalies))
+}
+
+func (h *Handler) getAnomaly(c *gin.Context) {
+	anomaly, err := h.anomalyService.GetAnomaly(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(anomaly))
+}
+
+func (h *Handler) resolveAnomaly(c *gin.Context) {
+	var req resolveAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var resolvedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		resolvedBy = &principal.UserID
+	}
+
+	anomaly, err := h.anomalyService.ResolveAnomaly(c.Request.Context(), c.Param("id"), req.Status, req.Note, resolvedBy)
+	h.recordAudit(c, service.AuditActionResolveAnomaly, "anpr_anomalies",
+		map[string]interface{}{"anomaly_id": c.Param("id"), "status": req.Status, "note": req.Note}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(anomaly))
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+type archiveRangeRequest struct {
+	From   time.Time `json:"from" binding:"required"`
+	To     time.Time `json:"to" binding:"required"`
+	Format string    `json:"format"`
+}
+
+func (h *Handler) listArchives(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	archives, err := h.archiveService.FindArchives(c.Request.Context(), optionalQuery(c, "from"), optionalQuery(c, "to"), limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(archives))
+}
+
+func (h *Handler) createArchive(c *gin.Context) {
+	var req archiveRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	info, err := h.archiveService.ArchiveRange(c.Request.Context(), req.From, req.To, req.Format)
+	auditResult := map[string]interface{}{}
+	if info != nil {
+		auditResult["archive_id"] = info.ID
+		auditResult["event_count"] = info.EventCount
+	}
+	h.recordAudit(c, service.AuditActionCreateArchive, "anpr_events",
+		map[string]interface{}{"from": req.From, "to": req.To, "format": req.Format}, auditResult, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if info == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "message": "no events in range"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(info))
+}
+
+// restoreArchives восстанавливает события диапазона в anpr_events_restored для расследований
+func (h *Handler) restoreArchives(c *gin.Context) {
+	var req archiveRangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	result, err := h.archiveService.Restore(c.Request.Context(), req.From, req.To)
+	auditResult := map[string]interface{}{}
+	if result != nil {
+		auditResult["archive_ids"] = result.ArchiveIDs
+		auditResult["restored"] = result.Restored
+	}
+	h.recordAudit(c, service.AuditActionRestoreArchive, "anpr_events_restored",
+		map[string]interface{}{"from": req.From, "to": req.To}, auditResult, err)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c).Error().Err(err).Msg("failed to restore archives")
+		c.JSON(http.StatusInternalServerError, errorResponse("failed to restore archives"))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(result))
+}
+
+func (h *Handler) listRestoredEvents(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	events, err := h.archiveService.FindRestoredEvents(
+		c.Request.Context(),
+		optionalQuery(c, "archive_id"),
+		optionalQuery(c, "plate"),
+		optionalQuery(c, "from"),
+		optionalQuery(c, "to"),
+		limit, offset,
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(events))
+}
+
+func parsePaging(c *gin.Context) (int, int) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+	return limit, offset
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+// recordAudit пишет административное действие в журнал аудита.
+// Запись выполняется с отдельным контекстом, чтобы отмена клиентского запроса не теряла след действия.
+func (h *Handler) recordAudit(c *gin.Context, action, target string, params, result map[string]interface{}, actionErr error) {
+	rec := service.AuditRecord{
+		Action:   action,
+		Target:   target,
+		Params:   params,
+		Result:   result,
+		Err:      actionErr,
+		ClientIP: c.ClientIP(),
+	}
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		rec.Principal = &principal
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	if err := h.auditService.Record(ctx, rec); err != nil {
+		h.logger(c).Error().Err(err).Str("action", action).Msg("failed to record audit entry")
+	}
+}
+
+// listAuditLog доступен только администратору акимата: журнал содержит действия всех организаций
+func (h *Handler) listAuditLog(c *gin.Context) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return
+	}
+	if !principal.IsAkimat() {
+		c.JSON(http.StatusForbidden, errorResponse("audit log is available to akimat administrators only"))
+		return
+	}
+
+	query := service.AuditQuery{
+		ActorID: optionalQuery(c, "actor_id"),
+		OrgID:   optionalQuery(c, "org_id"),
+		Action:  optionalQuery(c, "action"),
+		Target:  optionalQuery(c, "target"),
+		Status:  optionalQuery(c, "status"),
+		From:    optionalQuery(c, "from"),
+		To:      optionalQuery(c, "to"),
+	}
+
+	query.Limit, query.Offset = parsePaging(c)
+
+	entries, err := h.auditService.FindEntries(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c).Error().Err(err).Msg("failed to find audit log entries")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(entries))
+}
+
+func optionalQuery(c *gin.Context, key string) *string {
+	value := strings.TrimSpace(c.Query(key))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
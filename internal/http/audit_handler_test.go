@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/auth"
+	"anpr-service/internal/config"
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/service"
+)
+
+const auditTestSecret = "audit-test-secret"
+
+// fakeAuditRepository - журнал аудита в памяти
+type fakeAuditRepository struct {
+	mu      sync.Mutex
+	entries []repository.AuditLog
+}
+
+func (f *fakeAuditRepository) CreateAuditLog(_ context.Context, entry *repository.AuditLog) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+func (f *fakeAuditRepository) FindAuditLogs(context.Context, repository.AuditLogFilter) ([]repository.AuditLog, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]repository.AuditLog(nil), f.entries...), nil
+}
+
+// newAuditRouter - маршруты обработчика с настоящей проверкой JWT и журналом в памяти.
+// Остальные сервисы пустые: тесты вызывают только обработчики, отказывающие до обращения к базе.
+func newAuditRouter(audit *fakeAuditRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{
+		auditService:     service.NewAuditService(audit, zerolog.Nop()),
+		retentionService: &service.RetentionService{},
+		config:           &config.Config{},
+		log:              zerolog.Nop(),
+	}
+	h.Register(r, middleware.Auth(auth.NewParser(auditTestSecret)))
+	return r
+}
+
+func bearerToken(t *testing.T, principal model.Principal) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		UserID: principal.UserID,
+		OrgID:  principal.OrgID,
+		Role:   principal.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(auditTestSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestMutatingHandlerWritesAuditRow(t *testing.T) {
+	audit := &fakeAuditRepository{}
+	r := newAuditRouter(audit)
+	principal := model.Principal{UserID: uuid.New(), OrgID: uuid.New(), Role: model.UserRoleKguZkhAdmin}
+
+	// Пустой список событий отклоняет сервис - неудачное действие тоже попадает в журнал
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/trips/trip-42/events", strings.NewReader(`{"event_ids": []}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", bearerToken(t, principal))
+	req.RemoteAddr = "192.0.2.10:51234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400: %s", w.Code, w.Body)
+	}
+
+	if len(audit.entries) != 1 {
+		t.Fatalf("audit rows = %d, want 1", len(audit.entries))
+	}
+	entry := audit.entries[0]
+	if entry.Action != service.AuditActionLinkTripEvents || entry.Target == nil || *entry.Target != "trip-42" {
+		t.Errorf("action %q, target %v", entry.Action, entry.Target)
+	}
+	if entry.ActorID == nil || *entry.ActorID != principal.UserID ||
+		entry.ActorOrgID == nil || *entry.ActorOrgID != principal.OrgID ||
+		entry.ActorRole == nil || *entry.ActorRole != string(principal.Role) {
+		t.Errorf("actor not recorded: %+v", entry)
+	}
+	if entry.ClientIP == nil || *entry.ClientIP != "192.0.2.10" {
+		t.Errorf("client ip = %v, want 192.0.2.10", entry.ClientIP)
+	}
+	if entry.Status != service.AuditStatusFailure || entry.Error == nil {
+		t.Errorf("status %q, error %v; want failure with error", entry.Status, entry.Error)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(entry.Result, &result); err != nil || result["linked"] != float64(0) {
+		t.Errorf("result = %s, want linked count", entry.Result)
+	}
+}
+
+func TestAuditLogRequiresAkimat(t *testing.T) {
+	audit := &fakeAuditRepository{entries: []repository.AuditLog{{ID: uuid.New(), Action: service.AuditActionRunRetention, Status: service.AuditStatusSuccess}}}
+	r := newAuditRouter(audit)
+
+	tests := []struct {
+		role model.UserRole
+		want int
+	}{
+		{model.UserRoleAkimatAdmin, http.StatusOK},
+		{model.UserRoleKguZkhAdmin, http.StatusForbidden},
+		{model.UserRoleContractorAdmin, http.StatusForbidden},
+		{model.UserRoleDriver, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/audit", nil)
+		req.Header.Set("Authorization", bearerToken(t, model.Principal{UserID: uuid.New(), OrgID: uuid.New(), Role: tt.role}))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.role, w.Code, tt.want)
+		}
+	}
+}
+
+// auditExempt - защищённые изменяющие маршруты, которые ничего не меняют и в журнал не пишут
+var auditExempt = map[string]bool{
+	"previewRetention": true, // dry-run
+}
+
+// TestMutatingRoutesRecordAudit проверяет по исходному коду, что каждый защищённый обработчик
+// POST, PUT, PATCH и DELETE вызывает recordAudit - сам или через другой метод Handler
+func TestMutatingRoutesRecordAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{config: &config.Config{}}
+	h.Register(r, func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+	})
+
+	methods := parseHandlerMethods(t)
+	for _, route := range r.Routes() {
+		if route.Method == http.MethodGet {
+			continue
+		}
+		// Публичный приём камер пишет события, а не административные действия
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.Method, route.Path, nil))
+		if w.Code != http.StatusUnauthorized {
+			continue
+		}
+
+		name := handlerMethodName(route.Handler)
+		if auditExempt[name] {
+			continue
+		}
+		if !recordsAudit(methods, name, map[string]bool{}) {
+			t.Errorf("%s %s (%s) does not record an audit entry", route.Method, route.Path, name)
+		}
+	}
+}
+
+// handlerMethodName достаёт имя метода из "anpr-service/internal/http.(*Handler).name-fm"
+func handlerMethodName(handler string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+func parseHandlerMethods(t *testing.T) map[string]*ast.FuncDecl {
+	t.Helper()
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := map[string]*ast.FuncDecl{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil && fn.Body != nil {
+					methods[fn.Name.Name] = fn
+				}
+			}
+		}
+	}
+	return methods
+}
+
+func recordsAudit(methods map[string]*ast.FuncDecl, name string, visited map[string]bool) bool {
+	fn, ok := methods[name]
+	if !ok || visited[name] {
+		return false
+	}
+	visited[name] = true
+
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if recv, ok := sel.X.(*ast.Ident); ok && recv.Name == "h" {
+			found = sel.Sel.Name == "recordAudit" || recordsAudit(methods, sel.Sel.Name, visited)
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) listCameras(c *gin.Context) {
+	cameras, err := h.cameraService.ListCameras(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(cameras))
+}
+
+func (h *Handler) getCamera(c *gin.Context) {
+	camera, err := h.cameraService.GetCamera(c.Request.Context(), c.Param("camera_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(camera))
+}
+
+// listCameraConfidence возвращает статистику уверенности прочтений по всем камерам
+func (h *Handler) listCameraConfidence(c *gin.Context) {
+	stats, err := h.cameraService.ConfidenceStats(c.Request.Context(), nil, service.CameraStatsQuery{
+		From: optionalQuery(c, "from"),
+		To:   optionalQuery(c, "to"),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(stats))
+}
+
+// getCameraConfidence возвращает статистику уверенности камеры с динамикой по суткам
+func (h *Handler) getCameraConfidence(c *gin.Context) {
+	cameraID := c.Param("camera_id")
+	stats, err := h.cameraService.ConfidenceStats(c.Request.Context(), &cameraID, service.CameraStatsQuery{
+		From: optionalQuery(c, "from"),
+		To:   optionalQuery(c, "to"),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(stats[0]))
+}
+
+// listCameraClock возвращает расхождение часов камер с сервером
+func (h *Handler) listCameraClock(c *gin.Context) {
+	stats, err := h.cameraService.ClockStats(c.Request.Context(), service.CameraStatsQuery{
+		From: optionalQuery(c, "from"),
+		To:   optionalQuery(c, "to"),
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(stats))
+}
+
+// listCameraLiveness возвращает последнюю связь с камерами и их доступность
+func (h *Handler) listCameraLiveness(c *gin.Context) {
+	liveness, err := h.cameraService.ListLiveness(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(liveness))
+}
+
+// upsertCamera регистрирует камеру под camera_id, который она передаёт в событиях
+func (h *Handler) upsertCamera(c *gin.Context) {
+	var input service.CameraInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	cameraID := c.Param("camera_id")
+	camera, err := h.cameraService.UpsertCamera(c.Request.Context(), cameraID, input)
+	h.recordAudit(c, service.AuditActionUpsertCamera, "anpr_cameras",
+		map[string]interface{}{"camera_id": cameraID, "camera": input}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(camera))
+}
+
+// rotateCameraAPIKey выдаёт камере новый ключ API; ключ показывается только в этом ответе
+func (h *Handler) rotateCameraAPIKey(c *gin.Context) {
+	cameraID := c.Param("camera_id")
+	key, err := h.cameraService.RotateAPIKey(c.Request.Context(), cameraID)
+	h.recordAudit(c, service.AuditActionRotateCameraKey, "anpr_cameras",
+		map[string]interface{}{"camera_id": cameraID}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"camera_id": cameraID, "api_key": key}))
+}
+
+func (h *Handler) revokeCameraAPIKey(c *gin.Context) {
+	cameraID := c.Param("camera_id")
+	err := h.cameraService.RevokeAPIKey(c.Request.Context(), cameraID)
+	h.recordAudit(c, service.AuditActionRevokeCameraKey, "anpr_cameras",
+		map[string]interface{}{"camera_id": cameraID}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) deleteCamera(c *gin.Context) {
+	cameraID := c.Param("camera_id")
+	err := h.cameraService.DeleteCamera(c.Request.Context(), cameraID)
+	h.recordAudit(c, service.AuditActionDeleteCamera, "anpr_cameras",
+		map[string]interface{}{"camera_id": cameraID}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
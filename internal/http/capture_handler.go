@@ -0,0 +1,58 @@
+package http
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/capture"
+)
+
+const (
+	// captureCameraKey - camera_id, определённый обработчиком после разбора запроса
+	captureCameraKey = "capture_camera_id"
+	maxCaptureBody   = 10 << 20
+)
+
+// captureIngest сохраняет сырые запросы приёма событий выбранных камер (CAPTURE_*)
+func (h *Handler) captureIngest(c *gin.Context) {
+	if h.capture == nil {
+		c.Next()
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCaptureBody+1))
+	if err != nil {
+		h.logger(c).Warn().Err(err).Msg("failed to read body for capture")
+	}
+	truncated := len(body) > maxCaptureBody
+	if truncated {
+		body = body[:maxCaptureBody]
+	}
+	// Обработчик читает тело заново: сначала сохранённую часть, затем остаток
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	c.Next()
+
+	cameraID := c.GetString(captureCameraKey)
+	if cameraID == "" {
+		cameraID = c.Query("camera_id")
+	}
+	status := c.Writer.Status()
+	if !h.capture.ShouldCapture(cameraID, c.ClientIP(), status) {
+		return
+	}
+
+	path, err := h.capture.Save(c.Request, body, capture.Meta{
+		CameraID:   cameraID,
+		RemoteAddr: c.ClientIP(),
+		Status:     status,
+		Truncated:  truncated,
+	})
+	if err != nil {
+		h.logger(c).Error().Err(err).Msg("failed to capture ingest request")
+		return
+	}
+	h.logger(c).Debug().Str("capture_file", path).Msg("ingest request captured")
+}
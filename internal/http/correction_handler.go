@@ -0,0 +1,146 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+// correctEventPlate исправляет номер события, ошибочно прочитанный камерой
+func (h *Handler) correctEventPlate(c *gin.Context) {
+	var input service.EventCorrectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var correctedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		correctedBy = &principal.UserID
+	}
+
+	eventID := c.Param("id")
+	event, err := h.correctionService.CorrectEvent(c.Request.Context(), eventID, input, correctedBy)
+	h.recordAudit(c, service.AuditActionCorrectEventPlate, "anpr_events",
+		map[string]interface{}{"event_id": eventID, "plate": input.Plate, "reason": input.Reason}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(event))
+}
+
+// attachEventPlate привязывает номер, установленный оператором по снимку, к проезду без номера
+func (h *Handler) attachEventPlate(c *gin.Context) {
+	var input service.AttachPlateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var attachedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		attachedBy = &principal.UserID
+	}
+
+	eventID := c.Param("id")
+	event, err := h.correctionService.AttachPlate(c.Request.Context(), eventID, input, attachedBy)
+	h.recordAudit(c, service.AuditActionAttachPlate, "anpr_events",
+		map[string]interface{}{"event_id": eventID, "plate": input.Plate, "note": input.Note}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(event))
+}
+
+// verifyEventRead подтверждает номер события, прочитанный с низкой уверенностью
+func (h *Handler) verifyEventRead(c *gin.Context) {
+	var verifiedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		verifiedBy = &principal.UserID
+	}
+
+	eventID := c.Param("id")
+	event, err := h.correctionService.VerifyEvent(c.Request.Context(), eventID, verifiedBy)
+	h.recordAudit(c, service.AuditActionVerifyEventRead, "anpr_events",
+		map[string]interface{}{"event_id": eventID}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(event))
+}
+
+// mergePlates переносит события и членства ошибочного номера на настоящий
+func (h *Handler) mergePlates(c *gin.Context) {
+	var input service.PlateMergeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var mergedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		mergedBy = &principal.UserID
+	}
+
+	merge, err := h.correctionService.MergePlates(c.Request.Context(), input, mergedBy)
+	var result map[string]interface{}
+	if merge != nil {
+		result = map[string]interface{}{
+			"target_plate_id":   merge.TargetPlateID,
+			"events_moved":      merge.EventsMoved,
+			"memberships_moved": merge.MembershipsMoved,
+		}
+	}
+	h.recordAudit(c, service.AuditActionMergePlates, "anpr_plates",
+		map[string]interface{}{"merge": input}, result, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(merge))
+}
+
+func (h *Handler) listPlateMerges(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	merges, err := h.correctionService.FindMerges(c.Request.Context(), optionalQuery(c, "plate_id"), limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(merges))
+}
+
+// listPlateRecomputes отдаёт номера, производные данные которых нужно пересчитать
+func (h *Handler) listPlateRecomputes(c *gin.Context) {
+	limit, _ := parsePaging(c)
+	recomputes, err := h.correctionService.PendingRecomputes(c.Request.Context(), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(recomputes))
+}
+
+// acknowledgePlateRecomputes подтверждает выполненный пересчёт
+func (h *Handler) acknowledgePlateRecomputes(c *gin.Context) {
+	var input service.RecomputeAckInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	acknowledged, err := h.correctionService.AcknowledgeRecomputes(c.Request.Context(), input)
+	h.recordAudit(c, service.AuditActionAckPlateRecomputes, "anpr_plate_recomputes",
+		map[string]interface{}{"ids": len(input.IDs)}, map[string]interface{}{"acknowledged": acknowledged}, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(map[string]int64{"acknowledged": acknowledged}))
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/export"
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+type exportJobRequest struct {
+	Format           string             `json:"format" binding:"required"`
+	IncludeSnapshots bool               `json:"include_snapshots"`
+	Filters          service.EventQuery `json:"filters"`
+}
+
+// exportEvents потоково отдаёт все события по фильтрам GET /events в CSV, XLSX или NDJSON
+func (h *Handler) exportEvents(c *gin.Context) {
+	req := service.ExportRequest{
+		Query:            eventQueryFromRequest(c),
+		Format:           c.DefaultQuery("format", export.FormatCSV),
+		IncludeSnapshots: c.Query("include_snapshots") == "true",
+	}
+	auditParams := map[string]interface{}{
+		"format":            req.Format,
+		"include_snapshots": req.IncludeSnapshots,
+		"filters":           req.Query,
+	}
+
+	// Всё, что может завершиться понятной клиенту ошибкой, проверяем до отправки заголовков
+	if err := h.exportService.ValidateSync(c.Request.Context(), req); err != nil {
+		h.recordAudit(c, service.AuditActionExportEvents, "anpr_events", auditParams, nil, err)
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(req.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, service.ExportFileName(time.Now(), req.Format)))
+	c.Status(http.StatusOK)
+
+	rows, err := h.exportService.WriteEvents(c.Request.Context(), req, c.Writer)
+	h.recordAudit(c, service.AuditActionExportEvents, "anpr_events", auditParams,
+		map[string]interface{}{"rows": rows}, err)
+	if err != nil {
+		// Заголовки уже отправлены: клиент получит оборванный файл, причина остаётся в логе
+		h.logger(c).Error().Err(err).Int64("rows", rows).Msg("event export interrupted")
+	}
+}
+
+func (h *Handler) createExportJob(c *gin.Context) {
+	var req exportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		createdBy = &principal.UserID
+	}
+
+	job, err := h.exportService.CreateJob(c.Request.Context(), service.ExportRequest{
+		Query:            req.Filters,
+		Format:           req.Format,
+		IncludeSnapshots: req.IncludeSnapshots,
+	}, createdBy)
+	auditResult := map[string]interface{}{}
+	if job != nil {
+		auditResult["export_job_id"] = job.ID
+	}
+	h.recordAudit(c, service.AuditActionCreateExportJob, "anpr_events",
+		map[string]interface{}{"format": req.Format, "include_snapshots": req.IncludeSnapshots, "filters": req.Filters},
+		auditResult, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, successResponse(job))
+}
+
+// exportOwner - чьи задачи выгрузки видит пользователь: администратор акимата - все
+// (или только свои с mine=true), остальные - только свои. Выгрузка может содержать снимки,
+// поэтому чужие задачи недоступны.
+func (h *Handler) exportOwner(c *gin.Context) (*uuid.UUID, bool) {
+	principal, ok := middleware.MustPrincipal(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+		return nil, false
+	}
+	if principal.IsAkimat() && c.Query("mine") != "true" {
+		return nil, true
+	}
+	return &principal.UserID, true
+}
+
+func (h *Handler) listExportJobs(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	owner, ok := h.exportOwner(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := h.exportService.FindJobs(c.Request.Context(), owner, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(jobs))
+}
+
+func (h *Handler) getExportJob(c *gin.Context) {
+	owner, ok := h.exportOwner(c)
+	if !ok {
+		return
+	}
+	job, err := h.exportService.GetJob(c.Request.Context(), c.Param("id"), owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+func (h *Handler) downloadExport(c *gin.Context) {
+	owner, ok := h.exportOwner(c)
+	if !ok {
+		return
+	}
+	file, err := h.exportService.OpenFile(c.Request.Context(), c.Param("id"), owner)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer file.Body.Close()
+
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, file.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, file.Name),
+	})
+}
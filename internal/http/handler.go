@@ -1,49 +1,123 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
-	"encoding/xml"
 	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
+	"anpr-service/internal/capture"
 	"anpr-service/internal/config"
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/logger"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/ratelimit"
+	"anpr-service/internal/repository"
 	"anpr-service/internal/service"
+	"anpr-service/internal/tracing"
 )
 
+// ingestEventService и ingestCameraService - то, что приёму событий камер нужно от сервисов;
+// за интерфейсами обработчики приёма проверяются без базы
+type ingestEventService interface {
+	ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (*anpr.ProcessResult, error)
+	ProcessPlatelessPassage(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (*anpr.ProcessResult, error)
+}
+
+type ingestCameraService interface {
+	AddressAllowed(ctx context.Context, cameraID, clientIP string) (bool, error)
+	AuthenticateAPIKey(ctx context.Context, cameraID, key string) (bool, error)
+	CertificateMatches(ctx context.Context, cameraID, cn string) (bool, error)
+	Lookup(ctx context.Context, cameraID string) (*repository.Camera, error)
+	Location(ctx context.Context, cameraID string) *time.Location
+	Heartbeat(ctx context.Context, cameraID, address string, at time.Time) error
+	Seen(ctx context.Context, cameraID, address string, at time.Time)
+}
+
 type Handler struct {
-	anprService *service.ANPRService
-	config      *config.Config
-	log         zerolog.Logger
+	anprService       *service.ANPRService
+	auditService      *service.AuditService
+	retentionService  *service.RetentionService
+	archiveService    *service.ArchiveService
+	partitionService  *service.PartitionService
+	exportService     *service.ExportService
+	cameraService     *service.CameraService
+	anomalyService    *service.AnomalyService
+	vehicleService    *service.VehicleService
+	reviewService     *service.ReviewService
+	correctionService *service.CorrectionService
+	capture           *capture.Store
+	// ipLimiter и cameraLimiter - лимиты публичного приёма (INGEST_*); nil - без ограничения,
+	// signatures - недавно принятые подписи тел (защита от повтора)
+	ipLimiter     *ratelimit.Limiter
+	cameraLimiter *ratelimit.Limiter
+	signatures    *signatureCache
+	// ingestEvents и ingestCameras - anprService и cameraService для приёма событий
+	ingestEvents  ingestEventService
+	ingestCameras ingestCameraService
+	config        *config.Config
+	log           zerolog.Logger
 }
 
 func NewHandler(
 	anprService *service.ANPRService,
+	auditService *service.AuditService,
+	retentionService *service.RetentionService,
+	archiveService *service.ArchiveService,
+	partitionService *service.PartitionService,
+	exportService *service.ExportService,
+	cameraService *service.CameraService,
+	anomalyService *service.AnomalyService,
+	vehicleService *service.VehicleService,
+	reviewService *service.ReviewService,
+	correctionService *service.CorrectionService,
+	captureStore *capture.Store,
 	cfg *config.Config,
 	log zerolog.Logger,
 ) *Handler {
 	return &Handler{
-		anprService: anprService,
-		config:      cfg,
-		log:         log,
+		anprService:       anprService,
+		auditService:      auditService,
+		retentionService:  retentionService,
+		archiveService:    archiveService,
+		partitionService:  partitionService,
+		exportService:     exportService,
+		cameraService:     cameraService,
+		anomalyService:    anomalyService,
+		vehicleService:    vehicleService,
+		reviewService:     reviewService,
+		correctionService: correctionService,
+		capture:           captureStore,
+		ipLimiter:         ratelimit.New(cfg.Ingest.IPRate, cfg.Ingest.IPBurst),
+		cameraLimiter:     ratelimit.New(cfg.Ingest.CameraRate, cfg.Ingest.CameraBurst),
+		signatures:        newSignatureCache(cfg.Ingest.SignatureMaxAge),
+		ingestEvents:      anprService,
+		ingestCameras:     cameraService,
+		config:            cfg,
+		log:               log,
 	}
 }
 
+// logger возвращает логгер текущего запроса (с request_id)
+func (h *Handler) logger(c *gin.Context) *zerolog.Logger {
+	return logger.FromContext(c.Request.Context(), h.log)
+}
+
 func (h *Handler) Register(r *gin.Engine, authMiddleware gin.HandlerFunc) {
 	// Public endpoints
 	public := r.Group("/api/v1")
 	{
-		public.POST("/anpr/events", h.createANPREvent)
-		public.POST("/anpr/hikvision", h.createHikvisionEvent)
+		public.POST("/anpr/events", h.limitIngest, h.captureIngest, h.verifyIngestSignature, h.createANPREvent)
+		public.POST("/anpr/hikvision", h.limitIngest, h.captureIngest, h.verifyIngestSignature, h.createHikvisionEvent)
 		public.GET("/anpr/hikvision", h.checkHikvisionEndpoint) // Для проверки доступности камерой
 		public.GET("/plates", h.listPlates)
 		public.GET("/events", h.listEvents)
@@ -57,18 +131,83 @@ func (h *Handler) Register(r *gin.Engine, authMiddleware gin.HandlerFunc) {
 		protected.POST("/anpr/sync-vehicle", h.syncVehicleToWhitelist)
 		protected.DELETE("/anpr/events/old", h.deleteOldEvents)
 		protected.DELETE("/anpr/events/all", h.deleteAllEvents)
+		protected.GET("/audit", h.listAuditLog)
+
+		protected.GET("/events/export", h.exportEvents)
+		protected.GET("/events/exports", h.listExportJobs)
+		protected.POST("/events/exports", h.createExportJob)
+		protected.GET("/events/exports/:id", h.getExportJob)
+		protected.GET("/events/exports/:id/download", h.downloadExport)
+
+		protected.GET("/cameras", h.listCameras)
+		protected.GET("/cameras/confidence", h.listCameraConfidence)
+		protected.GET("/cameras/clock", h.listCameraClock)
+		protected.GET("/cameras/liveness", h.listCameraLiveness)
+		protected.GET("/cameras/:camera_id", h.getCamera)
+		protected.PUT("/cameras/:camera_id", h.upsertCamera)
+		protected.DELETE("/cameras/:camera_id", h.deleteCamera)
+		protected.GET("/cameras/:camera_id/confidence", h.getCameraConfidence)
+		protected.POST("/cameras/:camera_id/api-key", h.rotateCameraAPIKey)
+		protected.DELETE("/cameras/:camera_id/api-key", h.revokeCameraAPIKey)
+
+		protected.GET("/anomalies", h.listAnomalies)
+		protected.GET("/anomalies/:id", h.getAnomaly)
+		protected.PATCH("/anomalies/:id", h.resolveAnomaly)
+
+		protected.GET("/vehicles", h.listVehicles)
+		protected.POST("/vehicles/sync", h.syncVehicles)
+
+		protected.PATCH("/events/:id/plate", h.correctEventPlate)
+		protected.POST("/events/:id/verify", h.verifyEventRead)
+		protected.POST("/events/:id/attach-plate", h.attachEventPlate)
+		// Карточка номера раскрывает членство в списках с примечаниями и историю проездов
+		protected.GET("/plates/:id", h.getPlate)
+		protected.POST("/plates/merge", h.mergePlates)
+		protected.GET("/plates/merges", h.listPlateMerges)
+		protected.GET("/plates/recomputes", h.listPlateRecomputes)
+		protected.POST("/plates/recomputes/ack", h.acknowledgePlateRecomputes)
+
+		protected.GET("/review/items", h.listReviewItems)
+		protected.GET("/review/items/:id", h.getReviewItem)
+		protected.PATCH("/review/items/:id", h.resolveReviewItem)
+		protected.GET("/review/policies", h.listReviewPolicies)
+		protected.PUT("/review/policies/:polygon_id", h.upsertReviewPolicy)
+		protected.DELETE("/review/policies/:polygon_id", h.deleteReviewPolicy)
+
+		protected.GET("/retention/policies", h.listRetentionPolicies)
+		protected.POST("/retention/policies", h.createRetentionPolicy)
+		protected.PUT("/retention/policies/:id", h.updateRetentionPolicy)
+		protected.DELETE("/retention/policies/:id", h.deleteRetentionPolicy)
+		protected.POST("/retention/preview", h.previewRetention)
+		protected.POST("/retention/run", h.runRetentionNow)
+		protected.GET("/retention/runs", h.listRetentionRuns)
+		protected.GET("/retention/partitions", h.listPartitions)
+		protected.POST("/trips/:trip_id/events", h.linkTripEvents)
+		protected.DELETE("/trips/:trip_id/events", h.unlinkTrip)
+
+		protected.GET("/archives", h.listArchives)
+		protected.POST("/archives", h.createArchive)
+		protected.POST("/archives/restore", h.restoreArchives)
+		protected.GET("/archives/restored-events", h.listRestoredEvents)
 	}
 }
 
 func (h *Handler) createANPREvent(c *gin.Context) {
+	receivedAt := time.Now()
+	defer metrics.ObserveIngest(metrics.SourceJSON, receivedAt)
+
 	var payload anpr.EventPayload
 	
 	// Проверяем, является ли запрос multipart/form-data
 	contentType := c.Request.Header.Get("Content-Type")
 	if strings.Contains(contentType, "multipart/form-data") {
 		// Обрабатываем multipart запрос с JSON в поле "event"
-		if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-			h.log.Error().Err(err).Msg("failed to parse multipart request")
+		if err := c.Request.ParseMultipartForm(h.config.Ingest.MaxBodyBytes); err != nil {
+			if h.bodyTooLarge(c, err) {
+				return
+			}
+			h.logger(c).Error().Err(err).Msg("failed to parse multipart request")
+			metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
 			c.JSON(http.StatusBadRequest, errorResponse("invalid multipart payload"))
 			return
 		}
@@ -76,13 +215,15 @@ func (h *Handler) createANPREvent(c *gin.Context) {
 		// Извлекаем JSON из поля "event"
 		eventValue := c.Request.MultipartForm.Value["event"]
 		if len(eventValue) == 0 {
+			metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
 			c.JSON(http.StatusBadRequest, errorResponse("event field not found in multipart form"))
 			return
 		}
 		
 		// Парсим JSON
 		if err := json.Unmarshal([]byte(eventValue[0]), &payload); err != nil {
-			h.log.Error().Err(err).Msg("failed to parse event JSON from multipart")
+			h.logger(c).Error().Err(err).Msg("failed to parse event JSON from multipart")
+			metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
 			c.JSON(http.StatusBadRequest, errorResponse("invalid event JSON"))
 			return
 		}
@@ -91,30 +232,45 @@ func (h *Handler) createANPREvent(c *gin.Context) {
 		// Фотографии можно сохранить и добавить их URL в payload.SnapshotURL
 		// Пока просто логируем наличие фотографий
 		if files := c.Request.MultipartForm.File["photos"]; len(files) > 0 {
-			h.log.Debug().Int("photos_count", len(files)).Msg("received photos in multipart request")
+			h.logger(c).Debug().Int("photos_count", len(files)).Msg("received photos in multipart request")
 			// TODO: сохранить фотографии и добавить URL в payload
 		}
 	} else {
 		// Обычный JSON запрос
 		if err := c.ShouldBindJSON(&payload); err != nil {
+			if h.bodyTooLarge(c, err) {
+				return
+			}
+			metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
 	}
 
+	payload.ReceivedAt = receivedAt
 	if payload.EventTime.IsZero() {
-		payload.EventTime = time.Now()
+		metrics.EventTimeFallbacks.WithLabelValues(metrics.CameraLabel(payload.CameraID), metrics.TimeFallbackMissing).Inc()
+		h.logger(c).Warn().Str("camera_id", payload.CameraID).Msg("event_time is missing, using receive time")
+		payload.EventTime = receivedAt
+		payload.TimeSource = anpr.TimeSourceServer
+	}
+	c.Set(captureCameraKey, payload.CameraID)
+	// Лимит камеры расходуется только запросами, прошедшими аутентификацию или политику
+	auth, ok := h.authenticateCamera(c, payload.CameraID)
+	if !ok || !h.admitCamera(c, payload.CameraID, auth) {
+		return
 	}
+	payload.Auth = auth
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("plate", payload.Plate).
 		Str("camera_id", payload.CameraID).
 		Msg("processing ANPR event")
 
-	result, err := h.anprService.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model)
+	result, err := h.ingestEvents.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().
+			h.logger(c).Warn().
 				Err(err).
 				Str("plate", payload.Plate).
 				Str("camera_id", payload.CameraID).
@@ -122,7 +278,7 @@ func (h *Handler) createANPREvent(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().
+		h.logger(c).Error().
 			Err(err).
 			Str("plate", payload.Plate).
 			Str("camera_id", payload.CameraID).
@@ -131,7 +287,7 @@ func (h *Handler) createANPREvent(c *gin.Context) {
 		return
 	}
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("event_id", result.EventID.String()).
 		Str("plate_id", result.PlateID.String()).
 		Str("plate", result.Plate).
@@ -139,11 +295,13 @@ func (h *Handler) createANPREvent(c *gin.Context) {
 		Msg("successfully processed and saved ANPR event")
 
 	c.JSON(http.StatusCreated, gin.H{
-		"status":   "ok",
-		"event_id": result.EventID,
-		"plate_id": result.PlateID,
-		"plate":    result.Plate,
-		"hits":     result.Hits,
+		"status":         "ok",
+		"event_id":       result.EventID,
+		"plate_id":       plateIDOrNil(result.PlateID),
+		"plate":          result.Plate,
+		"read_status":    result.ReadStatus,
+		"hits":           result.Hits,
+		"review_item_id": result.ReviewItemID,
 	})
 }
 
@@ -160,7 +318,7 @@ func (h *Handler) listPlates(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Msg("failed to find plates")
+		h.logger(c).Error().Err(err).Msg("failed to find plates")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 		return
 	}
@@ -168,46 +326,72 @@ func (h *Handler) listPlates(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(plates))
 }
 
-func (h *Handler) listEvents(c *gin.Context) {
-	var plateQuery *string
-	if plate := strings.TrimSpace(c.Query("plate")); plate != "" {
-		plateQuery = &plate
+// getPlate возвращает карточку номера; окно статистики задаётся from/to (по умолчанию 30 дней)
+func (h *Handler) getPlate(c *gin.Context) {
+	query := service.PlateDetailQuery{
+		From: optionalQuery(c, "from"),
+		To:   optionalQuery(c, "to"),
 	}
-
-	var from, to *string
-	if f := strings.TrimSpace(c.Query("from")); f != "" {
-		from = &f
+	if l := c.Query("recent_limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			query.RecentLimit = parsed
+		}
 	}
-	if t := strings.TrimSpace(c.Query("to")); t != "" {
-		to = &t
+
+	detail, err := h.anprService.GetPlateDetail(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		h.handleError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, successResponse(detail))
+}
 
-	limit := 50
+func (h *Handler) listEvents(c *gin.Context) {
+	query := eventQueryFromRequest(c)
+	query.Cursor = strings.TrimSpace(c.Query("cursor"))
+	query.IncludeTotal = c.Query("include_total") == "true"
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := parseInt(l); err == nil && parsed > 0 {
-			limit = parsed
+			query.Limit = parsed
 		}
 	}
-
-	offset := 0
 	if o := c.Query("offset"); o != "" {
 		if parsed, err := parseInt(o); err == nil && parsed >= 0 {
-			offset = parsed
+			query.Offset = parsed
 		}
 	}
 
-	events, err := h.anprService.FindEvents(c.Request.Context(), plateQuery, from, to, limit, offset)
+	page, err := h.anprService.FindEventsPage(c.Request.Context(), query)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidInput) {
-			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
-			return
-		}
-		h.log.Error().Err(err).Msg("failed to find events")
-		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, successResponse(events))
+	c.JSON(http.StatusOK, page)
+}
+
+// eventQueryFromRequest собирает фильтры и сортировку событий из query-параметров
+// (общие для GET /events и выгрузки)
+func eventQueryFromRequest(c *gin.Context) service.EventQuery {
+	return service.EventQuery{
+		Plate:         optionalQuery(c, "plate"),
+		PlatePrefix:   optionalQuery(c, "plate_prefix"),
+		From:          optionalQuery(c, "from"),
+		To:            optionalQuery(c, "to"),
+		CameraID:      optionalQuery(c, "camera_id"),
+		PolygonID:     optionalQuery(c, "polygon_id"),
+		Direction:     optionalQuery(c, "direction"),
+		Lane:          optionalQuery(c, "lane"),
+		VehicleType:   optionalQuery(c, "vehicle_type"),
+		VehicleColor:  optionalQuery(c, "vehicle_color"),
+		MinConfidence: optionalQuery(c, "min_confidence"),
+		MaxConfidence: optionalQuery(c, "max_confidence"),
+		ListType:      optionalQuery(c, "list_type"),
+		MatchedSnow:   optionalQuery(c, "matched_snow"),
+		ReadStatus:    optionalQuery(c, "read_status"),
+		Auth:          optionalQuery(c, "auth"),
+		Sort:          strings.TrimSpace(c.Query("sort")),
+	}
 }
 
 func (h *Handler) handleError(c *gin.Context, err error) {
@@ -217,13 +401,16 @@ func (h *Handler) handleError(c *gin.Context, err error) {
 	case errors.Is(err, service.ErrNotFound):
 		c.JSON(http.StatusNotFound, errorResponse(err.Error()))
 	default:
-		h.log.Error().Err(err).Msg("handler error")
+		h.logger(c).Error().Err(err).Msg("handler error")
 		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
 	}
 }
 
 func (h *Handler) createHikvisionEvent(c *gin.Context) {
-	h.log.Info().
+	receivedAt := time.Now()
+	defer metrics.ObserveIngest(metrics.SourceHikvision, receivedAt)
+
+	h.logger(c).Info().
 		Str("method", c.Request.Method).
 		Str("path", c.Request.URL.Path).
 		Str("remote_addr", c.ClientIP()).
@@ -231,35 +418,47 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 		Str("content_type", c.Request.Header.Get("Content-Type")).
 		Msg("received Hikvision event request")
 
-	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		h.log.Error().Err(err).Msg("failed to parse multipart request")
+	_, parseSpan := tracing.Start(c.Request.Context(), "hikvision.parse_multipart")
+	// Тело уже ограничено INGEST_MAX_BODY_BYTES, поэтому форма целиком разбирается в памяти
+	err := c.Request.ParseMultipartForm(h.config.Ingest.MaxBodyBytes)
+	tracing.EndSpan(parseSpan, err)
+	if err != nil {
+		if h.bodyTooLarge(c, err) {
+			return
+		}
+		h.logger(c).Error().Err(err).Msg("failed to parse multipart request")
+		metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
 		c.JSON(http.StatusBadRequest, errorResponse("invalid multipart payload"))
 		return
 	}
 
-	xmlPayload, err := extractXMLPayload(c.Request.MultipartForm)
+	xmlPayload, err := hikvision.ExtractXMLPayload(c.Request.MultipartForm)
 	if err != nil {
-		h.log.Error().Err(err).Msg("failed to extract xml payload")
+		h.logger(c).Error().Err(err).Msg("failed to extract xml payload")
+		metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidXML)
 		c.JSON(http.StatusBadRequest, errorResponse("xml payload not found"))
 		return
 	}
 
-	h.log.Debug().
+	h.logger(c).Debug().
 		Int("xml_size", len(xmlPayload)).
 		Str("xml_preview", string(xmlPayload[:min(200, len(xmlPayload))])).
 		Msg("extracted XML payload")
 
-	hikEvent := &hikvisionEvent{}
-	if err := xml.Unmarshal(xmlPayload, hikEvent); err != nil {
-		h.log.Error().
+	_, xmlSpan := tracing.Start(c.Request.Context(), "hikvision.parse_xml")
+	hikEvent, err := hikvision.Parse(xmlPayload)
+	tracing.EndSpan(xmlSpan, err)
+	if err != nil {
+		h.logger(c).Error().
 			Err(err).
 			Str("xml_content", string(xmlPayload)).
 			Msg("failed to parse hikvision xml")
+		metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidXML)
 		c.JSON(http.StatusBadRequest, errorResponse("invalid xml payload"))
 		return
 	}
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("event_type", hikEvent.EventType).
 		Str("license_plate", hikEvent.ANPR.LicensePlate).
 		Str("device_id", hikEvent.DeviceID).
@@ -271,22 +470,76 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 		Str("vehicle_info_model", hikEvent.VehicleInfo.Model).
 		Str("vehicle_info_vehile_model", hikEvent.VehicleInfo.VehileModel).
 		Str("gat_color", hikEvent.VehicleGATInfo.ColorByGAT).
+		Str("kind", hikEvent.Kind()).
 		Msg("parsed Hikvision event")
 
-	payload := hikEvent.ToEventPayload(xmlPayload)
-
-	if payload.CameraID == "" {
-		cameraID := c.Query("camera_id")
+	cameraID := hikEvent.CameraID()
+	if cameraID == "" {
+		cameraID = c.Query("camera_id")
 		if cameraID == "" {
 			cameraID = h.config.Camera.HTTPHost
 		}
-		payload.CameraID = cameraID
 	}
+	c.Set(captureCameraKey, cameraID)
+	// Лимит камеры расходуется только запросами, прошедшими аутентификацию или политику
+	auth, ok := h.authenticateCamera(c, cameraID)
+	if !ok || !h.admitCamera(c, cameraID, auth) {
+		return
+	}
+
+	// Камера повторяет уведомление, на которое получила не 2xx, поэтому heartbeat и
+	// неинтересные приёму тревоги подтверждаются и только считаются
+	kind := hikEvent.Kind()
+	metrics.HikvisionNotifications.WithLabelValues(metrics.CameraLabel(cameraID), hikEvent.NormalizedEventType(), kind).Inc()
+	switch kind {
+	case hikvision.KindHeartbeat:
+		if err := h.ingestCameras.Heartbeat(c.Request.Context(), cameraID, c.ClientIP(), receivedAt); err != nil {
+			h.logger(c).Error().Err(err).Str("camera_id", cameraID).Msg("failed to record camera heartbeat")
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ok",
+			"kind":   kind,
+		})
+		return
+	case hikvision.KindOther:
+		h.ingestCameras.Seen(c.Request.Context(), cameraID, c.ClientIP(), receivedAt)
+		h.logger(c).Info().
+			Str("camera_id", cameraID).
+			Str("event_type", hikEvent.EventType).
+			Str("event_state", hikEvent.EventState).
+			Msg("acknowledged Hikvision notification without a vehicle passage")
+		c.JSON(http.StatusOK, gin.H{
+			"status":     "ok",
+			"kind":       kind,
+			"event_type": hikEvent.EventType,
+			"processed":  false,
+		})
+		return
+	}
+	h.ingestCameras.Seen(c.Request.Context(), cameraID, c.ClientIP(), receivedAt)
+
+	// Время без смещения - местное время камеры в её часовом поясе
+	payload, timeErr := hikEvent.ToEventPayload(xmlPayload, h.ingestCameras.Location(c.Request.Context(), cameraID))
+	payload.CameraID = cameraID
+	payload.ReceivedAt = receivedAt
+	payload.Auth = auth
 	if payload.CameraModel == "" {
 		payload.CameraModel = h.config.Camera.Model
 	}
-	if payload.EventTime.IsZero() {
-		payload.EventTime = time.Now()
+	// Событие без разборчивого времени не теряется, но время получения помечается явно
+	if timeErr != nil {
+		reason := metrics.TimeFallbackInvalid
+		if errors.Is(timeErr, hikvision.ErrMissingTime) {
+			reason = metrics.TimeFallbackMissing
+		}
+		metrics.EventTimeFallbacks.WithLabelValues(metrics.CameraLabel(cameraID), reason).Inc()
+		h.logger(c).Warn().
+			Err(timeErr).
+			Str("camera_id", cameraID).
+			Str("date_time", hikEvent.DateTime).
+			Msg("camera event time is unusable, using receive time")
+		payload.EventTime = receivedAt
+		payload.TimeSource = anpr.TimeSourceServer
 	}
 	if payload.RawPayload == nil {
 		payload.RawPayload = map[string]interface{}{
@@ -294,10 +547,15 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 		}
 	}
 
-	result, err := h.anprService.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model)
+	if kind == hikvision.KindPlateless {
+		h.processHikvisionPlateless(c, payload)
+		return
+	}
+
+	result, err := h.ingestEvents.ProcessIncomingEvent(c.Request.Context(), payload, h.config.Camera.Model)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
-			h.log.Warn().
+			h.logger(c).Warn().
 				Err(err).
 				Str("plate", payload.Plate).
 				Str("camera_id", payload.CameraID).
@@ -305,7 +563,7 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().
+		h.logger(c).Error().
 			Err(err).
 			Str("plate", payload.Plate).
 			Str("camera_id", payload.CameraID).
@@ -314,7 +572,7 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 		return
 	}
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("event_id", result.EventID.String()).
 		Str("plate_id", result.PlateID.String()).
 		Str("plate", result.Plate).
@@ -322,18 +580,48 @@ func (h *Handler) createHikvisionEvent(c *gin.Context) {
 		Msg("successfully processed and saved Hikvision event")
 
 	c.JSON(http.StatusCreated, gin.H{
-		"status":    "ok",
-		"event_id":  result.EventID,
-		"plate_id":  result.PlateID,
-		"plate":     result.Plate,
-		"hits":      result.Hits,
-		"processed": true,
+		"status":         "ok",
+		"event_id":       result.EventID,
+		"plate_id":       plateIDOrNil(result.PlateID),
+		"plate":          result.Plate,
+		"read_status":    result.ReadStatus,
+		"hits":           result.Hits,
+		"review_item_id": result.ReviewItemID,
+		"processed":      true,
+	})
+}
+
+// processHikvisionPlateless сохраняет проезд ТС, номер которого камера не прочитала
+func (h *Handler) processHikvisionPlateless(c *gin.Context, payload anpr.EventPayload) {
+	result, err := h.ingestEvents.ProcessPlatelessPassage(c.Request.Context(), payload, h.config.Camera.Model)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.logger(c).Warn().
+				Err(err).
+				Str("camera_id", payload.CameraID).
+				Msg("invalid input for Hikvision plateless passage")
+			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+			return
+		}
+		h.logger(c).Error().
+			Err(err).
+			Str("camera_id", payload.CameraID).
+			Msg("failed to process hikvision plateless passage")
+		c.JSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":      "ok",
+		"event_id":    result.EventID,
+		"read_status": result.ReadStatus,
+		"processed":   true,
 	})
 }
 
 // checkHikvisionEndpoint обрабатывает GET запросы от камеры для проверки доступности эндпоинта
 func (h *Handler) checkHikvisionEndpoint(c *gin.Context) {
-	h.log.Info().
+	h.logger(c).Info().
 		Str("method", c.Request.Method).
 		Str("path", c.Request.URL.Path).
 		Str("remote_addr", c.ClientIP()).
@@ -347,241 +635,19 @@ func (h *Handler) checkHikvisionEndpoint(c *gin.Context) {
 	})
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-func extractXMLPayload(form *multipart.Form) ([]byte, error) {
-	if form == nil {
-		return nil, errors.New("empty form")
-	}
-
-	for _, files := range form.File {
-		for _, fh := range files {
-			if isXMLFile(fh) {
-				file, err := fh.Open()
-				if err != nil {
-					return nil, err
-				}
-				defer file.Close()
-				return io.ReadAll(file)
-			}
-		}
-	}
-
-	for key, values := range form.Value {
-		if strings.Contains(strings.ToLower(key), "xml") && len(values) > 0 {
-			return []byte(values[0]), nil
-		}
-	}
-
-	return nil, errors.New("xml file not found")
-}
-
-func isXMLFile(fh *multipart.FileHeader) bool {
-	filename := strings.ToLower(fh.Filename)
-	if strings.HasSuffix(filename, ".xml") {
-		return true
-	}
-	contentType := strings.ToLower(fh.Header.Get("Content-Type"))
-	return strings.Contains(contentType, "xml")
-}
-
-type hikvisionEvent struct {
-	XMLName          xml.Name `xml:"EventNotificationAlert"`
-	EventType        string   `xml:"eventType" json:"event_type"`
-	EventDescription string   `xml:"eventDescription" json:"event_description"`
-	DateTime         string   `xml:"dateTime" json:"date_time"`
-	ChannelID        string   `xml:"channelID" json:"channel_id"`
-	DeviceID         string   `xml:"deviceID" json:"device_id"`
-	DeviceName       string   `xml:"deviceName" json:"device_name"`
-	IPAddress        string   `xml:"ipAddress" json:"ip_address"`
-	PortNo           string   `xml:"portNo" json:"port_no"`
-	ProtocolType     string   `xml:"protocolType" json:"protocol_type"`
-	ANPR             struct {
-		LicensePlate    string  `xml:"licensePlate" json:"license_plate"`
-		ConfidenceLevel float64 `xml:"confidenceLevel" json:"confidence_level"`
-		VehicleType     string  `xml:"vehicleType" json:"vehicle_type"`
-		VehicleColor    string  `xml:"vehicleColor" json:"vehicle_color"`
-		Color           string  `xml:"color" json:"color"`
-		PlateColor      string  `xml:"plateColor" json:"plate_color"`
-		Country         string  `xml:"country" json:"country"`
-		Brand           string  `xml:"brand" json:"brand"`
-		Direction       string  `xml:"direction" json:"direction"`
-		LaneNo          string  `xml:"laneNo" json:"lane_no"`
-		Speed           string  `xml:"speed" json:"speed"`
-	} `xml:"ANPR" json:"anpr"`
-	VehicleInfo struct {
-		Type             string `xml:"vehicleType" json:"vehicle_type"`
-		Color            string `xml:"color" json:"color"`
-		VehicleColor     string `xml:"vehicleColor" json:"vehicle_color"`
-		Brand            string `xml:"brand" json:"brand"`
-		VehicleLogoRecog string `xml:"vehicleLogoRecog" json:"vehicle_logo_recog"`
-		Model            string `xml:"vehicleModel" json:"vehicle_model"`
-		VehileModel      string `xml:"vehileModel" json:"vehile_model"`
-		PlateColor       string `xml:"plateColor" json:"plate_color"`
-		Country          string `xml:"country" json:"country"`
-		Speed            string `xml:"speed" json:"speed"`
-	} `xml:"vehicleInfo" json:"vehicle_info"`
-	VehicleGATInfo struct {
-		VehicleTypeByGAT string `xml:"vehicleTypeByGAT" json:"vehicle_type_by_gat"`
-		ColorByGAT       string `xml:"colorByGAT" json:"color_by_gat"`
-		PlateTypeByGAT   string `xml:"palteTypeByGAT" json:"plate_type_by_gat"`
-		PlateColorByGAT  string `xml:"plateColorByGAT" json:"plate_color_by_gat"`
-	} `xml:"VehicleGATInfo" json:"vehicle_gat_info"`
-	PicInfo struct {
-		StoragePath string   `xml:"ftpPath" json:"ftp_path"`
-		FilePath    string   `xml:"filePath" json:"file_path"`
-		FilePaths   []string `xml:"filePathList>filePath" json:"file_path_list"`
-	} `xml:"picInfo" json:"pic_info"`
-}
-
-func (e *hikvisionEvent) ToEventPayload(rawXML []byte) anpr.EventPayload {
-	eventTime := parseHikvisionTime(e.DateTime)
-	lane := parseLane(e.ANPR.LaneNo)
-
-	// Цвет: ПРИОРИТЕТ - текстовые значения из vehicleInfo, НЕ используем GAT коды если есть текст
-	// GAT коды (H, C и т.д.) - это числовые коды, не читаемые названия
-	vehicleColor := firstNonEmpty(
-		e.VehicleInfo.Color,        // "blue", "white" - текстовое значение (ПРИОРИТЕТ)
-		e.VehicleInfo.VehicleColor, // альтернативное поле в vehicleInfo
-		e.ANPR.VehicleColor,        // из ANPR секции (если есть)
-		e.ANPR.Color,               // альтернативное поле в ANPR
-	)
-	// НЕ используем GAT коды - они нечитаемые (H, C и т.д.)
-	// Если текстового значения нет, оставляем пустым
-
-	// Тип: сначала из ANPR, потом из GAT, потом из vehicleInfo
-	vehicleType := firstNonEmpty(
-		e.ANPR.VehicleType,
-		e.VehicleGATInfo.VehicleTypeByGAT,
-		e.VehicleInfo.Type,
-	)
-	vehiclePlateColor := firstNonEmpty(
-		e.ANPR.PlateColor,
-		e.VehicleGATInfo.PlateColorByGAT,
-		e.VehicleInfo.PlateColor,
-	)
-	vehicleCountry := firstNonEmpty(e.ANPR.Country, e.VehicleInfo.Country)
-
-	// Бренд: сначала текстовое значение, потом ID из vehicleLogoRecog
-	vehicleBrand := firstNonEmpty(e.VehicleInfo.Brand, e.ANPR.Brand)
-	// Если текстового значения нет, но есть ID логотипа, сохраняем ID
-	if vehicleBrand == "" && e.VehicleInfo.VehicleLogoRecog != "" && e.VehicleInfo.VehicleLogoRecog != "0" {
-		vehicleBrand = "brand_id:" + e.VehicleInfo.VehicleLogoRecog
-	}
-
-	// Модель: сначала текстовое значение, потом ID из vehileModel
-	vehicleModel := firstNonEmpty(e.VehicleInfo.Model, e.VehicleInfo.VehileModel)
-	// Если текстового значения нет, но есть ID модели, сохраняем ID (игнорируем "0")
-	if vehicleModel == "" || vehicleModel == "0" {
-		// Если есть другой ID модели, используем его
-		if e.VehicleInfo.VehileModel != "" && e.VehicleInfo.VehileModel != "0" {
-			vehicleModel = "model_id:" + e.VehicleInfo.VehileModel
-		} else {
-			vehicleModel = ""
-		}
-	}
-	speedPtr := parseOptionalFloat(firstNonEmpty(e.VehicleInfo.Speed, e.ANPR.Speed))
-
-	cameraModel := firstNonEmpty(e.DeviceName, e.DeviceID)
-	snapshotURL := firstNonEmpty(e.PicInfo.StoragePath, e.PicInfo.FilePath)
-	if snapshotURL == "" && len(e.PicInfo.FilePaths) > 0 {
-		snapshotURL = e.PicInfo.FilePaths[0]
-	}
-
-	rawPayload := map[string]interface{}{
-		"event_type":        e.EventType,
-		"event_description": e.EventDescription,
-		"device_id":         e.DeviceID,
-		"device_name":       e.DeviceName,
-		"channel_id":        e.ChannelID,
-		"ip_address":        e.IPAddress,
-		"port_no":           e.PortNo,
-		"protocol_type":     e.ProtocolType,
-		"anpr":              e.ANPR,
-		"vehicle_info":      e.VehicleInfo,
-		"vehicle_gat_info":  e.VehicleGATInfo,
-	}
-	if len(rawXML) > 0 {
-		rawPayload["xml"] = string(rawXML)
-	}
-
-	return anpr.EventPayload{
-		CameraID:    firstNonEmpty(e.ChannelID, e.DeviceID),
-		CameraModel: cameraModel,
-		Plate:       strings.TrimSpace(e.ANPR.LicensePlate),
-		Confidence:  e.ANPR.ConfidenceLevel,
-		Direction:   e.ANPR.Direction,
-		Lane:        lane,
-		EventTime:   eventTime,
-		Vehicle: anpr.VehicleInfo{
-			Color:      vehicleColor,
-			Type:       vehicleType,
-			Brand:      vehicleBrand,
-			Model:      vehicleModel,
-			Country:    vehicleCountry,
-			PlateColor: vehiclePlateColor,
-			Speed:      speedPtr,
-		},
-		SnapshotURL: snapshotURL,
-		RawPayload:  rawPayload,
-	}
-}
-
-func parseHikvisionTime(value string) time.Time {
-	if value == "" {
-		return time.Time{}
-	}
-
-	layouts := []string{
-		time.RFC3339Nano,
-		time.RFC3339,
-		"2006-01-02T15:04:05Z07:00",
-		"2006-01-02 15:04:05",
-	}
-
-	for _, layout := range layouts {
-		if ts, err := time.Parse(layout, value); err == nil {
-			return ts
-		}
-	}
-
-	return time.Time{}
-}
-
-func parseLane(value string) int {
-	if value == "" {
-		return 0
-	}
-	lane, err := strconv.Atoi(value)
-	if err != nil {
-		return 0
-	}
-	return lane
-}
-
-func parseOptionalFloat(value string) *float64 {
-	if strings.TrimSpace(value) == "" {
+// plateIDOrNil - nil для проезда без номера, чтобы в ответе не было нулевого UUID
+func plateIDOrNil(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
 		return nil
 	}
-
-	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
-		return &f
-	}
-	return nil
+	return &id
 }
 
-func firstNonEmpty(values ...string) string {
-	for _, v := range values {
-		if strings.TrimSpace(v) != "" {
-			return strings.TrimSpace(v)
-		}
+func min(a, b int) int {
+	if a < b {
+		return a
 	}
-	return ""
+	return b
 }
 
 func successResponse(data interface{}) gin.H {
@@ -601,13 +667,19 @@ func (h *Handler) syncVehicleToWhitelist(c *gin.Context) {
 	}
 
 	plateID, err := h.anprService.SyncVehicleToWhitelist(c.Request.Context(), req.PlateNumber)
+	auditResult := map[string]interface{}{}
+	if err == nil {
+		auditResult["plate_id"] = plateID.String()
+	}
+	h.recordAudit(c, service.AuditActionSyncVehicle, req.PlateNumber,
+		map[string]interface{}{"plate_number": req.PlateNumber}, auditResult, err)
 	if err != nil {
-		h.log.Error().Err(err).Str("plate_number", req.PlateNumber).Msg("failed to sync vehicle to whitelist")
+		h.logger(c).Error().Err(err).Str("plate_number", req.PlateNumber).Msg("failed to sync vehicle to whitelist")
 		c.JSON(http.StatusInternalServerError, errorResponse("failed to sync vehicle to whitelist"))
 		return
 	}
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("plate_number", req.PlateNumber).
 		Str("plate_id", plateID.String()).
 		Msg("vehicle synced to whitelist")
@@ -630,18 +702,21 @@ func (h *Handler) deleteOldEvents(c *gin.Context) {
 		return
 	}
 
-	deletedCount, err := h.anprService.DeleteOldEvents(c.Request.Context(), req.Days)
+	deletedCount, archiveID, err := h.anprService.DeleteOldEvents(c.Request.Context(), req.Days)
+	h.recordAudit(c, service.AuditActionDeleteOldEvents, "anpr_events",
+		map[string]interface{}{"days": req.Days},
+		map[string]interface{}{"deleted_count": deletedCount, "archive_id": archiveID}, err)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidInput) {
 			c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
 			return
 		}
-		h.log.Error().Err(err).Int("days", req.Days).Msg("failed to delete old events")
+		h.logger(c).Error().Err(err).Int("days", req.Days).Msg("failed to delete old events")
 		c.JSON(http.StatusInternalServerError, errorResponse("failed to delete old events"))
 		return
 	}
 
-	h.log.Info().
+	h.logger(c).Info().
 		Int("days", req.Days).
 		Int64("deleted_count", deletedCount).
 		Msg("deleted old events")
@@ -649,6 +724,7 @@ func (h *Handler) deleteOldEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":        "ok",
 		"deleted_count": deletedCount,
+		"archive_id":    archiveID,
 		"message":       fmt.Sprintf("deleted %d events older than %d days", deletedCount, req.Days),
 	})
 }
@@ -663,18 +739,22 @@ func (h *Handler) deleteAllEvents(c *gin.Context) {
 		return
 	}
 
-	deletedCount, err := h.anprService.DeleteAllEvents(c.Request.Context())
+	deletedCount, archiveID, err := h.anprService.DeleteAllEvents(c.Request.Context())
+	h.recordAudit(c, service.AuditActionDeleteAllEvents, "anpr_events",
+		map[string]interface{}{"confirm": req.Confirm},
+		map[string]interface{}{"deleted_count": deletedCount, "archive_id": archiveID}, err)
 	if err != nil {
-		h.log.Error().Err(err).Msg("failed to delete all events")
+		h.logger(c).Error().Err(err).Msg("failed to delete all events")
 		c.JSON(http.StatusInternalServerError, errorResponse("failed to delete all events"))
 		return
 	}
 
-	h.log.Warn().Int64("deleted_count", deletedCount).Msg("deleted ALL events")
+	h.logger(c).Warn().Int64("deleted_count", deletedCount).Msg("deleted ALL events")
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":        "ok",
 		"deleted_count": deletedCount,
+		"archive_id":    archiveID,
 		"message":       fmt.Sprintf("deleted all %d events", deletedCount),
 	})
 }
@@ -703,8 +783,12 @@ func (h *Handler) checkCameraStatus(c *gin.Context) {
 
 	// Проверяем доступность HTTP интерфейса камеры
 	if httpHost != "" {
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Get(httpHost)
+		client := tracing.HTTPClient(5 * time.Second)
+		req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, httpHost, nil)
+		var resp *http.Response
+		if err == nil {
+			resp, err = client.Do(req)
+		}
 		if err != nil {
 			status["http_accessible"] = false
 			status["http_error"] = err.Error()
@@ -721,7 +805,7 @@ func (h *Handler) checkCameraStatus(c *gin.Context) {
 	// RTSP URL проверяем только на наличие (для проверки подключения нужен специальный клиент)
 	status["rtsp_configured"] = rtspURL != ""
 
-	h.log.Info().
+	h.logger(c).Info().
 		Str("http_host", httpHost).
 		Bool("http_accessible", status["http_accessible"].(bool)).
 		Msg("camera status checked")
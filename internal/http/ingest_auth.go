@@ -0,0 +1,189 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/service"
+)
+
+const (
+	// Ключ API камеры: заголовок или параметр запроса (для камер, которым доступен только URL)
+	headerAPIKey = "X-API-Key"
+	queryAPIKey  = "api_key"
+	// Подпись тела пограничного ретранслятора, см. service.VerifySignature
+	headerSignature          = "X-Signature"
+	headerSignatureTimestamp = "X-Signature-Timestamp"
+
+	// signedIngestKey - подпись тела запроса проверена
+	signedIngestKey = "ingest_signed"
+
+	authPolicyUntrusted = "untrusted"
+	authPolicyReject    = "reject"
+)
+
+var errReplayedSignature = errors.New("signature was already used")
+
+// verifyIngestSignature проверяет HMAC-подпись сырого тела до его разбора, если запрос подписан.
+// Неверная или повторно использованная подпись отклоняется независимо от INGEST_AUTH_POLICY.
+func (h *Handler) verifyIngestSignature(c *gin.Context) {
+	signature := c.GetHeader(headerSignature)
+	if signature == "" {
+		c.Next()
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		if h.bodyTooLarge(c, err) {
+			return
+		}
+		h.logger(c).Error().Err(err).Msg("failed to read signed request body")
+		metrics.Reject(metrics.UnknownCamera, metrics.RejectInvalidPayload)
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse("invalid payload"))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := time.Now()
+	err = service.VerifySignature(h.config.Ingest.HMACSecrets, c.GetHeader(headerSignatureTimestamp), signature, body, now, h.config.Ingest.SignatureMaxAge)
+	if err == nil && !h.signatures.remember(signature, now) {
+		err = errReplayedSignature
+	}
+	if err != nil {
+		h.logger(c).Warn().Err(err).Str("remote_addr", c.ClientIP()).Msg("rejected signed ingest request")
+		h.unauthorized(c, metrics.UnknownCamera, metrics.RejectInvalidCredentials, "invalid signature")
+		return
+	}
+	c.Set(signedIngestKey, true)
+	c.Next()
+}
+
+// authenticateCamera определяет, чем подтверждён источник события камеры cameraID: подписью тела,
+// ключом API камеры или её клиентским сертификатом. Без подтверждения действует INGEST_AUTH_POLICY.
+// Возвращает значение anpr.EventPayload.Auth; false - запрос отклонён и ответ уже отправлен.
+func (h *Handler) authenticateCamera(c *gin.Context, cameraID string) (string, bool) {
+	// Событие без camera_id отклонит приём; его нечем сопоставить с ключом или сертификатом
+	if cameraID == "" {
+		return "", true
+	}
+	ctx := c.Request.Context()
+
+	method := ""
+	switch {
+	case c.GetBool(signedIngestKey):
+		method = anpr.AuthSignature
+	case ingestAPIKey(c) != "":
+		ok, err := h.ingestCameras.AuthenticateAPIKey(ctx, cameraID, ingestAPIKey(c))
+		if err != nil {
+			h.authLookupFailed(c, cameraID, err)
+			return "", false
+		}
+		if !ok {
+			h.logger(c).Warn().Str("camera_id", cameraID).Str("remote_addr", c.ClientIP()).Msg("invalid camera api key")
+			h.unauthorized(c, cameraID, metrics.RejectInvalidCredentials, "invalid api key")
+			return "", false
+		}
+		method = anpr.AuthAPIKey
+	case clientCertificateCN(c) != "":
+		cn := clientCertificateCN(c)
+		ok, err := h.ingestCameras.CertificateMatches(ctx, cameraID, cn)
+		if err != nil {
+			h.authLookupFailed(c, cameraID, err)
+			return "", false
+		}
+		if !ok {
+			h.logger(c).Warn().Str("camera_id", cameraID).Str("certificate_cn", cn).Msg("client certificate does not match camera")
+			h.unauthorized(c, cameraID, metrics.RejectInvalidCredentials, "client certificate does not match camera")
+			return "", false
+		}
+		method = anpr.AuthMTLS
+	}
+	if method != "" {
+		metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), method).Inc()
+		return method, true
+	}
+
+	switch h.config.Ingest.AuthPolicy {
+	case authPolicyReject:
+		h.logger(c).Warn().Str("camera_id", cameraID).Str("remote_addr", c.ClientIP()).Msg("unauthenticated ingest request rejected")
+		h.unauthorized(c, cameraID, metrics.RejectUnauthenticated, "camera authentication required")
+		return "", false
+	case authPolicyUntrusted:
+		metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), anpr.AuthUntrusted).Inc()
+		return anpr.AuthUntrusted, true
+	}
+	metrics.IngestAuth.WithLabelValues(metrics.CameraLabel(cameraID), metrics.AuthNone).Inc()
+	return "", true
+}
+
+func ingestAPIKey(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader(headerAPIKey)); key != "" {
+		return key
+	}
+	return strings.TrimSpace(c.Query(queryAPIKey))
+}
+
+// clientCertificateCN - CN проверенного клиентского сертификата (mTLS); пусто - сертификата нет
+func clientCertificateCN(c *gin.Context) string {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	return state.PeerCertificates[0].Subject.CommonName
+}
+
+func (h *Handler) unauthorized(c *gin.Context, cameraID, reason, message string) {
+	metrics.Reject(cameraID, reason)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(message))
+}
+
+func (h *Handler) authLookupFailed(c *gin.Context, cameraID string, err error) {
+	h.logger(c).Error().Err(err).Str("camera_id", cameraID).Msg("failed to authenticate camera")
+	metrics.Reject(cameraID, metrics.RejectDBError)
+	c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse("internal error"))
+}
+
+// signatureCache - подписи, принятые за последние INGEST_SIGNATURE_MAX_AGE: повтор перехваченного
+// подписанного запроса в пределах окна подписи отклоняется
+type signatureCache struct {
+	maxAge time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newSignatureCache(maxAge time.Duration) *signatureCache {
+	return &signatureCache{maxAge: maxAge, seen: make(map[string]time.Time)}
+}
+
+// remember запоминает подпись; false - подпись уже встречалась в окне
+func (s *signatureCache) remember(signature string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= s.maxAge {
+		for sig, at := range s.seen {
+			// Запоминается время получения, а метка подписи может отставать от него на maxAge
+			if now.Sub(at) > 2*s.maxAge {
+				delete(s.seen, sig)
+			}
+		}
+		s.lastSweep = now
+	}
+	if at, ok := s.seen[signature]; ok && now.Sub(at) <= 2*s.maxAge {
+		return false
+	}
+	s.seen[signature] = now
+	return true
+}
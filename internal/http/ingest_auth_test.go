@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/service"
+)
+
+func TestIngestAuthPolicies(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantStatus int
+		wantAuth   string
+	}{
+		{policy: "off", wantStatus: http.StatusCreated, wantAuth: ""},
+		{policy: authPolicyUntrusted, wantStatus: http.StatusCreated, wantAuth: anpr.AuthUntrusted},
+		{policy: authPolicyReject, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			events := &fakeIngestEvents{}
+			cameras := &fakeIngestCameras{registered: map[string]bool{"cam-1": true}}
+			r := newIngestRouter(events, cameras, config.IngestConfig{AuthPolicy: tt.policy})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, jsonEventRequest("cam-1", "123ABC02"))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(events.events) != 0 {
+					t.Error("rejected event must not be processed")
+				}
+				return
+			}
+			if len(events.events) != 1 || events.events[0].Auth != tt.wantAuth {
+				t.Errorf("events = %+v, want one with auth %q", events.events, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestIngestAPIKey(t *testing.T) {
+	cameras := &fakeIngestCameras{
+		registered: map[string]bool{"cam-1": true},
+		apiKeys:    map[string]string{"cam-1": "secret"},
+	}
+	withHeader := func(req *http.Request) *http.Request {
+		req.Header.Set(headerAPIKey, "secret")
+		return req
+	}
+	withQuery := func(req *http.Request) *http.Request {
+		req.URL.RawQuery = queryAPIKey + "=secret"
+		return req
+	}
+	withWrongKey := func(req *http.Request) *http.Request {
+		req.Header.Set(headerAPIKey, "stolen")
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		prepare    func(*http.Request) *http.Request
+		wantStatus int
+	}{
+		{name: "header", prepare: withHeader, wantStatus: http.StatusCreated},
+		{name: "query", prepare: withQuery, wantStatus: http.StatusCreated},
+		{name: "wrong key", prepare: withWrongKey, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &fakeIngestEvents{}
+			r := newIngestRouter(events, cameras, config.IngestConfig{AuthPolicy: authPolicyReject})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tt.prepare(jsonEventRequest("cam-1", "123ABC02")))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusCreated && (len(events.events) != 1 || events.events[0].Auth != anpr.AuthAPIKey) {
+				t.Errorf("events = %+v, want one with auth api_key", events.events)
+			}
+		})
+	}
+}
+
+func TestIngestSignature(t *testing.T) {
+	cameras := &fakeIngestCameras{registered: map[string]bool{"cam-1": true}}
+	events := &fakeIngestEvents{}
+	r := newIngestRouter(events, cameras, config.IngestConfig{
+		AuthPolicy:  authPolicyReject,
+		HMACSecrets: []string{"relay-secret"},
+	})
+
+	signed := func(secret string, at time.Time) *http.Request {
+		req := jsonEventRequest("cam-1", "123ABC02")
+		body, _ := io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		req.Header.Set(headerSignatureTimestamp, timestamp)
+		req.Header.Set(headerSignature, service.SignBody(secret, timestamp, body))
+		return req
+	}
+	send := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Тело и метка времени те же - повтор перехваченного запроса с той же подписью
+	now := time.Now()
+	if code := send(signed("relay-secret", now)); code != http.StatusCreated {
+		t.Fatalf("valid signature: status %d, want 201", code)
+	}
+	if len(events.events) != 1 || events.events[0].Auth != anpr.AuthSignature {
+		t.Fatalf("events = %+v, want one with auth signature", events.events)
+	}
+	if code := send(signed("relay-secret", now)); code != http.StatusUnauthorized {
+		t.Errorf("replayed signature: status %d, want 401", code)
+	}
+	if code := send(signed("wrong-secret", time.Now())); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status %d, want 401", code)
+	}
+	if code := send(signed("relay-secret", time.Now().Add(-time.Hour))); code != http.StatusUnauthorized {
+		t.Errorf("expired timestamp: status %d, want 401", code)
+	}
+	if len(events.events) != 1 {
+		t.Errorf("rejected signatures must not be processed: %d events", len(events.events))
+	}
+}
+
+func TestRejectedAuthDoesNotChargeCameraLimit(t *testing.T) {
+	cameras := &fakeIngestCameras{
+		registered: map[string]bool{"cam-1": true},
+		apiKeys:    map[string]string{"cam-1": "secret"},
+	}
+	r := newIngestRouter(&fakeIngestEvents{}, cameras, config.IngestConfig{
+		AuthPolicy:  authPolicyReject,
+		CameraRate:  0.001,
+		CameraBurst: 1,
+	})
+
+	for i := 0; i < 3; i++ {
+		req := jsonEventRequest("cam-1", "123ABC02")
+		req.Header.Set(headerAPIKey, "stolen")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong key: status %d, want 401", w.Code)
+		}
+	}
+	req := jsonEventRequest("cam-1", "123ABC02")
+	req.Header.Set(headerAPIKey, "secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("camera after rejected requests: status %d, want 201", w.Code)
+	}
+}
+
+func TestSignatureCache(t *testing.T) {
+	cache := newSignatureCache(time.Minute)
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+
+	if !cache.remember("sha256=aa", now) {
+		t.Fatal("first signature must be accepted")
+	}
+	if cache.remember("sha256=aa", now.Add(90*time.Second)) {
+		t.Error("signature repeated within twice the max age must be rejected")
+	}
+	if !cache.remember("sha256=bb", now.Add(90*time.Second)) {
+		t.Error("other signature must be accepted")
+	}
+	if !cache.remember("sha256=aa", now.Add(3*time.Minute)) {
+		t.Error("signature older than twice the max age is outside the window and can be remembered again")
+	}
+	if _, ok := cache.seen["sha256=bb"]; !ok {
+		t.Error("signature within the window must survive the sweep")
+	}
+	cache.remember("sha256=cc", now.Add(10*time.Minute))
+	if len(cache.seen) != 1 {
+		t.Errorf("expired signatures must be swept: %v", cache.seen)
+	}
+}
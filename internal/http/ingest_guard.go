@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/service"
+)
+
+// limitIngest защищает публичный приём: лимит запросов на адрес клиента и размер тела.
+// Стоит перед captureIngest, чтобы отклонённый поток запросов не сохранялся на диск.
+func (h *Handler) limitIngest(c *gin.Context) {
+	if ok, retryAfter := h.ipLimiter.Allow(c.ClientIP()); !ok {
+		h.logger(c).Warn().Str("remote_addr", c.ClientIP()).Msg("ingest rate limit exceeded for client address")
+		h.tooManyRequests(c, metrics.UnknownCamera, metrics.RejectIPRateLimited, retryAfter)
+		return
+	}
+
+	maxBody := h.config.Ingest.MaxBodyBytes
+	if c.Request.ContentLength > maxBody {
+		h.rejectBodyTooLarge(c, metrics.UnknownCamera)
+		return
+	}
+	// Тело без Content-Length (chunked) обрывается на лимите; ошибку разбора обработчик
+	// распознаёт через bodyTooLarge
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+	c.Next()
+}
+
+// admitCamera проверяет адрес клиента по allowlist камеры и лимит запросов камеры. Вызывается
+// после authenticateCamera: auth - чем подтверждён источник. false - запрос отклонён и ответ уже отправлен.
+func (h *Handler) admitCamera(c *gin.Context, cameraID, auth string) bool {
+	if cameraID == "" {
+		return true
+	}
+	camera, err := h.ingestCameras.Lookup(c.Request.Context(), cameraID)
+	if err != nil {
+		h.logger(c).Error().Err(err).Str("camera_id", cameraID).Msg("failed to look up camera")
+		metrics.Reject(cameraID, metrics.RejectDBError)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return false
+	}
+	allowed, err := h.ingestCameras.AddressAllowed(c.Request.Context(), cameraID, c.ClientIP())
+	if err != nil {
+		h.logger(c).Error().Err(err).Str("camera_id", cameraID).Msg("failed to check camera address allowlist")
+		metrics.Reject(cameraID, metrics.RejectDBError)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse("internal error"))
+		return false
+	}
+	if !allowed {
+		h.logger(c).Warn().
+			Str("camera_id", cameraID).
+			Str("remote_addr", c.ClientIP()).
+			Msg("ingest request from address not allowed for camera")
+		metrics.Reject(cameraID, metrics.RejectAddressNotAllowed)
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse("address is not allowed for this camera"))
+		return false
+	}
+	if ok, retryAfter := h.cameraLimiter.Allow(service.CameraBucket(cameraID, auth, camera != nil)); !ok {
+		h.logger(c).Warn().Str("camera_id", cameraID).Msg("ingest rate limit exceeded for camera")
+		h.tooManyRequests(c, cameraID, metrics.RejectCameraRateLimited, retryAfter)
+		return false
+	}
+	return true
+}
+
+// bodyTooLarge отвечает 413, если разбор тела оборвался на INGEST_MAX_BODY_BYTES
+func (h *Handler) bodyTooLarge(c *gin.Context, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+	h.rejectBodyTooLarge(c, metrics.UnknownCamera)
+	return true
+}
+
+func (h *Handler) rejectBodyTooLarge(c *gin.Context, cameraID string) {
+	h.logger(c).Warn().
+		Int64("content_length", c.Request.ContentLength).
+		Int64("max_body_bytes", h.config.Ingest.MaxBodyBytes).
+		Msg("ingest request body is too large")
+	metrics.Reject(cameraID, metrics.RejectBodyTooLarge)
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResponse("request body is too large"))
+}
+
+func (h *Handler) tooManyRequests(c *gin.Context, cameraID, reason string, retryAfter time.Duration) {
+	metrics.Reject(cameraID, reason)
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse("rate limit exceeded"))
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/ratelimit"
+	"anpr-service/internal/repository"
+)
+
+// fakeIngestEvents запоминает переданные на приём события
+type fakeIngestEvents struct {
+	mu        sync.Mutex
+	events    []anpr.EventPayload
+	plateless []anpr.EventPayload
+}
+
+func (f *fakeIngestEvents) ProcessIncomingEvent(_ context.Context, payload anpr.EventPayload, _ string) (*anpr.ProcessResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, payload)
+	return &anpr.ProcessResult{EventID: uuid.New(), PlateID: uuid.New(), Plate: payload.Plate, ReadStatus: "accepted"}, nil
+}
+
+func (f *fakeIngestEvents) ProcessPlatelessPassage(_ context.Context, payload anpr.EventPayload, _ string) (*anpr.ProcessResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.plateless = append(f.plateless, payload)
+	return &anpr.ProcessResult{EventID: uuid.New(), ReadStatus: "plateless"}, nil
+}
+
+// fakeIngestCameras - реестр камер в памяти: зарегистрированные камеры, ключи API и отметки связи
+type fakeIngestCameras struct {
+	mu         sync.Mutex
+	registered map[string]bool
+	apiKeys    map[string]string
+	heartbeats []string
+	seen       []string
+}
+
+func (f *fakeIngestCameras) Lookup(_ context.Context, cameraID string) (*repository.Camera, error) {
+	if !f.registered[cameraID] {
+		return nil, nil
+	}
+	return &repository.Camera{CameraID: cameraID}, nil
+}
+
+func (f *fakeIngestCameras) AddressAllowed(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeIngestCameras) AuthenticateAPIKey(_ context.Context, cameraID, key string) (bool, error) {
+	expected, ok := f.apiKeys[cameraID]
+	return ok && expected == key, nil
+}
+
+func (f *fakeIngestCameras) CertificateMatches(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeIngestCameras) Location(context.Context, string) *time.Location {
+	return time.UTC
+}
+
+func (f *fakeIngestCameras) Heartbeat(_ context.Context, cameraID, _ string, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.heartbeats = append(f.heartbeats, cameraID)
+	return nil
+}
+
+func (f *fakeIngestCameras) Seen(_ context.Context, cameraID, _ string, _ time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seen = append(f.seen, cameraID)
+}
+
+func newIngestRouter(events *fakeIngestEvents, cameras *fakeIngestCameras, ingest config.IngestConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	if ingest.MaxBodyBytes == 0 {
+		ingest.MaxBodyBytes = 1 << 20
+	}
+	if ingest.SignatureMaxAge == 0 {
+		ingest.SignatureMaxAge = 5 * time.Minute
+	}
+	h := &Handler{
+		cameraLimiter: ratelimit.New(ingest.CameraRate, ingest.CameraBurst),
+		ingestEvents:  events,
+		ingestCameras: cameras,
+		signatures:    newSignatureCache(ingest.SignatureMaxAge),
+		config:        &config.Config{Ingest: ingest},
+		log:           zerolog.Nop(),
+	}
+	r := gin.New()
+	h.Register(r, func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+	return r
+}
+
+// hikvisionRequest собирает multipart-уведомление камеры из XML testdata пакета hikvision
+func hikvisionRequest(t *testing.T, fixture string) *http.Request {
+	t.Helper()
+	xmlBody, err := os.ReadFile("../hikvision/testdata/" + fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("anpr.xml", "anpr.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(xmlBody)
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anpr/hikvision", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestHikvisionHeartbeatUpdatesLiveness(t *testing.T) {
+	events, cameras := &fakeIngestEvents{}, &fakeIngestCameras{}
+	r := newIngestRouter(events, cameras, config.IngestConfig{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, hikvisionRequest(t, "heartbeat.xml"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	if len(cameras.heartbeats) != 1 || cameras.heartbeats[0] != "1" {
+		t.Errorf("heartbeats = %v, want [1]", cameras.heartbeats)
+	}
+	if len(events.events)+len(events.plateless) != 0 {
+		t.Error("heartbeat must not be stored as an event")
+	}
+}
+
+func TestHikvisionOtherAlarmAcknowledgedAndCounted(t *testing.T) {
+	metrics.SetKnownCameras([]string{"1"})
+	counter := metrics.HikvisionNotifications.WithLabelValues("1", "vmd", "other")
+	before := testutil.ToFloat64(counter)
+
+	events, cameras := &fakeIngestEvents{}, &fakeIngestCameras{}
+	r := newIngestRouter(events, cameras, config.IngestConfig{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, hikvisionRequest(t, "vmd.xml"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("notifications counted %v times, want 1", got)
+	}
+	if len(cameras.seen) != 1 {
+		t.Errorf("alarm must mark the camera as seen: %v", cameras.seen)
+	}
+	if len(events.events)+len(events.plateless) != 0 {
+		t.Error("alarm without a vehicle passage must not be stored as an event")
+	}
+}
+
+func TestHikvisionPlatelessDetectionStored(t *testing.T) {
+	events, cameras := &fakeIngestEvents{}, &fakeIngestCameras{}
+	r := newIngestRouter(events, cameras, config.IngestConfig{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, hikvisionRequest(t, "vehicle_detection_noplate.xml"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d, want 201: %s", w.Code, w.Body)
+	}
+	if len(events.plateless) != 1 || len(events.events) != 0 {
+		t.Fatalf("plateless = %d, events = %d, want 1 plateless passage", len(events.plateless), len(events.events))
+	}
+	if p := events.plateless[0]; p.CameraID != "2" || p.Vehicle.Color != "black" {
+		t.Errorf("unexpected plateless payload: %+v", p)
+	}
+}
+
+// jsonEventRequest - событие в JSON от камеры cameraID
+func jsonEventRequest(cameraID, plate string) *http.Request {
+	body := `{"camera_id":"` + cameraID + `","plate":"` + plate + `","event_time":"2025-01-21T12:34:56Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/anpr/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestCameraLimitSharedByUnregisteredIDs(t *testing.T) {
+	events := &fakeIngestEvents{}
+	cameras := &fakeIngestCameras{registered: map[string]bool{"cam-1": true}, apiKeys: map[string]string{"cam-1": "secret"}}
+	r := newIngestRouter(events, cameras, config.IngestConfig{CameraRate: 0.001, CameraBurst: 1})
+
+	send := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := send(jsonEventRequest("spoofed-1", "123ABC02")); code != http.StatusCreated {
+		t.Fatalf("first unregistered camera: status %d, want 201", code)
+	}
+	if code := send(jsonEventRequest("spoofed-2", "123ABC02")); code != http.StatusTooManyRequests {
+		t.Errorf("another unregistered id must share the bucket: status %d, want 429", code)
+	}
+
+	// Неподтверждённые запросы от имени камеры не расходуют лимит её подтверждённых запросов
+	if code := send(jsonEventRequest("cam-1", "123ABC02")); code != http.StatusCreated {
+		t.Fatalf("unverified request: status %d, want 201", code)
+	}
+	if code := send(jsonEventRequest("cam-1", "123ABC02")); code != http.StatusTooManyRequests {
+		t.Errorf("second unverified request: status %d, want 429", code)
+	}
+	req := jsonEventRequest("cam-1", "123ABC02")
+	req.Header.Set(headerAPIKey, "secret")
+	if code := send(req); code != http.StatusCreated {
+		t.Errorf("authenticated request after spoofed ones: status %d, want 201", code)
+	}
+}
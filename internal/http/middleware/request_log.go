@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+
+	"anpr-service/internal/logger"
+	"anpr-service/internal/utils"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+	maxRequestIDLen = 128
+)
+
+// RequestLogConfig задаёт сэмплирование access-логов для высоконагруженных путей
+type RequestLogConfig struct {
+	// SampledPaths - пути, успешные запросы к которым логируются не все
+	SampledPaths []string
+	// SampleEvery - логировать каждый N-й успешный запрос к SampledPaths (1 - все)
+	SampleEvery uint32
+	// SkipPaths - пути, которые не логируются вовсе (health, metrics)
+	SkipPaths []string
+}
+
+// RequestLogger присваивает или продолжает X-Request-ID, кладёт логгер запроса в контекст
+// и пишет access-лог через zerolog
+func RequestLogger(base zerolog.Logger, cfg RequestLogConfig) gin.HandlerFunc {
+	sampled := make(map[string]bool, len(cfg.SampledPaths))
+	for _, p := range cfg.SampledPaths {
+		sampled[p] = true
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = true
+	}
+	var counter atomic.Uint32
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		logCtx := base.With().Str("request_id", requestID)
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			logCtx = logCtx.Str("trace_id", sc.TraceID().String())
+		}
+		reqLog := logCtx.Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), reqLog))
+
+		c.Next()
+
+		path := c.Request.URL.Path
+		if skip[path] {
+			return
+		}
+		status := c.Writer.Status()
+		if status < 400 && sampled[c.FullPath()] && cfg.SampleEvery > 1 {
+			if counter.Add(1)%cfg.SampleEvery != 0 {
+				return
+			}
+		}
+
+		var event *zerolog.Event
+		switch {
+		case status >= 500:
+			event = reqLog.Error()
+		case status >= 400:
+			event = reqLog.Warn()
+		default:
+			event = reqLog.Info()
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+		event.
+			Str("method", c.Request.Method).
+			Str("path", path).
+			Str("query", utils.RedactQuery(c.Request.URL.RawQuery, "api_key")).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Int("bytes_out", c.Writer.Size()).
+			Str("user_agent", c.Request.UserAgent()).
+			Msg("http request")
+	}
+}
+
+// validRequestID - входящий X-Request-ID можно продолжить: непустой, не длиннее maxRequestIDLen
+// и только из букв, цифр и "-_.:" - иначе через него в логи попали бы переводы строк и мусор
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID возвращает идентификатор текущего запроса
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
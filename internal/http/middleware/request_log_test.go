@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/logger"
+)
+
+// newLoggedRouter - роутер с RequestLogger, пишущим в buf; /status/:code отвечает заданным кодом
+// и пишет строку через логгер из контекста, как сервисы
+func newLoggedRouter(buf *bytes.Buffer, cfg RequestLogConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger(zerolog.New(buf), cfg))
+	r.GET("/status/:code", func(c *gin.Context) {
+		code := http.StatusOK
+		switch c.Param("code") {
+		case "400":
+			code = http.StatusBadRequest
+		case "500":
+			code = http.StatusInternalServerError
+		}
+		logger.FromContext(c.Request.Context(), zerolog.Nop()).Info().Msg("service log")
+		c.Status(code)
+	})
+	return r
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestLoggerRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		reused   bool
+	}{
+		{"valid id is reused", "req-42.abc:1", true},
+		{"no id", "", false},
+		{"newline", "abc\nfake=entry", false},
+		{"space", "abc def", false},
+		{"too long", strings.Repeat("a", maxRequestIDLen+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := newLoggedRouter(&buf, RequestLogConfig{})
+
+			req := httptest.NewRequest(http.MethodGet, "/status/200", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.reused && id != tt.incoming {
+				t.Errorf("response id %q, want incoming %q", id, tt.incoming)
+			}
+			if !tt.reused {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("response id %q, want a new UUID", id)
+				}
+			}
+
+			// Строка сервиса и access-лог несут тот же идентификатор, что вернулся клиенту
+			lines := logLines(t, &buf)
+			if len(lines) != 2 {
+				t.Fatalf("log lines = %d, want service log and access log", len(lines))
+			}
+			for _, line := range lines {
+				if line["request_id"] != id {
+					t.Errorf("%v: request_id %v, want %q", line["message"], line["request_id"], id)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestLoggerRedactsAPIKey(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf, RequestLogConfig{})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/200?camera_id=cam-1&api_key=secret&api%5Fkey=other", nil))
+
+	lines := logLines(t, &buf)
+	access := lines[len(lines)-1]
+	query, _ := access["query"].(string)
+	if strings.Contains(query, "secret") || strings.Contains(query, "other") {
+		t.Errorf("api key leaked into access log: %q", query)
+	}
+	if !strings.Contains(query, "camera_id=cam-1") || !strings.Contains(query, "api_key=REDACTED") {
+		t.Errorf("query = %q", query)
+	}
+}
+
+func TestRequestLoggerSamplingKeepsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf, RequestLogConfig{SampledPaths: []string{"/status/:code"}, SampleEvery: 1000})
+
+	const requests = 10
+	for _, code := range []string{"200", "400", "500"} {
+		for i := 0; i < requests; i++ {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/"+code, nil))
+		}
+	}
+
+	counts := map[string]int{}
+	for _, line := range logLines(t, &buf) {
+		if line["message"] == "http request" {
+			counts[line["level"].(string)]++
+		}
+	}
+	if counts["warn"] != requests || counts["error"] != requests {
+		t.Errorf("4xx logged %d, 5xx logged %d; want all %d", counts["warn"], counts["error"], requests)
+	}
+	if counts["info"] >= requests {
+		t.Errorf("successful requests logged %d of %d, want sampled", counts["info"], requests)
+	}
+}
+
+func TestRequestLoggerSkipPaths(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(&buf, RequestLogConfig{SkipPaths: []string{"/status/200"}})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status/200", nil))
+	for _, line := range logLines(t, &buf) {
+		if line["message"] == "http request" {
+			t.Errorf("skipped path logged: %v", line)
+		}
+	}
+}
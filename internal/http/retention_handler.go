@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) listRetentionPolicies(c *gin.Context) {
+	policies, err := h.retentionService.ListPolicies(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(policies))
+}
+
+func (h *Handler) createRetentionPolicy(c *gin.Context) {
+	var req service.RetentionPolicyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	policy, err := h.retentionService.CreatePolicy(c.Request.Context(), req)
+	auditResult := map[string]interface{}{}
+	if err == nil {
+		auditResult["policy_id"] = policy.ID
+	}
+	h.recordAudit(c, service.AuditActionCreateRetentionPolicy, req.Name, retentionAuditParams(req), auditResult, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(policy))
+}
+
+func (h *Handler) updateRetentionPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid policy id"))
+		return
+	}
+
+	var req service.RetentionPolicyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	policy, err := h.retentionService.UpdatePolicy(c.Request.Context(), id, req)
+	h.recordAudit(c, service.AuditActionUpdateRetentionPolicy, id.String(), retentionAuditParams(req), nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(policy))
+}
+
+func (h *Handler) deleteRetentionPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("invalid policy id"))
+		return
+	}
+
+	err = h.retentionService.DeletePolicy(c.Request.Context(), id)
+	h.recordAudit(c, service.AuditActionDeleteRetentionPolicy, id.String(), nil, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// previewRetention показывает, сколько строк затронет каждая политика, ничего не удаляя
+func (h *Handler) previewRetention(c *gin.Context) {
+	h.runRetention(c, true)
+}
+
+func (h *Handler) runRetentionNow(c *gin.Context) {
+	h.runRetention(c, false)
+}
+
+func (h *Handler) runRetention(c *gin.Context, dryRun bool) {
+	run, err := h.retentionService.Run(c.Request.Context(), service.RetentionTriggerManual, dryRun)
+	if !dryRun {
+		auditResult := map[string]interface{}{}
+		if run != nil {
+			auditResult["run_id"] = run.ID
+			auditResult["results"] = run.Results
+		}
+		h.recordAudit(c, service.AuditActionRunRetention, "anpr_events", nil, auditResult, err)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrRetentionRunning) {
+			c.JSON(http.StatusConflict, errorResponse(err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(run))
+}
+
+func (h *Handler) listRetentionRuns(c *gin.Context) {
+	limit, offset := parsePaging(c)
+
+	runs, err := h.retentionService.ListRuns(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(runs))
+}
+
+// linkTripEvents отмечает события поездки, чтобы политики хранения с trip_linked их отличали
+func (h *Handler) linkTripEvents(c *gin.Context) {
+	var req service.TripEventsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	linked, err := h.retentionService.LinkTripEvents(c.Request.Context(), c.Param("trip_id"), req)
+	h.recordAudit(c, service.AuditActionLinkTripEvents, c.Param("trip_id"),
+		map[string]interface{}{"event_ids": len(req.EventIDs)}, map[string]interface{}{"linked": linked}, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"linked": linked}))
+}
+
+func (h *Handler) unlinkTrip(c *gin.Context) {
+	unlinked, err := h.retentionService.UnlinkTrip(c.Request.Context(), c.Param("trip_id"))
+	h.recordAudit(c, service.AuditActionUnlinkTrip, c.Param("trip_id"), nil, map[string]interface{}{"unlinked": unlinked}, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"unlinked": unlinked}))
+}
+
+func retentionAuditParams(req service.RetentionPolicyInput) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        req.Name,
+		"target":      req.Target,
+		"camera_id":   req.CameraID,
+		"polygon_id":  req.PolygonID,
+		"list_type":   req.ListType,
+		"retain_days": req.RetainDays,
+		"priority":    req.Priority,
+		"enabled":     req.Enabled,
+
+		"trip_linked":  req.TripLinked,
+		"retain_until": req.RetainUntil,
+	}
+}
+
+func (h *Handler) listPartitions(c *gin.Context) {
+	partitions, err := h.partitionService.ListPartitions(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(partitions))
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"anpr-service/internal/http/middleware"
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) listReviewItems(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	items, err := h.reviewService.FindItems(c.Request.Context(), service.ReviewQuery{
+		Status:    optionalQuery(c, "status"),
+		PolygonID: optionalQuery(c, "polygon_id"),
+		CameraID:  optionalQuery(c, "camera_id"),
+		Plate:     optionalQuery(c, "plate"),
+	}, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(items))
+}
+
+func (h *Handler) getReviewItem(c *gin.Context) {
+	item, err := h.reviewService.GetItem(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(item))
+}
+
+// resolveReviewItem принимает решение оператора по номеру вне списков
+func (h *Handler) resolveReviewItem(c *gin.Context) {
+	var decision service.ReviewDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	var resolvedBy *uuid.UUID
+	if principal, ok := middleware.MustPrincipal(c); ok {
+		resolvedBy = &principal.UserID
+	}
+
+	item, err := h.reviewService.ResolveItem(c.Request.Context(), c.Param("id"), decision, resolvedBy)
+	h.recordAudit(c, service.AuditActionResolveReviewItem, "anpr_review_items",
+		map[string]interface{}{"review_item_id": c.Param("id"), "decision": decision}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(item))
+}
+
+func (h *Handler) listReviewPolicies(c *gin.Context) {
+	policies, err := h.reviewService.ListPolicies(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(policies))
+}
+
+func (h *Handler) upsertReviewPolicy(c *gin.Context) {
+	var input service.ReviewPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	polygonID := c.Param("polygon_id")
+	policy, err := h.reviewService.UpsertPolicy(c.Request.Context(), polygonID, input)
+	h.recordAudit(c, service.AuditActionUpsertReviewPolicy, "anpr_review_policies",
+		map[string]interface{}{"polygon_id": polygonID, "policy": input}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(policy))
+}
+
+func (h *Handler) deleteReviewPolicy(c *gin.Context) {
+	polygonID := c.Param("polygon_id")
+	err := h.reviewService.DeletePolicy(c.Request.Context(), polygonID)
+	h.recordAudit(c, service.AuditActionDeleteReviewPolicy, "anpr_review_policies",
+		map[string]interface{}{"polygon_id": polygonID}, nil, err)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
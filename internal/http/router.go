@@ -2,15 +2,18 @@ package http
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 
 	"anpr-service/internal/db"
+	"anpr-service/internal/http/middleware"
 )
 
 func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, database *gorm.DB) *gin.Engine {
@@ -19,43 +22,30 @@ func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, dat
 	}
 
 	router := gin.New()
+	// X-Forwarded-For принимается только от доверенных прокси: иначе клиент подменяет
+	// свой адрес в лимитах приёма и allowlist камер
+	if err := router.SetTrustedProxies(handler.config.HTTP.TrustedProxies); err != nil {
+		handler.log.Error().Err(err).Msg("invalid trusted proxies, forwarded headers are ignored")
+		_ = router.SetTrustedProxies(nil)
+	}
 	router.Use(gin.Recovery())
+	// Серверный спан на каждый запрос; входящий traceparent продолжает внешний трейс
+	router.Use(otelgin.Middleware(handler.config.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/metrics" && !strings.HasPrefix(r.URL.Path, "/health/")
+	})))
 	
-	// Логирование всех входящих запросов
-	router.Use(func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
-
-		c.Next()
-
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-
-		if raw != "" {
-			path = path + "?" + raw
-		}
+	// Access-лог и X-Request-ID; успешные запросы приёма событий сэмплируются (LOG_INGEST_SAMPLE_EVERY)
+	router.Use(middleware.RequestLogger(handler.log, middleware.RequestLogConfig{
+		SampledPaths: []string{"/api/v1/anpr/events", "/api/v1/anpr/hikvision"},
+		SampleEvery:  uint32(handler.config.Logging.IngestSampleEvery),
+		SkipPaths:    []string{"/metrics", "/health/live", "/health/ready"},
+	}))
 
-		// Логируем только важные запросы или ошибки
-		if statusCode >= 400 || path == "/api/v1/anpr/hikvision" {
-			fmt.Printf("[GIN] %v | %3d | %13v | %15s | %-7s %s\n",
-				time.Now().Format("2006/01/02 - 15:04:05"),
-				statusCode,
-				latency,
-				clientIP,
-				method,
-				path,
-			)
-		}
-	})
-	
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins: true,
 		AllowMethods:    []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:    []string{"*"},
-		ExposeHeaders:   []string{"Content-Type"},
+		ExposeHeaders:   []string{"Content-Type", "Content-Disposition", middleware.RequestIDHeader},
 		MaxAge:          12 * time.Hour,
 	}))
 
@@ -74,6 +64,8 @@ func NewRouter(handler *Handler, authMiddleware gin.HandlerFunc, env string, dat
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	handler.Register(router, authMiddleware)
 
 	return router
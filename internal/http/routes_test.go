@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/config"
+)
+
+func TestProtectedRoutesRequireAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{config: &config.Config{}}
+	h.Register(r, func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse("unauthorized"))
+	})
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/plates/7f1c1f4e-5d0a-4a53-9a7e-2b4f7f0c9d11"},
+		{http.MethodGet, "/api/v1/plates/merges"},
+		{http.MethodGet, "/api/v1/events/exports"},
+		{http.MethodGet, "/api/v1/cameras"},
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without token: status %d, want 401", route.method, route.path, w.Code)
+		}
+	}
+}
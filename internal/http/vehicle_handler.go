@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"anpr-service/internal/service"
+)
+
+func (h *Handler) listVehicles(c *gin.Context) {
+	limit, offset := parsePaging(c)
+	vehicles, err := h.vehicleService.FindVehicles(c.Request.Context(), service.VehicleQuery{
+		OrgID:        optionalQuery(c, "organization_id"),
+		ContractorID: optionalQuery(c, "contractor_id"),
+		Plate:        optionalQuery(c, "plate"),
+	}, limit, offset)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(vehicles))
+}
+
+// syncVehicles принимает полное состояние реестра ТС (или одной организации) и сверяет с ним зеркало
+func (h *Handler) syncVehicles(c *gin.Context) {
+	var req service.VehicleSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	result, err := h.vehicleService.Sync(c.Request.Context(), req)
+	if !req.DryRun {
+		params := map[string]interface{}{"vehicles": len(req.Vehicles), "force": req.Force}
+		if req.OrgID != nil {
+			params["organization_id"] = req.OrgID.String()
+		}
+		var counts map[string]interface{}
+		if result != nil {
+			counts = map[string]interface{}{
+				"added":   len(result.Added),
+				"updated": len(result.Updated),
+				"removed": len(result.Removed),
+				"skipped": len(result.Skipped),
+			}
+			if result.Whitelist != nil {
+				counts["whitelist_added"] = result.Whitelist.Added
+				counts["whitelist_removed"] = result.Whitelist.Removed
+			}
+		}
+		h.recordAudit(c, service.AuditActionSyncVehicles, "anpr_vehicles", params, counts, err)
+	}
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(result))
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+type ctxKey struct{}
+
+// WithContext кладёт логгер запроса в контекст
+func WithContext(ctx context.Context, log zerolog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &log)
+}
+
+// FromContext возвращает логгер запроса из контекста или fallback, если его нет
+func FromContext(ctx context.Context, fallback zerolog.Logger) *zerolog.Logger {
+	if ctx != nil {
+		if log, ok := ctx.Value(ctxKey{}).(*zerolog.Logger); ok {
+			return log
+		}
+	}
+	return &fallback
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestFromContextCarriesRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	reqLog := zerolog.New(&buf).With().Str("request_id", "req-1").Logger()
+	ctx := WithContext(context.Background(), reqLog)
+
+	// Сервис получает производный контекст, а не исходный
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var fallback bytes.Buffer
+	FromContext(ctx, zerolog.New(&fallback)).Info().Msg("from service")
+	if !strings.Contains(buf.String(), `"request_id":"req-1"`) || fallback.Len() != 0 {
+		t.Errorf("request log %q, fallback %q", buf.String(), fallback.String())
+	}
+}
+
+func TestFromContextFallback(t *testing.T) {
+	var fallback bytes.Buffer
+	FromContext(context.Background(), zerolog.New(&fallback)).Info().Msg("no request")
+	// FromContext допускает nil-контекст
+	FromContext(nil, zerolog.New(&fallback)).Info().Msg("nil context")
+	if strings.Count(fallback.String(), "\n") != 2 {
+		t.Errorf("fallback log %q, want two lines", fallback.String())
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"anpr-service/internal/gormhook"
+)
+
+const startKey = "metrics:start"
+
+// InstrumentGorm регистрирует callbacks gorm, замеряющие длительность запросов
+func InstrumentGorm(db *gorm.DB) error {
+	return gormhook.Register(db, "metrics",
+		func(_ string, tx *gorm.DB) {
+			tx.InstanceSet(startKey, time.Now())
+		},
+		func(operation string, tx *gorm.DB) {
+			value, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			if start, ok := value.(time.Time); ok {
+				DBDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+			}
+		},
+	)
+}
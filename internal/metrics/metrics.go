@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "anpr"
+
+// Причины отклонения событий (label reason)
+const (
+	RejectInvalidXML       = "invalid_xml"
+	RejectInvalidPayload   = "invalid_payload"
+	RejectMissingCamera    = "missing_camera"
+	RejectMissingEventTime = "missing_event_time"
+	RejectDBError          = "db_error"
+	// Защита публичного приёма: размер тела, лимиты запросов и allowlist адресов камеры
+	RejectBodyTooLarge      = "body_too_large"
+	RejectIPRateLimited     = "ip_rate_limited"
+	RejectCameraRateLimited = "camera_rate_limited"
+	RejectAddressNotAllowed = "address_not_allowed"
+	// Аутентификация камер: нет подтверждения источника при INGEST_AUTH_POLICY=reject или оно неверно
+	RejectUnauthenticated    = "unauthenticated"
+	RejectInvalidCredentials = "invalid_credentials"
+)
+
+// AuthNone - label method для событий, источник которых не проверялся (INGEST_AUTH_POLICY=off)
+const AuthNone = "none"
+
+// Источники событий (label source)
+const (
+	SourceJSON      = "json"
+	SourceHikvision = "hikvision"
+)
+
+// Причины, по которым время события взято с сервера (label reason)
+const (
+	TimeFallbackMissing = "missing"
+	TimeFallbackInvalid = "invalid"
+)
+
+// UnknownCamera подставляется, когда camera_id ещё не известен (например, XML не разобран)
+// или камера не зарегистрирована, см. CameraLabel
+const UnknownCamera = "unknown"
+
+var (
+	EventsAccepted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_accepted_total",
+		Help:      "ANPR events accepted and stored.",
+	}, []string{"camera_id"})
+
+	EventsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_rejected_total",
+		Help:      "ANPR events rejected, by reason.",
+	}, []string{"camera_id", "reason"})
+
+	IngestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ingest_duration_seconds",
+		Help:      "Time to receive, parse and store an incoming event.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+
+	DBDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation"})
+
+	ListHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_hits_total",
+		Help:      "Plates matched against lists, by list type.",
+	}, []string{"list_type"})
+
+	CandidateListHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "candidate_list_hits_total",
+		Help:      "List hits found only through an alternative plate candidate, by list type.",
+	}, []string{"list_type"})
+
+	Anomalies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "anomalies_total",
+		Help:      "Plate anomalies detected at ingest, by type.",
+	}, []string{"type"})
+
+	UnknownPlates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unknown_plates_total",
+		Help:      "Events of plates outside any list queued for operator review, by polygon.",
+	}, []string{"polygon_id"})
+
+	ReadOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "read_outcomes_total",
+		Help:      "Accepted events by read status (accepted, flagged, unverified, plateless) per camera.",
+	}, []string{"camera_id", "status"})
+
+	EventTimeFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_time_fallbacks_total",
+		Help:      "Events stored with the server receive time because the camera time was missing or unparseable.",
+	}, []string{"camera_id", "reason"})
+
+	ClockSkew = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "camera_clock_skew_seconds",
+		Help:      "Camera event time minus server receive time of the last event, per camera.",
+	}, []string{"camera_id"})
+
+	HikvisionNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hikvision_notifications_total",
+		Help:      "Hikvision notifications received, by event type and how they were handled (plate_read, plateless, heartbeat, other).",
+	}, []string{"camera_id", "event_type", "kind"})
+
+	IngestAuth = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_auth_total",
+		Help:      "Admitted ingest requests by how the source was authenticated (api_key, signature, mtls, untrusted, none).",
+	}, []string{"camera_id", "method"})
+
+	CameraLastSeen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "camera_last_seen_timestamp_seconds",
+		Help:      "Unix time of the last heartbeat or notification from a camera.",
+	}, []string{"camera_id"})
+
+	PartitionRowsMoved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "partition_default_rows_moved_total",
+		Help:      "Rows moved out of the DEFAULT partition into a newly created partition covering their event_time.",
+	})
+
+	PartitionCreateFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "partition_create_failures_total",
+		Help:      "Failed attempts to create a future partition of anpr_events.",
+	})
+
+	Confidence = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plate_confidence",
+		Help:      "Recognition confidence of accepted events per camera.",
+		Buckets:   []float64{.1, .2, .3, .4, .5, .6, .7, .8, .85, .9, .95, .98, 1},
+	}, []string{"camera_id"})
+)
+
+// RegisterDBStats публикует статистику пула соединений из sql.DB.Stats()
+func RegisterDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, "anpr"))
+}
+
+// ObserveIngest записывает длительность обработки входящего события
+func ObserveIngest(source string, start time.Time) {
+	IngestDuration.WithLabelValues(source).Observe(time.Since(start).Seconds())
+}
+
+// Reject увеличивает счётчик отклонённых событий
+func Reject(cameraID, reason string) {
+	EventsRejected.WithLabelValues(CameraLabel(cameraID), reason).Inc()
+}
+
+// knownCameras - camera_id зарегистрированных камер, см. SetKnownCameras
+var knownCameras atomic.Pointer[map[string]struct{}]
+
+// SetKnownCameras задаёт зарегистрированные камеры, id которых допустимы в label camera_id
+func SetKnownCameras(ids []string) {
+	known := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		known[id] = struct{}{}
+	}
+	knownCameras.Store(&known)
+}
+
+// CameraLabel возвращает значение label camera_id. camera_id приходит от непроверенного
+// отправителя, поэтому незарегистрированные камеры сводятся к UnknownCamera: иначе число
+// рядов метрик растёт без ограничения.
+func CameraLabel(cameraID string) string {
+	known := knownCameras.Load()
+	if cameraID == "" || known == nil {
+		return UnknownCamera
+	}
+	if _, ok := (*known)[cameraID]; !ok {
+		return UnknownCamera
+	}
+	return cameraID
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestCameraLabel(t *testing.T) {
+	knownCameras.Store(nil)
+	if got := CameraLabel("cam-1"); got != UnknownCamera {
+		t.Errorf("before registry load: CameraLabel = %q, want %q", got, UnknownCamera)
+	}
+
+	SetKnownCameras([]string{"cam-1", "cam-2"})
+	tests := []struct {
+		cameraID string
+		expected string
+	}{
+		{cameraID: "cam-1", expected: "cam-1"},
+		{cameraID: "cam-2", expected: "cam-2"},
+		{cameraID: "cam-3", expected: UnknownCamera},
+		{cameraID: "", expected: UnknownCamera},
+	}
+	for _, tt := range tests {
+		if got := CameraLabel(tt.cameraID); got != tt.expected {
+			t.Errorf("CameraLabel(%q) = %q, want %q", tt.cameraID, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+	l := New(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within burst rejected", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("10.0.0.1")
+	if ok {
+		t.Fatal("request over burst allowed")
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Errorf("retryAfter = %v, want 500ms", retryAfter)
+	}
+	if ok, _ := l.Allow("10.0.0.2"); !ok {
+		t.Error("other key shares the bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("10.0.0.1"); !ok {
+		t.Error("token not refilled after 500ms")
+	}
+	if ok, _ := l.Allow("10.0.0.1"); ok {
+		t.Error("refill exceeded rate")
+	}
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("refill above burst: request %d rejected", i+1)
+		}
+	}
+	if ok, _ := l.Allow("10.0.0.1"); ok {
+		t.Error("bucket refilled above burst")
+	}
+}
+
+func TestLimiterSweepsIdleKeys(t *testing.T) {
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+	l := New(1, 2)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(2 * sweepInterval)
+	l.Allow("b")
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("idle bucket was not removed")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("active bucket was removed")
+	}
+}
+
+func TestDisabledLimiter(t *testing.T) {
+	var l *Limiter = New(0, 10)
+	if l != nil {
+		t.Fatal("rate 0 must disable the limiter")
+	}
+	if ok, _ := l.Allow("any"); !ok {
+		t.Error("disabled limiter rejected a request")
+	}
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	AnomalyTypeAttributeMismatch = "attribute_mismatch"
+	AnomalyTypeImpossibleTravel  = "impossible_travel"
+
+	AnomalyStatusOpen      = "open"
+	AnomalyStatusConfirmed = "confirmed"
+	AnomalyStatusDismissed = "dismissed"
+)
+
+type AnomalyRepository struct {
+	db *gorm.DB
+}
+
+func NewAnomalyRepository(db *gorm.DB) *AnomalyRepository {
+	return &AnomalyRepository{db: db}
+}
+
+func (PlateProfileEntry) TableName() string {
+	return "anpr_plate_profiles"
+}
+
+func (Anomaly) TableName() string {
+	return "anpr_anomalies"
+}
+
+// PlateProfileEntry - сколько раз у номера наблюдалось значение атрибута ТС
+type PlateProfileEntry struct {
+	PlateID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Attribute string    `gorm:"primaryKey"`
+	Value     string    `gorm:"primaryKey"`
+	Count     int64     `gorm:"not null"`
+	LastSeen  time.Time `gorm:"not null"`
+}
+
+type Anomaly struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Type            string         `gorm:"not null"`
+	Status          string         `gorm:"not null"`
+	PlateID         uuid.UUID      `gorm:"type:uuid;not null"`
+	NormalizedPlate string         `gorm:"not null"`
+	EventID         uuid.UUID      `gorm:"type:uuid;not null"`
+	RelatedEventID  *uuid.UUID     `gorm:"type:uuid"`
+	CameraID        string         `gorm:"not null"`
+	EventTime       time.Time      `gorm:"not null"`
+	Score           float64        `gorm:"not null"`
+	Details         datatypes.JSON `gorm:"type:jsonb;not null"`
+	ResolvedBy      *uuid.UUID     `gorm:"type:uuid"`
+	ResolvedAt      *time.Time
+	ResolutionNote  *string
+	CreatedAt       time.Time
+}
+
+type AnomalyFilter struct {
+	Type            *string
+	Status          *string
+	NormalizedPlate *string
+	PlateID         *uuid.UUID
+	CameraID        *string
+	From            *time.Time
+	To              *time.Time
+}
+
+func (r *AnomalyRepository) GetProfile(ctx context.Context, plateID uuid.UUID) ([]PlateProfileEntry, error) {
+	var entries []PlateProfileEntry
+	err := r.db.WithContext(ctx).Where("plate_id = ?", plateID).Find(&entries).Error
+	return entries, err
+}
+
+// AddToProfile учитывает наблюдённые значения атрибутов в профиле номера
+func (r *AnomalyRepository) AddToProfile(ctx context.Context, plateID uuid.UUID, attributes map[string]string, seenAt time.Time) error {
+	for attribute, value := range attributes {
+		err := r.db.WithContext(ctx).Exec(`
+			INSERT INTO anpr_plate_profiles (plate_id, attribute, value, count, last_seen)
+			VALUES (?, ?, ?, 1, ?)
+			ON CONFLICT (plate_id, attribute, value) DO UPDATE
+			SET count = anpr_plate_profiles.count + 1,
+			    last_seen = GREATEST(anpr_plate_profiles.last_seen, EXCLUDED.last_seen)`,
+			plateID, attribute, value, seenAt).Error
+		if err != nil {
+			return fmt.Errorf("failed to update plate profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindPreviousEvent возвращает ближайшее предыдущее событие номера не раньше since.
+// Непроверенные прочтения и события неподтверждённого источника в пару не берутся.
+func (r *AnomalyRepository) FindPreviousEvent(ctx context.Context, plateID, excludeID uuid.UUID, before, since time.Time) (*ANPREvent, error) {
+	var event ANPREvent
+	err := r.db.WithContext(ctx).
+		Where("plate_id = ? AND id <> ? AND event_time <= ? AND event_time >= ?", plateID, excludeID, before, since).
+		Where("read_status <> 'unverified' AND auth IS DISTINCT FROM 'untrusted'").
+		Order("event_time DESC, id DESC").
+		First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// platelessPairColumns - признаки ТС, по которым сравниваются проезды без номера
+var platelessPairColumns = []string{"vehicle_type", "vehicle_color", "vehicle_brand", "vehicle_model"}
+
+// FindPlatelessPredecessor возвращает ближайший предыдущий проезд без номера не раньше since
+// на другой камере с теми же значениями признаков ТС (attributes - по именам колонок, в нижнем регистре)
+// и тем же направлением (direction в нижнем регистре; пусто - любое). Проезд, уже ставший парой
+// более позднего, не возвращается: у проезда ТС только одно продолжение.
+func (r *AnomalyRepository) FindPlatelessPredecessor(ctx context.Context, cameraID, direction string, attributes map[string]string, before, since time.Time) (*ANPREvent, error) {
+	query := r.db.WithContext(ctx).
+		Where("read_status = 'plateless' AND camera_id <> ? AND event_time <= ? AND event_time >= ?", cameraID, before, since).
+		Where("auth IS DISTINCT FROM 'untrusted'").
+		Where(`NOT EXISTS (SELECT 1 FROM anpr_events next
+			WHERE next.paired_event_id = anpr_events.id AND next.event_time >= anpr_events.event_time)`)
+	if direction != "" {
+		query = query.Where("LOWER(direction) = ?", direction)
+	}
+	for _, column := range platelessPairColumns {
+		if value, ok := attributes[column]; ok {
+			query = query.Where("LOWER("+column+") = ?", value)
+		}
+	}
+	var event ANPREvent
+	err := query.Order("event_time DESC, id DESC").First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *AnomalyRepository) CreateAnomaly(ctx context.Context, anomaly *Anomaly) error {
+	if anomaly.CreatedAt.IsZero() {
+		anomaly.CreatedAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(anomaly).Error; err != nil {
+		return fmt.Errorf("failed to create anomaly: %w", err)
+	}
+	return nil
+}
+
+func (r *AnomalyRepository) GetAnomaly(ctx context.Context, id uuid.UUID) (*Anomaly, error) {
+	var anomaly Anomaly
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&anomaly).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+func (r *AnomalyRepository) FindAnomalies(ctx context.Context, filter AnomalyFilter, limit, offset int) ([]Anomaly, error) {
+	query := r.db.WithContext(ctx).Model(&Anomaly{})
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.NormalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *filter.NormalizedPlate)
+	}
+	if filter.PlateID != nil {
+		query = query.Where("plate_id = ?", *filter.PlateID)
+	}
+	if filter.CameraID != nil {
+		query = query.Where("camera_id = ?", *filter.CameraID)
+	}
+	if filter.From != nil {
+		query = query.Where("event_time >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("event_time <= ?", *filter.To)
+	}
+	query = query.Order("event_time DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var anomalies []Anomaly
+	err := query.Find(&anomalies).Error
+	return anomalies, err
+}
+
+func (r *AnomalyRepository) ResolveAnomaly(ctx context.Context, id uuid.UUID, status string, resolvedBy *uuid.UUID, note *string) error {
+	return r.db.WithContext(ctx).
+		Model(&Anomaly{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"resolved_by":     resolvedBy,
+			"resolved_at":     time.Now(),
+			"resolution_note": note,
+		}).Error
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/tracing"
 )
 
 type ANPRRepository struct {
@@ -47,35 +50,68 @@ type Plate struct {
 }
 
 type ANPREvent struct {
-	ID                uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	PlateID           *uuid.UUID `gorm:"type:uuid"`
-	CameraID          string     `gorm:"not null"`
-	CameraUUID        *uuid.UUID `gorm:"type:uuid"`
-	PolygonID         *uuid.UUID `gorm:"type:uuid"`
-	CameraModel       *string
-	Direction         *string
-	Lane              *int
-	RawPlate          string `gorm:"not null"`
-	NormalizedPlate   string `gorm:"not null"`
-	Confidence        *float64
-	VehicleColor      *string
-	VehicleType       *string
-	VehicleBrand      *string
-	VehicleModel      *string
-	VehicleCountry    *string
-	VehiclePlateColor *string
-	VehicleSpeed      *float64
-	SnapshotURL       *string
-	EventTime         time.Time      `gorm:"not null"`
-	RawPayload        datatypes.JSON `gorm:"type:jsonb"`
+	ID                uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	PlateID           *uuid.UUID     `gorm:"type:uuid" json:"plate_id,omitempty"`
+	CameraID          string         `gorm:"not null" json:"camera_id"`
+	CameraUUID        *uuid.UUID     `gorm:"type:uuid" json:"camera_uuid,omitempty"`
+	PolygonID         *uuid.UUID     `gorm:"type:uuid" json:"polygon_id,omitempty"`
+	CameraModel       *string        `json:"camera_model,omitempty"`
+	Direction         *string        `json:"direction,omitempty"`
+	Lane              *int           `json:"lane,omitempty"`
+	RawPlate          string         `gorm:"not null" json:"raw_plate"`
+	NormalizedPlate   string         `gorm:"not null" json:"normalized_plate"`
+	Confidence        *float64       `json:"confidence,omitempty"`
+	VehicleColor      *string        `json:"vehicle_color,omitempty"`
+	VehicleType       *string        `json:"vehicle_type,omitempty"`
+	VehicleBrand      *string        `json:"vehicle_brand,omitempty"`
+	VehicleModel      *string        `json:"vehicle_model,omitempty"`
+	VehicleCountry    *string        `json:"vehicle_country,omitempty"`
+	VehiclePlateColor *string        `json:"vehicle_plate_color,omitempty"`
+	VehicleSpeed      *float64       `json:"vehicle_speed,omitempty"`
+	SnapshotURL       *string        `json:"snapshot_url,omitempty"`
+	EventTime         time.Time      `gorm:"not null" json:"event_time"`
+	RawPayload        datatypes.JSON `gorm:"type:jsonb" json:"raw_payload,omitempty"`
 	// Поля для данных о снеге
-	SnowEventTime         *time.Time `gorm:"type:timestamptz"`
-	SnowCameraID         *string
-	SnowVolumePercentage *float64
-	SnowVolumeConfidence *float64
-	SnowDirectionAI      *string
-	MatchedSnow          bool `gorm:"default:false"`
-	CreatedAt            time.Time
+	SnowEventTime        *time.Time `gorm:"type:timestamptz" json:"snow_event_time,omitempty"`
+	SnowCameraID         *string    `json:"snow_camera_id,omitempty"`
+	SnowVolumePercentage *float64   `json:"snow_volume_percentage,omitempty"`
+	SnowVolumeConfidence *float64   `json:"snow_volume_confidence,omitempty"`
+	SnowDirectionAI      *string    `json:"snow_direction_ai,omitempty"`
+	MatchedSnow          bool       `gorm:"default:false" json:"matched_snow"`
+	// Исправление номера оператором; raw_plate остаётся как было прочитано
+	OriginalPlateID  *uuid.UUID `gorm:"type:uuid" json:"original_plate_id,omitempty"`
+	CorrectedBy      *uuid.UUID `gorm:"type:uuid" json:"corrected_by,omitempty"`
+	CorrectedAt      *time.Time `json:"corrected_at,omitempty"`
+	CorrectionReason *string    `json:"correction_reason,omitempty"`
+	// ReadStatus - исход политики уверенности; verified_* - кто подтвердил прочтение
+	ReadStatus string     `gorm:"not null;default:accepted" json:"read_status"`
+	VerifiedBy *uuid.UUID `gorm:"type:uuid" json:"verified_by,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// Время получения сервером и расхождение часов камеры с ним
+	ReceivedAt       *time.Time `json:"received_at,omitempty"`
+	ClockSkewSeconds *float64   `json:"clock_skew_seconds,omitempty"`
+	TimeSource       string     `gorm:"not null;default:camera" json:"time_source"`
+	// PairedEventID - предыдущий проезд того же ТС без номера, найденный по признакам ТС
+	PairedEventID *uuid.UUID `gorm:"type:uuid" json:"paired_event_id,omitempty"`
+	// PlateCandidates - альтернативные прочтения номера ([]anpr.PlateCandidate)
+	PlateCandidates datatypes.JSON `gorm:"type:jsonb" json:"plate_candidates,omitempty"`
+	// Auth - чем подтверждён источник события: api_key, signature, mtls или untrusted
+	Auth      *string   `json:"auth,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (EventListHit) TableName() string {
+	return "anpr_event_list_hits"
+}
+
+// EventListHit - совпадение события со списком на момент приёма; CandidateRank > 0 - по альтернативному прочтению
+type EventListHit struct {
+	EventID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ListID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ListType      string    `gorm:"not null"`
+	MatchedPlate  string    `gorm:"not null"`
+	CandidateRank int       `gorm:"not null"`
+	CreatedAt     time.Time
 }
 
 type List struct {
@@ -87,15 +123,20 @@ type List struct {
 }
 
 type ListItem struct {
-	ListID    uuid.UUID `gorm:"type:uuid;primaryKey"`
-	PlateID   uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Note      *string
+	ListID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PlateID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Note    *string
+	// Source - vehicles, если номер добавлен синхронизацией с реестром ТС; nil - вручную
+	Source    *string
 	CreatedAt time.Time
 }
 
-func (r *ANPRRepository) GetOrCreatePlate(ctx context.Context, normalized, original string) (uuid.UUID, error) {
+func (r *ANPRRepository) GetOrCreatePlate(ctx context.Context, normalized, original string) (id uuid.UUID, err error) {
+	ctx, span := tracing.Start(ctx, "ANPRRepository.GetOrCreatePlate")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	var plate Plate
-	err := r.db.WithContext(ctx).Where("normalized = ?", normalized).First(&plate).Error
+	err = r.db.WithContext(ctx).Where("normalized = ?", normalized).First(&plate).Error
 	if err == nil {
 		return plate.ID, nil
 	}
@@ -115,16 +156,46 @@ func (r *ANPRRepository) GetOrCreatePlate(ctx context.Context, normalized, origi
 	return plate.ID, nil
 }
 
-func (r *ANPRRepository) CreateANPREvent(ctx context.Context, event *anpr.Event) error {
+func (r *ANPRRepository) CreateANPREvent(ctx context.Context, event *anpr.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "ANPRRepository.CreateANPREvent")
+	defer func() { tracing.EndSpan(span, err) }()
+
 	dbEvent := ANPREvent{
 		ID:              uuid.New(),
-		PlateID:         &event.PlateID,
 		CameraID:        event.CameraID,
+		PolygonID:       event.PolygonID,
 		RawPlate:        event.Plate,
 		NormalizedPlate: event.NormalizedPlate,
 		EventTime:       event.EventTime,
+		ReadStatus:      event.ReadStatus,
+		TimeSource:      event.TimeSource,
 		CreatedAt:       time.Now(),
 	}
+	// Проезд без номера не привязан к номеру
+	if event.PlateID != uuid.Nil {
+		dbEvent.PlateID = &event.PlateID
+	}
+	if dbEvent.ReadStatus == "" {
+		dbEvent.ReadStatus = anpr.ReadStatusAccepted
+	}
+	if dbEvent.TimeSource == "" {
+		dbEvent.TimeSource = anpr.TimeSourceCamera
+	}
+	if !event.ReceivedAt.IsZero() {
+		dbEvent.ReceivedAt = &event.ReceivedAt
+	}
+	dbEvent.ClockSkewSeconds = event.ClockSkew
+	dbEvent.PairedEventID = event.PairedEventID
+	if event.Auth != "" {
+		dbEvent.Auth = &event.Auth
+	}
+	if len(event.Candidates) > 0 {
+		candidates, err := json.Marshal(event.Candidates)
+		if err != nil {
+			return fmt.Errorf("marshal plate candidates: %w", err)
+		}
+		dbEvent.PlateCandidates = candidates
+	}
 
 	if event.CameraModel != "" {
 		dbEvent.CameraModel = &event.CameraModel
@@ -196,10 +267,11 @@ func (r *ANPRRepository) CreateANPREvent(ctx context.Context, event *anpr.Event)
 	return nil
 }
 
-func (r *ANPRRepository) FindListsForPlate(ctx context.Context, plateID uuid.UUID) ([]anpr.ListHit, error) {
-	var hits []anpr.ListHit
+func (r *ANPRRepository) FindListsForPlate(ctx context.Context, plateID uuid.UUID) (hits []anpr.ListHit, err error) {
+	ctx, span := tracing.Start(ctx, "ANPRRepository.FindListsForPlate")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	err := r.db.WithContext(ctx).
+	err = r.db.WithContext(ctx).
 		Table("anpr_list_items").
 		Select("anpr_lists.id as list_id, anpr_lists.name as list_name, anpr_lists.type as list_type").
 		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
@@ -213,6 +285,50 @@ func (r *ANPRRepository) FindListsForPlate(ctx context.Context, plateID uuid.UUI
 	return hits, nil
 }
 
+// FindListsForCandidates возвращает списки, в которых есть номера plates (нормализованные);
+// MatchedPlate - совпавший номер. Номера для кандидатов не создаются.
+func (r *ANPRRepository) FindListsForCandidates(ctx context.Context, plates []string) (hits []anpr.ListHit, err error) {
+	ctx, span := tracing.Start(ctx, "ANPRRepository.FindListsForCandidates")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if len(plates) == 0 {
+		return nil, nil
+	}
+	err = r.db.WithContext(ctx).
+		Table("anpr_plates").
+		Select("anpr_lists.id as list_id, anpr_lists.name as list_name, anpr_lists.type as list_type, anpr_plates.normalized as matched_plate").
+		Joins("JOIN anpr_list_items ON anpr_list_items.plate_id = anpr_plates.id").
+		Joins("JOIN anpr_lists ON anpr_list_items.list_id = anpr_lists.id").
+		Where("anpr_plates.normalized IN ?", plates).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// RecordListHits сохраняет совпадения события со списками на момент приёма; по ним политики
+// хранения отбирают события по list_type, даже если номер потом убран из списка
+func (r *ANPRRepository) RecordListHits(ctx context.Context, eventID uuid.UUID, hits []anpr.ListHit) (err error) {
+	ctx, span := tracing.Start(ctx, "ANPRRepository.RecordListHits")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if len(hits) == 0 {
+		return nil
+	}
+	rows := make([]EventListHit, 0, len(hits))
+	for _, hit := range hits {
+		rows = append(rows, EventListHit{
+			EventID:       eventID,
+			ListID:        hit.ListID,
+			ListType:      hit.ListType,
+			MatchedPlate:  hit.MatchedPlate,
+			CandidateRank: hit.CandidateRank,
+		})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
 func (r *ANPRRepository) FindPlatesByNormalized(ctx context.Context, normalized string) ([]Plate, error) {
 	var plates []Plate
 	err := r.db.WithContext(ctx).
@@ -221,51 +337,271 @@ func (r *ANPRRepository) FindPlatesByNormalized(ctx context.Context, normalized
 	return plates, err
 }
 
-func (r *ANPRRepository) FindEvents(ctx context.Context, normalizedPlate *string, from, to *time.Time, limit, offset int) ([]ANPREvent, error) {
-	query := r.db.WithContext(ctx).Model(&ANPREvent{})
+// PlateWithLastEvent - номер и время его последнего события
+type PlateWithLastEvent struct {
+	Plate
+	LastEventTime *time.Time
+}
 
-	if normalizedPlate != nil {
-		query = query.Where("normalized_plate = ?", *normalizedPlate)
+// FindPlatesWithLastEvent ищет номера вместе со временем последнего события одним запросом
+func (r *ANPRRepository) FindPlatesWithLastEvent(ctx context.Context, normalized string) ([]PlateWithLastEvent, error) {
+	var plates []PlateWithLastEvent
+	err := r.db.WithContext(ctx).
+		Table("anpr_plates AS p").
+		Select("p.*, (SELECT MAX(e.event_time) FROM anpr_events e WHERE e.plate_id = p.id) AS last_event_time").
+		Where("p.normalized = ?", normalized).
+		Scan(&plates).Error
+	return plates, err
+}
+
+func (r *ANPRRepository) GetPlate(ctx context.Context, id uuid.UUID) (*Plate, error) {
+	var plate Plate
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&plate).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
 	}
-	if from != nil {
-		query = query.Where("event_time >= ?", *from)
+	if err != nil {
+		return nil, err
 	}
-	if to != nil {
-		query = query.Where("event_time <= ?", *to)
+	return &plate, nil
+}
+
+// PlateListMembership - членство номера в списке с примечанием
+type PlateListMembership struct {
+	ListID   uuid.UUID
+	ListName string
+	ListType string
+	Note     *string
+	AddedAt  time.Time
+}
+
+func (r *ANPRRepository) FindPlateMemberships(ctx context.Context, plateID uuid.UUID) ([]PlateListMembership, error) {
+	var memberships []PlateListMembership
+	err := r.db.WithContext(ctx).
+		Table("anpr_list_items AS li").
+		Select("l.id AS list_id, l.name AS list_name, l.type AS list_type, li.note, li.created_at AS added_at").
+		Joins("JOIN anpr_lists l ON l.id = li.list_id").
+		Where("li.plate_id = ?", plateID).
+		Order("l.type, l.name").
+		Scan(&memberships).Error
+	return memberships, err
+}
+
+// PlateEventSummary - сводка по всем событиям номера и по событиям окна
+type PlateEventSummary struct {
+	FirstSeen    *time.Time
+	LastSeen     *time.Time
+	TotalEvents  int64
+	WindowEvents int64
+}
+
+func (r *ANPRRepository) GetPlateEventSummary(ctx context.Context, plateID uuid.UUID, from, to time.Time) (*PlateEventSummary, error) {
+	var summary PlateEventSummary
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT MIN(event_time) AS first_seen,
+		       MAX(event_time) AS last_seen,
+		       COUNT(*) AS total_events,
+		       COUNT(*) FILTER (WHERE event_time >= ? AND event_time < ?) AS window_events
+		FROM anpr_events
+		WHERE plate_id = ?`, from, to, plateID).Scan(&summary).Error
+	if err != nil {
+		return nil, err
 	}
+	return &summary, nil
+}
+
+// PlatePassageCount - число проездов по камере (CameraID заполнен) или по дню UTC (Day заполнен)
+type PlatePassageCount struct {
+	CameraID *string
+	Day      *time.Time
+	Count    int64
+	LastSeen time.Time
+}
+
+// CountPlatePassages считает проезды номера за окно по камерам и по дням одним запросом (GROUPING SETS)
+func (r *ANPRRepository) CountPlatePassages(ctx context.Context, plateID uuid.UUID, from, to time.Time) ([]PlatePassageCount, error) {
+	var counts []PlatePassageCount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT camera_id,
+		       date_trunc('day', event_time AT TIME ZONE 'UTC') AS day,
+		       COUNT(*) AS count,
+		       MAX(event_time) AS last_seen
+		FROM anpr_events
+		WHERE plate_id = ? AND event_time >= ? AND event_time < ?
+		GROUP BY GROUPING SETS ((camera_id), (date_trunc('day', event_time AT TIME ZONE 'UTC')))
+		ORDER BY day NULLS FIRST, count DESC`, plateID, from, to).Scan(&counts).Error
+	return counts, err
+}
 
-	query = query.Order("event_time DESC")
+// PlateAttributeCount - сколько раз значение атрибута ТС встречалось в событиях номера
+type PlateAttributeCount struct {
+	Attribute string
+	Value     string
+	Count     int64
+}
+
+// TopPlateAttributes возвращает до perAttribute самых частых значений каждого атрибута ТС за окно
+func (r *ANPRRepository) TopPlateAttributes(ctx context.Context, plateID uuid.UUID, from, to time.Time, perAttribute int) ([]PlateAttributeCount, error) {
+	var counts []PlateAttributeCount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT attribute, value, count
+		FROM (
+			SELECT v.attribute, v.value, COUNT(*) AS count,
+			       ROW_NUMBER() OVER (PARTITION BY v.attribute ORDER BY COUNT(*) DESC, v.value) AS rank
+			FROM anpr_events e
+			CROSS JOIN LATERAL (VALUES
+				('vehicle_type', e.vehicle_type),
+				('vehicle_color', e.vehicle_color),
+				('vehicle_brand', e.vehicle_brand),
+				('vehicle_model', e.vehicle_model),
+				('vehicle_country', e.vehicle_country),
+				('vehicle_plate_color', e.vehicle_plate_color)
+			) AS v(attribute, value)
+			WHERE e.plate_id = ? AND e.event_time >= ? AND e.event_time < ?
+			  AND v.value IS NOT NULL AND v.value <> ''
+			GROUP BY v.attribute, v.value
+		) ranked
+		WHERE rank <= ?
+		ORDER BY attribute, count DESC, value`, plateID, from, to, perAttribute).Scan(&counts).Error
+	return counts, err
+}
+
+func (r *ANPRRepository) FindRecentPlateEvents(ctx context.Context, plateID uuid.UUID, limit int) ([]ANPREvent, error) {
+	var events []ANPREvent
+	err := r.db.WithContext(ctx).
+		Where("plate_id = ?", plateID).
+		Order("event_time DESC, id DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// EventAuthNone - значение фильтра Auth для событий, источник которых не проверялся
+const EventAuthNone = "none"
+
+// EventFilter - критерии поиска событий; nil-поля не фильтруют
+type EventFilter struct {
+	NormalizedPlate *string
+	PlatePrefix     *string
+	From            *time.Time
+	To              *time.Time
+	CameraID        *string
+	PolygonID       *uuid.UUID
+	Direction       *string
+	Lane            *int
+	VehicleType     *string
+	VehicleColor    *string
+	MinConfidence   *float64
+	MaxConfidence   *float64
+	// ListType - WHITELIST, BLACKLIST или NONE (номер не состоит ни в одном списке)
+	ListType    *string
+	MatchedSnow *bool
+	ReadStatus  *string
+	// Auth - api_key, signature, mtls, untrusted или none (источник не проверялся)
+	Auth *string
+}
+
+func (f EventFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.NormalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *f.NormalizedPlate)
+	}
+	if f.PlatePrefix != nil {
+		query = query.Where("normalized_plate LIKE ?", escapeLike(*f.PlatePrefix)+"%")
+	}
+	if f.From != nil {
+		query = query.Where("event_time >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("event_time <= ?", *f.To)
+	}
+	if f.CameraID != nil {
+		query = query.Where("camera_id = ?", *f.CameraID)
+	}
+	if f.PolygonID != nil {
+		query = query.Where("polygon_id = ?", *f.PolygonID)
+	}
+	if f.Direction != nil {
+		query = query.Where("direction = ?", *f.Direction)
+	}
+	if f.Lane != nil {
+		query = query.Where("lane = ?", *f.Lane)
+	}
+	if f.VehicleType != nil {
+		query = query.Where("vehicle_type = ?", *f.VehicleType)
+	}
+	if f.VehicleColor != nil {
+		query = query.Where("vehicle_color = ?", *f.VehicleColor)
+	}
+	if f.MinConfidence != nil {
+		query = query.Where("confidence >= ?", *f.MinConfidence)
+	}
+	if f.MaxConfidence != nil {
+		query = query.Where("confidence <= ?", *f.MaxConfidence)
+	}
+	if f.ListType != nil {
+		if *f.ListType == RetentionListTypeNone {
+			query = query.Where(`NOT EXISTS (SELECT 1 FROM anpr_list_items li
+				WHERE li.plate_id = anpr_events.plate_id)`)
+		} else {
+			query = query.Where(`EXISTS (SELECT 1 FROM anpr_list_items li
+				JOIN anpr_lists l ON l.id = li.list_id
+				WHERE li.plate_id = anpr_events.plate_id AND l.type = ?)`, *f.ListType)
+		}
+	}
+	if f.MatchedSnow != nil {
+		query = query.Where("matched_snow = ?", *f.MatchedSnow)
+	}
+	if f.ReadStatus != nil {
+		query = query.Where("read_status = ?", *f.ReadStatus)
+	}
+	if f.Auth != nil {
+		if *f.Auth == EventAuthNone {
+			query = query.Where("auth IS NULL")
+		} else {
+			query = query.Where("auth = ?", *f.Auth)
+		}
+	}
+	return query
+}
 
-	if limit > 0 {
-		query = query.Limit(limit)
-		if limit > 100 {
-			query = query.Limit(100)
+// FindEventsPage возвращает страницу событий после after в порядке (event_time, id),
+// по убыванию или по возрастанию. Keyset-пагинация не пропускает и не дублирует строки
+// при поступлении новых событий.
+func (r *ANPRRepository) FindEventsPage(ctx context.Context, filter EventFilter, after *EventCursor, ascending bool, limit, offset int) ([]ANPREvent, error) {
+	query := filter.apply(r.db.WithContext(ctx).Model(&ANPREvent{}))
+
+	order := "event_time DESC, id DESC"
+	if ascending {
+		order = "event_time ASC, id ASC"
+	}
+	if after != nil {
+		if ascending {
+			query = query.Where("(event_time, id) > (?, ?)", after.EventTime, after.ID)
+		} else {
+			query = query.Where("(event_time, id) < (?, ?)", after.EventTime, after.ID)
 		}
 	}
+
 	if offset > 0 {
+		// Устаревший режим для клиентов, ещё не перешедших на курсоры
 		query = query.Offset(offset)
 	}
 
 	var events []ANPREvent
-	err := query.Find(&events).Error
+	err := query.Order(order).Limit(limit).Find(&events).Error
 	return events, err
 }
 
-func (r *ANPRRepository) GetLastEventTimeForPlate(ctx context.Context, plateID uuid.UUID) (*time.Time, error) {
-	var event ANPREvent
-	err := r.db.WithContext(ctx).
-		Where("plate_id = ?", plateID).
-		Order("event_time DESC").
-		First(&event).Error
-
-	if err == gorm.ErrRecordNotFound {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
+// CountEvents считает события, подходящие под фильтр
+func (r *ANPRRepository) CountEvents(ctx context.Context, filter EventFilter) (int64, error) {
+	var total int64
+	err := filter.apply(r.db.WithContext(ctx).Model(&ANPREvent{})).Count(&total).Error
+	return total, err
+}
 
-	return &event.EventTime, nil
+// escapeLike экранирует спецсимволы LIKE в пользовательском вводе
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
 }
 
 // SyncVehicleToWhitelist синхронизирует номер из vehicles в whitelist
@@ -281,6 +617,38 @@ func (r *ANPRRepository) SyncVehicleToWhitelist(ctx context.Context, plateNumber
 	return plateID, nil
 }
 
+// NextEventsCreatedBefore возвращает очередную пачку событий, созданных до before (nil - все), после курсора
+func (r *ANPRRepository) NextEventsCreatedBefore(ctx context.Context, before *time.Time, after *EventCursor, limit int) ([]ANPREvent, error) {
+	query := r.db.WithContext(ctx)
+	if before != nil {
+		query = query.Where("created_at < ?", *before)
+	}
+	if after != nil {
+		query = query.Where("(event_time, id) > (?, ?)", after.EventTime, after.ID)
+	}
+	var events []ANPREvent
+	err := query.Order("event_time, id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// DeleteEventsByID удаляет события по списку id пачками
+func (r *ANPRRepository) DeleteEventsByID(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return deleteEventsByID(r.db.WithContext(ctx), ids)
+}
+
+func deleteEventsByID(db *gorm.DB, ids []uuid.UUID) (int64, error) {
+	var total int64
+	for start := 0; start < len(ids); start += retentionBatchSize {
+		end := min(start+retentionBatchSize, len(ids))
+		result := db.Where("id IN ?", ids[start:end]).Delete(&ANPREvent{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
+
 // DeleteOldEvents удаляет события старше указанного количества дней
 func (r *ANPRRepository) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
 	cutoffTime := time.Now().AddDate(0, 0, -days)
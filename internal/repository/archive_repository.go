@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArchiveRepository struct {
+	db *gorm.DB
+}
+
+func NewArchiveRepository(db *gorm.DB) *ArchiveRepository {
+	return &ArchiveRepository{db: db}
+}
+
+func (Archive) TableName() string {
+	return "anpr_archives"
+}
+
+func (RestoredEvent) TableName() string {
+	return "anpr_events_restored"
+}
+
+type Archive struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Reason       string    `gorm:"not null"`
+	Format       string    `gorm:"not null"`
+	Store        string    `gorm:"not null"`
+	DataKey      string    `gorm:"not null"`
+	ManifestKey  string    `gorm:"not null"`
+	RangeFrom    *time.Time
+	RangeTo      *time.Time
+	MinEventTime *time.Time
+	MaxEventTime *time.Time
+	EventCount   int64  `gorm:"not null"`
+	SizeBytes    int64  `gorm:"not null"`
+	SHA256       string `gorm:"column:sha256;not null"`
+	CreatedAt    time.Time
+	RestoredAt   *time.Time
+}
+
+// RestoredEvent - событие, восстановленное из архива для расследований.
+// Таблица только для чтения: данные хранятся целиком в JSONB, чтобы не зависеть от схемы anpr_events.
+type RestoredEvent struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	ArchiveID       uuid.UUID      `gorm:"type:uuid;not null"`
+	PlateID         *uuid.UUID     `gorm:"type:uuid"`
+	CameraID        string         `gorm:"not null"`
+	NormalizedPlate string         `gorm:"not null"`
+	EventTime       time.Time      `gorm:"not null"`
+	Data            datatypes.JSON `gorm:"type:jsonb;not null"`
+	RestoredAt      time.Time
+}
+
+// EventCursor - позиция keyset-пагинации по (event_time, id)
+type EventCursor struct {
+	EventTime time.Time
+	ID        uuid.UUID
+}
+
+func (r *ArchiveRepository) CreateArchive(ctx context.Context, archive *Archive) error {
+	if archive.CreatedAt.IsZero() {
+		archive.CreatedAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(archive).Error; err != nil {
+		return fmt.Errorf("failed to create archive record: %w", err)
+	}
+	return nil
+}
+
+func (r *ArchiveRepository) GetArchive(ctx context.Context, id uuid.UUID) (*Archive, error) {
+	var archive Archive
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&archive).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// FindArchives возвращает архивы, диапазон событий которых пересекается с [from, to]
+func (r *ArchiveRepository) FindArchives(ctx context.Context, from, to *time.Time, limit, offset int) ([]Archive, error) {
+	query := r.db.WithContext(ctx).Model(&Archive{})
+	if from != nil {
+		query = query.Where("max_event_time >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("min_event_time <= ?", *to)
+	}
+	query = query.Order("min_event_time DESC NULLS LAST, created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var archives []Archive
+	err := query.Find(&archives).Error
+	return archives, err
+}
+
+func (r *ArchiveRepository) MarkRestored(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&Archive{}).
+		Where("id = ?", id).
+		Update("restored_at", at).Error
+}
+
+// NextEventsInRange возвращает очередную пачку событий из [from, to) после курсора
+func (r *ArchiveRepository) NextEventsInRange(ctx context.Context, from, to time.Time, after *EventCursor, limit int) ([]ANPREvent, error) {
+	query := r.db.WithContext(ctx).
+		Where("event_time >= ? AND event_time < ?", from, to)
+	if after != nil {
+		query = query.Where("(event_time, id) > (?, ?)", after.EventTime, after.ID)
+	}
+	var events []ANPREvent
+	err := query.Order("event_time, id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *ArchiveRepository) InsertRestoredEvents(ctx context.Context, events []RestoredEvent) (int64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&events)
+	return result.RowsAffected, result.Error
+}
+
+func (r *ArchiveRepository) FindRestoredEvents(ctx context.Context, archiveID *uuid.UUID, normalizedPlate *string, from, to *time.Time, limit, offset int) ([]RestoredEvent, error) {
+	query := r.db.WithContext(ctx).Model(&RestoredEvent{})
+	if archiveID != nil {
+		query = query.Where("archive_id = ?", *archiveID)
+	}
+	if normalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *normalizedPlate)
+	}
+	if from != nil {
+		query = query.Where("event_time >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("event_time <= ?", *to)
+	}
+	query = query.Order("event_time DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var events []RestoredEvent
+	err := query.Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AuditRepository хранит журнал административных действий.
+// Журнал append-only: методов изменения и удаления записей нет намеренно.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (AuditLog) TableName() string {
+	return "anpr_audit_log"
+}
+
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ActorID    *uuid.UUID `gorm:"type:uuid"`
+	ActorOrgID *uuid.UUID `gorm:"type:uuid"`
+	ActorRole  *string
+	Action     string `gorm:"not null"`
+	Target     *string
+	Params     datatypes.JSON `gorm:"type:jsonb"`
+	Result     datatypes.JSON `gorm:"type:jsonb"`
+	Status     string         `gorm:"not null"`
+	Error      *string
+	ClientIP   *string
+	CreatedAt  time.Time
+}
+
+type AuditLogFilter struct {
+	ActorID *uuid.UUID
+	OrgID   *uuid.UUID
+	Action  *string
+	Target  *string
+	Status  *string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+func (r *AuditRepository) CreateAuditLog(ctx context.Context, entry *AuditLog) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) FindAuditLogs(ctx context.Context, filter AuditLogFilter) ([]AuditLog, error) {
+	query := r.db.WithContext(ctx).Model(&AuditLog{})
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.OrgID != nil {
+		query = query.Where("actor_org_id = ?", *filter.OrgID)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.Target != nil {
+		query = query.Where("target = ?", *filter.Target)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	query = query.Order("created_at DESC")
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var entries []AuditLog
+	err := query.Find(&entries).Error
+	return entries, err
+}
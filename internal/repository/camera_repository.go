@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CameraRepository struct {
+	db *gorm.DB
+}
+
+func NewCameraRepository(db *gorm.DB) *CameraRepository {
+	return &CameraRepository{db: db}
+}
+
+func (Camera) TableName() string {
+	return "anpr_cameras"
+}
+
+// Camera - зарегистрированная камера; camera_id совпадает с camera_id событий
+type Camera struct {
+	CameraID  string `gorm:"primaryKey"`
+	Name      *string
+	Latitude  *float64
+	Longitude *float64
+	// PolygonID - полигон, к которому относятся события камеры
+	PolygonID *uuid.UUID `gorm:"type:uuid"`
+	// Пороги уверенности (0–1): ниже ConfidenceReviewBelow - на проверку,
+	// ниже ConfidenceUnverifiedBelow - без сверки со списками; nil - по умолчанию
+	ConfidenceReviewBelow     *float64
+	ConfidenceUnverifiedBelow *float64
+	// Timezone - часовой пояс (IANA) времени без смещения; nil - по умолчанию
+	Timezone *string
+	// AllowedIPs - JSON-массив адресов и подсетей, с которых принимаются события камеры; NULL - с любых
+	AllowedIPs datatypes.JSON `gorm:"type:jsonb"`
+	// APIKeyHash - SHA-256 ключа API камеры (hex); задаётся отдельно от регистрации камеры
+	APIKeyHash *string `gorm:"column:api_key_hash"`
+	// ClientCertCN - CN клиентского сертификата камеры; nil - CN должен совпадать с camera_id
+	ClientCertCN *string `gorm:"column:client_cert_cn"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ConfidenceStats - распределение уверенности прочтений камеры за период
+type ConfidenceStats struct {
+	CameraID       string
+	Events         int64
+	WithConfidence int64
+	AvgConfidence  *float64
+	P10            *float64
+	P50            *float64
+	P90            *float64
+	Flagged        int64
+	Unverified     int64
+}
+
+// ClockStats - расхождение часов камеры с временем получения за период
+type ClockStats struct {
+	CameraID string
+	Events   int64
+	// ServerTimeEvents - события, время которых взято с сервера
+	ServerTimeEvents int64
+	MedianSkew       *float64
+	MinSkew          *float64
+	MaxSkew          *float64
+	LastSkew         *float64
+	LastReceivedAt   *time.Time
+}
+
+// DailyConfidence - уверенность прочтений камеры за сутки
+type DailyConfidence struct {
+	Day           time.Time
+	Events        int64
+	AvgConfidence *float64
+	P50           *float64
+	Flagged       int64
+	Unverified    int64
+}
+
+func (r *CameraRepository) ListCameras(ctx context.Context) ([]Camera, error) {
+	var cameras []Camera
+	err := r.db.WithContext(ctx).Order("camera_id").Find(&cameras).Error
+	return cameras, err
+}
+
+func (r *CameraRepository) GetCamera(ctx context.Context, cameraID string) (*Camera, error) {
+	var camera Camera
+	err := r.db.WithContext(ctx).Where("camera_id = ?", cameraID).First(&camera).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &camera, nil
+}
+
+// UpsertCamera создаёт камеру или обновляет все её поля, кроме created_at
+func (r *CameraRepository) UpsertCamera(ctx context.Context, camera *Camera) error {
+	now := time.Now()
+	if camera.CreatedAt.IsZero() {
+		camera.CreatedAt = now
+	}
+	camera.UpdatedAt = now
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "camera_id"}},
+			DoUpdates: clause.AssignmentColumns(cameraUpdateColumns),
+		}).
+		Create(camera).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert camera: %w", err)
+	}
+	return nil
+}
+
+// cameraUpdateColumns - колонки, перезаписываемые при повторной регистрации камеры
+var cameraUpdateColumns = []string{
+	"name", "latitude", "longitude", "polygon_id",
+	"confidence_review_below", "confidence_unverified_below", "timezone", "allowed_ips", "client_cert_cn", "updated_at",
+}
+
+// SetAPIKeyHash заменяет хэш ключа API камеры; hash nil - отзывает ключ. false - камера не найдена
+func (r *CameraRepository) SetAPIKeyHash(ctx context.Context, cameraID string, hash *string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Camera{}).
+		Where("camera_id = ?", cameraID).
+		Updates(map[string]interface{}{"api_key_hash": hash, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to set camera api key: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *CameraRepository) DeleteCamera(ctx context.Context, cameraID string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("camera_id = ?", cameraID).Delete(&Camera{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ConfidenceStats считает распределение уверенности по камерам за [from, to); cameraID nil - все камеры
+func (r *CameraRepository) ConfidenceStats(ctx context.Context, cameraID *string, from, to time.Time) ([]ConfidenceStats, error) {
+	query := r.db.WithContext(ctx).
+		Table("anpr_events").
+		Select(`camera_id,
+			COUNT(*) AS events,
+			COUNT(confidence) AS with_confidence,
+			AVG(confidence) AS avg_confidence,
+			percentile_cont(0.1) WITHIN GROUP (ORDER BY confidence) AS p10,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY confidence) AS p50,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY confidence) AS p90,
+			COUNT(*) FILTER (WHERE read_status = 'flagged') AS flagged,
+			COUNT(*) FILTER (WHERE read_status = 'unverified') AS unverified`).
+		Where("event_time >= ? AND event_time < ?", from, to)
+	if cameraID != nil {
+		query = query.Where("camera_id = ?", *cameraID)
+	}
+	var stats []ConfidenceStats
+	err := query.Group("camera_id").Order("camera_id").Scan(&stats).Error
+	return stats, err
+}
+
+// DailyConfidence считает уверенность прочтений камеры по суткам за [from, to)
+func (r *CameraRepository) DailyConfidence(ctx context.Context, cameraID string, from, to time.Time) ([]DailyConfidence, error) {
+	var days []DailyConfidence
+	err := r.db.WithContext(ctx).
+		Table("anpr_events").
+		Select(`date_trunc('day', event_time) AS day,
+			COUNT(*) AS events,
+			AVG(confidence) AS avg_confidence,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY confidence) AS p50,
+			COUNT(*) FILTER (WHERE read_status = 'flagged') AS flagged,
+			COUNT(*) FILTER (WHERE read_status = 'unverified') AS unverified`).
+		Where("camera_id = ? AND event_time >= ? AND event_time < ?", cameraID, from, to).
+		Group("day").
+		Order("day").
+		Scan(&days).Error
+	return days, err
+}
+
+// ClockStats считает расхождение часов камер с временем получения за [from, to)
+func (r *CameraRepository) ClockStats(ctx context.Context, from, to time.Time) ([]ClockStats, error) {
+	var stats []ClockStats
+	err := r.db.WithContext(ctx).
+		Table("anpr_events").
+		Select(`camera_id,
+			COUNT(*) AS events,
+			COUNT(*) FILTER (WHERE time_source = 'server') AS server_time_events,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY clock_skew_seconds) AS median_skew,
+			MIN(clock_skew_seconds) AS min_skew,
+			MAX(clock_skew_seconds) AS max_skew,
+			(array_agg(clock_skew_seconds ORDER BY received_at DESC)
+				FILTER (WHERE clock_skew_seconds IS NOT NULL))[1] AS last_skew,
+			MAX(received_at) AS last_received_at`).
+		Where("event_time >= ? AND event_time < ? AND received_at IS NOT NULL", from, to).
+		Group("camera_id").
+		Order("camera_id").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (CameraLiveness) TableName() string {
+	return "anpr_camera_liveness"
+}
+
+// CameraLiveness - последняя связь с камерой, в том числе незарегистрированной
+type CameraLiveness struct {
+	CameraID        string `gorm:"primaryKey"`
+	LastHeartbeatAt *time.Time
+	LastEventAt     *time.Time
+	LastAddress     *string
+	UpdatedAt       time.Time
+}
+
+// RecordHeartbeat отмечает heartbeat камеры
+func (r *CameraRepository) RecordHeartbeat(ctx context.Context, cameraID, address string, at time.Time) error {
+	return r.recordContact(ctx, CameraLiveness{CameraID: cameraID, LastHeartbeatAt: &at, LastAddress: &address, UpdatedAt: time.Now()},
+		[]string{"last_heartbeat_at", "last_address", "updated_at"})
+}
+
+// RecordEventContact отмечает любое другое уведомление камеры
+func (r *CameraRepository) RecordEventContact(ctx context.Context, cameraID, address string, at time.Time) error {
+	return r.recordContact(ctx, CameraLiveness{CameraID: cameraID, LastEventAt: &at, LastAddress: &address, UpdatedAt: time.Now()},
+		[]string{"last_event_at", "last_address", "updated_at"})
+}
+
+func (r *CameraRepository) recordContact(ctx context.Context, liveness CameraLiveness, columns []string) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "camera_id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).
+		Create(&liveness).Error
+	if err != nil {
+		return fmt.Errorf("failed to record camera contact: %w", err)
+	}
+	return nil
+}
+
+// ListLiveness возвращает последнюю связь со всеми камерами, от которых приходили уведомления
+func (r *CameraRepository) ListLiveness(ctx context.Context) ([]CameraLiveness, error) {
+	var liveness []CameraLiveness
+	err := r.db.WithContext(ctx).Order("camera_id").Find(&liveness).Error
+	return liveness, err
+}
@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CorrectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCorrectionRepository(db *gorm.DB) *CorrectionRepository {
+	return &CorrectionRepository{db: db}
+}
+
+func (PlateMerge) TableName() string {
+	return "anpr_plate_merges"
+}
+
+// PlateMerge - слияние ошибочного номера с настоящим
+type PlateMerge struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	SourcePlateID    uuid.UUID  `gorm:"type:uuid;not null"`
+	SourcePlate      string     `gorm:"not null"`
+	TargetPlateID    uuid.UUID  `gorm:"type:uuid;not null"`
+	TargetPlate      string     `gorm:"not null"`
+	EventsMoved      int64      `gorm:"not null"`
+	MembershipsMoved int64      `gorm:"not null"`
+	MergedBy         *uuid.UUID `gorm:"type:uuid"`
+	Reason           string     `gorm:"not null"`
+	CreatedAt        time.Time
+}
+
+func (PlateRecompute) TableName() string {
+	return "anpr_plate_recomputes"
+}
+
+// Причины пересчёта производных данных номера
+const (
+	RecomputeReasonEventCorrection = "event_correction"
+	RecomputeReasonPlateAttach     = "plate_attach"
+	RecomputeReasonPlateMerge      = "plate_merge"
+)
+
+// PlateRecompute - сигнал потребителям (поездки, отчёты), что события номера изменились и
+// производные данные нужно пересчитать. ProcessedAt - потребитель подтвердил пересчёт.
+type PlateRecompute struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	PlateID         uuid.UUID `gorm:"type:uuid;not null"`
+	NormalizedPlate string    `gorm:"not null"`
+	Reason          string    `gorm:"not null"`
+	CreatedAt       time.Time
+	ProcessedAt     *time.Time
+}
+
+// EventCorrection - исправление номера одного события
+type EventCorrection struct {
+	EventID uuid.UUID
+	Target  Plate
+	// ReadPlate - нормализованная строка, прочитанная камерой (из raw_plate)
+	ReadPlate   string
+	CorrectedBy *uuid.UUID
+	Reason      string
+}
+
+func (r *CorrectionRepository) GetEvent(ctx context.Context, id uuid.UUID) (*ANPREvent, error) {
+	var event ANPREvent
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// CorrectEvent перепривязывает событие к настоящему номеру, сохраняя raw_plate и номер
+// до первого исправления, и пересчитывает профили обоих номеров. Исправленное прочтение
+// считается подтверждённым оператором.
+func (r *CorrectionRepository) CorrectEvent(ctx context.Context, correction EventCorrection) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event ANPREvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", correction.EventID).
+			First(&event).Error
+		if err != nil {
+			return fmt.Errorf("lock event: %w", err)
+		}
+
+		now := time.Now()
+		if err := correctEvents(tx, []ANPREvent{event}, correction, now); err != nil {
+			return err
+		}
+		return recordCorrection(tx, correction.ReadPlate, correction.Target.ID, now)
+	})
+}
+
+// correctEvents перепривязывает заблокированные события к correction.Target и пересчитывает
+// профили целевого номера и прежних номеров событий
+func correctEvents(tx *gorm.DB, events []ANPREvent, correction EventCorrection, now time.Time) error {
+	affected := []uuid.UUID{correction.Target.ID}
+	recompute := []Plate{correction.Target}
+	for _, event := range events {
+		err := tx.Exec(`
+			UPDATE anpr_events
+			SET original_plate_id = COALESCE(original_plate_id, plate_id),
+			    plate_id = ?, normalized_plate = ?,
+			    corrected_by = ?, corrected_at = ?, correction_reason = ?,
+			    read_status = 'accepted', verified_by = ?, verified_at = ?
+			WHERE id = ? AND event_time = ?`,
+			correction.Target.ID, correction.Target.Normalized,
+			correction.CorrectedBy, now, correction.Reason,
+			correction.CorrectedBy, now,
+			event.ID, event.EventTime).Error
+		if err != nil {
+			return fmt.Errorf("correct event plate: %w", err)
+		}
+		if event.PlateID != nil && *event.PlateID != correction.Target.ID {
+			affected = append(affected, *event.PlateID)
+			recompute = append(recompute, Plate{ID: *event.PlateID, Normalized: event.NormalizedPlate})
+		}
+	}
+	if err := recomputePlateProfiles(tx, affected); err != nil {
+		return err
+	}
+	return requestRecompute(tx, recompute, RecomputeReasonEventCorrection, now)
+}
+
+// AttachPlate привязывает номер, установленный оператором по снимку, к проезду без номера и
+// пересчитывает профиль номера. Возвращает false, если событие уже не plateless.
+func (r *CorrectionRepository) AttachPlate(ctx context.Context, event ANPREvent, target Plate, attachedBy *uuid.UUID, note string) (bool, error) {
+	var attached bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Exec(`
+			UPDATE anpr_events
+			SET plate_id = ?, normalized_plate = ?,
+			    corrected_by = ?, corrected_at = ?, correction_reason = ?,
+			    read_status = 'accepted', verified_by = ?, verified_at = ?
+			WHERE id = ? AND event_time = ? AND read_status = 'plateless'`,
+			target.ID, target.Normalized,
+			attachedBy, now, note,
+			attachedBy, now,
+			event.ID, event.EventTime)
+		if result.Error != nil {
+			return fmt.Errorf("attach plate: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		attached = true
+		if err := recomputePlateProfiles(tx, []uuid.UUID{target.ID}); err != nil {
+			return err
+		}
+		return requestRecompute(tx, []Plate{target}, RecomputeReasonPlateAttach, now)
+	})
+	return attached, err
+}
+
+// VerifyEvent подтверждает прочтение с низкой уверенностью как верное
+func (r *CorrectionRepository) VerifyEvent(ctx context.Context, event ANPREvent, verifiedBy *uuid.UUID) error {
+	return r.db.WithContext(ctx).Exec(`
+		UPDATE anpr_events
+		SET read_status = 'accepted', verified_by = ?, verified_at = ?
+		WHERE id = ? AND event_time = ?`,
+		verifiedBy, time.Now(), event.ID, event.EventTime).Error
+}
+
+// MergePlates переносит все события, членства в списках и связанные записи ошибочного номера
+// на настоящий одной транзакцией и удаляет ошибочный номер
+func (r *CorrectionRepository) MergePlates(ctx context.Context, source, target Plate, mergedBy *uuid.UUID, reason string) (*PlateMerge, error) {
+	merge := &PlateMerge{
+		SourcePlateID: source.ID,
+		SourcePlate:   source.Normalized,
+		TargetPlateID: target.ID,
+		TargetPlate:   target.Normalized,
+		MergedBy:      mergedBy,
+		Reason:        reason,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked []Plate
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{source.ID, target.ID}).
+			Order("id").
+			Find(&locked).Error
+		if err != nil {
+			return fmt.Errorf("lock plates: %w", err)
+		}
+		if len(locked) != 2 {
+			return fmt.Errorf("plate disappeared during merge")
+		}
+
+		now := time.Now()
+		events := tx.Exec(`
+			UPDATE anpr_events
+			SET original_plate_id = COALESCE(original_plate_id, plate_id),
+			    plate_id = ?, normalized_plate = ?,
+			    corrected_by = ?, corrected_at = ?, correction_reason = ?
+			WHERE plate_id = ?`,
+			target.ID, target.Normalized, mergedBy, now, reason, source.ID)
+		if events.Error != nil {
+			return fmt.Errorf("move events: %w", events.Error)
+		}
+		merge.EventsMoved = events.RowsAffected
+
+		memberships := tx.Exec(`
+			INSERT INTO anpr_list_items (list_id, plate_id, note, source, created_at)
+			SELECT list_id, ?, note, source, created_at
+			FROM anpr_list_items
+			WHERE plate_id = ?
+			ON CONFLICT (list_id, plate_id) DO NOTHING`, target.ID, source.ID)
+		if memberships.Error != nil {
+			return fmt.Errorf("move list memberships: %w", memberships.Error)
+		}
+		merge.MembershipsMoved = memberships.RowsAffected
+		if err := tx.Where("plate_id = ?", source.ID).Delete(&ListItem{}).Error; err != nil {
+			return fmt.Errorf("delete source memberships: %w", err)
+		}
+
+		err = tx.Model(&Anomaly{}).
+			Where("plate_id = ?", source.ID).
+			Updates(map[string]interface{}{"plate_id": target.ID, "normalized_plate": target.Normalized}).Error
+		if err != nil {
+			return fmt.Errorf("move anomalies: %w", err)
+		}
+
+		// Открытые записи очереди проверки ошибочного номера закрываются как ошибка распознавания,
+		// история переносится на настоящий номер, иначе её удалит каскад
+		err = tx.Model(&ReviewItem{}).
+			Where("plate_id = ? AND status = ?", source.ID, ReviewStatusPending).
+			Updates(map[string]interface{}{
+				"status":             ReviewStatusMisread,
+				"corrected_plate_id": target.ID,
+				"resolved_by":        mergedBy,
+				"resolved_at":        now,
+				"resolution_note":    reason,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("resolve review items: %w", err)
+		}
+		for _, column := range []string{"plate_id", "suggested_plate_id", "corrected_plate_id"} {
+			err = tx.Model(&ReviewItem{}).Where(column+" = ?", source.ID).Update(column, target.ID).Error
+			if err != nil {
+				return fmt.Errorf("move review items: %w", err)
+			}
+		}
+
+		// Исправления, указывавшие на ошибочный номер, теперь указывают на настоящий
+		err = tx.Exec(`
+			INSERT INTO anpr_plate_corrections (read_plate, plate_id, count, last_used)
+			SELECT read_plate, ?, count, last_used
+			FROM anpr_plate_corrections
+			WHERE plate_id = ?
+			ON CONFLICT (read_plate, plate_id) DO UPDATE
+			SET count = anpr_plate_corrections.count + EXCLUDED.count,
+			    last_used = GREATEST(anpr_plate_corrections.last_used, EXCLUDED.last_used)`,
+			target.ID, source.ID).Error
+		if err != nil {
+			return fmt.Errorf("move plate corrections: %w", err)
+		}
+		if err := recordCorrection(tx, source.Normalized, target.ID, now); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", source.ID).Delete(&Plate{}).Error; err != nil {
+			return fmt.Errorf("delete source plate: %w", err)
+		}
+		if err := recomputePlateProfiles(tx, []uuid.UUID{target.ID}); err != nil {
+			return err
+		}
+		// Производные данные ошибочного номера удаляются потребителем по source, настоящего - пересчитываются
+		if err := requestRecompute(tx, []Plate{source, target}, RecomputeReasonPlateMerge, now); err != nil {
+			return err
+		}
+
+		merge.CreatedAt = now
+		if err := tx.Create(merge).Error; err != nil {
+			return fmt.Errorf("record plate merge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+// recomputePlateProfiles строит профили атрибутов номеров заново по их событиям
+func recomputePlateProfiles(tx *gorm.DB, plateIDs []uuid.UUID) error {
+	if err := tx.Where("plate_id IN ?", plateIDs).Delete(&PlateProfileEntry{}).Error; err != nil {
+		return fmt.Errorf("delete plate profiles: %w", err)
+	}
+	err := tx.Exec(`
+		INSERT INTO anpr_plate_profiles (plate_id, attribute, value, count, last_seen)
+		SELECT e.plate_id, v.attribute, LOWER(TRIM(v.value)), COUNT(*), MAX(e.event_time)
+		FROM anpr_events e
+		CROSS JOIN LATERAL (VALUES
+			('vehicle_type', e.vehicle_type),
+			('vehicle_color', e.vehicle_color),
+			('vehicle_brand', e.vehicle_brand),
+			('vehicle_model', e.vehicle_model)
+		) AS v(attribute, value)
+		WHERE e.plate_id IN ? AND e.read_status <> 'unverified' AND e.auth IS DISTINCT FROM 'untrusted'
+		  AND v.value IS NOT NULL AND TRIM(v.value) <> ''
+		  AND LOWER(TRIM(v.value)) NOT IN ('unknown', 'other')
+		GROUP BY e.plate_id, v.attribute, LOWER(TRIM(v.value))`, plateIDs).Error
+	if err != nil {
+		return fmt.Errorf("rebuild plate profiles: %w", err)
+	}
+	return nil
+}
+
+// requestRecompute записывает в транзакции изменения сигнал пересчёта производных данных номеров
+func requestRecompute(tx *gorm.DB, plates []Plate, reason string, now time.Time) error {
+	requests := make([]PlateRecompute, 0, len(plates))
+	for _, plate := range plates {
+		requests = append(requests, PlateRecompute{
+			PlateID:         plate.ID,
+			NormalizedPlate: plate.Normalized,
+			Reason:          reason,
+			CreatedAt:       now,
+		})
+	}
+	if err := tx.Create(&requests).Error; err != nil {
+		return fmt.Errorf("request plate recompute: %w", err)
+	}
+	return nil
+}
+
+// FindPendingRecomputes возвращает неподтверждённые сигналы пересчёта, старые первыми
+func (r *CorrectionRepository) FindPendingRecomputes(ctx context.Context, limit int) ([]PlateRecompute, error) {
+	var recomputes []PlateRecompute
+	err := r.db.WithContext(ctx).
+		Where("processed_at IS NULL").
+		Order("created_at, id").
+		Limit(limit).
+		Find(&recomputes).Error
+	return recomputes, err
+}
+
+// MarkRecomputed подтверждает пересчёт; возвращает число подтверждённых записей
+func (r *CorrectionRepository) MarkRecomputed(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&PlateRecompute{}).
+		Where("id IN ? AND processed_at IS NULL", ids).
+		Update("processed_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// CountPlateVehicles считает ТС реестра с этим номером
+func (r *CorrectionRepository) CountPlateVehicles(ctx context.Context, plateID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Vehicle{}).Where("plate_id = ?", plateID).Count(&count).Error
+	return count, err
+}
+
+func (r *CorrectionRepository) FindMerges(ctx context.Context, plateID *uuid.UUID, limit, offset int) ([]PlateMerge, error) {
+	query := r.db.WithContext(ctx).Model(&PlateMerge{})
+	if plateID != nil {
+		query = query.Where("source_plate_id = ? OR target_plate_id = ?", *plateID, *plateID)
+	}
+	query = query.Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var merges []PlateMerge
+	err := query.Find(&merges).Error
+	return merges, err
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusRunning = "running"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired"
+)
+
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (ExportJob) TableName() string {
+	return "anpr_export_jobs"
+}
+
+// ExportJob - фоновая выгрузка событий; файл результата лежит в хранилище архивов
+type ExportJob struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Status           string         `gorm:"not null"`
+	Format           string         `gorm:"not null"`
+	Query            datatypes.JSON `gorm:"type:jsonb;not null"`
+	IncludeSnapshots bool           `gorm:"not null"`
+	Store            *string
+	DataKey          *string
+	RowCount         int64 `gorm:"not null"`
+	SizeBytes        int64 `gorm:"not null"`
+	Error            *string
+	CreatedBy        *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt        time.Time
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+	ExpiresAt        *time.Time
+}
+
+func (r *ExportRepository) CreateJob(ctx context.Context, job *ExportJob) error {
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	return nil
+}
+
+// GetJob возвращает задачу; createdBy задан - только задачу этого пользователя
+func (r *ExportRepository) GetJob(ctx context.Context, id uuid.UUID, createdBy *uuid.UUID) (*ExportJob, error) {
+	var job ExportJob
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	if createdBy != nil {
+		query = query.Where("created_by = ?", *createdBy)
+	}
+	err := query.First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportRepository) FindJobs(ctx context.Context, createdBy *uuid.UUID, limit, offset int) ([]ExportJob, error) {
+	query := r.db.WithContext(ctx).Model(&ExportJob{})
+	if createdBy != nil {
+		query = query.Where("created_by = ?", *createdBy)
+	}
+	query = query.Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var jobs []ExportJob
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// ClaimPendingJob переводит самую старую ожидающую задачу в running и возвращает её.
+// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать очередь без конфликтов.
+func (r *ExportRepository) ClaimPendingJob(ctx context.Context) (*ExportJob, error) {
+	var jobs []ExportJob
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE anpr_export_jobs SET status = ?, started_at = NOW()
+		WHERE id = (
+			SELECT id FROM anpr_export_jobs
+			WHERE status = ?
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, ExportStatusRunning, ExportStatusPending).Scan(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+func (r *ExportRepository) CompleteJob(ctx context.Context, id uuid.UUID, store, dataKey string, rows, size int64, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      ExportStatusDone,
+			"store":       store,
+			"data_key":    dataKey,
+			"row_count":   rows,
+			"size_bytes":  size,
+			"finished_at": time.Now(),
+			"expires_at":  expiresAt,
+		}).Error
+}
+
+func (r *ExportRepository) FailJob(ctx context.Context, id uuid.UUID, message string) error {
+	return r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      ExportStatusFailed,
+			"error":       message,
+			"finished_at": time.Now(),
+		}).Error
+}
+
+// FailStaleJobs помечает упавшими задачи, которые выполняются дольше таймаута
+// (например, экземпляр сервиса был остановлен посреди выгрузки)
+func (r *ExportRepository) FailStaleJobs(ctx context.Context, startedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("status = ? AND started_at < ?", ExportStatusRunning, startedBefore).
+		Updates(map[string]interface{}{
+			"status":      ExportStatusFailed,
+			"error":       "export job interrupted",
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ExportRepository) FindExpiredJobs(ctx context.Context, now time.Time, limit int) ([]ExportJob, error) {
+	var jobs []ExportJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", ExportStatusDone, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *ExportRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("id = ?", id).
+		Update("status", ExportStatusExpired).Error
+}
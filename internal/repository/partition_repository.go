@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const EventsTable = "anpr_events"
+
+type PartitionRepository struct {
+	db *gorm.DB
+}
+
+func NewPartitionRepository(db *gorm.DB) *PartitionRepository {
+	return &PartitionRepository{db: db}
+}
+
+// Partition - секция anpr_events. From == nil означает MINVALUE.
+type Partition struct {
+	Name      string     `json:"name"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	IsDefault bool       `json:"is_default"`
+}
+
+var partitionBoundRe = regexp.MustCompile(`FROM \((MINVALUE|'[^']+')\) TO \((MAXVALUE|'[^']+')\)`)
+
+// IsPartitioned проверяет, что anpr_events уже переведена на декларативное секционирование
+func (r *PartitionRepository) IsPartitioned(ctx context.Context) (bool, error) {
+	var kind string
+	err := r.db.WithContext(ctx).
+		Raw("SELECT relkind::text FROM pg_class WHERE oid = to_regclass(?)", EventsTable).
+		Scan(&kind).Error
+	if err != nil {
+		return false, err
+	}
+	return kind == "p", nil
+}
+
+func (r *PartitionRepository) ListPartitions(ctx context.Context) ([]Partition, error) {
+	var rows []struct {
+		Name  string
+		Bound string
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass(?)
+		ORDER BY c.relname`, EventsTable).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]Partition, 0, len(rows))
+	for _, row := range rows {
+		p, err := parsePartitionBound(row.Name, row.Bound)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, nil
+}
+
+func parsePartitionBound(name, bound string) (Partition, error) {
+	p := Partition{Name: name}
+	if bound == "DEFAULT" {
+		p.IsDefault = true
+		return p, nil
+	}
+
+	m := partitionBoundRe.FindStringSubmatch(bound)
+	if m == nil {
+		return p, fmt.Errorf("unexpected partition bound for %s: %s", name, bound)
+	}
+	var err error
+	if p.From, err = parseBoundValue(m[1]); err != nil {
+		return p, fmt.Errorf("partition %s: %w", name, err)
+	}
+	if p.To, err = parseBoundValue(m[2]); err != nil {
+		return p, fmt.Errorf("partition %s: %w", name, err)
+	}
+	return p, nil
+}
+
+func parseBoundValue(value string) (*time.Time, error) {
+	if value == "MINVALUE" || value == "MAXVALUE" {
+		return nil, nil
+	}
+	value = strings.Trim(value, "'")
+	layouts := []string{
+		"2006-01-02 15:04:05-07",
+		"2006-01-02 15:04:05-07:00",
+		"2006-01-02 15:04:05.999999-07",
+		"2006-01-02 15:04:05.999999-07:00",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse partition bound %q", value)
+}
+
+func (r *PartitionRepository) CreatePartition(ctx context.Context, name string, from, to time.Time) error {
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (?) TO (?)",
+		quoteIdent(name), EventsTable)
+	if err := r.db.WithContext(ctx).Exec(stmt, from.UTC(), to.UTC()).Error; err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// CountRowsInRange считает строки таблицы table (секции) с event_time в [from, to)
+func (r *PartitionRepository) CountRowsInRange(ctx context.Context, table string, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table(quoteIdent(table)).
+		Where("event_time >= ? AND event_time < ?", from.UTC(), to.UTC()).
+		Count(&count).Error
+	return count, err
+}
+
+// CreatePartitionFromDefault создаёт секцию [from, to), когда в секции DEFAULT уже есть строки
+// этого диапазона (например, события камер со спешащими часами): PostgreSQL не даёт создать такую
+// секцию напрямую. В одной транзакции строки переносятся из DEFAULT в новую таблицу, и она
+// подключается как секция. Возвращает число перенесённых строк.
+func (r *PartitionRepository) CreatePartitionFromDefault(ctx context.Context, name, defaultName string, from, to time.Time) (int64, error) {
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Новые строки диапазона не должны попасть в DEFAULT между переносом и подключением
+		if err := tx.Exec(fmt.Sprintf("LOCK TABLE %s IN EXCLUSIVE MODE", quoteIdent(defaultName))).Error; err != nil {
+			return fmt.Errorf("lock default partition: %w", err)
+		}
+		create := fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", quoteIdent(name), EventsTable)
+		if err := tx.Exec(create).Error; err != nil {
+			return fmt.Errorf("create table %s: %w", name, err)
+		}
+		move := fmt.Sprintf(`
+			WITH moved AS (
+				DELETE FROM %s WHERE event_time >= ? AND event_time < ? RETURNING *
+			)
+			INSERT INTO %s SELECT * FROM moved`, quoteIdent(defaultName), quoteIdent(name))
+		result := tx.Exec(move, from.UTC(), to.UTC())
+		if result.Error != nil {
+			return fmt.Errorf("move rows from %s: %w", defaultName, result.Error)
+		}
+		moved = result.RowsAffected
+		attach := fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (?) TO (?)", EventsTable, quoteIdent(name))
+		if err := tx.Exec(attach, from.UTC(), to.UTC()).Error; err != nil {
+			return fmt.Errorf("attach partition %s: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+func (r *PartitionRepository) DetachPartition(ctx context.Context, name string) error {
+	stmt := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", EventsTable, quoteIdent(name))
+	if err := r.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+		return fmt.Errorf("detach partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// AttachPartition возвращает ранее отсоединённую секцию с её прежними границами
+func (r *PartitionRepository) AttachPartition(ctx context.Context, p Partition) error {
+	from, to := "MINVALUE", "MAXVALUE"
+	var args []interface{}
+	if p.From != nil {
+		from = "?"
+		args = append(args, p.From.UTC())
+	}
+	if p.To != nil {
+		to = "?"
+		args = append(args, p.To.UTC())
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)",
+		EventsTable, quoteIdent(p.Name), from, to)
+	if err := r.db.WithContext(ctx).Exec(stmt, args...).Error; err != nil {
+		return fmt.Errorf("attach partition %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+func (r *PartitionRepository) DropTable(ctx context.Context, name string) error {
+	stmt := fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdent(name))
+	if err := r.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+		return fmt.Errorf("drop table %s: %w", name, err)
+	}
+	return nil
+}
+
+func (r *PartitionRepository) CountRows(ctx context.Context, table string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table(quoteIdent(table)).Count(&count).Error
+	return count, err
+}
+
+// NextEventsInTable читает события из отдельной (отсоединённой) секции для архивации
+func (r *PartitionRepository) NextEventsInTable(ctx context.Context, table string, after *EventCursor, limit int) ([]ANPREvent, error) {
+	query := r.db.WithContext(ctx).Table(quoteIdent(table))
+	if after != nil {
+		query = query.Where("(event_time, id) > (?, ?)", after.EventTime, after.ID)
+	}
+	var events []ANPREvent
+	err := query.Order("event_time, id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
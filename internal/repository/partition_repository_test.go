@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartitionBound(t *testing.T) {
+	p, err := parsePartitionBound("anpr_events_p20260101", "FOR VALUES FROM ('2026-01-01 00:00:00+00') TO ('2026-01-02 05:00:00+05')")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if p.From == nil || !p.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected from: %v", p.From)
+	}
+	if p.To == nil || !p.To.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected to: %v", p.To)
+	}
+
+	p, err = parsePartitionBound("anpr_events_legacy", "FOR VALUES FROM (MINVALUE) TO ('2025-12-01 00:00:00+00')")
+	if err != nil {
+		t.Fatalf("parse legacy: %v", err)
+	}
+	if p.From != nil || p.To == nil {
+		t.Errorf("unexpected legacy bounds: %+v", p)
+	}
+
+	p, err = parsePartitionBound("anpr_events_default", "DEFAULT")
+	if err != nil || !p.IsDefault {
+		t.Errorf("expected default partition, got %+v, %v", p, err)
+	}
+
+	if _, err := parsePartitionBound("x", "FOR VALUES IN (1)"); err == nil {
+		t.Error("expected error for list partition bound")
+	}
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	RetentionTargetEvents      = "events"
+	RetentionTargetRawPayloads = "raw_payloads"
+	RetentionTargetSnapshots   = "snapshots"
+
+	RetentionListTypeNone = "NONE"
+
+	retentionBatchSize = 5000
+)
+
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+func (RetentionPolicy) TableName() string {
+	return "anpr_retention_policies"
+}
+
+func (RetentionRun) TableName() string {
+	return "anpr_retention_runs"
+}
+
+type RetentionPolicy struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name        string    `gorm:"not null"`
+	Target      string    `gorm:"not null"`
+	CameraID    *string
+	PolygonID   *uuid.UUID `gorm:"type:uuid"`
+	ListType    *string
+	RetainDays  int  `gorm:"not null"`
+	Priority    int  `gorm:"not null;default:0"`
+	Enabled     bool `gorm:"not null;default:true"`
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// TripLinked - true: только события, связанные с поездкой; false: только несвязанные
+	TripLinked *bool
+	// RetainUntil - до этого момента (например, конца сезона) политика ничего не удаляет,
+	// но по-прежнему закрывает свои события от политик ниже
+	RetainUntil *time.Time
+}
+
+type RetentionRun struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Trigger    string         `gorm:"not null"`
+	DryRun     bool           `gorm:"not null"`
+	Status     string         `gorm:"not null"`
+	Results    datatypes.JSON `gorm:"type:jsonb"`
+	Error      *string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (r *RetentionRepository) ListPolicies(ctx context.Context) ([]RetentionPolicy, error) {
+	var policies []RetentionPolicy
+	err := r.db.WithContext(ctx).
+		Order("target, priority DESC, created_at").
+		Find(&policies).Error
+	return policies, err
+}
+
+func (r *RetentionRepository) GetPolicy(ctx context.Context, id uuid.UUID) (*RetentionPolicy, error) {
+	var policy RetentionPolicy
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *RetentionRepository) CreatePolicy(ctx context.Context, policy *RetentionPolicy) error {
+	now := time.Now()
+	policy.ID = uuid.New()
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return fmt.Errorf("failed to create retention policy: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) UpdatePolicy(ctx context.Context, policy *RetentionPolicy) error {
+	policy.UpdatedAt = time.Now()
+	if err := r.db.WithContext(ctx).Save(policy).Error; err != nil {
+		return fmt.Errorf("failed to update retention policy: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) DeletePolicy(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&RetentionPolicy{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ApplyPolicy применяет одну политику к anpr_events.
+// shadowed - политики с более высоким приоритетом для той же цели: строки,
+// которые подпадают под них, управляются ими и здесь не трогаются.
+// В режиме dryRun только считает затронутые строки.
+func (r *RetentionRepository) ApplyPolicy(ctx context.Context, policy RetentionPolicy, shadowed []RetentionPolicy, now time.Time, dryRun bool) (int64, error) {
+	where, args := retentionWhere(policy, shadowed, now)
+
+	if dryRun {
+		var count int64
+		err := r.db.WithContext(ctx).
+			Table("anpr_events").
+			Where(where, args...).
+			Count(&count).Error
+		return count, err
+	}
+
+	var action string
+	switch policy.Target {
+	case RetentionTargetEvents:
+		action = "DELETE FROM anpr_events"
+	case RetentionTargetRawPayloads:
+		action = "UPDATE anpr_events SET raw_payload = NULL"
+	case RetentionTargetSnapshots:
+		action = "UPDATE anpr_events SET snapshot_url = NULL"
+	default:
+		return 0, fmt.Errorf("unknown retention target %q", policy.Target)
+	}
+
+	// Удаляем пачками, чтобы не держать долгие блокировки на большой таблице
+	stmt := fmt.Sprintf("%s WHERE id IN (SELECT id FROM anpr_events WHERE %s LIMIT %d)", action, where, retentionBatchSize)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result := r.db.WithContext(ctx).Exec(stmt, args...)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// NextPolicyBatch возвращает очередную пачку событий, подпадающих под политику, после курсора.
+// Используется для архивации перед удалением.
+func (r *RetentionRepository) NextPolicyBatch(ctx context.Context, policy RetentionPolicy, shadowed []RetentionPolicy, now time.Time, after *EventCursor, limit int) ([]ANPREvent, error) {
+	where, args := retentionWhere(policy, shadowed, now)
+	query := r.db.WithContext(ctx).Where(where, args...)
+	if after != nil {
+		query = query.Where("(event_time, id) > (?, ?)", after.EventTime, after.ID)
+	}
+	var events []ANPREvent
+	err := query.Order("event_time, id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// DeleteEventsByID удаляет события по списку id пачками
+func (r *RetentionRepository) DeleteEventsByID(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return deleteEventsByID(r.db.WithContext(ctx), ids)
+}
+
+func retentionWhere(policy RetentionPolicy, shadowed []RetentionPolicy, now time.Time) (string, []interface{}) {
+	cutoff := now.AddDate(0, 0, -policy.RetainDays)
+
+	clauses := []string{"event_time < ?"}
+	args := []interface{}{cutoff}
+
+	switch policy.Target {
+	case RetentionTargetRawPayloads:
+		clauses = append(clauses, "raw_payload IS NOT NULL")
+	case RetentionTargetSnapshots:
+		clauses = append(clauses, "snapshot_url IS NOT NULL")
+	}
+
+	if policy.RetainUntil != nil && now.Before(*policy.RetainUntil) {
+		clauses = append(clauses, "FALSE")
+	}
+
+	if cond, condArgs := retentionMatch(policy); cond != "" {
+		clauses = append(clauses, cond)
+		args = append(args, condArgs...)
+	}
+
+	for _, other := range shadowed {
+		cond, condArgs := retentionMatch(other)
+		if cond == "" {
+			// Политика без критериев перекрывает всё - применять нечего
+			clauses = append(clauses, "FALSE")
+			continue
+		}
+		clauses = append(clauses, "NOT COALESCE(("+cond+"), FALSE)")
+		args = append(args, condArgs...)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// retentionMatch строит условие отбора событий по критериям политики.
+// Пустая строка означает "все события".
+func retentionMatch(policy RetentionPolicy) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if policy.CameraID != nil {
+		clauses = append(clauses, "camera_id = ?")
+		args = append(args, *policy.CameraID)
+	}
+	if policy.PolygonID != nil {
+		clauses = append(clauses, "polygon_id = ?")
+		args = append(args, *policy.PolygonID)
+	}
+	// Совпадение со списком берётся на момент приёма события: последующие изменения списков
+	// и слияния номеров не переводят уже принятые события под другую политику
+	if policy.ListType != nil {
+		if *policy.ListType == RetentionListTypeNone {
+			clauses = append(clauses, `NOT EXISTS (SELECT 1 FROM anpr_event_list_hits h
+				WHERE h.event_id = anpr_events.id)`)
+		} else {
+			clauses = append(clauses, `EXISTS (SELECT 1 FROM anpr_event_list_hits h
+				WHERE h.event_id = anpr_events.id AND h.list_type = ?)`)
+			args = append(args, *policy.ListType)
+		}
+	}
+	if policy.TripLinked != nil {
+		cond := `EXISTS (SELECT 1 FROM anpr_event_trip_links tl WHERE tl.event_id = anpr_events.id)`
+		if !*policy.TripLinked {
+			cond = "NOT " + cond
+		}
+		clauses = append(clauses, cond)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// LinkTripEvents связывает события с поездкой внешней системы; повторная связь не ошибка.
+// Возвращает число новых связей.
+func (r *RetentionRepository) LinkTripEvents(ctx context.Context, tripID string, eventIDs []uuid.UUID) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO anpr_event_trip_links (event_id, trip_id)
+		SELECT e.id, ? FROM anpr_events e WHERE e.id IN ?
+		ON CONFLICT DO NOTHING`, tripID, eventIDs)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to link trip events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UnlinkTrip снимает все связи событий с поездкой, например при её отмене
+func (r *RetentionRepository) UnlinkTrip(ctx context.Context, tripID string) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`DELETE FROM anpr_event_trip_links WHERE trip_id = ?`, tripID)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to unlink trip events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// SweepEventLinks удаляет совпадения со списками и связи с поездками удалённых событий.
+// Ссылок на anpr_events у этих таблиц нет: события секционированы и удаляются ещё и секциями.
+func (r *RetentionRepository) SweepEventLinks(ctx context.Context) (int64, error) {
+	var total int64
+	for _, table := range []string{"anpr_event_list_hits", "anpr_event_trip_links"} {
+		stmt := fmt.Sprintf(`DELETE FROM %[1]s WHERE ctid IN (
+			SELECT l.ctid FROM %[1]s l
+			WHERE NOT EXISTS (SELECT 1 FROM anpr_events e WHERE e.id = l.event_id)
+			LIMIT %[2]d)`, table, retentionBatchSize)
+		for {
+			if err := ctx.Err(); err != nil {
+				return total, err
+			}
+			result := r.db.WithContext(ctx).Exec(stmt)
+			if result.Error != nil {
+				return total, fmt.Errorf("failed to sweep %s: %w", table, result.Error)
+			}
+			total += result.RowsAffected
+			if result.RowsAffected < retentionBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+func (r *RetentionRepository) CreateRun(ctx context.Context, run *RetentionRun) error {
+	run.ID = uuid.New()
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create retention run: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) FinishRun(ctx context.Context, run *RetentionRun) error {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	err := r.db.WithContext(ctx).
+		Model(&RetentionRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"results":     run.Results,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to finish retention run: %w", err)
+	}
+	return nil
+}
+
+func (r *RetentionRepository) ListRuns(ctx context.Context, limit, offset int) ([]RetentionRun, error) {
+	query := r.db.WithContext(ctx).Order("started_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var runs []RetentionRun
+	err := query.Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/db/dbtest"
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestRetentionWhereListTypeUsesIngestHits(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	blacklist := "BLACKLIST"
+	none := RetentionListTypeNone
+
+	where, args := retentionWhere(RetentionPolicy{Target: RetentionTargetEvents, RetainDays: 30, ListType: &blacklist}, nil, now)
+	if !strings.Contains(where, "anpr_event_list_hits") || strings.Contains(where, "anpr_list_items") {
+		t.Errorf("list policy must match hits recorded at ingest, not current membership: %s", where)
+	}
+	if len(args) != 2 || args[1] != blacklist {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	where, _ = retentionWhere(RetentionPolicy{Target: RetentionTargetEvents, RetainDays: 30, ListType: &none}, nil, now)
+	if !strings.Contains(where, "NOT EXISTS (SELECT 1 FROM anpr_event_list_hits") {
+		t.Errorf("NONE must exclude events with any hit: %s", where)
+	}
+}
+
+func TestRetentionWhereTripLinkedSeason(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	linked, unlinked := true, false
+	seasonEnd := time.Date(2027, 4, 15, 0, 0, 0, 0, time.UTC)
+	season := RetentionPolicy{Name: "season", Target: RetentionTargetEvents, RetainDays: 1, TripLinked: &linked, RetainUntil: &seasonEnd}
+
+	where, _ := retentionWhere(season, nil, now)
+	if !strings.Contains(where, "FALSE") || !strings.Contains(where, "EXISTS (SELECT 1 FROM anpr_event_trip_links") {
+		t.Errorf("season policy must delete nothing before retain_until: %s", where)
+	}
+
+	// После конца сезона действует retain_days
+	where, _ = retentionWhere(season, nil, seasonEnd.Add(time.Hour))
+	if strings.Contains(where, "FALSE") {
+		t.Errorf("season policy must apply after retain_until: %s", where)
+	}
+
+	// Политика ниже не трогает события поездок, пока сезон не закончился
+	where, _ = retentionWhere(RetentionPolicy{Target: RetentionTargetEvents, RetainDays: 30}, []RetentionPolicy{season}, now)
+	if !strings.Contains(where, "NOT COALESCE((EXISTS (SELECT 1 FROM anpr_event_trip_links") {
+		t.Errorf("lower policy must skip trip-linked events: %s", where)
+	}
+
+	where, _ = retentionWhere(RetentionPolicy{Target: RetentionTargetEvents, RetainDays: 30, TripLinked: &unlinked}, nil, now)
+	if !strings.Contains(where, "NOT EXISTS (SELECT 1 FROM anpr_event_trip_links") {
+		t.Errorf("trip_linked=false must select unlinked events: %s", where)
+	}
+}
+
+// Работает с PostgreSQL (TEST_DB_DSN)
+func TestRetentionListPolicySurvivesMembershipChange(t *testing.T) {
+	database := dbtest.Open(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	normalized := "R" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:11])
+	plate := Plate{ID: uuid.New(), Number: normalized, Normalized: normalized}
+	list := List{ID: uuid.New(), Name: "test-" + uuid.NewString(), Type: "BLACKLIST"}
+	cameraID := "retention-" + uuid.NewString()
+	event := ANPREvent{ID: uuid.New(), PlateID: &plate.ID, CameraID: cameraID, RawPlate: normalized, NormalizedPlate: normalized, EventTime: now.AddDate(0, 0, -40)}
+	for _, row := range []interface{}{&plate, &list, &ListItem{ListID: list.ID, PlateID: plate.ID}, &event} {
+		if err := database.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	hit := anpr.ListHit{ListID: list.ID, ListType: list.Type, MatchedPlate: normalized}
+	if err := NewANPRRepository(database).RecordListHits(ctx, event.ID, []anpr.ListHit{hit}); err != nil {
+		t.Fatal(err)
+	}
+	// Номер убран из чёрного списка после проезда
+	if err := database.Where("list_id = ?", list.ID).Delete(&ListItem{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewRetentionRepository(database)
+	blacklist, none := "BLACKLIST", RetentionListTypeNone
+	for listType, want := range map[*string]int64{&blacklist: 1, &none: 0} {
+		policy := RetentionPolicy{Target: RetentionTargetEvents, RetainDays: 30, CameraID: &cameraID, ListType: listType}
+		count, err := repo.ApplyPolicy(ctx, policy, nil, now, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("list_type %s: %d events, want %d", *listType, count, want)
+		}
+	}
+}
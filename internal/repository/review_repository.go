@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusApproved  = "approved"
+	ReviewStatusMisread   = "misread"
+	ReviewStatusDismissed = "dismissed"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (ReviewPolicy) TableName() string {
+	return "anpr_review_policies"
+}
+
+func (ReviewItem) TableName() string {
+	return "anpr_review_items"
+}
+
+func (PlateCorrection) TableName() string {
+	return "anpr_plate_corrections"
+}
+
+// ReviewPolicy - ставить ли номера вне списков в очередь проверки на полигоне
+type ReviewPolicy struct {
+	PolygonID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Enabled   bool      `gorm:"not null"`
+	// Direction - только события этого направления; nil - любые
+	Direction *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReviewItem - номер вне списков, замеченный на полигоне и ожидающий решения оператора
+type ReviewItem struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Status           string     `gorm:"not null"`
+	PlateID          uuid.UUID  `gorm:"type:uuid;not null"`
+	NormalizedPlate  string     `gorm:"not null"`
+	PolygonID        uuid.UUID  `gorm:"type:uuid;not null"`
+	CameraID         string     `gorm:"not null"`
+	FirstEventID     uuid.UUID  `gorm:"type:uuid;not null"`
+	LastEventID      uuid.UUID  `gorm:"type:uuid;not null"`
+	FirstSeen        time.Time  `gorm:"not null"`
+	LastSeen         time.Time  `gorm:"not null"`
+	Occurrences      int        `gorm:"not null"`
+	SuggestedPlateID *uuid.UUID `gorm:"type:uuid"`
+	ListID           *uuid.UUID `gorm:"type:uuid"`
+	CorrectedPlateID *uuid.UUID `gorm:"type:uuid"`
+	ResolvedBy       *uuid.UUID `gorm:"type:uuid"`
+	ResolvedAt       *time.Time
+	ResolutionNote   *string
+	CreatedAt        time.Time
+}
+
+// PlateCorrection - сколько раз прочитанная строка оказывалась номером plate_id
+type PlateCorrection struct {
+	ReadPlate string    `gorm:"primaryKey"`
+	PlateID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Count     int       `gorm:"not null"`
+	LastUsed  time.Time `gorm:"not null"`
+}
+
+type ReviewFilter struct {
+	Status          *string
+	PolygonID       *uuid.UUID
+	CameraID        *string
+	NormalizedPlate *string
+}
+
+// ReviewResolution - решение оператора по записи очереди
+type ReviewResolution struct {
+	Status string
+	// ListID - список, в который одобрен номер (approved)
+	ListID *uuid.UUID
+	// CorrectedPlateID - настоящий номер ТС (misread)
+	CorrectedPlateID *uuid.UUID
+	ResolvedBy       *uuid.UUID
+	Note             *string
+}
+
+func (r *ReviewRepository) ListPolicies(ctx context.Context) ([]ReviewPolicy, error) {
+	var policies []ReviewPolicy
+	err := r.db.WithContext(ctx).Order("polygon_id").Find(&policies).Error
+	return policies, err
+}
+
+func (r *ReviewRepository) UpsertPolicy(ctx context.Context, policy *ReviewPolicy) error {
+	now := time.Now()
+	if policy.CreatedAt.IsZero() {
+		policy.CreatedAt = now
+	}
+	policy.UpdatedAt = now
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "polygon_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "direction", "updated_at"}),
+		}).
+		Create(policy).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert review policy: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepository) DeletePolicy(ctx context.Context, polygonID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("polygon_id = ?", polygonID).Delete(&ReviewPolicy{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// EnqueueItem добавляет номер в очередь полигона или, если по номеру уже есть открытая
+// запись, учитывает в ней новое событие. Возвращает id записи.
+func (r *ReviewRepository) EnqueueItem(ctx context.Context, item *ReviewItem) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO anpr_review_items (status, plate_id, normalized_plate, polygon_id, camera_id,
+			first_event_id, last_event_id, first_seen, last_seen, occurrences, suggested_plate_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, NOW())
+		ON CONFLICT (plate_id, polygon_id) WHERE status = 'pending' DO UPDATE
+		SET occurrences = anpr_review_items.occurrences + 1,
+		    camera_id = EXCLUDED.camera_id,
+		    last_event_id = EXCLUDED.last_event_id,
+		    last_seen = GREATEST(anpr_review_items.last_seen, EXCLUDED.last_seen),
+		    suggested_plate_id = COALESCE(EXCLUDED.suggested_plate_id, anpr_review_items.suggested_plate_id)
+		RETURNING id`,
+		ReviewStatusPending, item.PlateID, item.NormalizedPlate, item.PolygonID, item.CameraID,
+		item.FirstEventID, item.LastEventID, item.FirstSeen, item.LastSeen, item.SuggestedPlateID).
+		Scan(&id).Error
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to enqueue review item: %w", err)
+	}
+	return id, nil
+}
+
+func (r *ReviewRepository) GetItem(ctx context.Context, id uuid.UUID) (*ReviewItem, error) {
+	var item ReviewItem
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ReviewRepository) FindItems(ctx context.Context, filter ReviewFilter, limit, offset int) ([]ReviewItem, error) {
+	query := r.db.WithContext(ctx).Model(&ReviewItem{})
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.PolygonID != nil {
+		query = query.Where("polygon_id = ?", *filter.PolygonID)
+	}
+	if filter.CameraID != nil {
+		query = query.Where("camera_id = ?", *filter.CameraID)
+	}
+	if filter.NormalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *filter.NormalizedPlate)
+	}
+	query = query.Order("last_seen DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var items []ReviewItem
+	err := query.Find(&items).Error
+	return items, err
+}
+
+// ResolveItem закрывает открытую запись одной транзакцией вместе с последствиями решения:
+// approved - номер добавляется в список, и открытые записи этого номера на других полигонах
+// тоже закрываются; misread - события записи (номер на полигоне между first_seen и
+// last_seen) перепривязываются к настоящему номеру так же, как при ручном исправлении,
+// а прочитанная строка учитывается как ошибка распознавания. Одобрение учитывается как подтверждение, что строка - настоящий номер.
+// Возвращает false, если запись уже не в статусе pending.
+func (r *ReviewRepository) ResolveItem(ctx context.Context, item *ReviewItem, resolution ReviewResolution) (bool, error) {
+	resolved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":             resolution.Status,
+			"list_id":            resolution.ListID,
+			"corrected_plate_id": resolution.CorrectedPlateID,
+			"resolved_by":        resolution.ResolvedBy,
+			"resolved_at":        now,
+			"resolution_note":    resolution.Note,
+		}
+		result := tx.Model(&ReviewItem{}).
+			Where("id = ? AND status = ?", item.ID, ReviewStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("update review item: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		resolved = true
+
+		switch resolution.Status {
+		case ReviewStatusApproved:
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ListItem{
+				ListID:    *resolution.ListID,
+				PlateID:   item.PlateID,
+				Note:      resolution.Note,
+				CreatedAt: now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("add plate to list: %w", err)
+			}
+			err = tx.Model(&ReviewItem{}).
+				Where("plate_id = ? AND status = ? AND id <> ?", item.PlateID, ReviewStatusPending, item.ID).
+				Updates(updates).Error
+			if err != nil {
+				return fmt.Errorf("resolve other review items: %w", err)
+			}
+			return recordCorrection(tx, item.NormalizedPlate, item.PlateID, now)
+		case ReviewStatusMisread:
+			return correctReviewEvents(tx, item, resolution, now)
+		}
+		return nil
+	})
+	return resolved, err
+}
+
+// correctReviewEvents переносит события записи очереди на исправленный номер
+func correctReviewEvents(tx *gorm.DB, item *ReviewItem, resolution ReviewResolution, now time.Time) error {
+	var target Plate
+	if err := tx.Where("id = ?", *resolution.CorrectedPlateID).First(&target).Error; err != nil {
+		return fmt.Errorf("get corrected plate: %w", err)
+	}
+	var events []ANPREvent
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("plate_id = ? AND polygon_id = ? AND event_time BETWEEN ? AND ?",
+			item.PlateID, item.PolygonID, item.FirstSeen, item.LastSeen).
+		Or("id IN ? AND plate_id = ?", []uuid.UUID{item.FirstEventID, item.LastEventID}, item.PlateID).
+		Find(&events).Error
+	if err != nil {
+		return fmt.Errorf("lock review events: %w", err)
+	}
+	reason := "review misread"
+	if resolution.Note != nil && *resolution.Note != "" {
+		reason = *resolution.Note
+	}
+	correction := EventCorrection{
+		Target:      target,
+		ReadPlate:   item.NormalizedPlate,
+		CorrectedBy: resolution.ResolvedBy,
+		Reason:      reason,
+	}
+	if err := correctEvents(tx, events, correction, now); err != nil {
+		return err
+	}
+	return recordCorrection(tx, item.NormalizedPlate, target.ID, now)
+}
+
+func recordCorrection(tx *gorm.DB, readPlate string, plateID uuid.UUID, usedAt time.Time) error {
+	err := tx.Exec(`
+		INSERT INTO anpr_plate_corrections (read_plate, plate_id, count, last_used)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (read_plate, plate_id) DO UPDATE
+		SET count = anpr_plate_corrections.count + 1,
+		    last_used = GREATEST(anpr_plate_corrections.last_used, EXCLUDED.last_used)`,
+		readPlate, plateID, usedAt).Error
+	if err != nil {
+		return fmt.Errorf("record plate correction: %w", err)
+	}
+	return nil
+}
+
+// FindCorrection возвращает номер, которым чаще всего оказывалась прочитанная строка
+// (без подтверждений самой строки); nil - исправлений не было
+func (r *ReviewRepository) FindCorrection(ctx context.Context, readPlate string) (*PlateCorrection, error) {
+	var correction PlateCorrection
+	err := r.db.WithContext(ctx).
+		Table("anpr_plate_corrections AS c").
+		Select("c.*").
+		Joins("JOIN anpr_plates p ON p.id = c.plate_id").
+		Where("c.read_plate = ? AND p.normalized <> c.read_plate", readPlate).
+		Order("c.count DESC, c.last_used DESC").
+		Limit(1).
+		Scan(&correction).Error
+	if err != nil {
+		return nil, err
+	}
+	if correction.PlateID == uuid.Nil {
+		return nil, nil
+	}
+	return &correction, nil
+}
+
+// ListExists проверяет, что список существует
+func (r *ReviewRepository) ListExists(ctx context.Context, listID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&List{}).Where("id = ?", listID).Count(&count).Error
+	return count > 0, err
+}
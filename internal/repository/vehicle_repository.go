@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// ListItemSourceVehicles - членство в списке управляется синхронизацией с реестром ТС
+	ListItemSourceVehicles = "vehicles"
+	// VehicleWhitelistName - список, в который попадают номера активных ТС
+	VehicleWhitelistName = "default_whitelist"
+)
+
+type VehicleRepository struct {
+	db *gorm.DB
+}
+
+func NewVehicleRepository(db *gorm.DB) *VehicleRepository {
+	return &VehicleRepository{db: db}
+}
+
+func (Vehicle) TableName() string {
+	return "anpr_vehicles"
+}
+
+// Vehicle - зеркало записи vehicles сервиса roles
+type Vehicle struct {
+	VehicleID       uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrgID           uuid.UUID  `gorm:"type:uuid;not null"`
+	ContractorID    *uuid.UUID `gorm:"type:uuid"`
+	ContractorName  *string
+	PlateNumber     string     `gorm:"not null"`
+	NormalizedPlate string     `gorm:"not null"`
+	PlateID         *uuid.UUID `gorm:"type:uuid"`
+	IsActive        bool       `gorm:"not null"`
+	SourceUpdatedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type VehicleFilter struct {
+	OrgID           *uuid.UUID
+	ContractorID    *uuid.UUID
+	NormalizedPlate *string
+}
+
+// VehicleChanges - изменения зеркала, применяемые одной транзакцией
+type VehicleChanges struct {
+	Upserts []Vehicle
+	Deletes []uuid.UUID
+	// PruneOrphans - убрать из whitelist все номера синхронизации, не принадлежащие активным ТС
+	// (только при полной сверке без ограничения по организации)
+	PruneOrphans bool
+}
+
+// WhitelistChanges - как изменилось членство номеров в whitelist
+type WhitelistChanges struct {
+	Added   int64 `json:"added"`
+	Removed int64 `json:"removed"`
+}
+
+func (r *VehicleRepository) FindVehicles(ctx context.Context, filter VehicleFilter, limit, offset int) ([]Vehicle, error) {
+	query := r.db.WithContext(ctx).Model(&Vehicle{})
+	if filter.OrgID != nil {
+		query = query.Where("org_id = ?", *filter.OrgID)
+	}
+	if filter.ContractorID != nil {
+		query = query.Where("contractor_id = ?", *filter.ContractorID)
+	}
+	if filter.NormalizedPlate != nil {
+		query = query.Where("normalized_plate = ?", *filter.NormalizedPlate)
+	}
+	query = query.Order("normalized_plate, vehicle_id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	var vehicles []Vehicle
+	err := query.Find(&vehicles).Error
+	return vehicles, err
+}
+
+// ApplyVehicleChanges применяет изменения зеркала и приводит whitelist в соответствие:
+// номера активных ТС добавляются, номера, которые больше не принадлежат ни одному
+// активному ТС, удаляются (только членства, добавленные синхронизацией)
+func (r *VehicleRepository) ApplyVehicleChanges(ctx context.Context, changes VehicleChanges) (*WhitelistChanges, error) {
+	result := &WhitelistChanges{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Номера до изменений: их членство нужно пересмотреть
+		affected := make(map[uuid.UUID]struct{})
+		touched := make([]uuid.UUID, 0, len(changes.Upserts)+len(changes.Deletes))
+		for _, v := range changes.Upserts {
+			touched = append(touched, v.VehicleID)
+		}
+		touched = append(touched, changes.Deletes...)
+		if len(touched) > 0 {
+			var before []uuid.UUID
+			if err := tx.Model(&Vehicle{}).
+				Where("vehicle_id IN ? AND plate_id IS NOT NULL", touched).
+				Pluck("plate_id", &before).Error; err != nil {
+				return fmt.Errorf("load previous vehicle plates: %w", err)
+			}
+			for _, id := range before {
+				affected[id] = struct{}{}
+			}
+		}
+
+		now := time.Now()
+		for i := range changes.Upserts {
+			v := &changes.Upserts[i]
+			plateID, err := upsertPlate(tx, v.NormalizedPlate, v.PlateNumber)
+			if err != nil {
+				return err
+			}
+			v.PlateID = &plateID
+			affected[plateID] = struct{}{}
+			if v.CreatedAt.IsZero() {
+				v.CreatedAt = now
+			}
+			v.UpdatedAt = now
+		}
+		if len(changes.Upserts) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "vehicle_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"org_id", "contractor_id", "contractor_name", "plate_number", "normalized_plate",
+					"plate_id", "is_active", "source_updated_at", "updated_at",
+				}),
+			}).CreateInBatches(changes.Upserts, 500).Error
+			if err != nil {
+				return fmt.Errorf("upsert vehicles: %w", err)
+			}
+		}
+		if len(changes.Deletes) > 0 {
+			if err := tx.Where("vehicle_id IN ?", changes.Deletes).Delete(&Vehicle{}).Error; err != nil {
+				return fmt.Errorf("delete vehicles: %w", err)
+			}
+		}
+
+		plates := make([]uuid.UUID, 0, len(affected))
+		for id := range affected {
+			plates = append(plates, id)
+		}
+		if len(plates) == 0 && !changes.PruneOrphans {
+			return nil
+		}
+
+		if len(plates) > 0 {
+			added := tx.Exec(`
+				INSERT INTO anpr_list_items (list_id, plate_id, note, source)
+				SELECT l.id, v.plate_id, 'Автоматически добавлен из vehicles', ?
+				FROM anpr_lists l
+				CROSS JOIN (SELECT DISTINCT plate_id FROM anpr_vehicles WHERE is_active AND plate_id IN ?) v
+				WHERE l.name = ?
+				ON CONFLICT (list_id, plate_id) DO NOTHING`,
+				ListItemSourceVehicles, plates, VehicleWhitelistName)
+			if added.Error != nil {
+				return fmt.Errorf("add vehicle plates to whitelist: %w", added.Error)
+			}
+			result.Added = added.RowsAffected
+		}
+
+		remove := tx.Where("source = ?", ListItemSourceVehicles).
+			Where("list_id IN (?)", tx.Table("anpr_lists").Select("id").Where("name = ?", VehicleWhitelistName)).
+			Where("NOT EXISTS (SELECT 1 FROM anpr_vehicles v WHERE v.plate_id = anpr_list_items.plate_id AND v.is_active)")
+		if !changes.PruneOrphans {
+			remove = remove.Where("plate_id IN ?", plates)
+		}
+		removed := remove.Delete(&ListItem{})
+		if removed.Error != nil {
+			return fmt.Errorf("remove vehicle plates from whitelist: %w", removed.Error)
+		}
+		result.Removed = removed.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// upsertPlate возвращает id номера, создавая его при необходимости
+func upsertPlate(tx *gorm.DB, normalized, original string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Raw(`
+		INSERT INTO anpr_plates (number, normalized)
+		VALUES (?, ?)
+		ON CONFLICT (normalized) DO UPDATE SET normalized = EXCLUDED.normalized
+		RETURNING id`, original, normalized).Scan(&id).Error
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("upsert plate %s: %w", normalized, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,103 @@
+package roles
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/tracing"
+)
+
+// VehiclesPath - эндпоинт списка ТС сервиса roles
+const VehiclesPath = "/api/v1/vehicles"
+
+// Vehicle - ТС в формате сервиса roles
+type Vehicle struct {
+	ID             uuid.UUID  `json:"id"`
+	OrgID          uuid.UUID  `json:"organization_id"`
+	ContractorID   *uuid.UUID `json:"contractor_id,omitempty"`
+	ContractorName *string    `json:"contractor_name,omitempty"`
+	PlateNumber    string     `json:"plate_number"`
+	// IsActive - nil считается активным
+	IsActive  *bool      `json:"is_active,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func (v Vehicle) Active() bool {
+	return v.IsActive == nil || *v.IsActive
+}
+
+type vehiclesPage struct {
+	Data []Vehicle `json:"data"`
+}
+
+// Client читает полный список ТС из сервиса roles постранично (limit/offset)
+type Client struct {
+	baseURL  string
+	token    string
+	pageSize int
+	http     *http.Client
+}
+
+func NewClient(baseURL, token string, pageSize int, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		pageSize: pageSize,
+		http:     tracing.HTTPClient(timeout),
+	}
+}
+
+func (c *Client) ListVehicles(ctx context.Context) ([]Vehicle, error) {
+	var vehicles []Vehicle
+	for offset := 0; ; offset += c.pageSize {
+		page, err := c.fetchPage(ctx, offset)
+		if err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, page...)
+		if len(page) < c.pageSize {
+			return vehicles, nil
+		}
+	}
+}
+
+func (c *Client) fetchPage(ctx context.Context, offset int) ([]Vehicle, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(c.pageSize))
+	query.Set("offset", strconv.Itoa(offset))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+VehiclesPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("roles vehicles request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("roles vehicles request: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var page vehiclesPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decode roles vehicles: %w", err)
+	}
+	return page.Data, nil
+}
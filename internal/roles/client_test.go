@@ -0,0 +1,42 @@
+package roles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClientPaginatesStub(t *testing.T) {
+	vehicles := make([]Vehicle, 5)
+	for i := range vehicles {
+		vehicles[i] = Vehicle{ID: uuid.New(), OrgID: uuid.New(), PlateNumber: "123ABC0" + string(rune('0'+i))}
+	}
+	inactive := false
+	vehicles[4].IsActive = &inactive
+
+	file := filepath.Join(t.TempDir(), "vehicles.json")
+	raw, _ := json.Marshal(vehicles)
+	if err := os.WriteFile(file, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(NewStubHandler(file))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "token", 2, 5*time.Second).ListVehicles(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(vehicles) {
+		t.Fatalf("expected %d vehicles, got %d", len(vehicles), len(got))
+	}
+	if got[3].ID != vehicles[3].ID || !got[0].Active() || got[4].Active() {
+		t.Fatalf("unexpected vehicles: %+v", got)
+	}
+}
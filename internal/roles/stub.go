@@ -0,0 +1,46 @@
+package roles
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// NewStubHandler - локальная замена сервиса roles для разработки: отдаёт ТС из JSON-файла
+// (массив Vehicle) в том же формате и с той же пагинацией. Файл перечитывается на каждый
+// запрос, поэтому его правка имитирует изменения в реестре.
+func NewStubHandler(file string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(VehiclesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var vehicles []Vehicle
+		if err := json.Unmarshal(raw, &vehicles); err != nil {
+			http.Error(w, "invalid vehicles file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if offset < 0 || offset > len(vehicles) {
+			offset = len(vehicles)
+		}
+		end := len(vehicles)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(vehiclesPage{Data: vehicles[offset:end]})
+	})
+	return mux
+}
@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+const earthRadiusMeters = 6371000
+
+// AnomalyService строит профиль атрибутов ТС по каждому номеру и при приёме события
+// ищет признаки клонированного номера: противоречие профилю и невозможное перемещение между камерами
+type AnomalyService struct {
+	repo    *repository.AnomalyRepository
+	cameras *CameraService
+	cfg     config.AnomalyConfig
+	log     zerolog.Logger
+}
+
+func NewAnomalyService(repo *repository.AnomalyRepository, cameras *CameraService, cfg config.AnomalyConfig, log zerolog.Logger) *AnomalyService {
+	return &AnomalyService{
+		repo:    repo,
+		cameras: cameras,
+		cfg:     cfg,
+		log:     log,
+	}
+}
+
+// AttributeMismatch - атрибут события, противоречащий устойчивому значению из профиля
+type AttributeMismatch struct {
+	Attribute     string  `json:"attribute"`
+	Observed      string  `json:"observed"`
+	Expected      string  `json:"expected"`
+	ExpectedShare float64 `json:"expected_share"`
+	ObservedCount int64   `json:"observed_count"`
+	Observations  int64   `json:"observations"`
+}
+
+type travelDetails struct {
+	PreviousCameraID  string    `json:"previous_camera_id"`
+	PreviousEventTime time.Time `json:"previous_event_time"`
+	DistanceMeters    float64   `json:"distance_meters"`
+	ElapsedSeconds    float64   `json:"elapsed_seconds"`
+	SpeedKMH          *float64  `json:"speed_kmh,omitempty"`
+	MaxSpeedKMH       float64   `json:"max_speed_kmh"`
+}
+
+// Check проверяет только что сохранённое событие и возвращает типы найденных аномалий
+func (s *AnomalyService) Check(ctx context.Context, event *anpr.Event) ([]string, error) {
+	var found []string
+
+	attributes := profileAttributes(event.Vehicle)
+	if len(attributes) > 0 {
+		profile, err := s.repo.GetProfile(ctx, event.PlateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plate profile: %w", err)
+		}
+		mismatches := attributeMismatches(profile, attributes, s.cfg)
+		if len(mismatches) >= s.cfg.MinMismatches {
+			var score float64
+			for _, m := range mismatches {
+				score += m.ExpectedShare
+			}
+			score /= float64(len(mismatches))
+			if err := s.record(ctx, event, repository.AnomalyTypeAttributeMismatch, nil, score,
+				map[string]interface{}{"mismatches": mismatches}); err != nil {
+				return nil, err
+			}
+			found = append(found, repository.AnomalyTypeAttributeMismatch)
+		}
+		// Профиль пополняется после проверки, чтобы событие не сравнивалось само с собой
+		if err := s.repo.AddToProfile(ctx, event.PlateID, attributes, event.EventTime); err != nil {
+			return found, err
+		}
+	}
+
+	travel, err := s.checkTravel(ctx, event)
+	if err != nil {
+		return found, err
+	}
+	if travel {
+		found = append(found, repository.AnomalyTypeImpossibleTravel)
+	}
+	return found, nil
+}
+
+func (s *AnomalyService) checkTravel(ctx context.Context, event *anpr.Event) (bool, error) {
+	prev, err := s.repo.FindPreviousEvent(ctx, event.PlateID, event.ID, event.EventTime, event.EventTime.Add(-s.cfg.TravelWindow))
+	if err != nil {
+		return false, fmt.Errorf("failed to find previous plate event: %w", err)
+	}
+	if prev == nil || prev.CameraID == event.CameraID {
+		return false, nil
+	}
+
+	from, err := s.cameras.Lookup(ctx, prev.CameraID)
+	if err != nil {
+		return false, err
+	}
+	to, err := s.cameras.Lookup(ctx, event.CameraID)
+	if err != nil {
+		return false, err
+	}
+	if !hasLocation(from) || !hasLocation(to) {
+		return false, nil
+	}
+
+	distance := haversineMeters(*from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+	elapsed := event.EventTime.Sub(prev.EventTime)
+	speed, impossible := impossibleTravel(distance, elapsed, s.cfg)
+	if !impossible {
+		return false, nil
+	}
+
+	details := travelDetails{
+		PreviousCameraID:  prev.CameraID,
+		PreviousEventTime: prev.EventTime,
+		DistanceMeters:    math.Round(distance),
+		ElapsedSeconds:    elapsed.Seconds(),
+		MaxSpeedKMH:       s.cfg.MaxSpeedKMH,
+	}
+	// Отношение требуемой скорости к допустимой; одновременные события на разных камерах - максимум
+	score := 10.0
+	if !math.IsInf(speed, 1) {
+		rounded := math.Round(speed)
+		details.SpeedKMH = &rounded
+		score = math.Min(speed/s.cfg.MaxSpeedKMH, score)
+	}
+	if err := s.record(ctx, event, repository.AnomalyTypeImpossibleTravel, &prev.ID, score, details); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *AnomalyService) record(ctx context.Context, event *anpr.Event, anomalyType string, relatedEventID *uuid.UUID, score float64, details interface{}) error {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshal anomaly details: %w", err)
+	}
+	anomaly := &repository.Anomaly{
+		Type:            anomalyType,
+		Status:          repository.AnomalyStatusOpen,
+		PlateID:         event.PlateID,
+		NormalizedPlate: event.NormalizedPlate,
+		EventID:         event.ID,
+		RelatedEventID:  relatedEventID,
+		CameraID:        event.CameraID,
+		EventTime:       event.EventTime,
+		Score:           score,
+		Details:         raw,
+	}
+	if err := s.repo.CreateAnomaly(ctx, anomaly); err != nil {
+		return err
+	}
+	metrics.Anomalies.WithLabelValues(anomalyType).Inc()
+	s.log.Warn().
+		Str("anomaly_id", anomaly.ID.String()).
+		Str("type", anomalyType).
+		Str("plate", event.NormalizedPlate).
+		Str("camera_id", event.CameraID).
+		Str("event_id", event.ID.String()).
+		Float64("score", score).
+		Msg("plate anomaly detected")
+	return nil
+}
+
+// PairPlateless ищет предыдущий проезд того же ТС без номера: ближайший в пределах TravelWindow
+// на другой камере в том же направлении (если оно известно) с совпадающими признаками, ещё не
+// связанный с другим проездом, переезд к которому физически возможен.
+// Возвращает его идентификатор; nil - пары нет или признаков для сравнения недостаточно.
+func (s *AnomalyService) PairPlateless(ctx context.Context, event *anpr.Event) (*uuid.UUID, error) {
+	attributes, ok := platelessPairAttributes(event.Vehicle)
+	if !ok {
+		return nil, nil
+	}
+	direction := strings.ToLower(strings.TrimSpace(event.Direction))
+	prev, err := s.repo.FindPlatelessPredecessor(ctx, event.CameraID, direction, attributes, event.EventTime, event.EventTime.Add(-s.cfg.TravelWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find previous plateless passage: %w", err)
+	}
+	if prev == nil {
+		return nil, nil
+	}
+
+	from, err := s.cameras.Lookup(ctx, prev.CameraID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.cameras.Lookup(ctx, event.CameraID)
+	if err != nil {
+		return nil, err
+	}
+	// Проезд, до которого нельзя успеть доехать, - другое ТС с такими же признаками
+	if hasLocation(from) && hasLocation(to) {
+		distance := haversineMeters(*from.Latitude, *from.Longitude, *to.Latitude, *to.Longitude)
+		if _, impossible := impossibleTravel(distance, event.EventTime.Sub(prev.EventTime), s.cfg); impossible {
+			return nil, nil
+		}
+	}
+	return &prev.ID, nil
+}
+
+// platelessPairAttributes - признаки проезда без номера для поиска пары. Без типа и цвета ТС
+// пара слишком неоднозначна и не ищется.
+func platelessPairAttributes(v anpr.VehicleInfo) (map[string]string, bool) {
+	attributes := profileAttributes(v)
+	if attributes["vehicle_type"] == "" || attributes["vehicle_color"] == "" {
+		return nil, false
+	}
+	return attributes, true
+}
+
+// profileAttributes возвращает нормализованные атрибуты ТС, пригодные для профиля
+func profileAttributes(v anpr.VehicleInfo) map[string]string {
+	attributes := make(map[string]string, 4)
+	for attribute, value := range map[string]string{
+		"vehicle_type":  v.Type,
+		"vehicle_color": v.Color,
+		"vehicle_brand": v.Brand,
+		"vehicle_model": v.Model,
+	} {
+		value = strings.ToLower(strings.TrimSpace(value))
+		// Камера возвращает unknown/other, когда не смогла классифицировать ТС
+		if value == "" || value == "unknown" || value == "other" {
+			continue
+		}
+		attributes[attribute] = value
+	}
+	return attributes
+}
+
+// attributeMismatches сравнивает атрибуты события с профилем номера. Противоречием считается
+// значение, отличное от устойчивого (доля >= DominantShare при >= MinObservations наблюдениях)
+// и само почти не встречавшееся у номера.
+func attributeMismatches(profile []repository.PlateProfileEntry, attributes map[string]string, cfg config.AnomalyConfig) []AttributeMismatch {
+	type distribution struct {
+		total    int64
+		top      string
+		topCount int64
+		counts   map[string]int64
+	}
+	byAttribute := make(map[string]*distribution)
+	for _, entry := range profile {
+		d, ok := byAttribute[entry.Attribute]
+		if !ok {
+			d = &distribution{counts: make(map[string]int64)}
+			byAttribute[entry.Attribute] = d
+		}
+		d.total += entry.Count
+		d.counts[entry.Value] += entry.Count
+		if entry.Count > d.topCount || (entry.Count == d.topCount && entry.Value < d.top) {
+			d.top, d.topCount = entry.Value, entry.Count
+		}
+	}
+
+	var mismatches []AttributeMismatch
+	for attribute, observed := range attributes {
+		d, ok := byAttribute[attribute]
+		if !ok || d.total < cfg.MinObservations || observed == d.top {
+			continue
+		}
+		share := float64(d.topCount) / float64(d.total)
+		if share < cfg.DominantShare {
+			continue
+		}
+		// Значение, которое уже заметно встречалось у номера, не считается противоречием
+		if float64(d.counts[observed])/float64(d.total) >= 1-cfg.DominantShare {
+			continue
+		}
+		mismatches = append(mismatches, AttributeMismatch{
+			Attribute:     attribute,
+			Observed:      observed,
+			Expected:      d.top,
+			ExpectedShare: math.Round(share*1000) / 1000,
+			ObservedCount: d.counts[observed],
+			Observations:  d.total,
+		})
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Attribute < mismatches[j].Attribute })
+	return mismatches
+}
+
+// impossibleTravel возвращает требуемую скорость в км/ч и признак невозможного перемещения
+func impossibleTravel(distanceMeters float64, elapsed time.Duration, cfg config.AnomalyConfig) (float64, bool) {
+	if distanceMeters < cfg.MinDistanceMeters {
+		return 0, false
+	}
+	if elapsed <= 0 {
+		return math.Inf(1), true
+	}
+	speed := distanceMeters / 1000 / elapsed.Hours()
+	return speed, speed > cfg.MaxSpeedKMH
+}
+
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+func hasLocation(c *repository.Camera) bool {
+	return c != nil && c.Latitude != nil && c.Longitude != nil
+}
+
+// AnomalyQuery - фильтры GET /anomalies
+type AnomalyQuery struct {
+	Type     *string
+	Status   *string
+	Plate    *string
+	PlateID  *string
+	CameraID *string
+	From     *string
+	To       *string
+}
+
+type AnomalyInfo struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	PlateID         string          `json:"plate_id"`
+	NormalizedPlate string          `json:"normalized_plate"`
+	EventID         string          `json:"event_id"`
+	RelatedEventID  *string         `json:"related_event_id,omitempty"`
+	CameraID        string          `json:"camera_id"`
+	EventTime       time.Time       `json:"event_time"`
+	Score           float64         `json:"score"`
+	Details         json.RawMessage `json:"details"`
+	ResolvedBy      *string         `json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time      `json:"resolved_at,omitempty"`
+	ResolutionNote  *string         `json:"resolution_note,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func (s *AnomalyService) FindAnomalies(ctx context.Context, q AnomalyQuery, limit, offset int) ([]AnomalyInfo, error) {
+	var filter repository.AnomalyFilter
+	if q.Type != nil {
+		if *q.Type != repository.AnomalyTypeAttributeMismatch && *q.Type != repository.AnomalyTypeImpossibleTravel {
+			return nil, fmt.Errorf("%w: unknown anomaly type", ErrInvalidInput)
+		}
+		filter.Type = q.Type
+	}
+	if q.Status != nil {
+		if !validAnomalyStatus(*q.Status) {
+			return nil, fmt.Errorf("%w: unknown anomaly status", ErrInvalidInput)
+		}
+		filter.Status = q.Status
+	}
+	if q.Plate != nil {
+		if normalized := utils.NormalizePlate(*q.Plate); normalized != "" {
+			filter.NormalizedPlate = &normalized
+		}
+	}
+	if q.PlateID != nil {
+		id, err := uuid.Parse(*q.PlateID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid plate_id", ErrInvalidInput)
+		}
+		filter.PlateID = &id
+	}
+	filter.CameraID = q.CameraID
+
+	var err error
+	if filter.From, err = parseOptionalTime(q.From, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseOptionalTime(q.To, "to"); err != nil {
+		return nil, err
+	}
+
+	anomalies, err := s.repo.FindAnomalies(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find anomalies: %w", err)
+	}
+	result := make([]AnomalyInfo, 0, len(anomalies))
+	for _, a := range anomalies {
+		result = append(result, anomalyInfo(a))
+	}
+	return result, nil
+}
+
+func (s *AnomalyService) GetAnomaly(ctx context.Context, id string) (*AnomalyInfo, error) {
+	anomalyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid anomaly id", ErrInvalidInput)
+	}
+	anomaly, err := s.repo.GetAnomaly(ctx, anomalyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomaly: %w", err)
+	}
+	if anomaly == nil {
+		return nil, fmt.Errorf("%w: anomaly not found", ErrNotFound)
+	}
+	info := anomalyInfo(*anomaly)
+	return &info, nil
+}
+
+// ResolveAnomaly фиксирует решение оператора: confirmed - номер действительно клонирован,
+// dismissed - ложное срабатывание, open - вернуть на рассмотрение
+func (s *AnomalyService) ResolveAnomaly(ctx context.Context, id, status string, note *string, resolvedBy *uuid.UUID) (*AnomalyInfo, error) {
+	if !validAnomalyStatus(status) {
+		return nil, fmt.Errorf("%w: status must be open, confirmed or dismissed", ErrInvalidInput)
+	}
+	current, err := s.GetAnomaly(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	anomalyID, _ := uuid.Parse(current.ID)
+	if err := s.repo.ResolveAnomaly(ctx, anomalyID, status, resolvedBy, note); err != nil {
+		return nil, fmt.Errorf("failed to resolve anomaly: %w", err)
+	}
+	return s.GetAnomaly(ctx, id)
+}
+
+func validAnomalyStatus(status string) bool {
+	switch status {
+	case repository.AnomalyStatusOpen, repository.AnomalyStatusConfirmed, repository.AnomalyStatusDismissed:
+		return true
+	}
+	return false
+}
+
+func anomalyInfo(a repository.Anomaly) AnomalyInfo {
+	info := AnomalyInfo{
+		ID:              a.ID.String(),
+		Type:            a.Type,
+		Status:          a.Status,
+		PlateID:         a.PlateID.String(),
+		NormalizedPlate: a.NormalizedPlate,
+		EventID:         a.EventID.String(),
+		CameraID:        a.CameraID,
+		EventTime:       a.EventTime,
+		Score:           a.Score,
+		Details:         json.RawMessage(a.Details),
+		ResolvedAt:      a.ResolvedAt,
+		ResolutionNote:  a.ResolutionNote,
+		CreatedAt:       a.CreatedAt,
+	}
+	if a.RelatedEventID != nil {
+		id := a.RelatedEventID.String()
+		info.RelatedEventID = &id
+	}
+	if a.ResolvedBy != nil {
+		id := a.ResolvedBy.String()
+		info.ResolvedBy = &id
+	}
+	return info
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+var testAnomalyConfig = config.AnomalyConfig{
+	MinObservations:   5,
+	DominantShare:     0.8,
+	MinMismatches:     2,
+	MaxSpeedKMH:       150,
+	MinDistanceMeters: 1000,
+}
+
+func profileEntries(attribute string, counts map[string]int64) []repository.PlateProfileEntry {
+	plateID := uuid.New()
+	var entries []repository.PlateProfileEntry
+	for value, count := range counts {
+		entries = append(entries, repository.PlateProfileEntry{PlateID: plateID, Attribute: attribute, Value: value, Count: count})
+	}
+	return entries
+}
+
+func TestAttributeMismatches(t *testing.T) {
+	profile := append(profileEntries("vehicle_color", map[string]int64{"orange": 19, "white": 1}),
+		profileEntries("vehicle_type", map[string]int64{"truck": 20})...)
+	profile = append(profile, profileEntries("vehicle_brand", map[string]int64{"isuzu": 3})...)
+
+	attributes := profileAttributes(anpr.VehicleInfo{Color: " White ", Type: "sedan", Brand: "toyota", Model: "unknown"})
+	if _, ok := attributes["vehicle_model"]; ok {
+		t.Fatal("unknown value must be skipped")
+	}
+
+	mismatches := attributeMismatches(profile, attributes, testAnomalyConfig)
+	// brand не проверяется: всего 3 наблюдения
+	if len(mismatches) != 2 {
+		t.Fatalf("expected 2 mismatches, got %+v", mismatches)
+	}
+	if mismatches[0].Attribute != "vehicle_color" || mismatches[0].Expected != "orange" || mismatches[0].Observed != "white" {
+		t.Fatalf("unexpected color mismatch: %+v", mismatches[0])
+	}
+	if mismatches[1].Attribute != "vehicle_type" || mismatches[1].ExpectedShare != 1 {
+		t.Fatalf("unexpected type mismatch: %+v", mismatches[1])
+	}
+
+	// Значение, уже заметно встречавшееся у номера, не противоречит профилю
+	mixed := profileEntries("vehicle_color", map[string]int64{"orange": 16, "white": 4})
+	if got := attributeMismatches(mixed, map[string]string{"vehicle_color": "white"}, testAnomalyConfig); len(got) != 0 {
+		t.Fatalf("expected no mismatches for known value, got %+v", got)
+	}
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	// Около 11 км между точками Алматы
+	distance := haversineMeters(43.2567, 76.9286, 43.2380, 77.0560)
+	if distance < 10000 || distance > 11500 {
+		t.Fatalf("unexpected distance %f", distance)
+	}
+
+	if _, impossible := impossibleTravel(distance, 10*time.Minute, testAnomalyConfig); impossible {
+		t.Fatal("10 km in 10 minutes is possible")
+	}
+	speed, impossible := impossibleTravel(distance, time.Minute, testAnomalyConfig)
+	if !impossible || speed < 600 {
+		t.Fatalf("10 km in a minute must be impossible, speed %f", speed)
+	}
+	if speed, impossible := impossibleTravel(distance, 0, testAnomalyConfig); !impossible || !math.IsInf(speed, 1) {
+		t.Fatal("simultaneous reads at distant cameras must be impossible")
+	}
+	if _, impossible := impossibleTravel(500, 0, testAnomalyConfig); impossible {
+		t.Fatal("nearby cameras must not be checked")
+	}
+}
+
+func TestPlatelessPairAttributes(t *testing.T) {
+	attributes, ok := platelessPairAttributes(anpr.VehicleInfo{Type: "Truck", Color: " White ", Brand: "unknown"})
+	if !ok {
+		t.Fatal("type and color must be enough to pair")
+	}
+	if attributes["vehicle_type"] != "truck" || attributes["vehicle_color"] != "white" {
+		t.Fatalf("unexpected attributes %+v", attributes)
+	}
+	if _, found := attributes["vehicle_brand"]; found {
+		t.Fatal("unknown brand must not be compared")
+	}
+
+	for _, v := range []anpr.VehicleInfo{
+		{},
+		{Type: "truck"},
+		{Color: "white", Brand: "volvo"},
+		{Type: "other", Color: "white"},
+	} {
+		if _, ok := platelessPairAttributes(v); ok {
+			t.Errorf("%+v: pairing without type and color must be skipped", v)
+		}
+	}
+}
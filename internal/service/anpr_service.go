@@ -8,9 +8,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/logger"
+	"anpr-service/internal/metrics"
 	"anpr-service/internal/repository"
+	"anpr-service/internal/tracing"
 	"anpr-service/internal/utils"
 )
 
@@ -21,47 +26,108 @@ var (
 
 type ANPRService struct {
 	repo *repository.ANPRRepository
-	log  zerolog.Logger
+	// cameras - реестр камер: по нему событию назначаются полигон и пороги уверенности
+	cameras *CameraService
+	// anomalies - проверка событий на клонированные номера; nil - проверка выключена
+	anomalies *AnomalyService
+	// pairing - поиск пары проездов без номера; работает и при выключенной проверке аномалий
+	pairing *AnomalyService
+	// reviews - очередь проверки номеров вне списков
+	reviews *ReviewService
+	// archiver - если задан, ручное удаление событий сначала выгружает их в архив
+	archiver *ArchiveService
+	events   *EventBus
+	log      zerolog.Logger
 }
 
-func NewANPRService(repo *repository.ANPRRepository, log zerolog.Logger) *ANPRService {
+func NewANPRService(repo *repository.ANPRRepository, cameras *CameraService, anomalies, pairing *AnomalyService, reviews *ReviewService, archiver *ArchiveService, log zerolog.Logger) *ANPRService {
 	return &ANPRService{
-		repo: repo,
-		log:  log,
+		repo:      repo,
+		cameras:   cameras,
+		anomalies: anomalies,
+		pairing:   pairing,
+		reviews:   reviews,
+		archiver:  archiver,
+		events:    NewEventBus(),
+		log:       log,
 	}
 }
 
-func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (*anpr.ProcessResult, error) {
-	if payload.Plate == "" {
-		return nil, fmt.Errorf("%w: plate is required", ErrInvalidInput)
+func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (result *anpr.ProcessResult, err error) {
+	ctx, span := tracing.Start(ctx, "ANPRService.ProcessIncomingEvent", trace.WithAttributes(
+		attribute.String("anpr.camera_id", payload.CameraID),
+		attribute.String("anpr.raw_plate", payload.Plate),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Уверенность в JSON и gRPC - доля 0–1 или проценты (1, 100]; проценты Hikvision переводит разбор XML
+	var ok bool
+	if payload.Confidence, ok = utils.NormalizeConfidence(payload.Confidence); !ok {
+		metrics.Reject(payload.CameraID, metrics.RejectInvalidPayload)
+		return nil, fmt.Errorf("%w: confidence must be between 0 and 100", ErrInvalidInput)
+	}
+	for i := range payload.Candidates {
+		if payload.Candidates[i].Confidence, ok = utils.NormalizeConfidence(payload.Candidates[i].Confidence); !ok {
+			metrics.Reject(payload.CameraID, metrics.RejectInvalidPayload)
+			return nil, fmt.Errorf("%w: candidate confidence must be between 0 and 100", ErrInvalidInput)
+		}
+	}
+
+	// Без основного прочтения основным становится лучший из кандидатов
+	candidates := plateCandidates(utils.NormalizePlate(payload.Plate), payload.Candidates)
+	if utils.NormalizePlate(payload.Plate) == "" && len(candidates) > 0 {
+		payload.Plate = candidates[0].Plate
+		if payload.Confidence == 0 {
+			payload.Confidence = candidates[0].Confidence
+		}
+		candidates = candidates[1:]
+	}
+	payload.Candidates = candidates
+
+	// Пустое прочтение - проезд без номера: сохраняется как улика, а не отклоняется
+	if utils.NormalizePlate(payload.Plate) == "" {
+		return s.ProcessPlatelessPassage(ctx, payload, defaultCameraModel)
 	}
 	if payload.CameraID == "" {
+		metrics.Reject(payload.CameraID, metrics.RejectMissingCamera)
 		return nil, fmt.Errorf("%w: camera_id is required", ErrInvalidInput)
 	}
 	if payload.EventTime.IsZero() {
+		metrics.Reject(payload.CameraID, metrics.RejectMissingEventTime)
 		return nil, fmt.Errorf("%w: event_time is required", ErrInvalidInput)
 	}
 
 	normalized := utils.NormalizePlate(payload.Plate)
-	if normalized == "" {
-		return nil, fmt.Errorf("%w: plate cannot be empty after normalization", ErrInvalidInput)
+
+	if payload.ReceivedAt.IsZero() {
+		payload.ReceivedAt = time.Now()
+	}
+	if payload.TimeSource == "" {
+		payload.TimeSource = anpr.TimeSourceCamera
 	}
 
+	// Логгер запроса дополняем камерой и номером, чтобы их несли и логи репозитория
+	log := logger.FromContext(ctx, s.log).With().
+		Str("camera_id", payload.CameraID).
+		Str("plate", normalized).
+		Logger()
+	ctx = logger.WithContext(ctx, log)
+
 	plateID, err := s.repo.GetOrCreatePlate(ctx, normalized, payload.Plate)
 	if err != nil {
-		s.log.Error().
+		metrics.Reject(payload.CameraID, metrics.RejectDBError)
+		log.Error().
 			Err(err).
-			Str("normalized", normalized).
 			Str("original", payload.Plate).
 			Msg("failed to get or create plate")
 		return nil, fmt.Errorf("failed to get or create plate: %w", err)
 	}
-	
-	s.log.Info().
+
+	log.Info().
 		Str("plate_id", plateID.String()).
-		Str("normalized", normalized).
 		Str("original", payload.Plate).
 		Msg("plate retrieved or created successfully")
+	span.SetAttributes(attribute.String("anpr.plate_id", plateID.String()))
 
 	cameraModel := payload.CameraModel
 	if cameraModel == "" {
@@ -100,58 +166,152 @@ func (s *ANPRService) ProcessIncomingEvent(ctx context.Context, payload anpr.Eve
 		}
 	}
 
+	camera := s.annotateEvent(ctx, event, log)
+	event.ReadStatus = s.cameras.ReadStatus(camera, payload.Confidence)
+	span.SetAttributes(attribute.String("anpr.read_status", event.ReadStatus))
+
 	if err := s.repo.CreateANPREvent(ctx, event); err != nil {
-		s.log.Error().
+		metrics.Reject(payload.CameraID, metrics.RejectDBError)
+		log.Error().
 			Err(err).
-			Str("plate", normalized).
-			Str("camera_id", payload.CameraID).
 			Msg("failed to create ANPR event")
 		return nil, fmt.Errorf("failed to create ANPR event: %w", err)
 	}
 
-	s.log.Info().
+	log.Info().
 		Str("event_id", event.ID.String()).
 		Str("plate_id", plateID.String()).
-		Str("plate", normalized).
 		Str("raw_plate", payload.Plate).
-		Str("camera_id", payload.CameraID).
 		Time("event_time", payload.EventTime).
 		Msg("saved ANPR event to database")
 
+	metrics.EventsAccepted.WithLabelValues(metrics.CameraLabel(payload.CameraID)).Inc()
+	if dropped := s.events.Publish(eventInfoFromDomain(event)); dropped > 0 {
+		log.Warn().Int("dropped", dropped).Msg("event watchers are too slow, event dropped")
+	}
+	if payload.Confidence > 0 {
+		metrics.Confidence.WithLabelValues(metrics.CameraLabel(payload.CameraID)).Observe(payload.Confidence)
+	}
+	metrics.ReadOutcomes.WithLabelValues(metrics.CameraLabel(payload.CameraID), event.ReadStatus).Inc()
+
+	// Непроверенное прочтение только сохраняется: без сверки со списками, аномалий и очереди проверки
+	if event.ReadStatus == anpr.ReadStatusUnverified {
+		log.Info().
+			Str("event_id", event.ID.String()).
+			Float64("confidence", payload.Confidence).
+			Msg("low confidence read stored as unverified")
+		return &anpr.ProcessResult{
+			EventID:    event.ID,
+			PlateID:    plateID,
+			Plate:      normalized,
+			ReadStatus: event.ReadStatus,
+			Hits:       []anpr.ListHit{},
+		}, nil
+	}
+	// Событие неподтверждённого источника может быть подделкой: оно сохраняется, но не вызывает срабатываний
+	if payload.Auth == anpr.AuthUntrusted {
+		log.Warn().
+			Str("event_id", event.ID.String()).
+			Msg("event from unauthenticated source stored as untrusted")
+		return &anpr.ProcessResult{
+			EventID:    event.ID,
+			PlateID:    plateID,
+			Plate:      normalized,
+			ReadStatus: event.ReadStatus,
+			Hits:       []anpr.ListHit{},
+		}, nil
+	}
+
+	// Ошибка проверки аномалий не должна приводить к отказу в приёме: событие уже сохранено
+	var anomalies []string
+	if s.anomalies != nil {
+		anomalies, err = s.anomalies.Check(ctx, event)
+		if err != nil {
+			log.Error().Err(err).Str("event_id", event.ID.String()).Msg("failed to check event for anomalies")
+		}
+	}
+
 	hits, err := s.repo.FindListsForPlate(ctx, plateID)
 	if err != nil {
-		s.log.Error().
+		log.Error().
 			Err(err).
 			Str("plate_id", plateID.String()).
 			Msg("failed to find lists for plate")
 		return nil, fmt.Errorf("failed to find lists for plate: %w", err)
 	}
 
+	for i := range hits {
+		hits[i].MatchedPlate = normalized
+	}
+
+	// Альтернативные прочтения сверяются только с существующими номерами; ошибка не отменяет приём
+	if matchable := s.cameras.MatchCandidates(camera, payload.Candidates); len(matchable) > 0 {
+		plates := make([]string, 0, len(matchable))
+		for _, c := range matchable {
+			plates = append(plates, c.Plate)
+		}
+		candidateHits, err := s.repo.FindListsForCandidates(ctx, plates)
+		if err != nil {
+			log.Error().Err(err).Strs("candidates", plates).Msg("failed to find lists for plate candidates")
+		} else {
+			hits = mergeCandidateHits(hits, candidateHits, payload.Candidates)
+		}
+	}
+
+	for _, hit := range hits {
+		if hit.CandidateRank > 0 {
+			metrics.CandidateListHits.WithLabelValues(hit.ListType).Inc()
+			continue
+		}
+		metrics.ListHits.WithLabelValues(hit.ListType).Inc()
+	}
+
+	span.SetAttributes(attribute.Int("anpr.list_hits", len(hits)))
+	// Без записи совпадения событие попадёт под политику хранения обычных прочтений, поэтому
+	// ошибка громкая, но приём не отменяет: событие уже сохранено
+	if err := s.repo.RecordListHits(ctx, event.ID, hits); err != nil {
+		log.Error().Err(err).Str("event_id", event.ID.String()).Msg("failed to record event list hits for retention")
+	}
 	if len(hits) > 0 {
-		s.log.Info().
+		log.Info().
 			Str("plate_id", plateID.String()).
-			Str("plate", normalized).
 			Int("hits_count", len(hits)).
 			Msg("plate found in lists")
 		for _, hit := range hits {
-			s.log.Debug().
+			log.Debug().
 				Str("list_id", hit.ListID.String()).
 				Str("list_name", hit.ListName).
 				Str("list_type", hit.ListType).
+				Str("matched_plate", hit.MatchedPlate).
+				Int("candidate_rank", hit.CandidateRank).
 				Msg("list hit")
 		}
 	} else {
-		s.log.Debug().
+		log.Debug().
 			Str("plate_id", plateID.String()).
-			Str("plate", normalized).
 			Msg("plate not found in any lists")
 	}
 
+	// Номер вне списков на полигоне - незарегистрированная машина; ошибка очереди не отменяет приём
+	var reviewItemID *uuid.UUID
+	if len(hits) == 0 {
+		var reviewErr error
+		reviewItemID, reviewErr = s.reviews.Enqueue(ctx, event)
+		if reviewErr != nil {
+			log.Error().Err(reviewErr).Str("event_id", event.ID.String()).Msg("failed to queue unknown plate for review")
+		} else if reviewItemID != nil {
+			log.Info().Str("review_item_id", reviewItemID.String()).Msg("unknown plate queued for review")
+		}
+	}
+
 	return &anpr.ProcessResult{
-		EventID: event.ID,
-		PlateID: plateID,
-		Plate:   normalized,
-		Hits:    hits,
+		EventID:      event.ID,
+		PlateID:      plateID,
+		Plate:        normalized,
+		ReadStatus:   event.ReadStatus,
+		Hits:         hits,
+		Anomalies:    anomalies,
+		ReviewItemID: reviewItemID,
 	}, nil
 }
 
@@ -161,19 +321,18 @@ func (s *ANPRService) FindPlates(ctx context.Context, plateQuery string) ([]Plat
 		return nil, fmt.Errorf("%w: plate query cannot be empty", ErrInvalidInput)
 	}
 
-	plates, err := s.repo.FindPlatesByNormalized(ctx, normalized)
+	plates, err := s.repo.FindPlatesWithLastEvent(ctx, normalized)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find plates: %w", err)
 	}
 
 	result := make([]PlateInfo, 0, len(plates))
 	for _, p := range plates {
-		lastEventTime, _ := s.repo.GetLastEventTimeForPlate(ctx, p.ID)
 		info := PlateInfo{
 			ID:            p.ID.String(),
 			Number:        p.Number,
 			Normalized:    p.Normalized,
-			LastEventTime: lastEventTime,
+			LastEventTime: p.LastEventTime,
 		}
 		result = append(result, info)
 	}
@@ -181,90 +340,38 @@ func (s *ANPRService) FindPlates(ctx context.Context, plateQuery string) ([]Plat
 	return result, nil
 }
 
-func (s *ANPRService) FindEvents(ctx context.Context, plateQuery *string, from, to *string, limit, offset int) ([]EventInfo, error) {
-	var normalizedPlate *string
-	if plateQuery != nil {
-		normalized := utils.NormalizePlate(*plateQuery)
-		if normalized != "" {
-			normalizedPlate = &normalized
-		}
-	}
-
-	var fromTime, toTime *time.Time
-	if from != nil && *from != "" {
-		t, err := time.Parse(time.RFC3339, *from)
-		if err != nil {
-			return nil, fmt.Errorf("%w: invalid from time format", ErrInvalidInput)
-		}
-		fromTime = &t
-	}
-	if to != nil && *to != "" {
-		t, err := time.Parse(time.RFC3339, *to)
-		if err != nil {
-			return nil, fmt.Errorf("%w: invalid to time format", ErrInvalidInput)
-		}
-		toTime = &t
-	}
+// Events возвращает шину принятых событий
+func (s *ANPRService) Events() *EventBus {
+	return s.events
+}
 
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 100 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
+// CheckPlate проверяет номер по спискам, не создавая номер и событие
+func (s *ANPRService) CheckPlate(ctx context.Context, plateQuery string) (*PlateCheck, error) {
+	normalized := utils.NormalizePlate(plateQuery)
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: plate query cannot be empty", ErrInvalidInput)
 	}
 
-	events, err := s.repo.FindEvents(ctx, normalizedPlate, fromTime, toTime, limit, offset)
+	result := &PlateCheck{Plate: normalized, Hits: []anpr.ListHit{}}
+	plates, err := s.repo.FindPlatesByNormalized(ctx, normalized)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find events: %w", err)
+		return nil, fmt.Errorf("failed to find plates: %w", err)
 	}
-
-	result := make([]EventInfo, 0, len(events))
-	for _, e := range events {
-		var plateID *string
-		if e.PlateID != nil {
-			id := e.PlateID.String()
-			plateID = &id
-		}
-		info := EventInfo{
-			ID:                e.ID.String(),
-			PlateID:           plateID,
-			CameraID:          e.CameraID,
-			CameraModel:       e.CameraModel,
-			Direction:         e.Direction,
-			Lane:              e.Lane,
-			RawPlate:          e.RawPlate,
-			NormalizedPlate:   e.NormalizedPlate,
-			Confidence:        e.Confidence,
-			VehicleColor:      e.VehicleColor,
-			VehicleType:       e.VehicleType,
-			VehicleBrand:      e.VehicleBrand,
-			VehicleModel:      e.VehicleModel,
-			VehicleCountry:    e.VehicleCountry,
-			VehiclePlateColor: e.VehiclePlateColor,
-			VehicleSpeed:      e.VehicleSpeed,
-			SnapshotURL:       e.SnapshotURL,
-			EventTime:         e.EventTime,
-		}
-		result = append(result, info)
+	if len(plates) == 0 {
+		return result, nil
 	}
 
-	return result, nil
-}
-
-// CleanupOldEvents удаляет события старше указанного количества дней
-func (s *ANPRService) CleanupOldEvents(ctx context.Context, days int) (int64, error) {
-	deleted, err := s.repo.DeleteOldEvents(ctx, days)
+	plateID := plates[0].ID.String()
+	result.Known = true
+	result.PlateID = &plateID
+	hits, err := s.repo.FindListsForPlate(ctx, plates[0].ID)
 	if err != nil {
-		s.log.Error().Err(err).Int("days", days).Msg("failed to cleanup old events")
-		return 0, err
+		return nil, fmt.Errorf("failed to find lists for plate: %w", err)
 	}
-	if deleted > 0 {
-		s.log.Info().Int64("deleted_count", deleted).Int("days", days).Msg("cleaned up old events")
+	if hits != nil {
+		result.Hits = hits
 	}
-	return deleted, nil
+	return result, nil
 }
 
 // SyncVehicleToWhitelist синхронизирует номер транспортного средства в whitelist
@@ -284,42 +391,79 @@ func (s *ANPRService) SyncVehicleToWhitelist(ctx context.Context, plateNumber st
 	return plateID, nil
 }
 
-func (s *ANPRService) DeleteOldEvents(ctx context.Context, days int) (int64, error) {
+// DeleteOldEvents удаляет события старше days дней. С архиватором удаляются только события,
+// попавшие в загруженный архив; возвращается и id архива (пусто - архив не создавался).
+func (s *ANPRService) DeleteOldEvents(ctx context.Context, days int) (int64, string, error) {
 	if days < 1 {
-		return 0, fmt.Errorf("%w: days must be >= 1", ErrInvalidInput)
+		return 0, "", fmt.Errorf("%w: days must be >= 1", ErrInvalidInput)
 	}
 
-	deletedCount, err := s.repo.DeleteOldEvents(ctx, days)
+	var deletedCount int64
+	var archiveID string
+	var err error
+	if s.archiver != nil {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		deletedCount, archiveID, err = s.archiveAndDelete(ctx, ArchiveReasonDeleteOld, &cutoff)
+	} else {
+		deletedCount, err = s.repo.DeleteOldEvents(ctx, days)
+	}
 	if err != nil {
 		s.log.Error().
 			Err(err).
 			Int("days", days).
 			Msg("failed to delete old events")
-		return 0, fmt.Errorf("failed to delete old events: %w", err)
+		return 0, "", fmt.Errorf("failed to delete old events: %w", err)
 	}
 
 	s.log.Info().
 		Int("days", days).
 		Int64("deleted_count", deletedCount).
+		Str("archive_id", archiveID).
 		Msg("deleted old events")
 
-	return deletedCount, nil
+	return deletedCount, archiveID, nil
 }
 
-func (s *ANPRService) DeleteAllEvents(ctx context.Context) (int64, error) {
-	deletedCount, err := s.repo.DeleteAllEvents(ctx)
+// DeleteAllEvents удаляет все события, с архиватором - после выгрузки в архив
+func (s *ANPRService) DeleteAllEvents(ctx context.Context) (int64, string, error) {
+	var deletedCount int64
+	var archiveID string
+	var err error
+	if s.archiver != nil {
+		deletedCount, archiveID, err = s.archiveAndDelete(ctx, ArchiveReasonDeleteAll, nil)
+	} else {
+		deletedCount, err = s.repo.DeleteAllEvents(ctx)
+	}
 	if err != nil {
 		s.log.Error().
 			Err(err).
 			Msg("failed to delete all events")
-		return 0, fmt.Errorf("failed to delete all events: %w", err)
+		return 0, "", fmt.Errorf("failed to delete all events: %w", err)
 	}
 
 	s.log.Warn().
 		Int64("deleted_count", deletedCount).
+		Str("archive_id", archiveID).
 		Msg("deleted ALL events")
 
-	return deletedCount, nil
+	return deletedCount, archiveID, nil
+}
+
+// archiveAndDelete выгружает события, созданные до before, в архив и удаляет ровно заархивированные:
+// события, пришедшие во время выгрузки, остаются
+func (s *ANPRService) archiveAndDelete(ctx context.Context, reason string, before *time.Time) (int64, string, error) {
+	source := func(ctx context.Context, after *repository.EventCursor, limit int) ([]repository.ANPREvent, error) {
+		return s.repo.NextEventsCreatedBefore(ctx, before, after, limit)
+	}
+	info, ids, err := s.archiver.Export(ctx, reason, nil, nil, source)
+	if err != nil {
+		return 0, "", fmt.Errorf("archive before delete: %w", err)
+	}
+	if info == nil {
+		return 0, "", nil
+	}
+	deleted, err := s.repo.DeleteEventsByID(ctx, ids)
+	return deleted, info.ID, err
 }
 
 type PlateInfo struct {
@@ -329,6 +473,13 @@ type PlateInfo struct {
 	LastEventTime *time.Time `json:"last_event_time,omitempty"`
 }
 
+type PlateCheck struct {
+	Plate   string         `json:"plate"`
+	Known   bool           `json:"known"`
+	PlateID *string        `json:"plate_id,omitempty"`
+	Hits    []anpr.ListHit `json:"hits"`
+}
+
 type EventInfo struct {
 	ID                string    `json:"id"`
 	PlateID           *string   `json:"plate_id,omitempty"`
@@ -348,4 +499,94 @@ type EventInfo struct {
 	VehicleSpeed      *float64  `json:"vehicle_speed,omitempty"`
 	SnapshotURL       *string   `json:"snapshot_url,omitempty"`
 	EventTime         time.Time `json:"event_time"`
+	// Исправление номера оператором
+	OriginalPlateID  *string    `json:"original_plate_id,omitempty"`
+	CorrectedBy      *string    `json:"corrected_by,omitempty"`
+	CorrectedAt      *time.Time `json:"corrected_at,omitempty"`
+	CorrectionReason *string    `json:"correction_reason,omitempty"`
+	// ReadStatus - исход политики уверенности; verified_* - подтверждение прочтения оператором
+	ReadStatus string     `json:"read_status"`
+	VerifiedBy *string    `json:"verified_by,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// Время получения сервером, расхождение часов камеры с ним и источник event_time (camera или server)
+	ReceivedAt       *time.Time `json:"received_at,omitempty"`
+	ClockSkewSeconds *float64   `json:"clock_skew_seconds,omitempty"`
+	TimeSource       string     `json:"time_source"`
+	// PairedEventID - предыдущий проезд того же ТС без номера (только для plateless)
+	PairedEventID *string `json:"paired_event_id,omitempty"`
+	// Candidates - альтернативные прочтения номера по убыванию уверенности
+	Candidates []anpr.PlateCandidate `json:"candidates,omitempty"`
+	// Auth - чем подтверждён источник события: api_key, signature, mtls или untrusted
+	Auth *string `json:"auth,omitempty"`
+}
+
+// annotateEvent назначает событию полигон камеры и расхождение её часов с сервером.
+// Незарегистрированная камера или недоступный реестр не мешают приёму: событие останется без полигона,
+// а уверенность сравнивается с порогами по умолчанию
+func (s *ANPRService) annotateEvent(ctx context.Context, event *anpr.Event, log zerolog.Logger) *repository.Camera {
+	camera, err := s.cameras.Lookup(ctx, event.CameraID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to look up camera")
+	} else if camera != nil {
+		event.PolygonID = camera.PolygonID
+	}
+
+	// Расхождение часов камеры с сервером; сбитые часы от задержек доставки отличает медиана в /cameras/clock
+	if event.TimeSource == anpr.TimeSourceCamera {
+		skew := event.EventTime.Sub(event.ReceivedAt).Seconds()
+		event.ClockSkew = &skew
+		metrics.ClockSkew.WithLabelValues(metrics.CameraLabel(event.CameraID)).Set(skew)
+		if s.cameras.ClockDrifting(skew) {
+			log.Warn().
+				Float64("clock_skew_seconds", skew).
+				Time("event_time", event.EventTime).
+				Time("received_at", event.ReceivedAt).
+				Msg("camera clock differs from server time")
+		}
+	}
+	return camera
+}
+
+// eventInfoFromDomain собирает EventInfo из только что сохранённого события
+func eventInfoFromDomain(e *anpr.Event) EventInfo {
+	info := EventInfo{
+		ID:              e.ID.String(),
+		CameraID:        e.CameraID,
+		RawPlate:        e.Plate,
+		NormalizedPlate: e.NormalizedPlate,
+		EventTime:       e.EventTime,
+		VehicleSpeed:    e.Vehicle.Speed,
+		ReadStatus:      e.ReadStatus,
+		TimeSource:      e.TimeSource,
+	}
+	if !e.ReceivedAt.IsZero() {
+		receivedAt := e.ReceivedAt
+		info.ReceivedAt = &receivedAt
+	}
+	if e.PlateID != uuid.Nil {
+		plateID := e.PlateID.String()
+		info.PlateID = &plateID
+	}
+	info.ClockSkewSeconds = e.ClockSkew
+	info.PairedEventID = uuidString(e.PairedEventID)
+	info.Candidates = e.Candidates
+	info.Auth = nonEmpty(&e.Auth)
+	info.CameraModel = nonEmpty(&e.CameraModel)
+	info.Direction = nonEmpty(&e.Direction)
+	info.VehicleColor = nonEmpty(&e.Vehicle.Color)
+	info.VehicleType = nonEmpty(&e.Vehicle.Type)
+	info.VehicleBrand = nonEmpty(&e.Vehicle.Brand)
+	info.VehicleModel = nonEmpty(&e.Vehicle.Model)
+	info.VehicleCountry = nonEmpty(&e.Vehicle.Country)
+	info.VehiclePlateColor = nonEmpty(&e.Vehicle.PlateColor)
+	info.SnapshotURL = nonEmpty(&e.SnapshotURL)
+	if e.Lane != 0 {
+		lane := e.Lane
+		info.Lane = &lane
+	}
+	if e.Confidence != 0 {
+		confidence := e.Confidence
+		info.Confidence = &confidence
+	}
+	return info
 }
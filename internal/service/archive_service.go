@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/archive"
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+const (
+	archiveBatchSize = 5000
+
+	ArchiveReasonManual = "manual"
+	// Ручная очистка DELETE /anpr/events/old и /anpr/events/all
+	ArchiveReasonDeleteOld = "manual_delete:old"
+	ArchiveReasonDeleteAll = "manual_delete:all"
+)
+
+// EventBatchSource возвращает очередную пачку событий после курсора; пустая пачка - конец
+type EventBatchSource func(ctx context.Context, after *repository.EventCursor, limit int) ([]repository.ANPREvent, error)
+
+type ArchiveService struct {
+	repo  *repository.ArchiveRepository
+	store archive.Store
+	cfg   config.ArchiveConfig
+	log   zerolog.Logger
+}
+
+func NewArchiveService(repo *repository.ArchiveRepository, store archive.Store, cfg config.ArchiveConfig, log zerolog.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:  repo,
+		store: store,
+		cfg:   cfg,
+		log:   log,
+	}
+}
+
+// ArchiveRange выгружает события из [from, to) в архив, не удаляя их
+func (s *ArchiveService) ArchiveRange(ctx context.Context, from, to time.Time, format string) (*ArchiveInfo, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+	if format != "" && format != archive.FormatNDJSONGzip {
+		return nil, fmt.Errorf("%w: unsupported archive format %q", ErrInvalidInput, format)
+	}
+
+	source := func(ctx context.Context, after *repository.EventCursor, limit int) ([]repository.ANPREvent, error) {
+		return s.repo.NextEventsInRange(ctx, from, to, after, limit)
+	}
+	info, _, err := s.Export(ctx, ArchiveReasonManual, &from, &to, source)
+	return info, err
+}
+
+// Export пишет все события из source в один архив, загружает его в хранилище
+// и регистрирует в anpr_archives. Возвращает id заархивированных событий, чтобы
+// вызывающий мог удалить ровно то, что попало в архив. Если событий нет, архив не создаётся.
+func (s *ArchiveService) Export(ctx context.Context, reason string, rangeFrom, rangeTo *time.Time, source EventBatchSource) (*ArchiveInfo, []uuid.UUID, error) {
+	writer, err := archive.NewWriter(s.cfg.TempDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer writer.Remove()
+
+	var ids []uuid.UUID
+	var minTime, maxTime *time.Time
+	var cursor *repository.EventCursor
+	for {
+		batch, err := source(ctx, cursor, archiveBatchSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read events for archive: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			if err := writer.Write(&batch[i]); err != nil {
+				return nil, nil, err
+			}
+			ids = append(ids, batch[i].ID)
+			t := batch[i].EventTime
+			if minTime == nil || t.Before(*minTime) {
+				minTime = &t
+			}
+			if maxTime == nil || t.After(*maxTime) {
+				maxTime = &t
+			}
+		}
+		last := batch[len(batch)-1]
+		cursor = &repository.EventCursor{EventTime: last.EventTime, ID: last.ID}
+		if len(batch) < archiveBatchSize {
+			break
+		}
+	}
+
+	if writer.Count() == 0 {
+		return nil, nil, nil
+	}
+
+	checksum, size, err := writer.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("finalize archive: %w", err)
+	}
+
+	now := time.Now().UTC()
+	archiveID := uuid.New()
+	base := path.Join(s.cfg.Prefix, now.Format("2006/01/02"), archiveID.String())
+	dataKey := base + "." + archive.FormatNDJSONGzip
+	manifestKey := base + ".manifest.json"
+
+	if err := s.store.Put(ctx, dataKey, writer.File(), size); err != nil {
+		return nil, nil, fmt.Errorf("upload archive: %w", err)
+	}
+
+	manifest := archive.Manifest{
+		Version:      archive.ManifestVersion,
+		ArchiveID:    archiveID.String(),
+		Format:       archive.FormatNDJSONGzip,
+		DataKey:      dataKey,
+		Reason:       reason,
+		RangeFrom:    rangeFrom,
+		RangeTo:      rangeTo,
+		MinEventTime: minTime,
+		MaxEventTime: maxTime,
+		EventCount:   writer.Count(),
+		SizeBytes:    size,
+		SHA256:       checksum,
+		CreatedAt:    now,
+	}
+	manifestRaw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := s.store.Put(ctx, manifestKey, bytes.NewReader(manifestRaw), int64(len(manifestRaw))); err != nil {
+		return nil, nil, fmt.Errorf("upload manifest: %w", err)
+	}
+
+	record := &repository.Archive{
+		ID:           archiveID,
+		Reason:       reason,
+		Format:       archive.FormatNDJSONGzip,
+		Store:        s.store.Name(),
+		DataKey:      dataKey,
+		ManifestKey:  manifestKey,
+		RangeFrom:    rangeFrom,
+		RangeTo:      rangeTo,
+		MinEventTime: minTime,
+		MaxEventTime: maxTime,
+		EventCount:   writer.Count(),
+		SizeBytes:    size,
+		SHA256:       checksum,
+		CreatedAt:    now,
+	}
+	if err := s.repo.CreateArchive(ctx, record); err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info().
+		Str("archive_id", archiveID.String()).
+		Str("reason", reason).
+		Str("store", s.store.Name()).
+		Str("data_key", dataKey).
+		Int64("event_count", record.EventCount).
+		Int64("size_bytes", size).
+		Msg("events archived")
+
+	info := toArchiveInfo(*record)
+	return &info, ids, nil
+}
+
+// Restore восстанавливает события из всех архивов, пересекающихся с [from, to],
+// в таблицу anpr_events_restored. Контрольная сумма каждого архива проверяется.
+func (s *ArchiveService) Restore(ctx context.Context, from, to time.Time) (*RestoreResult, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+
+	archives, err := s.repo.FindArchives(ctx, &from, &to, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archives: %w", err)
+	}
+
+	result := &RestoreResult{ArchiveIDs: make([]string, 0, len(archives))}
+	for _, a := range archives {
+		restored, err := s.restoreArchive(ctx, a, from, to)
+		if err != nil {
+			return result, fmt.Errorf("restore archive %s: %w", a.ID, err)
+		}
+		result.ArchiveIDs = append(result.ArchiveIDs, a.ID.String())
+		result.Restored += restored
+	}
+
+	s.log.Info().
+		Time("from", from).
+		Time("to", to).
+		Int("archives", len(result.ArchiveIDs)).
+		Int64("restored", result.Restored).
+		Msg("archived events restored")
+
+	return result, nil
+}
+
+func (s *ArchiveService) restoreArchive(ctx context.Context, a repository.Archive, from, to time.Time) (int64, error) {
+	if a.Store != s.store.Name() {
+		return 0, fmt.Errorf("archive stored in %q, current store is %q", a.Store, s.store.Name())
+	}
+
+	body, err := s.store.Get(ctx, a.DataKey)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	reader, err := archive.NewReader(body, a.SHA256)
+	if err != nil {
+		return 0, err
+	}
+
+	// Вставка идёт пачками до проверки контрольной суммы в конце файла,
+	// поэтому при несовпадении частично вставленные строки остаются - это
+	// таблица для расследований, повторное восстановление идемпотентно.
+	restoredAt := time.Now()
+	var total int64
+	batch := make([]repository.RestoredEvent, 0, archiveBatchSize)
+	flush := func() error {
+		inserted, err := s.repo.InsertRestoredEvents(ctx, batch)
+		total += inserted
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		var event repository.ANPREvent
+		err := reader.Next(&event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		if event.EventTime.Before(from) || event.EventTime.After(to) {
+			continue
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return total, err
+		}
+		batch = append(batch, repository.RestoredEvent{
+			ID:              event.ID,
+			ArchiveID:       a.ID,
+			PlateID:         event.PlateID,
+			CameraID:        event.CameraID,
+			NormalizedPlate: event.NormalizedPlate,
+			EventTime:       event.EventTime,
+			Data:            datatypes.JSON(data),
+			RestoredAt:      restoredAt,
+		})
+		if len(batch) >= archiveBatchSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return total, err
+	}
+
+	if err := s.repo.MarkRestored(ctx, a.ID, restoredAt); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+func (s *ArchiveService) FindArchives(ctx context.Context, from, to *string, limit, offset int) ([]ArchiveInfo, error) {
+	fromTime, toTime, err := parseTimeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	archives, err := s.repo.FindArchives(ctx, fromTime, toTime, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archives: %w", err)
+	}
+	result := make([]ArchiveInfo, 0, len(archives))
+	for _, a := range archives {
+		result = append(result, toArchiveInfo(a))
+	}
+	return result, nil
+}
+
+func (s *ArchiveService) FindRestoredEvents(ctx context.Context, archiveID, plateQuery, from, to *string, limit, offset int) ([]RestoredEventInfo, error) {
+	fromTime, toTime, err := parseTimeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var archiveUUID *uuid.UUID
+	if archiveID != nil && *archiveID != "" {
+		id, err := uuid.Parse(*archiveID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid archive_id", ErrInvalidInput)
+		}
+		archiveUUID = &id
+	}
+	var normalizedPlate *string
+	if plateQuery != nil {
+		if normalized := utils.NormalizePlate(*plateQuery); normalized != "" {
+			normalizedPlate = &normalized
+		}
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	events, err := s.repo.FindRestoredEvents(ctx, archiveUUID, normalizedPlate, fromTime, toTime, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find restored events: %w", err)
+	}
+	result := make([]RestoredEventInfo, 0, len(events))
+	for _, e := range events {
+		result = append(result, RestoredEventInfo{
+			ArchiveID:  e.ArchiveID.String(),
+			Event:      json.RawMessage(e.Data),
+			RestoredAt: e.RestoredAt,
+		})
+	}
+	return result, nil
+}
+
+func parseTimeRange(from, to *string) (*time.Time, *time.Time, error) {
+	var fromTime, toTime *time.Time
+	if from != nil && *from != "" {
+		t, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid from time format", ErrInvalidInput)
+		}
+		fromTime = &t
+	}
+	if to != nil && *to != "" {
+		t, err := time.Parse(time.RFC3339, *to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid to time format", ErrInvalidInput)
+		}
+		toTime = &t
+	}
+	return fromTime, toTime, nil
+}
+
+func toArchiveInfo(a repository.Archive) ArchiveInfo {
+	return ArchiveInfo{
+		ID:           a.ID.String(),
+		Reason:       a.Reason,
+		Format:       a.Format,
+		Store:        a.Store,
+		DataKey:      a.DataKey,
+		ManifestKey:  a.ManifestKey,
+		RangeFrom:    a.RangeFrom,
+		RangeTo:      a.RangeTo,
+		MinEventTime: a.MinEventTime,
+		MaxEventTime: a.MaxEventTime,
+		EventCount:   a.EventCount,
+		SizeBytes:    a.SizeBytes,
+		SHA256:       a.SHA256,
+		CreatedAt:    a.CreatedAt,
+		RestoredAt:   a.RestoredAt,
+	}
+}
+
+type ArchiveInfo struct {
+	ID           string     `json:"id"`
+	Reason       string     `json:"reason"`
+	Format       string     `json:"format"`
+	Store        string     `json:"store"`
+	DataKey      string     `json:"data_key"`
+	ManifestKey  string     `json:"manifest_key"`
+	RangeFrom    *time.Time `json:"range_from,omitempty"`
+	RangeTo      *time.Time `json:"range_to,omitempty"`
+	MinEventTime *time.Time `json:"min_event_time,omitempty"`
+	MaxEventTime *time.Time `json:"max_event_time,omitempty"`
+	EventCount   int64      `json:"event_count"`
+	SizeBytes    int64      `json:"size_bytes"`
+	SHA256       string     `json:"sha256"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RestoredAt   *time.Time `json:"restored_at,omitempty"`
+}
+
+type RestoreResult struct {
+	ArchiveIDs []string `json:"archive_ids"`
+	Restored   int64    `json:"restored"`
+}
+
+type RestoredEventInfo struct {
+	ArchiveID  string          `json:"archive_id"`
+	Event      json.RawMessage `json:"event"`
+	RestoredAt time.Time       `json:"restored_at"`
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/model"
+	"anpr-service/internal/repository"
+)
+
+const (
+	AuditActionSyncVehicle     = "sync_vehicle_to_whitelist"
+	AuditActionDeleteOldEvents = "delete_old_events"
+	AuditActionDeleteAllEvents = "delete_all_events"
+
+	AuditActionCreateRetentionPolicy = "create_retention_policy"
+	AuditActionUpdateRetentionPolicy = "update_retention_policy"
+	AuditActionDeleteRetentionPolicy = "delete_retention_policy"
+	AuditActionRunRetention          = "run_retention"
+	AuditActionLinkTripEvents        = "link_trip_events"
+	AuditActionUnlinkTrip            = "unlink_trip"
+
+	AuditActionCreateArchive  = "create_archive"
+	AuditActionRestoreArchive = "restore_archive"
+
+	AuditActionExportEvents    = "export_events"
+	AuditActionCreateExportJob = "create_export_job"
+
+	AuditActionUpsertCamera   = "upsert_camera"
+	AuditActionDeleteCamera   = "delete_camera"
+	AuditActionResolveAnomaly = "resolve_anomaly"
+
+	AuditActionSyncVehicles = "sync_vehicles"
+
+	AuditActionResolveReviewItem  = "resolve_review_item"
+	AuditActionUpsertReviewPolicy = "upsert_review_policy"
+	AuditActionDeleteReviewPolicy = "delete_review_policy"
+
+	AuditActionCorrectEventPlate = "correct_event_plate"
+	AuditActionMergePlates       = "merge_plates"
+	AuditActionVerifyEventRead   = "verify_event_read"
+	AuditActionAttachPlate       = "attach_plate"
+
+	AuditActionAckPlateRecomputes = "ack_plate_recomputes"
+
+	AuditActionRotateCameraKey = "rotate_camera_api_key"
+	AuditActionRevokeCameraKey = "revoke_camera_api_key"
+
+	AuditStatusSuccess = "success"
+	AuditStatusFailure = "failure"
+)
+
+// AuditStore - хранилище журнала аудита (repository.AuditRepository); за интерфейсом запись
+// журнала обработчиками проверяется без базы
+type AuditStore interface {
+	CreateAuditLog(ctx context.Context, entry *repository.AuditLog) error
+	FindAuditLogs(ctx context.Context, filter repository.AuditLogFilter) ([]repository.AuditLog, error)
+}
+
+type AuditService struct {
+	repo AuditStore
+	log  zerolog.Logger
+}
+
+func NewAuditService(repo AuditStore, log zerolog.Logger) *AuditService {
+	return &AuditService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// AuditRecord описывает одно административное действие для записи в журнал
+type AuditRecord struct {
+	Principal *model.Principal
+	Action    string
+	Target    string
+	Params    map[string]interface{}
+	Result    map[string]interface{}
+	Err       error
+	ClientIP  string
+}
+
+// Record сохраняет запись в журнал аудита.
+// Ошибка записи журнала логируется и возвращается, но не должна ломать основное действие.
+func (s *AuditService) Record(ctx context.Context, rec AuditRecord) error {
+	entry := &repository.AuditLog{
+		Action: rec.Action,
+		Status: AuditStatusSuccess,
+	}
+
+	if rec.Principal != nil {
+		actorID := rec.Principal.UserID
+		orgID := rec.Principal.OrgID
+		role := string(rec.Principal.Role)
+		entry.ActorID = &actorID
+		entry.ActorOrgID = &orgID
+		entry.ActorRole = &role
+	}
+	if rec.Target != "" {
+		entry.Target = &rec.Target
+	}
+	if rec.ClientIP != "" {
+		entry.ClientIP = &rec.ClientIP
+	}
+	if rec.Err != nil {
+		errText := rec.Err.Error()
+		entry.Status = AuditStatusFailure
+		entry.Error = &errText
+	}
+	if len(rec.Params) > 0 {
+		raw, err := json.Marshal(rec.Params)
+		if err != nil {
+			return fmt.Errorf("marshal audit params: %w", err)
+		}
+		entry.Params = datatypes.JSON(raw)
+	}
+	if len(rec.Result) > 0 {
+		raw, err := json.Marshal(rec.Result)
+		if err != nil {
+			return fmt.Errorf("marshal audit result: %w", err)
+		}
+		entry.Result = datatypes.JSON(raw)
+	}
+
+	if err := s.repo.CreateAuditLog(ctx, entry); err != nil {
+		s.log.Error().
+			Err(err).
+			Str("action", rec.Action).
+			Str("target", rec.Target).
+			Msg("failed to write audit log entry")
+		return err
+	}
+
+	return nil
+}
+
+func (s *AuditService) FindEntries(ctx context.Context, query AuditQuery) ([]AuditEntryInfo, error) {
+	filter := repository.AuditLogFilter{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+
+	if query.ActorID != nil && *query.ActorID != "" {
+		id, err := uuid.Parse(*query.ActorID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid actor_id", ErrInvalidInput)
+		}
+		filter.ActorID = &id
+	}
+	if query.OrgID != nil && *query.OrgID != "" {
+		id, err := uuid.Parse(*query.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid org_id", ErrInvalidInput)
+		}
+		filter.OrgID = &id
+	}
+	if query.Status != nil && *query.Status != "" {
+		if *query.Status != AuditStatusSuccess && *query.Status != AuditStatusFailure {
+			return nil, fmt.Errorf("%w: invalid status", ErrInvalidInput)
+		}
+		filter.Status = query.Status
+	}
+	filter.Action = nonEmpty(query.Action)
+	filter.Target = nonEmpty(query.Target)
+
+	if query.From != nil && *query.From != "" {
+		t, err := time.Parse(time.RFC3339, *query.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid from time format", ErrInvalidInput)
+		}
+		filter.From = &t
+	}
+	if query.To != nil && *query.To != "" {
+		t, err := time.Parse(time.RFC3339, *query.To)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid to time format", ErrInvalidInput)
+		}
+		filter.To = &t
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	entries, err := s.repo.FindAuditLogs(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit log entries: %w", err)
+	}
+
+	result := make([]AuditEntryInfo, 0, len(entries))
+	for _, e := range entries {
+		info := AuditEntryInfo{
+			ID:        e.ID.String(),
+			ActorRole: e.ActorRole,
+			Action:    e.Action,
+			Target:    e.Target,
+			Params:    json.RawMessage(e.Params),
+			Result:    json.RawMessage(e.Result),
+			Status:    e.Status,
+			Error:     e.Error,
+			ClientIP:  e.ClientIP,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.ActorID != nil {
+			id := e.ActorID.String()
+			info.ActorID = &id
+		}
+		if e.ActorOrgID != nil {
+			id := e.ActorOrgID.String()
+			info.ActorOrgID = &id
+		}
+		if len(e.Params) == 0 {
+			info.Params = nil
+		}
+		if len(e.Result) == 0 {
+			info.Result = nil
+		}
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+func nonEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+type AuditQuery struct {
+	ActorID *string
+	OrgID   *string
+	Action  *string
+	Target  *string
+	Status  *string
+	From    *string
+	To      *string
+	Limit   int
+	Offset  int
+}
+
+type AuditEntryInfo struct {
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actor_id,omitempty"`
+	ActorOrgID *string         `json:"actor_org_id,omitempty"`
+	ActorRole  *string         `json:"actor_role,omitempty"`
+	Action     string          `json:"action"`
+	Target     *string         `json:"target,omitempty"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Status     string          `json:"status"`
+	Error      *string         `json:"error,omitempty"`
+	ClientIP   *string         `json:"client_ip,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
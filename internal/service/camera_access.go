@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+)
+
+// normalizeAllowedIPs проверяет allowlist камеры: адреса приводятся к каноническому виду,
+// подсети - к адресу сети (10.0.5.7/24 -> 10.0.5.0/24); пустой список - без ограничения
+func normalizeAllowedIPs(raw []string) (datatypes.JSON, error) {
+	var allowed []string
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("%w: allowed_ips: invalid CIDR %q", ErrInvalidInput, item)
+			}
+			allowed = append(allowed, prefix.Masked().String())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: allowed_ips: invalid IP address %q", ErrInvalidInput, item)
+		}
+		allowed = append(allowed, addr.Unmap().String())
+	}
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(allowed)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(encoded), nil
+}
+
+// cameraAllowedIPs - allowlist камеры из реестра
+func cameraAllowedIPs(camera repository.Camera) []string {
+	if len(camera.AllowedIPs) == 0 {
+		return nil
+	}
+	var allowed []string
+	if err := json.Unmarshal(camera.AllowedIPs, &allowed); err != nil {
+		return nil
+	}
+	return allowed
+}
+
+// AddressAllowed проверяет адрес клиента по allowlist камеры. Незарегистрированная камера
+// и камера без allowlist принимаются с любого адреса.
+func (s *CameraService) AddressAllowed(ctx context.Context, cameraID, clientIP string) (bool, error) {
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil {
+		return false, err
+	}
+	if camera == nil {
+		return true, nil
+	}
+	return addressAllowed(cameraAllowedIPs(*camera), clientIP), nil
+}
+
+func addressAllowed(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, item := range allowed {
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if allowedAddr, err := netip.ParseAddr(item); err == nil && allowedAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// signaturePrefix - схема подписи в X-Signature: sha256=<hex HMAC-SHA256>
+const signaturePrefix = "sha256="
+
+// RotateAPIKey выдаёт камере новый ключ API; прежний ключ сразу перестаёт действовать.
+// Ключ возвращается один раз: в реестре хранится только его SHA-256.
+func (s *CameraService) RotateAPIKey(ctx context.Context, cameraID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := hex.EncodeToString(raw)
+	hash := apiKeyHash(key)
+	updated, err := s.repo.SetAPIKeyHash(ctx, cameraID, &hash)
+	if err != nil {
+		return "", err
+	}
+	if !updated {
+		return "", fmt.Errorf("%w: camera not found", ErrNotFound)
+	}
+	s.invalidate()
+	return key, nil
+}
+
+// RevokeAPIKey отзывает ключ API камеры
+func (s *CameraService) RevokeAPIKey(ctx context.Context, cameraID string) error {
+	updated, err := s.repo.SetAPIKeyHash(ctx, cameraID, nil)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return fmt.Errorf("%w: camera not found", ErrNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// AuthenticateAPIKey проверяет ключ API камеры; у незарегистрированной камеры и камеры без ключа
+// ключ не подходит
+func (s *CameraService) AuthenticateAPIKey(ctx context.Context, cameraID, key string) (bool, error) {
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil {
+		return false, err
+	}
+	if camera == nil || camera.APIKeyHash == nil {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(apiKeyHash(key)), []byte(*camera.APIKeyHash)) == 1, nil
+}
+
+// CertificateMatches проверяет, что клиентский сертификат с CN cn выдан камере cameraID:
+// CN совпадает с client_cert_cn камеры, а без него - с camera_id
+func (s *CameraService) CertificateMatches(ctx context.Context, cameraID, cn string) (bool, error) {
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil {
+		return false, err
+	}
+	expected := cameraID
+	if camera != nil && camera.ClientCertCN != nil {
+		expected = *camera.ClientCertCN
+	}
+	return cn != "" && cn == expected, nil
+}
+
+const (
+	// UnregisteredCameraBucket - общий лимит всех незарегистрированных camera_id: произвольные
+	// идентификаторы не создают по своему token bucket
+	UnregisteredCameraBucket = "unregistered"
+	// unverifiedCameraPrefix - лимит неподтверждённых запросов с camera_id зарегистрированной
+	// камеры отделён от её подтверждённых запросов: подделка не исчерпывает лимит камеры
+	unverifiedCameraPrefix = "unverified:"
+)
+
+// CameraBucket - ключ лимита запросов камеры (INGEST_CAMERA_RATE): camera_id подтверждённого
+// источника, отдельный ключ для неподтверждённых запросов зарегистрированной камеры и один
+// общий - для незарегистрированных. auth - значение anpr.EventPayload.Auth.
+func CameraBucket(cameraID, auth string, registered bool) string {
+	switch {
+	case !registered:
+		return UnregisteredCameraBucket
+	case auth == "" || auth == anpr.AuthUntrusted:
+		return unverifiedCameraPrefix + cameraID
+	}
+	return cameraID
+}
+
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySignature проверяет подпись тела пограничного ретранслятора: signature -
+// sha256=<hex HMAC-SHA256("<timestamp>.<body>")> одним из secrets, timestamp - Unix-время
+// подписи, отличающееся от now не больше чем на maxAge
+func VerifySignature(secrets []string, timestamp, signature string, body []byte, now time.Time, maxAge time.Duration) error {
+	if len(secrets) == 0 {
+		return errors.New("signed requests are not accepted: no signing secrets configured")
+	}
+	unix, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > maxAge || age < -maxAge {
+		return errors.New("signature timestamp is outside the allowed window")
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errors.New("unsupported signature scheme")
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return errors.New("invalid signature encoding")
+	}
+	for _, secret := range secrets {
+		if hmac.Equal(got, signBody(secret, strings.TrimSpace(timestamp), body)) {
+			return nil
+		}
+	}
+	return errors.New("signature mismatch")
+}
+
+// SignBody возвращает значение X-Signature для тела body с меткой времени timestamp
+// (Unix-время строкой) - подпись, которую принимает VerifySignature
+func SignBody(secret, timestamp string, body []byte) string {
+	return signaturePrefix + hex.EncodeToString(signBody(secret, timestamp, body))
+}
+
+func signBody(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestNormalizeAllowedIPs(t *testing.T) {
+	raw, err := normalizeAllowedIPs([]string{" 10.0.5.7/24 ", "", "192.168.1.64", "::ffff:192.168.1.65"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["10.0.5.0/24","192.168.1.64","192.168.1.65"]`; string(raw) != want {
+		t.Errorf("normalized = %s, want %s", raw, want)
+	}
+
+	if raw, err := normalizeAllowedIPs([]string{" "}); err != nil || raw != nil {
+		t.Errorf("empty list: got %s, %v", raw, err)
+	}
+	for _, bad := range []string{"10.0.0.0/33", "camera-1", "300.1.1.1"} {
+		if _, err := normalizeAllowedIPs([]string{bad}); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%q: expected ErrInvalidInput, got %v", bad, err)
+		}
+	}
+}
+
+func TestAddressAllowed(t *testing.T) {
+	allowed := []string{"10.0.5.0/24", "192.168.1.64"}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.0.5.200", true},
+		{"192.168.1.64", true},
+		{"::ffff:192.168.1.64", true},
+		{"192.168.1.65", false},
+		{"10.0.6.1", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := addressAllowed(allowed, tt.ip); got != tt.want {
+			t.Errorf("addressAllowed(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+	if !addressAllowed(nil, "203.0.113.9") {
+		t.Error("camera without allowlist must accept any address")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"camera_id":"cam-1","plate":"A123BC77"}`)
+	timestamp := "1700000000"
+	valid := SignBody("old-secret", timestamp, body)
+	secrets := []string{"new-secret", "old-secret"}
+
+	tests := []struct {
+		name      string
+		secrets   []string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   bool
+	}{
+		{name: "valid", secrets: secrets, timestamp: timestamp, signature: valid, body: body},
+		{name: "no secrets", timestamp: timestamp, signature: valid, body: body, wantErr: true},
+		{name: "tampered body", secrets: secrets, timestamp: timestamp, signature: valid, body: []byte(`{"camera_id":"cam-2"}`), wantErr: true},
+		{name: "other timestamp", secrets: secrets, timestamp: "1700000001", signature: valid, body: body, wantErr: true},
+		{name: "expired", secrets: secrets, timestamp: "1699999000", signature: valid, body: body, wantErr: true},
+		{name: "bad timestamp", secrets: secrets, timestamp: "yesterday", signature: valid, body: body, wantErr: true},
+		{name: "unknown scheme", secrets: secrets, timestamp: timestamp, signature: "md5=00", body: body, wantErr: true},
+		{name: "bad encoding", secrets: secrets, timestamp: timestamp, signature: signaturePrefix + "zz", body: body, wantErr: true},
+		{name: "unknown secret", secrets: []string{"new-secret"}, timestamp: timestamp, signature: valid, body: body, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secrets, tt.timestamp, tt.signature, tt.body, now, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHash(t *testing.T) {
+	hash := apiKeyHash("secret-key")
+	if len(hash) != 64 || hash == "secret-key" {
+		t.Errorf("apiKeyHash = %q, want hex SHA-256", hash)
+	}
+	if apiKeyHash("secret-key") != hash || apiKeyHash("other-key") == hash {
+		t.Error("apiKeyHash must be deterministic and differ for different keys")
+	}
+}
+
+func TestCameraBucket(t *testing.T) {
+	tests := []struct {
+		cameraID   string
+		auth       string
+		registered bool
+		want       string
+	}{
+		{"cam-1", anpr.AuthAPIKey, true, "cam-1"},
+		{"cam-1", anpr.AuthSignature, true, "cam-1"},
+		{"cam-1", "", true, "unverified:cam-1"},
+		{"cam-1", anpr.AuthUntrusted, true, "unverified:cam-1"},
+		{"spoofed-1", "", false, UnregisteredCameraBucket},
+		{"spoofed-2", anpr.AuthSignature, false, UnregisteredCameraBucket},
+	}
+	for _, tt := range tests {
+		if got := CameraBucket(tt.cameraID, tt.auth, tt.registered); got != tt.want {
+			t.Errorf("CameraBucket(%q, %q, %v) = %q, want %q", tt.cameraID, tt.auth, tt.registered, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CameraClockInfo - расхождение часов камеры с временем получения событий сервером.
+// Положительное расхождение - часы камеры спешат; отрицательное - отстают или события
+// доставляются с задержкой, поэтому сбитой камера считается по медиане, а не по отдельным событиям.
+type CameraClockInfo struct {
+	CameraID string `json:"camera_id"`
+	Timezone string `json:"timezone"`
+	Events   int64  `json:"events"`
+	// ServerTimeEvents - события без разборчивого времени камеры
+	ServerTimeEvents  int64      `json:"server_time_events"`
+	MedianSkewSeconds *float64   `json:"median_skew_seconds,omitempty"`
+	MinSkewSeconds    *float64   `json:"min_skew_seconds,omitempty"`
+	MaxSkewSeconds    *float64   `json:"max_skew_seconds,omitempty"`
+	LastSkewSeconds   *float64   `json:"last_skew_seconds,omitempty"`
+	LastReceivedAt    *time.Time `json:"last_received_at,omitempty"`
+	// Drifting - медианное расхождение больше CAMERA_CLOCK_SKEW_THRESHOLD
+	Drifting bool `json:"drifting"`
+}
+
+// Location возвращает часовой пояс камеры для времени без смещения. Незарегистрированная
+// камера или недоступный реестр - пояс по умолчанию.
+func (s *CameraService) Location(ctx context.Context, cameraID string) *time.Location {
+	name := s.clock.DefaultTimezone
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil {
+		s.log.Error().Err(err).Str("camera_id", cameraID).Msg("failed to look up camera timezone")
+	} else if camera != nil && camera.Timezone != nil {
+		name = *camera.Timezone
+	}
+	return s.location(name)
+}
+
+func (s *CameraService) location(name string) *time.Location {
+	s.mu.RLock()
+	loc, ok := s.locations[name]
+	s.mu.RUnlock()
+	if ok {
+		return loc
+	}
+
+	// Пояса проверяются при регистрации камеры и в конфигурации, ошибка здесь - только устаревшая tzdata
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		s.log.Error().Err(err).Str("timezone", name).Msg("failed to load camera timezone, using UTC")
+		loc = time.UTC
+	}
+	s.mu.Lock()
+	s.locations[name] = loc
+	s.mu.Unlock()
+	return loc
+}
+
+// ClockDrifting - расхождение часов (секунды) больше допустимого
+func (s *CameraService) ClockDrifting(skewSeconds float64) bool {
+	return clockDrifting(skewSeconds, s.clock.SkewThreshold)
+}
+
+func clockDrifting(skewSeconds float64, threshold time.Duration) bool {
+	return math.Abs(skewSeconds) > threshold.Seconds()
+}
+
+// ClockStats возвращает расхождение часов камер за период (по умолчанию последние сутки)
+func (s *CameraService) ClockStats(ctx context.Context, q CameraStatsQuery) ([]CameraClockInfo, error) {
+	from, to, err := q.window(time.Now(), 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ClockStats(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clock stats: %w", err)
+	}
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cameras: %w", err)
+	}
+	timezones := make(map[string]string, len(cameras))
+	for _, c := range cameras {
+		if c.Timezone != nil {
+			timezones[c.CameraID] = *c.Timezone
+		}
+	}
+
+	result := make([]CameraClockInfo, 0, len(stats))
+	for _, st := range stats {
+		info := CameraClockInfo{
+			CameraID:          st.CameraID,
+			Timezone:          s.clock.DefaultTimezone,
+			Events:            st.Events,
+			ServerTimeEvents:  st.ServerTimeEvents,
+			MedianSkewSeconds: st.MedianSkew,
+			MinSkewSeconds:    st.MinSkew,
+			MaxSkewSeconds:    st.MaxSkew,
+			LastSkewSeconds:   st.LastSkew,
+			LastReceivedAt:    st.LastReceivedAt,
+		}
+		if tz, ok := timezones[st.CameraID]; ok {
+			info.Timezone = tz
+		}
+		if st.MedianSkew != nil {
+			info.Drifting = clockDrifting(*st.MedianSkew, s.clock.SkewThreshold)
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockDrifting(t *testing.T) {
+	cases := []struct {
+		skew float64
+		want bool
+	}{
+		{0, false},
+		{119, false},
+		{120, false},
+		{121, true},
+		{-121, true},
+		{-3600 * 5, true},
+	}
+	for _, tc := range cases {
+		if got := clockDrifting(tc.skew, 2*time.Minute); got != tc.want {
+			t.Errorf("skew %v: got %v, want %v", tc.skew, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"anpr-service/internal/metrics"
+)
+
+// livenessWriteInterval - не чаще этого heartbeat и уведомления камеры обновляют её последнюю связь в базе
+const livenessWriteInterval = time.Minute
+
+// contactKey - камера и вид связи: heartbeat и события записываются в разные поля
+type contactKey struct {
+	cameraID  string
+	heartbeat bool
+}
+
+// CameraLivenessInfo - последняя связь с камерой. Зарегистрированная камера, от которой ещё
+// ничего не приходило, тоже попадает в список - недоступной.
+type CameraLivenessInfo struct {
+	CameraID        string     `json:"camera_id"`
+	Registered      bool       `json:"registered"`
+	LastHeartbeatAt *time.Time `json:"last_heartbeat_at,omitempty"`
+	LastEventAt     *time.Time `json:"last_event_at,omitempty"`
+	LastSeenAt      *time.Time `json:"last_seen_at,omitempty"`
+	LastAddress     *string    `json:"last_address,omitempty"`
+	// Online - heartbeat или событие были не раньше CAMERA_OFFLINE_AFTER назад
+	Online bool `json:"online"`
+}
+
+// Heartbeat отмечает heartbeat камеры. Учитываются только зарегистрированные камеры,
+// запись в базу - не чаще livenessWriteInterval на камеру.
+func (s *CameraService) Heartbeat(ctx context.Context, cameraID, address string, at time.Time) error {
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil || camera == nil {
+		return err
+	}
+	metrics.CameraLastSeen.WithLabelValues(metrics.CameraLabel(cameraID)).Set(float64(at.Unix()))
+	if !s.contactDue(contactKey{cameraID: cameraID, heartbeat: true}, at) {
+		return nil
+	}
+	return s.repo.RecordHeartbeat(ctx, cameraID, address, at)
+}
+
+// Seen отмечает уведомление камеры. Как и Heartbeat, учитывает только зарегистрированные камеры
+// и пишет в базу не чаще livenessWriteInterval; ошибка только логируется: она не должна
+// мешать приёму события.
+func (s *CameraService) Seen(ctx context.Context, cameraID, address string, at time.Time) {
+	camera, err := s.Lookup(ctx, cameraID)
+	if err != nil {
+		s.log.Error().Err(err).Str("camera_id", cameraID).Msg("failed to look up camera for contact")
+		return
+	}
+	if camera == nil {
+		return
+	}
+	metrics.CameraLastSeen.WithLabelValues(metrics.CameraLabel(cameraID)).Set(float64(at.Unix()))
+	if !s.contactDue(contactKey{cameraID: cameraID}, at) {
+		return
+	}
+	if err := s.repo.RecordEventContact(ctx, cameraID, address, at); err != nil {
+		s.log.Error().Err(err).Str("camera_id", cameraID).Msg("failed to record camera contact")
+	}
+}
+
+// contactDue - связь key пора записать в базу: с прошлой записи прошло не меньше
+// livenessWriteInterval. Запоминает at как время записи.
+func (s *CameraService) contactDue(key contactKey, at time.Time) bool {
+	s.contactMu.Lock()
+	defer s.contactMu.Unlock()
+	if last, ok := s.contacts[key]; ok && at.Sub(last) < livenessWriteInterval {
+		return false
+	}
+	s.contacts[key] = at
+	return true
+}
+
+// ListLiveness возвращает последнюю связь с камерами: зарегистрированными и всеми, от кого приходили уведомления
+func (s *CameraService) ListLiveness(ctx context.Context) ([]CameraLivenessInfo, error) {
+	liveness, err := s.repo.ListLiveness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool, len(cameras))
+	for _, camera := range cameras {
+		registered[camera.CameraID] = true
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(liveness))
+	result := make([]CameraLivenessInfo, 0, len(liveness)+len(cameras))
+	for _, l := range liveness {
+		seen[l.CameraID] = true
+		lastSeen := latestTime(l.LastHeartbeatAt, l.LastEventAt)
+		result = append(result, CameraLivenessInfo{
+			CameraID:        l.CameraID,
+			Registered:      registered[l.CameraID],
+			LastHeartbeatAt: l.LastHeartbeatAt,
+			LastEventAt:     l.LastEventAt,
+			LastSeenAt:      lastSeen,
+			LastAddress:     l.LastAddress,
+			Online:          cameraOnline(lastSeen, now, s.clock.OfflineAfter),
+		})
+	}
+	for _, camera := range cameras {
+		if !seen[camera.CameraID] {
+			result = append(result, CameraLivenessInfo{CameraID: camera.CameraID, Registered: true})
+		}
+	}
+	return result, nil
+}
+
+// latestTime - более позднее из двух времён; nil, если нет ни одного
+func latestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.After(*b) {
+		return a
+	}
+	return b
+}
+
+// cameraOnline - камера выходила на связь не раньше offlineAfter назад
+func cameraOnline(lastSeen *time.Time, now time.Time, offlineAfter time.Duration) bool {
+	return lastSeen != nil && now.Sub(*lastSeen) <= offlineAfter
+}
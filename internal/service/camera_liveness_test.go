@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/repository"
+)
+
+func TestCameraOnline(t *testing.T) {
+	now := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+	heartbeat := now.Add(-10 * time.Minute)
+	event := now.Add(-2 * time.Minute)
+
+	if got := latestTime(&heartbeat, &event); !got.Equal(event) {
+		t.Errorf("latestTime = %v, want %v", got, event)
+	}
+	if got := latestTime(nil, &heartbeat); !got.Equal(heartbeat) {
+		t.Errorf("latestTime(nil, heartbeat) = %v, want %v", got, heartbeat)
+	}
+	if latestTime(nil, nil) != nil {
+		t.Error("latestTime(nil, nil) must be nil")
+	}
+
+	cases := []struct {
+		lastSeen *time.Time
+		want     bool
+	}{
+		{nil, false},
+		{&event, true},
+		{&heartbeat, false},
+	}
+	for _, tc := range cases {
+		if got := cameraOnline(tc.lastSeen, now, 5*time.Minute); got != tc.want {
+			t.Errorf("lastSeen %v: got %v, want %v", tc.lastSeen, got, tc.want)
+		}
+	}
+}
+
+func TestContactDue(t *testing.T) {
+	s := &CameraService{contacts: make(map[contactKey]time.Time)}
+	at := time.Date(2025, 1, 21, 12, 0, 0, 0, time.UTC)
+	event := contactKey{cameraID: "cam-1"}
+	heartbeat := contactKey{cameraID: "cam-1", heartbeat: true}
+
+	if !s.contactDue(event, at) {
+		t.Fatal("first contact must be written")
+	}
+	if s.contactDue(event, at.Add(30*time.Second)) {
+		t.Error("contact within livenessWriteInterval must be skipped")
+	}
+	if !s.contactDue(heartbeat, at.Add(30*time.Second)) {
+		t.Error("heartbeat is throttled separately from events")
+	}
+	if !s.contactDue(event, at.Add(livenessWriteInterval)) {
+		t.Error("contact after livenessWriteInterval must be written")
+	}
+}
+
+func TestLivenessIgnoresUnregisteredCameras(t *testing.T) {
+	// Свежий кэш реестра: поиск камеры не обращается к базе
+	s := &CameraService{
+		log:      zerolog.Nop(),
+		cache:    map[string]repository.Camera{"cam-1": {CameraID: "cam-1"}},
+		loadedAt: time.Now(),
+		contacts: make(map[contactKey]time.Time),
+	}
+	ctx := context.Background()
+
+	s.Seen(ctx, "spoofed-1", "203.0.113.9", time.Now())
+	if err := s.Heartbeat(ctx, "spoofed-2", "203.0.113.9", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.contacts) != 0 {
+		t.Errorf("unregistered cameras must not be tracked: %v", s.contacts)
+	}
+}
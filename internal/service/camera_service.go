@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// cameraCacheTTL - как долго приём событий использует закэшированный реестр камер
+const cameraCacheTTL = 30 * time.Second
+
+// cameraStatsMaxWindow - самый длинный период статистики камер
+const cameraStatsMaxWindow = 93 * 24 * time.Hour
+
+type CameraService struct {
+	repo *repository.CameraRepository
+	// confidence - пороги уверенности для камер без своих порогов
+	confidence config.ConfidenceConfig
+	clock      config.ClockConfig
+	log        zerolog.Logger
+
+	mu       sync.RWMutex
+	cache    map[string]repository.Camera
+	loadedAt time.Time
+	// locations - загруженные часовые пояса камер по имени
+	locations map[string]*time.Location
+
+	contactMu sync.Mutex
+	// contacts - время последней записи связи с камерой, см. Seen и Heartbeat
+	contacts map[contactKey]time.Time
+}
+
+func NewCameraService(repo *repository.CameraRepository, confidence config.ConfidenceConfig, clock config.ClockConfig, log zerolog.Logger) *CameraService {
+	return &CameraService{
+		repo:       repo,
+		confidence: confidence,
+		clock:      clock,
+		log:        log,
+		locations:  make(map[string]*time.Location),
+		contacts:   make(map[contactKey]time.Time),
+	}
+}
+
+// CameraInput - пороги уверенности принимаются долей 0–1
+type CameraInput struct {
+	Name                      *string  `json:"name"`
+	Latitude                  *float64 `json:"latitude"`
+	Longitude                 *float64 `json:"longitude"`
+	PolygonID                 *string  `json:"polygon_id"`
+	ConfidenceReviewBelow     *float64 `json:"confidence_review_below"`
+	ConfidenceUnverifiedBelow *float64 `json:"confidence_unverified_below"`
+	// Timezone - часовой пояс IANA (Asia/Almaty) для времени камеры без смещения
+	Timezone *string `json:"timezone"`
+	// AllowedIPs - адреса и подсети (CIDR), с которых принимаются события камеры; пусто - с любых
+	AllowedIPs []string `json:"allowed_ips"`
+	// ClientCertCN - CN клиентского сертификата камеры для mTLS; пусто - CN равен camera_id
+	ClientCertCN *string `json:"client_cert_cn"`
+}
+
+type CameraInfo struct {
+	CameraID  string   `json:"camera_id"`
+	Name      *string  `json:"name,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	PolygonID *string  `json:"polygon_id,omitempty"`
+	// Собственные пороги камеры; nil - действуют значения по умолчанию
+	ConfidenceReviewBelow     *float64  `json:"confidence_review_below,omitempty"`
+	ConfidenceUnverifiedBelow *float64  `json:"confidence_unverified_below,omitempty"`
+	Timezone                  *string   `json:"timezone,omitempty"`
+	AllowedIPs                []string  `json:"allowed_ips,omitempty"`
+	ClientCertCN              *string   `json:"client_cert_cn,omitempty"`
+	HasAPIKey                 bool      `json:"has_api_key"`
+	CreatedAt                 time.Time `json:"created_at"`
+	UpdatedAt                 time.Time `json:"updated_at"`
+}
+
+// ConfidenceThresholds - действующие пороги уверенности камеры
+type ConfidenceThresholds struct {
+	ReviewBelow     float64 `json:"review_below"`
+	UnverifiedBelow float64 `json:"unverified_below"`
+}
+
+type CameraConfidenceStats struct {
+	CameraID       string               `json:"camera_id"`
+	Thresholds     ConfidenceThresholds `json:"thresholds"`
+	Events         int64                `json:"events"`
+	WithConfidence int64                `json:"with_confidence"`
+	AvgConfidence  *float64             `json:"avg_confidence,omitempty"`
+	P10            *float64             `json:"p10,omitempty"`
+	P50            *float64             `json:"p50,omitempty"`
+	P90            *float64             `json:"p90,omitempty"`
+	Flagged        int64                `json:"flagged"`
+	Unverified     int64                `json:"unverified"`
+	// FlaggedShare и UnverifiedShare - доли от всех событий камеры за период
+	FlaggedShare    float64 `json:"flagged_share"`
+	UnverifiedShare float64 `json:"unverified_share"`
+	// Daily - динамика по суткам, только в статистике одной камеры
+	Daily []DailyConfidenceInfo `json:"daily,omitempty"`
+}
+
+type DailyConfidenceInfo struct {
+	Day           time.Time `json:"day"`
+	Events        int64     `json:"events"`
+	AvgConfidence *float64  `json:"avg_confidence,omitempty"`
+	P50           *float64  `json:"p50,omitempty"`
+	Flagged       int64     `json:"flagged"`
+	Unverified    int64     `json:"unverified"`
+}
+
+// CameraStatsQuery - период статистики камер в RFC3339
+type CameraStatsQuery struct {
+	From *string
+	To   *string
+}
+
+func (s *CameraService) ListCameras(ctx context.Context) ([]CameraInfo, error) {
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cameras: %w", err)
+	}
+	result := make([]CameraInfo, 0, len(cameras))
+	for _, c := range cameras {
+		result = append(result, cameraInfo(c))
+	}
+	return result, nil
+}
+
+func (s *CameraService) GetCamera(ctx context.Context, cameraID string) (*CameraInfo, error) {
+	camera, err := s.repo.GetCamera(ctx, cameraID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera: %w", err)
+	}
+	if camera == nil {
+		return nil, fmt.Errorf("%w: camera not found", ErrNotFound)
+	}
+	info := cameraInfo(*camera)
+	return &info, nil
+}
+
+// UpsertCamera регистрирует камеру или обновляет её параметры
+func (s *CameraService) UpsertCamera(ctx context.Context, cameraID string, input CameraInput) (*CameraInfo, error) {
+	cameraID = strings.TrimSpace(cameraID)
+	if cameraID == "" {
+		return nil, fmt.Errorf("%w: camera_id is required", ErrInvalidInput)
+	}
+	if (input.Latitude == nil) != (input.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidInput)
+	}
+	if input.Latitude != nil && (*input.Latitude < -90 || *input.Latitude > 90) {
+		return nil, fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidInput)
+	}
+	if input.Longitude != nil && (*input.Longitude < -180 || *input.Longitude > 180) {
+		return nil, fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidInput)
+	}
+
+	camera := &repository.Camera{
+		CameraID:  cameraID,
+		Name:      input.Name,
+		Latitude:  input.Latitude,
+		Longitude: input.Longitude,
+	}
+	var err error
+	if camera.ConfidenceReviewBelow, err = parseThreshold(input.ConfidenceReviewBelow, "confidence_review_below"); err != nil {
+		return nil, err
+	}
+	if camera.ConfidenceUnverifiedBelow, err = parseThreshold(input.ConfidenceUnverifiedBelow, "confidence_unverified_below"); err != nil {
+		return nil, err
+	}
+	thresholds := s.thresholds(camera)
+	if thresholds.UnverifiedBelow > thresholds.ReviewBelow {
+		return nil, fmt.Errorf("%w: confidence_unverified_below must not exceed confidence_review_below", ErrInvalidInput)
+	}
+	if input.Timezone != nil && strings.TrimSpace(*input.Timezone) != "" {
+		name := strings.TrimSpace(*input.Timezone)
+		if _, err := time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, name)
+		}
+		camera.Timezone = &name
+	}
+	if camera.AllowedIPs, err = normalizeAllowedIPs(input.AllowedIPs); err != nil {
+		return nil, err
+	}
+	if input.ClientCertCN != nil && strings.TrimSpace(*input.ClientCertCN) != "" {
+		cn := strings.TrimSpace(*input.ClientCertCN)
+		camera.ClientCertCN = &cn
+	}
+	if input.PolygonID != nil && *input.PolygonID != "" {
+		id, err := uuid.Parse(*input.PolygonID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		camera.PolygonID = &id
+	}
+	if err := s.repo.UpsertCamera(ctx, camera); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.GetCamera(ctx, cameraID)
+}
+
+func (s *CameraService) DeleteCamera(ctx context.Context, cameraID string) error {
+	deleted, err := s.repo.DeleteCamera(ctx, cameraID)
+	if err != nil {
+		return fmt.Errorf("failed to delete camera: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: camera not found", ErrNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// Lookup возвращает камеру из кэша реестра; nil - камера не зарегистрирована
+func (s *CameraService) Lookup(ctx context.Context, cameraID string) (*repository.Camera, error) {
+	s.mu.RLock()
+	fresh := s.cache != nil && time.Since(s.loadedAt) < cameraCacheTTL
+	camera, ok := s.cache[cameraID]
+	s.mu.RUnlock()
+	if fresh {
+		if !ok {
+			return nil, nil
+		}
+		return &camera, nil
+	}
+
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cameras: %w", err)
+	}
+	cache := make(map[string]repository.Camera, len(cameras))
+	ids := make([]string, 0, len(cameras))
+	for _, c := range cameras {
+		cache[c.CameraID] = c
+		ids = append(ids, c.CameraID)
+	}
+	metrics.SetKnownCameras(ids)
+
+	s.mu.Lock()
+	s.cache = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	if camera, ok := cache[cameraID]; ok {
+		return &camera, nil
+	}
+	return nil, nil
+}
+
+// ReadStatus применяет политику уверенности камеры к прочтению; camera nil - камера
+// не зарегистрирована и действуют пороги по умолчанию
+func (s *CameraService) ReadStatus(camera *repository.Camera, confidence float64) string {
+	return readStatus(s.thresholds(camera), confidence)
+}
+
+func (s *CameraService) thresholds(camera *repository.Camera) ConfidenceThresholds {
+	t := ConfidenceThresholds{
+		ReviewBelow:     s.confidence.ReviewBelow,
+		UnverifiedBelow: s.confidence.UnverifiedBelow,
+	}
+	if camera == nil {
+		return t
+	}
+	if camera.ConfidenceReviewBelow != nil {
+		t.ReviewBelow = *camera.ConfidenceReviewBelow
+	}
+	if camera.ConfidenceUnverifiedBelow != nil {
+		t.UnverifiedBelow = *camera.ConfidenceUnverifiedBelow
+	}
+	return t
+}
+
+// readStatus - исход прочтения по порогам. Прочтение без уверенности (0) принимается:
+// камеры, не передающие уверенность, иначе не смогли бы вызвать ни одного срабатывания.
+func readStatus(t ConfidenceThresholds, confidence float64) string {
+	switch {
+	case confidence <= 0:
+		return anpr.ReadStatusAccepted
+	case confidence < t.UnverifiedBelow:
+		return anpr.ReadStatusUnverified
+	case confidence < t.ReviewBelow:
+		return anpr.ReadStatusFlagged
+	default:
+		return anpr.ReadStatusAccepted
+	}
+}
+
+// ConfidenceStats возвращает распределение уверенности по камерам за период
+// (по умолчанию последние 7 суток); cameraID задан - с динамикой по суткам
+func (s *CameraService) ConfidenceStats(ctx context.Context, cameraID *string, q CameraStatsQuery) ([]CameraConfidenceStats, error) {
+	from, to, err := q.window(time.Now(), 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.ConfidenceStats(ctx, cameraID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confidence stats: %w", err)
+	}
+	cameras, err := s.repo.ListCameras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cameras: %w", err)
+	}
+	registry := make(map[string]*repository.Camera, len(cameras))
+	for i := range cameras {
+		registry[cameras[i].CameraID] = &cameras[i]
+	}
+
+	result := make([]CameraConfidenceStats, 0, len(stats))
+	for _, st := range stats {
+		info := CameraConfidenceStats{
+			CameraID:       st.CameraID,
+			Thresholds:     s.thresholds(registry[st.CameraID]),
+			Events:         st.Events,
+			WithConfidence: st.WithConfidence,
+			AvgConfidence:  st.AvgConfidence,
+			P10:            st.P10,
+			P50:            st.P50,
+			P90:            st.P90,
+			Flagged:        st.Flagged,
+			Unverified:     st.Unverified,
+		}
+		if st.Events > 0 {
+			info.FlaggedShare = float64(st.Flagged) / float64(st.Events)
+			info.UnverifiedShare = float64(st.Unverified) / float64(st.Events)
+		}
+		result = append(result, info)
+	}
+
+	if cameraID == nil {
+		return result, nil
+	}
+	if len(result) == 0 {
+		if registry[*cameraID] == nil {
+			return nil, fmt.Errorf("%w: camera not found", ErrNotFound)
+		}
+		result = append(result, CameraConfidenceStats{CameraID: *cameraID, Thresholds: s.thresholds(registry[*cameraID])})
+	}
+	days, err := s.repo.DailyConfidence(ctx, *cameraID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily confidence: %w", err)
+	}
+	result[0].Daily = make([]DailyConfidenceInfo, 0, len(days))
+	for _, d := range days {
+		result[0].Daily = append(result[0].Daily, DailyConfidenceInfo(d))
+	}
+	return result, nil
+}
+
+// window возвращает период [from, to): по умолчанию period до текущего момента
+func (q CameraStatsQuery) window(now time.Time, period time.Duration) (time.Time, time.Time, error) {
+	from, err := parseOptionalTime(q.From, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseOptionalTime(q.To, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end := now
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-period)
+	if from != nil {
+		start = *from
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if end.Sub(start) > cameraStatsMaxWindow {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: period must not exceed %d days", ErrInvalidInput, int(cameraStatsMaxWindow.Hours()/24))
+	}
+	return start, end, nil
+}
+
+// parseThreshold проверяет, что порог задан долей 0–1
+func parseThreshold(raw *float64, name string) (*float64, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if !utils.ValidConfidence(*raw) {
+		return nil, fmt.Errorf("%w: %s must be between 0 and 1", ErrInvalidInput, name)
+	}
+	threshold := *raw
+	return &threshold, nil
+}
+
+func (s *CameraService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func cameraInfo(c repository.Camera) CameraInfo {
+	info := CameraInfo{
+		CameraID:                  c.CameraID,
+		Name:                      c.Name,
+		Latitude:                  c.Latitude,
+		Longitude:                 c.Longitude,
+		ConfidenceReviewBelow:     c.ConfidenceReviewBelow,
+		ConfidenceUnverifiedBelow: c.ConfidenceUnverifiedBelow,
+		Timezone:                  c.Timezone,
+		AllowedIPs:                cameraAllowedIPs(c),
+		ClientCertCN:              c.ClientCertCN,
+		HasAPIKey:                 c.APIKeyHash != nil,
+		CreatedAt:                 c.CreatedAt,
+		UpdatedAt:                 c.UpdatedAt,
+	}
+	if c.PolygonID != nil {
+		id := c.PolygonID.String()
+		info.PolygonID = &id
+	}
+	return info
+}
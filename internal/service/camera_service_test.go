@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestReadStatus(t *testing.T) {
+	thresholds := ConfidenceThresholds{ReviewBelow: 0.8, UnverifiedBelow: 0.5}
+	cases := []struct {
+		confidence float64
+		want       string
+	}{
+		{0, anpr.ReadStatusAccepted},
+		{0.2, anpr.ReadStatusUnverified},
+		{0.5, anpr.ReadStatusFlagged},
+		{0.79, anpr.ReadStatusFlagged},
+		{0.8, anpr.ReadStatusAccepted},
+		{1, anpr.ReadStatusAccepted},
+	}
+	for _, tc := range cases {
+		if got := readStatus(thresholds, tc.confidence); got != tc.want {
+			t.Errorf("confidence %v: got %s, want %s", tc.confidence, got, tc.want)
+		}
+	}
+
+	// Нулевые пороги - политика выключена
+	if got := readStatus(ConfidenceThresholds{}, 0.1); got != anpr.ReadStatusAccepted {
+		t.Errorf("zero thresholds: got %s, want %s", got, anpr.ReadStatusAccepted)
+	}
+}
+
+func TestCameraStatsWindow(t *testing.T) {
+	str := func(v string) *string { return &v }
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	from, to, err := CameraStatsQuery{}.window(now, 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !to.Equal(now) || !from.Equal(now.Add(-7*24*time.Hour)) {
+		t.Fatalf("unexpected default window: %s - %s", from, to)
+	}
+
+	invalid := []CameraStatsQuery{
+		{From: str("2025-03-10T12:00:00Z"), To: str("2025-03-01T00:00:00Z")},
+		{From: str("2024-01-01T00:00:00Z")},
+		{To: str("tomorrow")},
+	}
+	for _, q := range invalid {
+		if _, _, err := q.window(now, 7*24*time.Hour); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", q, err)
+		}
+	}
+}
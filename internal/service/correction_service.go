@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// CorrectionService исправляет ошибки распознавания: перепривязывает события к настоящему
+// номеру и сливает ошибочные номера с настоящими вместе с производными данными
+type CorrectionService struct {
+	repo   correctionStore
+	plates plateStore
+	log    zerolog.Logger
+}
+
+// correctionStore - методы CorrectionRepository, на которых построены исправления
+type correctionStore interface {
+	GetEvent(ctx context.Context, id uuid.UUID) (*repository.ANPREvent, error)
+	CorrectEvent(ctx context.Context, correction repository.EventCorrection) error
+	AttachPlate(ctx context.Context, event repository.ANPREvent, target repository.Plate, attachedBy *uuid.UUID, note string) (bool, error)
+	VerifyEvent(ctx context.Context, event repository.ANPREvent, verifiedBy *uuid.UUID) error
+	MergePlates(ctx context.Context, source, target repository.Plate, mergedBy *uuid.UUID, reason string) (*repository.PlateMerge, error)
+	CountPlateVehicles(ctx context.Context, plateID uuid.UUID) (int64, error)
+	FindMerges(ctx context.Context, plateID *uuid.UUID, limit, offset int) ([]repository.PlateMerge, error)
+	FindPendingRecomputes(ctx context.Context, limit int) ([]repository.PlateRecompute, error)
+	MarkRecomputed(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+// plateStore - методы ANPRRepository для поиска и создания номеров
+type plateStore interface {
+	GetPlate(ctx context.Context, id uuid.UUID) (*repository.Plate, error)
+	GetOrCreatePlate(ctx context.Context, normalized, original string) (uuid.UUID, error)
+}
+
+func NewCorrectionService(repo *repository.CorrectionRepository, plates *repository.ANPRRepository, log zerolog.Logger) *CorrectionService {
+	return &CorrectionService{
+		repo:   repo,
+		plates: plates,
+		log:    log,
+	}
+}
+
+type EventCorrectionInput struct {
+	Plate  string `json:"plate" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AttachPlateInput - номер, который оператор увидел на снимке проезда без номера
+type AttachPlateInput struct {
+	Plate string  `json:"plate" binding:"required"`
+	Note  *string `json:"note"`
+}
+
+// PlateMergeInput - настоящий номер задаётся TargetPlateID или строкой TargetPlate
+type PlateMergeInput struct {
+	SourcePlateID string  `json:"source_plate_id" binding:"required"`
+	TargetPlateID *string `json:"target_plate_id"`
+	TargetPlate   *string `json:"target_plate"`
+	Reason        string  `json:"reason" binding:"required"`
+}
+
+type PlateMergeInfo struct {
+	ID               string    `json:"id"`
+	SourcePlateID    string    `json:"source_plate_id"`
+	SourcePlate      string    `json:"source_plate"`
+	TargetPlateID    string    `json:"target_plate_id"`
+	TargetPlate      string    `json:"target_plate"`
+	EventsMoved      int64     `json:"events_moved"`
+	MembershipsMoved int64     `json:"memberships_moved"`
+	MergedBy         *string   `json:"merged_by,omitempty"`
+	Reason           string    `json:"reason"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// PlateRecomputeInfo - сигнал пересчёта производных данных номера (поездки, отчёты)
+type PlateRecomputeInfo struct {
+	ID        string    `json:"id"`
+	PlateID   string    `json:"plate_id"`
+	Plate     string    `json:"plate"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RecomputeAckInput - сигналы, пересчёт по которым выполнен
+type RecomputeAckInput struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// maxRecomputeBatch - сколько сигналов пересчёта отдаётся за раз
+const maxRecomputeBatch = 500
+
+// CorrectEvent привязывает событие к исправленному номеру (создавая его при необходимости).
+// raw_plate не меняется, номер до исправления сохраняется в original_plate_id.
+func (s *CorrectionService) CorrectEvent(ctx context.Context, eventID string, input EventCorrectionInput, correctedBy *uuid.UUID) (*EventInfo, error) {
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidInput)
+	}
+	normalized := utils.NormalizePlate(input.Plate)
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: plate cannot be empty after normalization", ErrInvalidInput)
+	}
+
+	event, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if event == nil {
+		return nil, fmt.Errorf("%w: event not found", ErrNotFound)
+	}
+	if event.ReadStatus == anpr.ReadStatusPlateless {
+		return nil, fmt.Errorf("%w: event has no plate, attach a plate instead", ErrInvalidInput)
+	}
+	if event.NormalizedPlate == normalized {
+		return nil, fmt.Errorf("%w: event already has plate %s", ErrInvalidInput, normalized)
+	}
+
+	targetID, err := s.plates.GetOrCreatePlate(ctx, normalized, input.Plate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create plate: %w", err)
+	}
+	err = s.repo.CorrectEvent(ctx, repository.EventCorrection{
+		EventID:     id,
+		Target:      repository.Plate{ID: targetID, Normalized: normalized},
+		ReadPlate:   utils.NormalizePlate(event.RawPlate),
+		CorrectedBy: correctedBy,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to correct event: %w", err)
+	}
+
+	s.log.Info().
+		Str("event_id", eventID).
+		Str("from", event.NormalizedPlate).
+		Str("to", normalized).
+		Msg("event plate corrected")
+
+	corrected, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	info := eventInfoFromRecord(*corrected)
+	return &info, nil
+}
+
+// AttachPlate привязывает к проезду без номера номер, установленный оператором по снимку.
+// Событие становится обычным подтверждённым прочтением; сверка со списками задним числом не выполняется.
+func (s *CorrectionService) AttachPlate(ctx context.Context, eventID string, input AttachPlateInput, attachedBy *uuid.UUID) (*EventInfo, error) {
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	normalized := utils.NormalizePlate(input.Plate)
+	if normalized == "" {
+		return nil, fmt.Errorf("%w: plate cannot be empty after normalization", ErrInvalidInput)
+	}
+	note := "plate attached from snapshot"
+	if input.Note != nil && strings.TrimSpace(*input.Note) != "" {
+		note = strings.TrimSpace(*input.Note)
+	}
+
+	event, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if event == nil {
+		return nil, fmt.Errorf("%w: event not found", ErrNotFound)
+	}
+	if event.ReadStatus != anpr.ReadStatusPlateless {
+		return nil, fmt.Errorf("%w: event has a plate, use plate correction instead", ErrInvalidInput)
+	}
+
+	targetID, err := s.plates.GetOrCreatePlate(ctx, normalized, input.Plate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create plate: %w", err)
+	}
+	attached, err := s.repo.AttachPlate(ctx, *event, repository.Plate{ID: targetID, Normalized: normalized}, attachedBy, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach plate: %w", err)
+	}
+	if !attached {
+		return nil, fmt.Errorf("%w: event has a plate, use plate correction instead", ErrInvalidInput)
+	}
+
+	s.log.Info().
+		Str("event_id", eventID).
+		Str("plate", normalized).
+		Msg("plate attached to plateless passage")
+
+	updated, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	info := eventInfoFromRecord(*updated)
+	return &info, nil
+}
+
+// VerifyEvent подтверждает прочтение, помеченное на проверку или сохранённое непроверенным.
+// Сверка со списками задним числом не выполняется.
+func (s *CorrectionService) VerifyEvent(ctx context.Context, eventID string, verifiedBy *uuid.UUID) (*EventInfo, error) {
+	id, err := uuid.Parse(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid event id", ErrInvalidInput)
+	}
+	event, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	if event == nil {
+		return nil, fmt.Errorf("%w: event not found", ErrNotFound)
+	}
+	if event.ReadStatus == anpr.ReadStatusAccepted {
+		return nil, fmt.Errorf("%w: event read is already accepted", ErrInvalidInput)
+	}
+	if event.ReadStatus == anpr.ReadStatusPlateless {
+		return nil, fmt.Errorf("%w: event has no plate, attach a plate instead", ErrInvalidInput)
+	}
+
+	if err := s.repo.VerifyEvent(ctx, *event, verifiedBy); err != nil {
+		return nil, fmt.Errorf("failed to verify event: %w", err)
+	}
+
+	s.log.Info().
+		Str("event_id", eventID).
+		Str("plate", event.NormalizedPlate).
+		Str("read_status", event.ReadStatus).
+		Msg("event read verified")
+
+	verified, err := s.repo.GetEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	info := eventInfoFromRecord(*verified)
+	return &info, nil
+}
+
+// MergePlates сливает ошибочный номер с настоящим. Номер, принадлежащий ТС реестра,
+// ошибочным не считается и слиянию не подлежит.
+func (s *CorrectionService) MergePlates(ctx context.Context, input PlateMergeInput, mergedBy *uuid.UUID) (*PlateMergeInfo, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidInput)
+	}
+	sourceID, err := uuid.Parse(input.SourcePlateID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid source_plate_id", ErrInvalidInput)
+	}
+	source, err := s.plates.GetPlate(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate: %w", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: source plate not found", ErrNotFound)
+	}
+
+	target, err := s.mergeTarget(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID == source.ID {
+		return nil, fmt.Errorf("%w: source and target plates are the same", ErrInvalidInput)
+	}
+
+	vehicles, err := s.repo.CountPlateVehicles(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check plate vehicles: %w", err)
+	}
+	if vehicles > 0 {
+		return nil, fmt.Errorf("%w: source plate belongs to a registered vehicle", ErrInvalidInput)
+	}
+
+	merge, err := s.repo.MergePlates(ctx, *source, *target, mergedBy, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge plates: %w", err)
+	}
+
+	s.log.Info().
+		Str("source", source.Normalized).
+		Str("target", target.Normalized).
+		Int64("events_moved", merge.EventsMoved).
+		Int64("memberships_moved", merge.MembershipsMoved).
+		Msg("plates merged")
+
+	info := plateMergeInfo(*merge)
+	return &info, nil
+}
+
+func (s *CorrectionService) mergeTarget(ctx context.Context, input PlateMergeInput) (*repository.Plate, error) {
+	switch {
+	case input.TargetPlateID != nil:
+		id, err := uuid.Parse(*input.TargetPlateID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid target_plate_id", ErrInvalidInput)
+		}
+		plate, err := s.plates.GetPlate(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plate: %w", err)
+		}
+		if plate == nil {
+			return nil, fmt.Errorf("%w: target plate not found", ErrNotFound)
+		}
+		return plate, nil
+	case input.TargetPlate != nil:
+		normalized := utils.NormalizePlate(*input.TargetPlate)
+		if normalized == "" {
+			return nil, fmt.Errorf("%w: target_plate cannot be empty after normalization", ErrInvalidInput)
+		}
+		id, err := s.plates.GetOrCreatePlate(ctx, normalized, *input.TargetPlate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get or create plate: %w", err)
+		}
+		return &repository.Plate{ID: id, Normalized: normalized}, nil
+	}
+	return nil, fmt.Errorf("%w: target_plate_id or target_plate is required", ErrInvalidInput)
+}
+
+func (s *CorrectionService) FindMerges(ctx context.Context, plateID *string, limit, offset int) ([]PlateMergeInfo, error) {
+	var filter *uuid.UUID
+	if plateID != nil {
+		id, err := uuid.Parse(*plateID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid plate_id", ErrInvalidInput)
+		}
+		filter = &id
+	}
+	merges, err := s.repo.FindMerges(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate merges: %w", err)
+	}
+	result := make([]PlateMergeInfo, 0, len(merges))
+	for _, m := range merges {
+		result = append(result, plateMergeInfo(m))
+	}
+	return result, nil
+}
+
+// PendingRecomputes возвращает неподтверждённые сигналы пересчёта: исправления, привязка номера
+// к проезду и слияния записывают их в той же транзакции, что и перенос событий
+func (s *CorrectionService) PendingRecomputes(ctx context.Context, limit int) ([]PlateRecomputeInfo, error) {
+	if limit <= 0 || limit > maxRecomputeBatch {
+		limit = maxRecomputeBatch
+	}
+	recomputes, err := s.repo.FindPendingRecomputes(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate recomputes: %w", err)
+	}
+	result := make([]PlateRecomputeInfo, 0, len(recomputes))
+	for _, r := range recomputes {
+		result = append(result, PlateRecomputeInfo{
+			ID:        r.ID.String(),
+			PlateID:   r.PlateID.String(),
+			Plate:     r.NormalizedPlate,
+			Reason:    r.Reason,
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// AcknowledgeRecomputes отмечает сигналы пересчёта выполненными
+func (s *CorrectionService) AcknowledgeRecomputes(ctx context.Context, input RecomputeAckInput) (int64, error) {
+	if len(input.IDs) == 0 || len(input.IDs) > maxRecomputeBatch {
+		return 0, fmt.Errorf("%w: ids must contain 1..%d items", ErrInvalidInput, maxRecomputeBatch)
+	}
+	ids := make([]uuid.UUID, 0, len(input.IDs))
+	for _, raw := range input.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid id %q", ErrInvalidInput, raw)
+		}
+		ids = append(ids, id)
+	}
+	acknowledged, err := s.repo.MarkRecomputed(ctx, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acknowledge plate recomputes: %w", err)
+	}
+	return acknowledged, nil
+}
+
+func plateMergeInfo(m repository.PlateMerge) PlateMergeInfo {
+	return PlateMergeInfo{
+		ID:               m.ID.String(),
+		SourcePlateID:    m.SourcePlateID.String(),
+		SourcePlate:      m.SourcePlate,
+		TargetPlateID:    m.TargetPlateID.String(),
+		TargetPlate:      m.TargetPlate,
+		EventsMoved:      m.EventsMoved,
+		MembershipsMoved: m.MembershipsMoved,
+		MergedBy:         uuidString(m.MergedBy),
+		Reason:           m.Reason,
+		CreatedAt:        m.CreatedAt,
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/db/dbtest"
+	"anpr-service/internal/repository"
+)
+
+// fakeCorrections - хранилище исправлений без БД: ТС реестра и вызовы слияния
+type fakeCorrections struct {
+	correctionStore
+	vehicles map[uuid.UUID]int64
+	merges   int
+}
+
+func (f *fakeCorrections) CountPlateVehicles(_ context.Context, plateID uuid.UUID) (int64, error) {
+	return f.vehicles[plateID], nil
+}
+
+func (f *fakeCorrections) MergePlates(_ context.Context, source, target repository.Plate, _ *uuid.UUID, reason string) (*repository.PlateMerge, error) {
+	f.merges++
+	return &repository.PlateMerge{ID: uuid.New(), SourcePlateID: source.ID, TargetPlateID: target.ID, Reason: reason}, nil
+}
+
+// fakePlates - номера в памяти
+type fakePlates map[uuid.UUID]repository.Plate
+
+func (f fakePlates) GetPlate(_ context.Context, id uuid.UUID) (*repository.Plate, error) {
+	plate, ok := f[id]
+	if !ok {
+		return nil, nil
+	}
+	return &plate, nil
+}
+
+func (f fakePlates) GetOrCreatePlate(_ context.Context, normalized, _ string) (uuid.UUID, error) {
+	for id, plate := range f {
+		if plate.Normalized == normalized {
+			return id, nil
+		}
+	}
+	id := uuid.New()
+	f[id] = repository.Plate{ID: id, Normalized: normalized}
+	return id, nil
+}
+
+func TestMergePlatesValidation(t *testing.T) {
+	junk := repository.Plate{ID: uuid.New(), Normalized: "123A8C02"}
+	real := repository.Plate{ID: uuid.New(), Normalized: "123ABC02"}
+	registered := repository.Plate{ID: uuid.New(), Normalized: "777AAA02"}
+	plates := fakePlates{junk.ID: junk, real.ID: real, registered.ID: registered}
+
+	id := func(plate repository.Plate) *string {
+		value := plate.ID.String()
+		return &value
+	}
+	sameByText := "123 a8c 02"
+
+	tests := []struct {
+		name  string
+		input PlateMergeInput
+		want  string
+	}{
+		{"same plate id", PlateMergeInput{SourcePlateID: junk.ID.String(), TargetPlateID: id(junk), Reason: "ocr"}, "are the same"},
+		{"same plate text", PlateMergeInput{SourcePlateID: junk.ID.String(), TargetPlate: &sameByText, Reason: "ocr"}, "are the same"},
+		{"registered vehicle", PlateMergeInput{SourcePlateID: registered.ID.String(), TargetPlateID: id(real), Reason: "ocr"}, "registered vehicle"},
+		{"no target", PlateMergeInput{SourcePlateID: junk.ID.String(), Reason: "ocr"}, "is required"},
+		{"no reason", PlateMergeInput{SourcePlateID: junk.ID.String(), TargetPlateID: id(real), Reason: " "}, "reason is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeCorrections{vehicles: map[uuid.UUID]int64{registered.ID: 1}}
+			svc := &CorrectionService{repo: store, plates: plates, log: zerolog.Nop()}
+
+			_, err := svc.MergePlates(context.Background(), tt.input, nil)
+			if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want invalid input %q", err, tt.want)
+			}
+			if store.merges != 0 {
+				t.Error("rejected merge must not reach the repository")
+			}
+		})
+	}
+
+	store := &fakeCorrections{}
+	svc := &CorrectionService{repo: store, plates: plates, log: zerolog.Nop()}
+	if _, err := svc.MergePlates(context.Background(), PlateMergeInput{SourcePlateID: junk.ID.String(), TargetPlateID: id(real), Reason: "ocr"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if store.merges != 1 {
+		t.Errorf("valid merge: %d repository calls, want 1", store.merges)
+	}
+}
+
+// Тесты ниже работают с PostgreSQL (TEST_DB_DSN): переносы выполняет SQL репозитория
+
+func newCorrectionServiceDB(t *testing.T) (*CorrectionService, *gorm.DB) {
+	database := dbtest.Open(t)
+	return NewCorrectionService(repository.NewCorrectionRepository(database), repository.NewANPRRepository(database), zerolog.Nop()), database
+}
+
+// createPlate создаёт номер с уникальной строкой, чтобы тесты не зависели от данных прошлых запусков
+func createPlate(t *testing.T, database *gorm.DB) repository.Plate {
+	t.Helper()
+	normalized := "T" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:11])
+	plate := repository.Plate{ID: uuid.New(), Number: normalized, Normalized: normalized}
+	if err := database.Create(&plate).Error; err != nil {
+		t.Fatal(err)
+	}
+	return plate
+}
+
+func createList(t *testing.T, database *gorm.DB, plates ...repository.Plate) repository.List {
+	t.Helper()
+	list := repository.List{ID: uuid.New(), Name: "test-" + uuid.NewString(), Type: "BLACKLIST"}
+	if err := database.Create(&list).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, plate := range plates {
+		if err := database.Create(&repository.ListItem{ListID: list.ID, PlateID: plate.ID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return list
+}
+
+func mergeInput(source, target repository.Plate) PlateMergeInput {
+	targetID := target.ID.String()
+	return PlateMergeInput{SourcePlateID: source.ID.String(), TargetPlateID: &targetID, Reason: "ocr misread"}
+}
+
+func TestMergePlatesMembershipConflicts(t *testing.T) {
+	svc, database := newCorrectionServiceDB(t)
+	ctx := context.Background()
+	source, target := createPlate(t, database), createPlate(t, database)
+	shared := createList(t, database, source, target)
+	sourceOnly := createList(t, database, source)
+
+	merge, err := svc.MergePlates(ctx, mergeInput(source, target), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Членство в общем списке уже есть у настоящего номера: ON CONFLICT DO NOTHING его не считает
+	if merge.MembershipsMoved != 1 {
+		t.Errorf("memberships_moved = %d, want 1", merge.MembershipsMoved)
+	}
+
+	var lists []uuid.UUID
+	if err := database.Model(&repository.ListItem{}).Where("plate_id = ?", target.ID).Order("list_id").Pluck("list_id", &lists).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 2 || !containsUUID(lists, shared.ID) || !containsUUID(lists, sourceOnly.ID) {
+		t.Errorf("target lists = %v, want both %s and %s", lists, shared.ID, sourceOnly.ID)
+	}
+	var left int64
+	database.Model(&repository.ListItem{}).Where("plate_id = ?", source.ID).Count(&left)
+	if left != 0 {
+		t.Errorf("%d memberships left on the source plate", left)
+	}
+}
+
+func TestMergePlatesClosesPendingReviewItems(t *testing.T) {
+	svc, database := newCorrectionServiceDB(t)
+	ctx := context.Background()
+	source, target := createPlate(t, database), createPlate(t, database)
+
+	item := repository.ReviewItem{
+		ID:              uuid.New(),
+		Status:          repository.ReviewStatusPending,
+		PlateID:         source.ID,
+		NormalizedPlate: source.Normalized,
+		PolygonID:       uuid.New(),
+		CameraID:        "cam-1",
+		FirstEventID:    uuid.New(),
+		LastEventID:     uuid.New(),
+		Occurrences:     1,
+	}
+	if err := database.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	mergedBy := uuid.New()
+	if _, err := svc.MergePlates(ctx, mergeInput(source, target), &mergedBy); err != nil {
+		t.Fatal(err)
+	}
+
+	var resolved repository.ReviewItem
+	if err := database.Where("id = ?", item.ID).First(&resolved).Error; err != nil {
+		t.Fatalf("review item must survive the merge: %v", err)
+	}
+	if resolved.Status != repository.ReviewStatusMisread || resolved.PlateID != target.ID {
+		t.Errorf("status %s, plate %s; want misread on %s", resolved.Status, resolved.PlateID, target.ID)
+	}
+	if resolved.CorrectedPlateID == nil || *resolved.CorrectedPlateID != target.ID || resolved.ResolvedBy == nil || *resolved.ResolvedBy != mergedBy {
+		t.Errorf("resolution not recorded: %+v", resolved)
+	}
+}
+
+func TestMergeHistorySurvivesLaterMerge(t *testing.T) {
+	svc, database := newCorrectionServiceDB(t)
+	ctx := context.Background()
+	first, second, final := createPlate(t, database), createPlate(t, database), createPlate(t, database)
+
+	if _, err := svc.MergePlates(ctx, mergeInput(first, second), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Целевой номер первого слияния сам оказывается ошибочным
+	if _, err := svc.MergePlates(ctx, mergeInput(second, final), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	secondID := second.ID.String()
+	history, err := svc.FindMerges(ctx, &secondID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Errorf("merges of %s = %d, want 2 (as target and as source)", second.Normalized, len(history))
+	}
+}
+
+func TestMergePlatesRequestsRecompute(t *testing.T) {
+	svc, database := newCorrectionServiceDB(t)
+	ctx := context.Background()
+	source, target := createPlate(t, database), createPlate(t, database)
+
+	if _, err := svc.MergePlates(ctx, mergeInput(source, target), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var recomputes []repository.PlateRecompute
+	err := database.Where("plate_id IN ? AND processed_at IS NULL", []uuid.UUID{source.ID, target.ID}).Find(&recomputes).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recomputes) != 2 {
+		t.Fatalf("recomputes = %+v, want one per plate", recomputes)
+	}
+	ids := make([]string, 0, len(recomputes))
+	for _, r := range recomputes {
+		if r.Reason != repository.RecomputeReasonPlateMerge {
+			t.Errorf("reason = %s, want %s", r.Reason, repository.RecomputeReasonPlateMerge)
+		}
+		ids = append(ids, r.ID.String())
+	}
+	acknowledged, err := svc.AcknowledgeRecomputes(ctx, RecomputeAckInput{IDs: ids})
+	if err != nil || acknowledged != 2 {
+		t.Errorf("acknowledged %d, %v; want 2", acknowledged, err)
+	}
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
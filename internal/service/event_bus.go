@@ -0,0 +1,52 @@
+package service
+
+import "sync"
+
+// EventBus рассылает принятые события подписчикам внутри процесса (gRPC WatchEvents).
+// Медленный подписчик не блокирует приём: события, не поместившиеся в буфер, отбрасываются.
+type EventBus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]chan EventInfo
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]chan EventInfo)}
+}
+
+// Subscribe возвращает канал событий и функцию отписки
+func (b *EventBus) Subscribe(buffer int) (<-chan EventInfo, func()) {
+	ch := make(chan EventInfo, buffer)
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish рассылает событие, возвращает количество подписчиков, которым оно не поместилось
+func (b *EventBus) Publish(event EventInfo) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	dropped := 0
+	for _, ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
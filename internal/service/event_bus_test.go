@@ -0,0 +1,23 @@
+package service
+
+import "testing"
+
+func TestEventBusDropsForSlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	fast, unsubscribeFast := bus.Subscribe(2)
+	defer unsubscribeFast()
+	_, unsubscribeSlow := bus.Subscribe(0)
+
+	if dropped := bus.Publish(EventInfo{ID: "1"}); dropped != 1 {
+		t.Fatalf("expected unbuffered subscriber to drop, dropped=%d", dropped)
+	}
+	if e := <-fast; e.ID != "1" {
+		t.Fatalf("unexpected event %q", e.ID)
+	}
+
+	unsubscribeSlow()
+	unsubscribeSlow()
+	if dropped := bus.Publish(EventInfo{ID: "2"}); dropped != 0 {
+		t.Fatalf("unsubscribed channel must not receive events, dropped=%d", dropped)
+	}
+}
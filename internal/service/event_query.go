@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+const (
+	EventSortTimeDesc = "-event_time"
+	EventSortTimeAsc  = "event_time"
+
+	defaultEventsLimit = 50
+	maxEventsLimit     = 100
+)
+
+// EventQuery - параметры GET /events в строковом виде, как они пришли из запроса
+type EventQuery struct {
+	Plate         *string `json:"plate,omitempty"`
+	PlatePrefix   *string `json:"plate_prefix,omitempty"`
+	From          *string `json:"from,omitempty"`
+	To            *string `json:"to,omitempty"`
+	CameraID      *string `json:"camera_id,omitempty"`
+	PolygonID     *string `json:"polygon_id,omitempty"`
+	Direction     *string `json:"direction,omitempty"`
+	Lane          *string `json:"lane,omitempty"`
+	VehicleType   *string `json:"vehicle_type,omitempty"`
+	VehicleColor  *string `json:"vehicle_color,omitempty"`
+	MinConfidence *string `json:"min_confidence,omitempty"`
+	MaxConfidence *string `json:"max_confidence,omitempty"`
+	ListType      *string `json:"list_type,omitempty"`
+	MatchedSnow   *string `json:"matched_snow,omitempty"`
+	// ReadStatus - accepted, flagged, unverified или plateless
+	ReadStatus *string `json:"read_status,omitempty"`
+	// Auth - api_key, signature, mtls, untrusted или none
+	Auth *string `json:"auth,omitempty"`
+	// Sort - -event_time (по умолчанию, новые первыми) или event_time
+	Sort   string `json:"sort,omitempty"`
+	Cursor string `json:"-"`
+	Limit  int    `json:"-"`
+	// Offset - устаревшая пагинация, игнорируется при наличии курсора
+	Offset       int  `json:"-"`
+	IncludeTotal bool `json:"-"`
+}
+
+type EventPage struct {
+	Events     []EventInfo `json:"data"`
+	NextCursor *string     `json:"next_cursor"`
+	Total      *int64      `json:"total,omitempty"`
+}
+
+// eventCursor - содержимое непрозрачного курсора
+type eventCursor struct {
+	EventTime time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Sort      string    `json:"s"`
+}
+
+func encodeEventCursor(c eventCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeEventCursor(value string) (eventCursor, error) {
+	var c eventCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil || c.EventTime.IsZero() {
+		return c, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	return c, nil
+}
+
+// FindEventsPage ищет события с keyset-пагинацией по (event_time, id)
+func (s *ANPRService) FindEventsPage(ctx context.Context, q EventQuery) (*EventPage, error) {
+	filter, err := q.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	sort, err := q.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultEventsLimit
+	}
+	if limit > maxEventsLimit {
+		limit = maxEventsLimit
+	}
+
+	var after *repository.EventCursor
+	offset := q.Offset
+	if q.Cursor != "" {
+		c, err := decodeEventCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sort {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %s", ErrInvalidInput, c.Sort)
+		}
+		after = &repository.EventCursor{EventTime: c.EventTime, ID: c.ID}
+		offset = 0
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Читаем на одну запись больше, чтобы знать, есть ли следующая страница
+	events, err := s.repo.FindEventsPage(ctx, filter, after, sort == EventSortTimeAsc, limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find events: %w", err)
+	}
+
+	page := &EventPage{}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		next := encodeEventCursor(eventCursor{EventTime: last.EventTime, ID: last.ID, Sort: sort})
+		page.NextCursor = &next
+	}
+	page.Events = make([]EventInfo, 0, len(events))
+	for _, e := range events {
+		page.Events = append(page.Events, eventInfoFromRecord(e))
+	}
+
+	if q.IncludeTotal {
+		total, err := s.repo.CountEvents(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count events: %w", err)
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+func (q EventQuery) sort() (string, error) {
+	switch q.Sort {
+	case "":
+		return EventSortTimeDesc, nil
+	case EventSortTimeDesc, EventSortTimeAsc:
+		return q.Sort, nil
+	default:
+		return "", fmt.Errorf("%w: sort must be %s or %s", ErrInvalidInput, EventSortTimeDesc, EventSortTimeAsc)
+	}
+}
+
+func (q EventQuery) filter() (repository.EventFilter, error) {
+	var f repository.EventFilter
+
+	if q.Plate != nil {
+		if normalized := utils.NormalizePlate(*q.Plate); normalized != "" {
+			f.NormalizedPlate = &normalized
+		}
+	}
+	if q.PlatePrefix != nil {
+		if normalized := utils.NormalizePlate(*q.PlatePrefix); normalized != "" {
+			f.PlatePrefix = &normalized
+		}
+	}
+
+	var err error
+	if f.From, err = parseOptionalTime(q.From, "from"); err != nil {
+		return f, err
+	}
+	if f.To, err = parseOptionalTime(q.To, "to"); err != nil {
+		return f, err
+	}
+	if q.PolygonID != nil {
+		id, err := uuid.Parse(*q.PolygonID)
+		if err != nil {
+			return f, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		f.PolygonID = &id
+	}
+	if q.Lane != nil {
+		lane, err := strconv.Atoi(*q.Lane)
+		if err != nil {
+			return f, fmt.Errorf("%w: invalid lane", ErrInvalidInput)
+		}
+		f.Lane = &lane
+	}
+	if f.MinConfidence, err = parseOptionalFloat(q.MinConfidence, "min_confidence"); err != nil {
+		return f, err
+	}
+	if f.MaxConfidence, err = parseOptionalFloat(q.MaxConfidence, "max_confidence"); err != nil {
+		return f, err
+	}
+	for _, bound := range []*float64{f.MinConfidence, f.MaxConfidence} {
+		if bound != nil && !utils.ValidConfidence(*bound) {
+			return f, fmt.Errorf("%w: min_confidence and max_confidence must be between 0 and 1", ErrInvalidInput)
+		}
+	}
+	if q.ListType != nil {
+		listType := strings.ToUpper(*q.ListType)
+		switch listType {
+		case "WHITELIST", "BLACKLIST", repository.RetentionListTypeNone:
+		default:
+			return f, fmt.Errorf("%w: list_type must be WHITELIST, BLACKLIST or NONE", ErrInvalidInput)
+		}
+		f.ListType = &listType
+	}
+	if q.MatchedSnow != nil {
+		matched, err := strconv.ParseBool(*q.MatchedSnow)
+		if err != nil {
+			return f, fmt.Errorf("%w: invalid matched_snow", ErrInvalidInput)
+		}
+		f.MatchedSnow = &matched
+	}
+	if q.ReadStatus != nil {
+		status := strings.ToLower(*q.ReadStatus)
+		switch status {
+		case anpr.ReadStatusAccepted, anpr.ReadStatusFlagged, anpr.ReadStatusUnverified, anpr.ReadStatusPlateless:
+		default:
+			return f, fmt.Errorf("%w: read_status must be accepted, flagged, unverified or plateless", ErrInvalidInput)
+		}
+		f.ReadStatus = &status
+	}
+	if q.Auth != nil {
+		auth := strings.ToLower(*q.Auth)
+		switch auth {
+		case anpr.AuthAPIKey, anpr.AuthSignature, anpr.AuthMTLS, anpr.AuthUntrusted, repository.EventAuthNone:
+		default:
+			return f, fmt.Errorf("%w: auth must be api_key, signature, mtls, untrusted or none", ErrInvalidInput)
+		}
+		f.Auth = &auth
+	}
+
+	f.CameraID = q.CameraID
+	f.Direction = q.Direction
+	f.VehicleType = q.VehicleType
+	f.VehicleColor = q.VehicleColor
+	return f, nil
+}
+
+func parseOptionalTime(value *string, name string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s time format", ErrInvalidInput, name)
+	}
+	return &t, nil
+}
+
+func parseOptionalFloat(value *string, name string) (*float64, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(*value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidInput, name)
+	}
+	return &f, nil
+}
+
+func eventInfoFromRecord(e repository.ANPREvent) EventInfo {
+	var plateID *string
+	if e.PlateID != nil {
+		id := e.PlateID.String()
+		plateID = &id
+	}
+	return EventInfo{
+		ID:                e.ID.String(),
+		PlateID:           plateID,
+		CameraID:          e.CameraID,
+		CameraModel:       e.CameraModel,
+		Direction:         e.Direction,
+		Lane:              e.Lane,
+		RawPlate:          e.RawPlate,
+		NormalizedPlate:   e.NormalizedPlate,
+		Confidence:        e.Confidence,
+		VehicleColor:      e.VehicleColor,
+		VehicleType:       e.VehicleType,
+		VehicleBrand:      e.VehicleBrand,
+		VehicleModel:      e.VehicleModel,
+		VehicleCountry:    e.VehicleCountry,
+		VehiclePlateColor: e.VehiclePlateColor,
+		VehicleSpeed:      e.VehicleSpeed,
+		SnapshotURL:       e.SnapshotURL,
+		EventTime:         e.EventTime,
+		OriginalPlateID:   uuidString(e.OriginalPlateID),
+		CorrectedBy:       uuidString(e.CorrectedBy),
+		CorrectedAt:       e.CorrectedAt,
+		CorrectionReason:  e.CorrectionReason,
+		ReadStatus:        e.ReadStatus,
+		VerifiedBy:        uuidString(e.VerifiedBy),
+		VerifiedAt:        e.VerifiedAt,
+		ReceivedAt:        e.ReceivedAt,
+		ClockSkewSeconds:  e.ClockSkewSeconds,
+		TimeSource:        e.TimeSource,
+		PairedEventID:     uuidString(e.PairedEventID),
+		Candidates:        storedCandidates(e.PlateCandidates),
+		Auth:              e.Auth,
+	}
+}
+
+// storedCandidates разбирает plate_candidates события; повреждённое значение не мешает выдаче
+func storedCandidates(raw datatypes.JSON) []anpr.PlateCandidate {
+	if len(raw) == 0 {
+		return nil
+	}
+	var candidates []anpr.PlateCandidate
+	if err := json.Unmarshal(raw, &candidates); err != nil {
+		return nil
+	}
+	return candidates
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEventCursorRoundTrip(t *testing.T) {
+	c := eventCursor{
+		EventTime: time.Date(2025, 1, 21, 12, 34, 56, 123456000, time.UTC),
+		ID:        uuid.New(),
+		Sort:      EventSortTimeDesc,
+	}
+
+	decoded, err := decodeEventCursor(encodeEventCursor(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.EventTime.Equal(c.EventTime) || decoded.ID != c.ID || decoded.Sort != c.Sort {
+		t.Fatalf("cursor mismatch: %+v != %+v", decoded, c)
+	}
+
+	for _, bad := range []string{"not base64!", "e30", encodeEventCursor(eventCursor{Sort: EventSortTimeAsc})} {
+		if _, err := decodeEventCursor(bad); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("cursor %q: expected ErrInvalidInput, got %v", bad, err)
+		}
+	}
+}
+
+func TestEventQueryFilter(t *testing.T) {
+	str := func(v string) *string { return &v }
+
+	f, err := EventQuery{
+		Plate:         str("123 abc 02"),
+		PlatePrefix:   str("123a"),
+		Lane:          str("2"),
+		MinConfidence: str("0.8"),
+		MaxConfidence: str("0.95"),
+		ListType:      str("blacklist"),
+		MatchedSnow:   str("true"),
+		ReadStatus:    str("Flagged"),
+		Auth:          str("Untrusted"),
+	}.filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *f.NormalizedPlate != "123ABC02" || *f.PlatePrefix != "123A" {
+		t.Fatalf("plates must be normalized: %q %q", *f.NormalizedPlate, *f.PlatePrefix)
+	}
+	if *f.Lane != 2 || *f.MinConfidence != 0.8 || *f.MaxConfidence != 0.95 || *f.ListType != "BLACKLIST" ||
+		!*f.MatchedSnow || *f.ReadStatus != "flagged" || *f.Auth != "untrusted" {
+		t.Fatalf("unexpected filter: %+v", f)
+	}
+
+	invalid := []EventQuery{
+		{From: str("yesterday")},
+		{PolygonID: str("abc")},
+		{Lane: str("x")},
+		{MaxConfidence: str("95")},
+		{ListType: str("GRAYLIST")},
+		{MatchedSnow: str("maybe")},
+		{ReadStatus: str("rejected")},
+		{Auth: str("password")},
+	}
+	for _, q := range invalid {
+		if _, err := q.filter(); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%+v: expected ErrInvalidInput, got %v", q, err)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/archive"
+	"anpr-service/internal/config"
+	"anpr-service/internal/export"
+	"anpr-service/internal/repository"
+)
+
+const (
+	exportBatchSize       = 1000
+	exportPollInterval    = 30 * time.Second
+	exportCleanupInterval = time.Hour
+)
+
+// ExportRequest - параметры выгрузки: те же фильтры, что у GET /events
+type ExportRequest struct {
+	Query            EventQuery
+	Format           string
+	IncludeSnapshots bool
+}
+
+type ExportJobInfo struct {
+	ID               string     `json:"id"`
+	Status           string     `json:"status"`
+	Format           string     `json:"format"`
+	Query            EventQuery `json:"query"`
+	IncludeSnapshots bool       `json:"include_snapshots"`
+	RowCount         int64      `json:"row_count"`
+	SizeBytes        int64      `json:"size_bytes"`
+	Error            *string    `json:"error,omitempty"`
+	DownloadURL      *string    `json:"download_url,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+}
+
+// ExportFile - готовый файл фоновой выгрузки для скачивания
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+type ExportService struct {
+	events  *repository.ANPRRepository
+	repo    *repository.ExportRepository
+	store   archive.Store
+	cfg     config.ExportConfig
+	tempDir string
+	log     zerolog.Logger
+	// wake будит воркеры сразу после постановки задачи, не дожидаясь опроса
+	wake chan struct{}
+}
+
+func NewExportService(
+	events *repository.ANPRRepository,
+	repo *repository.ExportRepository,
+	store archive.Store,
+	cfg config.ExportConfig,
+	tempDir string,
+	log zerolog.Logger,
+) *ExportService {
+	return &ExportService{
+		events:  events,
+		repo:    repo,
+		store:   store,
+		cfg:     cfg,
+		tempDir: tempDir,
+		log:     log,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+var exportColumns = []export.Column{
+	{Name: "id"},
+	{Name: "event_time"},
+	{Name: "camera_id"},
+	{Name: "camera_model"},
+	{Name: "direction"},
+	{Name: "lane", Numeric: true},
+	{Name: "raw_plate"},
+	{Name: "normalized_plate"},
+	{Name: "confidence", Numeric: true},
+	{Name: "vehicle_type"},
+	{Name: "vehicle_color"},
+	{Name: "vehicle_brand"},
+	{Name: "vehicle_model"},
+	{Name: "vehicle_country"},
+	{Name: "vehicle_plate_color"},
+	{Name: "vehicle_speed", Numeric: true},
+}
+
+// ValidateSync проверяет запрос прямой выгрузки до отправки заголовков ответа:
+// большие выгрузки должны идти фоновой задачей
+func (s *ExportService) ValidateSync(ctx context.Context, req ExportRequest) error {
+	filter, err := req.validate()
+	if err != nil {
+		return err
+	}
+	if s.cfg.SyncMaxRows <= 0 {
+		return nil
+	}
+	total, err := s.events.CountEvents(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to count events: %w", err)
+	}
+	if total > s.cfg.SyncMaxRows {
+		return fmt.Errorf("%w: export matches %d events, more than %d allowed for direct download; use POST /api/v1/events/exports",
+			ErrInvalidInput, total, s.cfg.SyncMaxRows)
+	}
+	return nil
+}
+
+// WriteEvents потоково пишет все события по фильтрам запроса, читая их пачками по курсору
+func (s *ExportService) WriteEvents(ctx context.Context, req ExportRequest, w io.Writer) (int64, error) {
+	filter, err := req.validate()
+	if err != nil {
+		return 0, err
+	}
+	sort, _ := req.Query.sort()
+
+	writer, err := export.NewWriter(req.Format, w)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	columns := exportColumns
+	if req.IncludeSnapshots {
+		columns = append(columns[:len(columns):len(columns)], export.Column{Name: "snapshot_url"})
+	}
+	if err := writer.WriteHeader(columns); err != nil {
+		return 0, fmt.Errorf("write export header: %w", err)
+	}
+
+	var rows int64
+	var after *repository.EventCursor
+	for {
+		batch, err := s.events.FindEventsPage(ctx, filter, after, sort == EventSortTimeAsc, exportBatchSize, 0)
+		if err != nil {
+			return rows, fmt.Errorf("failed to read events for export: %w", err)
+		}
+		for i := range batch {
+			info := eventInfoFromRecord(batch[i])
+			if !req.IncludeSnapshots {
+				info.SnapshotURL = nil
+			}
+			if err := writer.WriteRow(exportRow(info, req.IncludeSnapshots), info); err != nil {
+				return rows, fmt.Errorf("write export row: %w", err)
+			}
+			rows++
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		after = &repository.EventCursor{EventTime: last.EventTime, ID: last.ID}
+	}
+
+	if err := writer.Close(); err != nil {
+		return rows, fmt.Errorf("finalize export: %w", err)
+	}
+	return rows, nil
+}
+
+func (req ExportRequest) validate() (repository.EventFilter, error) {
+	if !export.Valid(req.Format) {
+		return repository.EventFilter{}, fmt.Errorf("%w: format must be %s, %s or %s",
+			ErrInvalidInput, export.FormatCSV, export.FormatXLSX, export.FormatNDJSON)
+	}
+	if _, err := req.Query.sort(); err != nil {
+		return repository.EventFilter{}, err
+	}
+	return req.Query.filter()
+}
+
+func exportRow(e EventInfo, includeSnapshots bool) []string {
+	row := []string{
+		e.ID,
+		e.EventTime.UTC().Format(time.RFC3339),
+		e.CameraID,
+		stringValue(e.CameraModel),
+		stringValue(e.Direction),
+		intValue(e.Lane),
+		e.RawPlate,
+		e.NormalizedPlate,
+		floatValue(e.Confidence),
+		stringValue(e.VehicleType),
+		stringValue(e.VehicleColor),
+		stringValue(e.VehicleBrand),
+		stringValue(e.VehicleModel),
+		stringValue(e.VehicleCountry),
+		stringValue(e.VehiclePlateColor),
+		floatValue(e.VehicleSpeed),
+	}
+	if includeSnapshots {
+		row = append(row, stringValue(e.SnapshotURL))
+	}
+	return row
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func intValue(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func floatValue(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// CreateJob ставит фоновую выгрузку в очередь
+func (s *ExportService) CreateJob(ctx context.Context, req ExportRequest, createdBy *uuid.UUID) (*ExportJobInfo, error) {
+	if _, err := req.validate(); err != nil {
+		return nil, err
+	}
+	query, err := json.Marshal(req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("marshal export query: %w", err)
+	}
+
+	job := &repository.ExportJob{
+		Status:           repository.ExportStatusPending,
+		Format:           req.Format,
+		Query:            query,
+		IncludeSnapshots: req.IncludeSnapshots,
+		CreatedBy:        createdBy,
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	info := exportJobInfo(*job)
+	return &info, nil
+}
+
+// GetJob возвращает задачу выгрузки. owner задан - видны только задачи этого пользователя,
+// чужая задача не отличается от несуществующей; nil - все задачи (администратор)
+func (s *ExportService) GetJob(ctx context.Context, id string, owner *uuid.UUID) (*ExportJobInfo, error) {
+	job, err := s.getJob(ctx, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	info := exportJobInfo(*job)
+	return &info, nil
+}
+
+// FindJobs возвращает задачи пользователя owner; nil - все задачи (администратор)
+func (s *ExportService) FindJobs(ctx context.Context, owner *uuid.UUID, limit, offset int) ([]ExportJobInfo, error) {
+	jobs, err := s.repo.FindJobs(ctx, owner, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find export jobs: %w", err)
+	}
+	result := make([]ExportJobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, exportJobInfo(job))
+	}
+	return result, nil
+}
+
+// OpenFile открывает файл завершённой выгрузки пользователя owner (nil - любой); вызывающий закрывает Body
+func (s *ExportService) OpenFile(ctx context.Context, id string, owner *uuid.UUID) (*ExportFile, error) {
+	job, err := s.getJob(ctx, id, owner)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != repository.ExportStatusDone || job.DataKey == nil {
+		return nil, fmt.Errorf("%w: export job is %s", ErrInvalidInput, job.Status)
+	}
+
+	body, err := s.store.Get(ctx, *job.DataKey)
+	if errors.Is(err, archive.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: export file not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open export file: %w", err)
+	}
+	return &ExportFile{
+		Name:        ExportFileName(job.CreatedAt, job.Format),
+		ContentType: export.ContentType(job.Format),
+		Size:        job.SizeBytes,
+		Body:        body,
+	}, nil
+}
+
+func (s *ExportService) getJob(ctx context.Context, id string, owner *uuid.UUID) (*repository.ExportJob, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid export job id", ErrInvalidInput)
+	}
+	job, err := s.repo.GetJob(ctx, jobID, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: export job not found", ErrNotFound)
+	}
+	return job, nil
+}
+
+// Start запускает воркеры фоновых выгрузок и очистку просроченных файлов; блокируется до отмены ctx
+func (s *ExportService) Start(ctx context.Context) {
+	s.log.Info().
+		Int("workers", s.cfg.MaxConcurrent).
+		Dur("ttl", s.cfg.TTL).
+		Msg("export job workers started")
+
+	for i := 0; i < s.cfg.MaxConcurrent; i++ {
+		go s.worker(ctx)
+	}
+
+	s.cleanup(ctx)
+	ticker := time.NewTicker(exportCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("export job workers stopped")
+			return
+		case <-ticker.C:
+			s.cleanup(ctx)
+		}
+	}
+}
+
+func (s *ExportService) worker(ctx context.Context) {
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+	for {
+		// Разбираем очередь, пока есть задачи, затем ждём сигнала или следующего опроса
+		for ctx.Err() == nil {
+			job, err := s.repo.ClaimPendingJob(ctx)
+			if err != nil {
+				s.log.Error().Err(err).Msg("failed to claim export job")
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.runJob(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ExportService) runJob(ctx context.Context, job *repository.ExportJob) {
+	log := s.log.With().Str("export_job_id", job.ID.String()).Str("format", job.Format).Logger()
+	started := time.Now()
+
+	jobCtx, cancel := context.WithTimeout(ctx, s.cfg.JobTimeout)
+	defer cancel()
+
+	key, rows, size, err := s.produce(jobCtx, job)
+	if err != nil {
+		log.Error().Err(err).Msg("export job failed")
+		// Статус пишем и при остановке сервиса, поэтому без отменённого контекста
+		if ferr := s.repo.FailJob(context.WithoutCancel(ctx), job.ID, err.Error()); ferr != nil {
+			log.Error().Err(ferr).Msg("failed to mark export job failed")
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(s.cfg.TTL)
+	if err := s.repo.CompleteJob(context.WithoutCancel(ctx), job.ID, s.store.Name(), key, rows, size, expiresAt); err != nil {
+		log.Error().Err(err).Msg("failed to mark export job done")
+		return
+	}
+	log.Info().
+		Int64("rows", rows).
+		Int64("size_bytes", size).
+		Dur("duration", time.Since(started)).
+		Msg("export job finished")
+}
+
+// produce пишет выгрузку во временный файл и загружает его в хранилище архивов
+func (s *ExportService) produce(ctx context.Context, job *repository.ExportJob) (string, int64, int64, error) {
+	req := ExportRequest{Format: job.Format, IncludeSnapshots: job.IncludeSnapshots}
+	if err := json.Unmarshal(job.Query, &req.Query); err != nil {
+		return "", 0, 0, fmt.Errorf("decode export query: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.tempDir, "anpr-export-*."+job.Format)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("create export temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	rows, err := s.WriteEvents(ctx, req, tmp)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("stat export file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, 0, fmt.Errorf("rewind export file: %w", err)
+	}
+
+	key := path.Join(s.cfg.Prefix, job.CreatedAt.UTC().Format("2006/01/02"), job.ID.String()+"."+job.Format)
+	if err := s.store.Put(ctx, key, tmp, size); err != nil {
+		return "", 0, 0, fmt.Errorf("upload export file: %w", err)
+	}
+	return key, rows, size, nil
+}
+
+// cleanup удаляет файлы просроченных выгрузок и снимает зависшие задачи
+func (s *ExportService) cleanup(ctx context.Context) {
+	if n, err := s.repo.FailStaleJobs(ctx, time.Now().Add(-s.cfg.JobTimeout)); err != nil {
+		s.log.Error().Err(err).Msg("failed to fail stale export jobs")
+	} else if n > 0 {
+		s.log.Warn().Int64("jobs", n).Msg("stale export jobs marked failed")
+	}
+
+	jobs, err := s.repo.FindExpiredJobs(ctx, time.Now(), 100)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to find expired export jobs")
+		return
+	}
+	for _, job := range jobs {
+		if job.DataKey != nil {
+			if err := s.store.Delete(ctx, *job.DataKey); err != nil {
+				s.log.Error().Err(err).Str("export_job_id", job.ID.String()).Msg("failed to delete export file")
+				continue
+			}
+		}
+		if err := s.repo.MarkExpired(ctx, job.ID); err != nil {
+			s.log.Error().Err(err).Str("export_job_id", job.ID.String()).Msg("failed to mark export job expired")
+		}
+	}
+}
+
+func exportJobInfo(job repository.ExportJob) ExportJobInfo {
+	info := ExportJobInfo{
+		ID:               job.ID.String(),
+		Status:           job.Status,
+		Format:           job.Format,
+		IncludeSnapshots: job.IncludeSnapshots,
+		RowCount:         job.RowCount,
+		SizeBytes:        job.SizeBytes,
+		Error:            job.Error,
+		CreatedAt:        job.CreatedAt,
+		StartedAt:        job.StartedAt,
+		FinishedAt:       job.FinishedAt,
+		ExpiresAt:        job.ExpiresAt,
+	}
+	_ = json.Unmarshal(job.Query, &info.Query)
+	if job.Status == repository.ExportStatusDone {
+		url := "/api/v1/events/exports/" + info.ID + "/download"
+		info.DownloadURL = &url
+	}
+	return info
+}
+
+// ExportFileName - имя файла выгрузки для Content-Disposition
+func ExportFileName(at time.Time, format string) string {
+	return "anpr-events-" + at.UTC().Format("20060102-150405") + "." + format
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/repository"
+)
+
+const (
+	PartitionIntervalDay   = "day"
+	PartitionIntervalMonth = "month"
+
+	PartitionActionDrop   = "drop"
+	PartitionActionDetach = "detach"
+)
+
+// PartitionService управляет секциями anpr_events: заранее создаёт будущие
+// и отсоединяет/удаляет секции, целиком вышедшие за срок хранения.
+type PartitionService struct {
+	repo *repository.PartitionRepository
+	// archiver - если задан, содержимое секции архивируется перед удалением
+	archiver *ArchiveService
+	cfg      config.PartitionConfig
+	log      zerolog.Logger
+}
+
+func NewPartitionService(repo *repository.PartitionRepository, archiver *ArchiveService, cfg config.PartitionConfig, log zerolog.Logger) *PartitionService {
+	return &PartitionService{
+		repo:     repo,
+		archiver: archiver,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Start периодически создаёт будущие секции до отмены ctx
+func (s *PartitionService) Start(ctx context.Context) {
+	if !s.cfg.Enabled {
+		s.log.Info().Msg("partition manager disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.EnsureFuture(ctx, time.Now()); err != nil {
+			s.log.Error().Err(err).Msg("failed to create future partitions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureFuture создаёт секции так, чтобы покрыть время до now + Premake периодов.
+// Новые секции начинаются с верхней границы последней существующей, поэтому дыр не бывает.
+func (s *PartitionService) EnsureFuture(ctx context.Context, now time.Time) ([]string, error) {
+	partitioned, err := s.repo.IsPartitioned(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check partitioning: %w", err)
+	}
+	if !partitioned {
+		return nil, nil
+	}
+
+	partitions, err := s.repo.ListPartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list partitions: %w", err)
+	}
+
+	var upper *time.Time
+	defaultName := ""
+	for _, p := range partitions {
+		if p.IsDefault {
+			defaultName = p.Name
+		}
+		if p.To != nil && (upper == nil || p.To.After(*upper)) {
+			upper = p.To
+		}
+	}
+
+	start := s.periodStart(now.UTC())
+	if upper != nil && upper.After(start) {
+		start = upper.UTC()
+	}
+	until := s.advance(s.periodStart(now.UTC()), s.cfg.Premake+1)
+
+	var created []string
+	for start.Before(until) {
+		end := s.nextBoundary(start)
+		name := s.partitionName(start)
+		if err := s.createPartition(ctx, name, defaultName, start, end); err != nil {
+			metrics.PartitionCreateFailures.Inc()
+			return created, err
+		}
+		created = append(created, name)
+		start = end
+	}
+
+	if len(created) > 0 {
+		s.log.Info().Strs("partitions", created).Msg("created event partitions")
+	}
+	return created, nil
+}
+
+// createPartition создаёт секцию [from, to). Если в DEFAULT уже лежат строки этого диапазона,
+// они переносятся в новую секцию: иначе создание падало бы при каждом запуске.
+func (s *PartitionService) createPartition(ctx context.Context, name, defaultName string, from, to time.Time) error {
+	if defaultName == "" {
+		return s.repo.CreatePartition(ctx, name, from, to)
+	}
+	conflicting, err := s.repo.CountRowsInRange(ctx, defaultName, from, to)
+	if err != nil {
+		return fmt.Errorf("count default partition rows: %w", err)
+	}
+	if conflicting == 0 {
+		return s.repo.CreatePartition(ctx, name, from, to)
+	}
+
+	moved, err := s.repo.CreatePartitionFromDefault(ctx, name, defaultName, from, to)
+	if err != nil {
+		return err
+	}
+	metrics.PartitionRowsMoved.Add(float64(moved))
+	s.log.Warn().
+		Str("partition", name).
+		Str("default_partition", defaultName).
+		Int64("moved_rows", moved).
+		Msg("moved rows from default partition into new partition")
+	return nil
+}
+
+// Expire обрабатывает секции, верхняя граница которых не позже horizon.
+// В режиме dryRun только возвращает список с количеством строк.
+func (s *PartitionService) Expire(ctx context.Context, horizon time.Time, dryRun bool) ([]PartitionExpiry, error) {
+	if !s.cfg.Enabled {
+		return nil, nil
+	}
+	partitioned, err := s.repo.IsPartitioned(ctx)
+	if err != nil || !partitioned {
+		return nil, err
+	}
+
+	partitions, err := s.repo.ListPartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list partitions: %w", err)
+	}
+
+	var result []PartitionExpiry
+	for _, p := range partitions {
+		if p.IsDefault || p.To == nil || p.To.After(horizon) {
+			continue
+		}
+
+		rows, err := s.repo.CountRows(ctx, p.Name)
+		if err != nil {
+			return result, fmt.Errorf("count rows in %s: %w", p.Name, err)
+		}
+		expiry := PartitionExpiry{Partition: p, Rows: rows, Action: s.cfg.ExpiredAction}
+		if dryRun {
+			result = append(result, expiry)
+			continue
+		}
+
+		if err := s.repo.DetachPartition(ctx, p.Name); err != nil {
+			return result, err
+		}
+
+		if s.cfg.ExpiredAction == PartitionActionDrop {
+			archiveID, err := s.archivePartition(ctx, p)
+			if err != nil {
+				// Возвращаем секцию на место, чтобы данные не потерялись и не выпали из запросов
+				if attachErr := s.repo.AttachPartition(ctx, p); attachErr != nil {
+					s.log.Error().Err(attachErr).Str("partition", p.Name).Msg("failed to re-attach partition after archive failure")
+				}
+				return result, fmt.Errorf("archive partition %s: %w", p.Name, err)
+			}
+			expiry.ArchiveID = archiveID
+			if err := s.repo.DropTable(ctx, p.Name); err != nil {
+				return result, err
+			}
+		}
+
+		s.log.Info().
+			Str("partition", p.Name).
+			Str("action", s.cfg.ExpiredAction).
+			Int64("rows", rows).
+			Str("archive_id", expiry.ArchiveID).
+			Msg("expired event partition")
+		result = append(result, expiry)
+	}
+
+	return result, nil
+}
+
+func (s *PartitionService) archivePartition(ctx context.Context, p repository.Partition) (string, error) {
+	if s.archiver == nil {
+		return "", nil
+	}
+	source := func(ctx context.Context, after *repository.EventCursor, limit int) ([]repository.ANPREvent, error) {
+		return s.repo.NextEventsInTable(ctx, p.Name, after, limit)
+	}
+	info, _, err := s.archiver.Export(ctx, "partition:"+p.Name, p.From, p.To, source)
+	if err != nil || info == nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+func (s *PartitionService) ListPartitions(ctx context.Context) ([]repository.Partition, error) {
+	partitions, err := s.repo.ListPartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list partitions: %w", err)
+	}
+	return partitions, nil
+}
+
+func (s *PartitionService) periodStart(t time.Time) time.Time {
+	if s.cfg.Interval == PartitionIntervalMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nextBoundary возвращает ближайшую границу периода строго после t
+func (s *PartitionService) nextBoundary(t time.Time) time.Time {
+	return s.advance(s.periodStart(t), 1)
+}
+
+func (s *PartitionService) advance(t time.Time, periods int) time.Time {
+	if s.cfg.Interval == PartitionIntervalMonth {
+		return t.AddDate(0, periods, 0)
+	}
+	return t.AddDate(0, 0, periods)
+}
+
+func (s *PartitionService) partitionName(from time.Time) string {
+	// Секция, начинающаяся не с начала месяца (например, после миграции), именуется по дню
+	if s.cfg.Interval == PartitionIntervalMonth && from.Equal(s.periodStart(from)) {
+		return fmt.Sprintf("%s_p%s", repository.EventsTable, from.Format("200601"))
+	}
+	return fmt.Sprintf("%s_p%s", repository.EventsTable, from.Format("20060102"))
+}
+
+type PartitionExpiry struct {
+	repository.Partition
+	Rows      int64  `json:"rows"`
+	Action    string `json:"action"`
+	ArchiveID string `json:"archive_id,omitempty"`
+}
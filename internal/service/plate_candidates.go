@@ -0,0 +1,87 @@
+package service
+
+import (
+	"sort"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// maxStoredCandidates - сколько альтернативных прочтений хранится у события
+const maxStoredCandidates = 10
+
+// plateCandidates нормализует альтернативные прочтения: без пустых, повторов и основного
+// номера primary, по убыванию уверенности (при равной - в порядке движка), не больше maxStoredCandidates
+func plateCandidates(primary string, candidates []anpr.PlateCandidate) []anpr.PlateCandidate {
+	seen := map[string]bool{primary: true, "": true}
+	result := make([]anpr.PlateCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		plate := utils.NormalizePlate(c.Plate)
+		if seen[plate] {
+			continue
+		}
+		seen[plate] = true
+		result = append(result, anpr.PlateCandidate{
+			Plate:      plate,
+			Confidence: c.Confidence,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Confidence > result[j].Confidence })
+	if len(result) > maxStoredCandidates {
+		result = result[:maxStoredCandidates]
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// MatchCandidates возвращает альтернативные прочтения, сверяемые со списками: первые
+// CONFIDENCE_MATCH_CANDIDATES, уверенность которых известна и не ниже порога непроверенных прочтений камеры
+func (s *CameraService) MatchCandidates(camera *repository.Camera, candidates []anpr.PlateCandidate) []anpr.PlateCandidate {
+	return matchCandidates(candidates, s.confidence.MatchCandidates, s.thresholds(camera).UnverifiedBelow)
+}
+
+func matchCandidates(candidates []anpr.PlateCandidate, topN int, minConfidence float64) []anpr.PlateCandidate {
+	if topN > len(candidates) {
+		topN = len(candidates)
+	}
+	var result []anpr.PlateCandidate
+	for _, c := range candidates[:topN] {
+		if c.Confidence > 0 && c.Confidence < minConfidence {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// mergeCandidateHits дополняет совпадения основного прочтения совпадениями альтернатив.
+// Список, уже найденный по основному номеру или лучшей альтернативе, повторно не добавляется;
+// CandidateRank - позиция альтернативы в candidates (с 1).
+func mergeCandidateHits(primary, candidateHits []anpr.ListHit, candidates []anpr.PlateCandidate) []anpr.ListHit {
+	rank := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		rank[c.Plate] = i + 1
+	}
+	sort.SliceStable(candidateHits, func(i, j int) bool {
+		return rank[candidateHits[i].MatchedPlate] < rank[candidateHits[j].MatchedPlate]
+	})
+
+	seen := make(map[string]bool, len(primary))
+	result := primary
+	for _, hit := range primary {
+		seen[hit.ListID.String()] = true
+	}
+	for _, hit := range candidateHits {
+		r, ok := rank[hit.MatchedPlate]
+		if !ok || seen[hit.ListID.String()] {
+			continue
+		}
+		seen[hit.ListID.String()] = true
+		hit.CandidateRank = r
+		result = append(result, hit)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/domain/anpr"
+)
+
+func TestPlateCandidates(t *testing.T) {
+	got := plateCandidates("123ABC02", []anpr.PlateCandidate{
+		{Plate: "123 abc 02", Confidence: 0.9},
+		{Plate: "123A8C02", Confidence: 0.4},
+		{Plate: "", Confidence: 0.99},
+		{Plate: "128ABC02", Confidence: 0.7},
+		{Plate: "123a8c02", Confidence: 0.95},
+	})
+	want := []anpr.PlateCandidate{
+		{Plate: "128ABC02", Confidence: 0.7},
+		{Plate: "123A8C02", Confidence: 0.4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("plateCandidates = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("candidate %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := plateCandidates("A", nil); got != nil {
+		t.Errorf("no candidates: got %+v, want nil", got)
+	}
+
+	many := make([]anpr.PlateCandidate, 0, maxStoredCandidates+5)
+	for i := 0; i < maxStoredCandidates+5; i++ {
+		many = append(many, anpr.PlateCandidate{Plate: string(rune('A'+i)) + "1", Confidence: 0.5})
+	}
+	if got := plateCandidates("", many); len(got) != maxStoredCandidates || got[0].Plate != "A1" {
+		t.Errorf("cap: got %d candidates starting with %+v", len(got), got[0])
+	}
+}
+
+func TestMatchCandidates(t *testing.T) {
+	candidates := []anpr.PlateCandidate{
+		{Plate: "A1", Confidence: 0.8},
+		{Plate: "B2", Confidence: 0.3},
+		{Plate: "C3", Confidence: 0},
+		{Plate: "D4", Confidence: 0.7},
+	}
+	got := matchCandidates(candidates, 3, 0.5)
+	if len(got) != 2 || got[0].Plate != "A1" || got[1].Plate != "C3" {
+		t.Errorf("matchCandidates = %+v, want A1 and C3 (unknown confidence)", got)
+	}
+	if got := matchCandidates(candidates, 0, 0.5); got != nil {
+		t.Errorf("topN 0: got %+v, want nil", got)
+	}
+	if got := matchCandidates(candidates, 10, 0); len(got) != len(candidates) {
+		t.Errorf("topN above length: got %d, want %d", len(got), len(candidates))
+	}
+}
+
+func TestMergeCandidateHits(t *testing.T) {
+	listA, listB, listC := uuid.New(), uuid.New(), uuid.New()
+	candidates := []anpr.PlateCandidate{{Plate: "X1"}, {Plate: "X2"}}
+	primary := []anpr.ListHit{{ListID: listA, MatchedPlate: "P1"}}
+	candidateHits := []anpr.ListHit{
+		{ListID: listC, MatchedPlate: "X2"},
+		{ListID: listB, MatchedPlate: "X2"},
+		{ListID: listB, MatchedPlate: "X1"},
+		{ListID: listA, MatchedPlate: "X1"},
+		{ListID: uuid.New(), MatchedPlate: "ZZ"},
+	}
+
+	got := mergeCandidateHits(primary, candidateHits, candidates)
+	if len(got) != 3 {
+		t.Fatalf("mergeCandidateHits = %+v, want 3 hits", got)
+	}
+	if got[0].ListID != listA || got[0].CandidateRank != 0 {
+		t.Errorf("primary hit = %+v", got[0])
+	}
+	if got[1].ListID != listB || got[1].MatchedPlate != "X1" || got[1].CandidateRank != 1 {
+		t.Errorf("best candidate hit = %+v, want list B via X1 rank 1", got[1])
+	}
+	if got[2].ListID != listC || got[2].CandidateRank != 2 {
+		t.Errorf("second candidate hit = %+v, want list C rank 2", got[2])
+	}
+}
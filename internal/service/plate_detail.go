@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+const (
+	defaultPlateWindow       = 30 * 24 * time.Hour
+	maxPlateWindow           = 366 * 24 * time.Hour
+	defaultPlateRecentEvents = 20
+	maxPlateRecentEvents     = 100
+	plateTopAttributeValues  = 3
+)
+
+// PlateDetailQuery - параметры GET /plates/:id; окно по умолчанию - последние 30 дней
+type PlateDetailQuery struct {
+	From        *string
+	To          *string
+	RecentLimit int
+}
+
+type PlateDetail struct {
+	ID          string                  `json:"id"`
+	Number      string                  `json:"number"`
+	Normalized  string                  `json:"normalized"`
+	Country     *string                 `json:"country,omitempty"`
+	Region      *string                 `json:"region,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	Lists       []PlateListMembership   `json:"lists"`
+	FirstSeen   *time.Time              `json:"first_seen,omitempty"`
+	LastSeen    *time.Time              `json:"last_seen,omitempty"`
+	TotalEvents int64                   `json:"total_events"`
+	Window      PlateWindowStats        `json:"window"`
+	Attributes  map[string][]ValueCount `json:"attributes"`
+	Recent      []EventInfo             `json:"recent_events"`
+}
+
+type PlateListMembership struct {
+	ListID   string    `json:"list_id"`
+	ListName string    `json:"list_name"`
+	ListType string    `json:"list_type"`
+	Note     *string   `json:"note,omitempty"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// PlateWindowStats - проезды номера за окно [from, to)
+type PlateWindowStats struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Events  int64           `json:"events"`
+	Cameras []CameraCount   `json:"per_camera"`
+	Days    []DailyPassages `json:"per_day"`
+}
+
+type CameraCount struct {
+	CameraID string    `json:"camera_id"`
+	Count    int64     `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type DailyPassages struct {
+	// Day - дата UTC в формате YYYY-MM-DD
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// GetPlateDetail собирает карточку номера: списки, первое/последнее появление,
+// проезды по камерам и дням за окно, частые атрибуты ТС и последние события
+func (s *ANPRService) GetPlateDetail(ctx context.Context, id string, q PlateDetailQuery) (*PlateDetail, error) {
+	plateID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid plate id", ErrInvalidInput)
+	}
+	from, to, err := plateWindow(q, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	limit := q.RecentLimit
+	if limit <= 0 {
+		limit = defaultPlateRecentEvents
+	}
+	if limit > maxPlateRecentEvents {
+		limit = maxPlateRecentEvents
+	}
+
+	plate, err := s.repo.GetPlate(ctx, plateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate: %w", err)
+	}
+	if plate == nil {
+		return nil, fmt.Errorf("%w: plate not found", ErrNotFound)
+	}
+
+	memberships, err := s.repo.FindPlateMemberships(ctx, plateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate lists: %w", err)
+	}
+	summary, err := s.repo.GetPlateEventSummary(ctx, plateID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate summary: %w", err)
+	}
+	passages, err := s.repo.CountPlatePassages(ctx, plateID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count plate passages: %w", err)
+	}
+	attributes, err := s.repo.TopPlateAttributes(ctx, plateID, from, to, plateTopAttributeValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plate attributes: %w", err)
+	}
+	recent, err := s.repo.FindRecentPlateEvents(ctx, plateID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recent plate events: %w", err)
+	}
+
+	detail := &PlateDetail{
+		ID:          plate.ID.String(),
+		Number:      plate.Number,
+		Normalized:  plate.Normalized,
+		Country:     plate.Country,
+		Region:      plate.Region,
+		CreatedAt:   plate.CreatedAt,
+		Lists:       make([]PlateListMembership, 0, len(memberships)),
+		FirstSeen:   summary.FirstSeen,
+		LastSeen:    summary.LastSeen,
+		TotalEvents: summary.TotalEvents,
+		Window:      buildPlateWindow(from, to, summary.WindowEvents, passages),
+		Attributes:  groupAttributeCounts(attributes),
+		Recent:      make([]EventInfo, 0, len(recent)),
+	}
+	for _, m := range memberships {
+		detail.Lists = append(detail.Lists, PlateListMembership{
+			ListID:   m.ListID.String(),
+			ListName: m.ListName,
+			ListType: m.ListType,
+			Note:     m.Note,
+			AddedAt:  m.AddedAt,
+		})
+	}
+	for _, e := range recent {
+		detail.Recent = append(detail.Recent, eventInfoFromRecord(e))
+	}
+	return detail, nil
+}
+
+func plateWindow(q PlateDetailQuery, now time.Time) (time.Time, time.Time, error) {
+	fromPtr, err := parseOptionalTime(q.From, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	toPtr, err := parseOptionalTime(q.To, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to := now
+	if toPtr != nil {
+		to = *toPtr
+	}
+	from := to.Add(-defaultPlateWindow)
+	if fromPtr != nil {
+		from = *fromPtr
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be after from", ErrInvalidInput)
+	}
+	if to.Sub(from) > maxPlateWindow {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: window must not exceed 366 days", ErrInvalidInput)
+	}
+	return from, to, nil
+}
+
+// buildPlateWindow раскладывает строки GROUPING SETS на разбивку по камерам и по дням
+func buildPlateWindow(from, to time.Time, events int64, passages []repository.PlatePassageCount) PlateWindowStats {
+	stats := PlateWindowStats{
+		From:    from,
+		To:      to,
+		Events:  events,
+		Cameras: []CameraCount{},
+		Days:    []DailyPassages{},
+	}
+	for _, p := range passages {
+		switch {
+		case p.CameraID != nil:
+			stats.Cameras = append(stats.Cameras, CameraCount{CameraID: *p.CameraID, Count: p.Count, LastSeen: p.LastSeen})
+		case p.Day != nil:
+			stats.Days = append(stats.Days, DailyPassages{Day: p.Day.Format("2006-01-02"), Count: p.Count})
+		}
+	}
+	return stats
+}
+
+func groupAttributeCounts(counts []repository.PlateAttributeCount) map[string][]ValueCount {
+	result := make(map[string][]ValueCount)
+	for _, c := range counts {
+		result[c.Attribute] = append(result[c.Attribute], ValueCount{Value: c.Value, Count: c.Count})
+	}
+	return result
+}
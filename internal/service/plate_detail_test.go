@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"anpr-service/internal/repository"
+)
+
+func TestPlateWindow(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	from, to, err := plateWindow(PlateDetailQuery{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !to.Equal(now) || !from.Equal(now.Add(-defaultPlateWindow)) {
+		t.Fatalf("unexpected default window %s - %s", from, to)
+	}
+
+	start := "2025-02-01T00:00:00Z"
+	end := "2025-01-01T00:00:00Z"
+	if _, _, err := plateWindow(PlateDetailQuery{From: &start, To: &end}, now); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for reversed window, got %v", err)
+	}
+
+	old := "2020-01-01T00:00:00Z"
+	if _, _, err := plateWindow(PlateDetailQuery{From: &old}, now); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for too long window, got %v", err)
+	}
+}
+
+func TestBuildPlateWindow(t *testing.T) {
+	camera := "cam-1"
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	stats := buildPlateWindow(day, day.Add(24*time.Hour), 5, []repository.PlatePassageCount{
+		{Day: &day, Count: 5},
+		{CameraID: &camera, Count: 5, LastSeen: day.Add(time.Hour)},
+	})
+
+	if len(stats.Cameras) != 1 || stats.Cameras[0].CameraID != "cam-1" || stats.Cameras[0].Count != 5 {
+		t.Fatalf("unexpected cameras: %+v", stats.Cameras)
+	}
+	if len(stats.Days) != 1 || stats.Days[0].Day != "2025-03-01" {
+		t.Fatalf("unexpected days: %+v", stats.Days)
+	}
+}
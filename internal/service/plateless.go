@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/logger"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProcessPlatelessPassage сохраняет проезд ТС, номер которого камера не прочитала: камера, время,
+// признаки ТС и снимок. Такое событие не привязано к номеру и не сверяется со списками; номер
+// позже привязывает оператор по снимку. Проезд связывается с предыдущим проездом того же ТС без номера.
+func (s *ANPRService) ProcessPlatelessPassage(ctx context.Context, payload anpr.EventPayload, defaultCameraModel string) (result *anpr.ProcessResult, err error) {
+	ctx, span := tracing.Start(ctx, "ANPRService.ProcessPlatelessPassage", trace.WithAttributes(
+		attribute.String("anpr.camera_id", payload.CameraID),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if payload.CameraID == "" {
+		metrics.Reject(payload.CameraID, metrics.RejectMissingCamera)
+		return nil, fmt.Errorf("%w: camera_id is required", ErrInvalidInput)
+	}
+	if payload.EventTime.IsZero() {
+		metrics.Reject(payload.CameraID, metrics.RejectMissingEventTime)
+		return nil, fmt.Errorf("%w: event_time is required", ErrInvalidInput)
+	}
+
+	payload.Plate = ""
+	payload.Confidence = 0
+	payload.Candidates = nil
+	if payload.ReceivedAt.IsZero() {
+		payload.ReceivedAt = time.Now()
+	}
+	if payload.TimeSource == "" {
+		payload.TimeSource = anpr.TimeSourceCamera
+	}
+	if payload.CameraModel == "" {
+		payload.CameraModel = defaultCameraModel
+	}
+
+	log := logger.FromContext(ctx, s.log).With().
+		Str("camera_id", payload.CameraID).
+		Logger()
+	ctx = logger.WithContext(ctx, log)
+
+	event := &anpr.Event{EventPayload: payload}
+	s.annotateEvent(ctx, event, log)
+	event.ReadStatus = anpr.ReadStatusPlateless
+
+	// Ошибка поиска пары не отменяет приём: проезд сохраняется без пары.
+	// Проезд неподтверждённого источника не связывается: подделка исказила бы маршрут ТС.
+	if s.pairing != nil && payload.Auth != anpr.AuthUntrusted {
+		paired, err := s.pairing.PairPlateless(ctx, event)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to pair plateless passage")
+		}
+		event.PairedEventID = paired
+	}
+
+	if err := s.repo.CreateANPREvent(ctx, event); err != nil {
+		metrics.Reject(payload.CameraID, metrics.RejectDBError)
+		log.Error().Err(err).Msg("failed to create plateless passage")
+		return nil, fmt.Errorf("failed to create plateless passage: %w", err)
+	}
+
+	logEvent := log.Info().
+		Str("event_id", event.ID.String()).
+		Time("event_time", payload.EventTime)
+	if event.PairedEventID != nil {
+		logEvent = logEvent.Str("paired_event_id", event.PairedEventID.String())
+	}
+	logEvent.Msg("saved plateless passage")
+
+	metrics.EventsAccepted.WithLabelValues(metrics.CameraLabel(payload.CameraID)).Inc()
+	metrics.ReadOutcomes.WithLabelValues(metrics.CameraLabel(payload.CameraID), event.ReadStatus).Inc()
+	if dropped := s.events.Publish(eventInfoFromDomain(event)); dropped > 0 {
+		log.Warn().Int("dropped", dropped).Msg("event watchers are too slow, event dropped")
+	}
+
+	return &anpr.ProcessResult{
+		EventID:    event.ID,
+		ReadStatus: event.ReadStatus,
+		Hits:       []anpr.ListHit{},
+	}, nil
+}
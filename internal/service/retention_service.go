@@ -0,0 +1,585 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"gorm.io/datatypes"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+)
+
+const (
+	RetentionTriggerScheduled = "scheduled"
+	RetentionTriggerManual    = "manual"
+
+	RetentionRunStatusRunning   = "running"
+	RetentionRunStatusCompleted = "completed"
+	RetentionRunStatusFailed    = "failed"
+)
+
+var ErrRetentionRunning = fmt.Errorf("retention run already in progress")
+
+type RetentionService struct {
+	repo *repository.RetentionRepository
+	// archiver - если задан, события архивируются перед удалением
+	archiver *ArchiveService
+	// partitions - если задан, секции anpr_events целиком за сроком хранения удаляются до построчной очистки
+	partitions *PartitionService
+	cfg        config.RetentionConfig
+	log        zerolog.Logger
+
+	mu sync.Mutex
+}
+
+func NewRetentionService(
+	repo *repository.RetentionRepository,
+	archiver *ArchiveService,
+	partitions *PartitionService,
+	cfg config.RetentionConfig,
+	log zerolog.Logger,
+) *RetentionService {
+	return &RetentionService{
+		repo:       repo,
+		archiver:   archiver,
+		partitions: partitions,
+		cfg:        cfg,
+		log:        log,
+	}
+}
+
+// Start запускает периодическое применение политик хранения до отмены ctx
+func (s *RetentionService) Start(ctx context.Context) {
+	if !s.cfg.Enabled {
+		s.log.Info().Msg("retention scheduler disabled")
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(s.cfg.InitialDelay):
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Run(ctx, RetentionTriggerScheduled, false); err != nil && err != ErrRetentionRunning {
+			s.log.Error().Err(err).Msg("scheduled retention run failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run применяет все активные политики. При dryRun ничего не удаляет,
+// а только считает строки, которые были бы затронуты. Каждый запуск
+// (включая dry-run) сохраняется в anpr_retention_runs.
+func (s *RetentionService) Run(ctx context.Context, trigger string, dryRun bool) (*RetentionRunInfo, error) {
+	if !s.mu.TryLock() {
+		return nil, ErrRetentionRunning
+	}
+	defer s.mu.Unlock()
+
+	plan, err := s.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &repository.RetentionRun{
+		Trigger: trigger,
+		DryRun:  dryRun,
+		Status:  RetentionRunStatusRunning,
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]RetentionPolicyResult, 0, len(plan))
+	var expired []PartitionExpiry
+	var runErr error
+
+	// Сначала дешёвое удаление целых секций, потом построчная очистка остатка
+	if horizon, ok := partitionHorizon(plan, now); ok && s.partitions != nil {
+		expired, runErr = s.partitions.Expire(ctx, horizon, dryRun)
+	}
+
+	for i, policy := range plan {
+		if runErr != nil {
+			break
+		}
+		shadowed := shadowedPolicies(plan[:i], policy.Target)
+		result := RetentionPolicyResult{
+			PolicyID:   policyIDString(policy),
+			PolicyName: policy.Name,
+			Target:     policy.Target,
+			RetainDays: policy.RetainDays,
+			Cutoff:     now.AddDate(0, 0, -policy.RetainDays),
+		}
+
+		var err error
+		if !dryRun && s.archiver != nil && policy.Target == repository.RetentionTargetEvents {
+			result.Affected, result.ArchiveID, err = s.archiveAndDelete(ctx, policy, shadowed, now)
+		} else {
+			result.Affected, err = s.repo.ApplyPolicy(ctx, policy, shadowed, now, dryRun)
+		}
+		results = append(results, result)
+		if err != nil {
+			runErr = fmt.Errorf("apply retention policy %q: %w", policy.Name, err)
+			break
+		}
+	}
+
+	run.Status = RetentionRunStatusCompleted
+	if runErr != nil {
+		run.Status = RetentionRunStatusFailed
+		errText := runErr.Error()
+		run.Error = &errText
+	}
+	if raw, err := json.Marshal(retentionRunResults{Policies: results, Partitions: expired}); err == nil {
+		run.Results = datatypes.JSON(raw)
+	}
+
+	if runErr == nil && !dryRun {
+		if swept, err := s.repo.SweepEventLinks(ctx); err != nil {
+			s.log.Error().Err(err).Str("run_id", run.ID.String()).Msg("failed to sweep links of deleted events")
+		} else if swept > 0 {
+			s.log.Info().Int64("rows", swept).Msg("swept list hits and trip links of deleted events")
+		}
+	}
+
+	finishCtx := context.WithoutCancel(ctx)
+	if err := s.repo.FinishRun(finishCtx, run); err != nil {
+		s.log.Error().Err(err).Str("run_id", run.ID.String()).Msg("failed to record retention run")
+	}
+
+	logEvent := s.log.Info()
+	if runErr != nil {
+		logEvent = s.log.Error().Err(runErr)
+	}
+	logEvent.
+		Str("run_id", run.ID.String()).
+		Str("trigger", trigger).
+		Bool("dry_run", dryRun).
+		Int("policies", len(plan)).
+		Msg("retention run finished")
+
+	info := toRetentionRunInfo(*run)
+	info.Results = results
+	info.Partitions = expired
+	return &info, runErr
+}
+
+// partitionHorizon возвращает момент, старше которого ни одна политика не хранит события.
+// Если политика по умолчанию выключена, непокрытые политиками события хранятся вечно и секции не удаляются.
+// Пока действует retain_until какой-либо политики, её события могут лежать в любой секции - секции тоже не удаляются.
+func partitionHorizon(plan []repository.RetentionPolicy, now time.Time) (time.Time, bool) {
+	hasDefault := false
+	maxDays := 0
+	for _, p := range plan {
+		if p.Target != repository.RetentionTargetEvents {
+			continue
+		}
+		if p.RetainUntil != nil && now.Before(*p.RetainUntil) {
+			return time.Time{}, false
+		}
+		if policySpecificity(p) == 0 {
+			hasDefault = true
+		}
+		maxDays = max(maxDays, p.RetainDays)
+	}
+	if !hasDefault {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -maxDays), true
+}
+
+// archiveAndDelete выгружает подпадающие под политику события в архив и удаляет
+// только те строки, которые попали в успешно загруженный архив.
+func (s *RetentionService) archiveAndDelete(ctx context.Context, policy repository.RetentionPolicy, shadowed []repository.RetentionPolicy, now time.Time) (int64, string, error) {
+	source := func(ctx context.Context, after *repository.EventCursor, limit int) ([]repository.ANPREvent, error) {
+		return s.repo.NextPolicyBatch(ctx, policy, shadowed, now, after, limit)
+	}
+
+	cutoff := now.AddDate(0, 0, -policy.RetainDays)
+	info, ids, err := s.archiver.Export(ctx, "retention:"+policy.Name, nil, &cutoff, source)
+	if err != nil {
+		return 0, "", fmt.Errorf("archive before delete: %w", err)
+	}
+	if info == nil {
+		return 0, "", nil
+	}
+
+	deleted, err := s.repo.DeleteEventsByID(ctx, ids)
+	return deleted, info.ID, err
+}
+
+// plan возвращает активные политики в порядке применения: внутри каждой цели
+// сначала более приоритетные и более специфичные, в конце - политика по умолчанию из конфигурации.
+func (s *RetentionService) plan(ctx context.Context) ([]repository.RetentionPolicy, error) {
+	stored, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+
+	policies := make([]repository.RetentionPolicy, 0, len(stored)+3)
+	for _, p := range stored {
+		if p.Enabled {
+			policies = append(policies, p)
+		}
+	}
+	policies = append(policies, s.defaultPolicies()...)
+
+	sortRetentionPolicies(policies)
+	return policies, nil
+}
+
+func (s *RetentionService) defaultPolicies() []repository.RetentionPolicy {
+	defaults := []struct {
+		target string
+		days   int
+	}{
+		{repository.RetentionTargetEvents, s.cfg.DefaultEventDays},
+		{repository.RetentionTargetRawPayloads, s.cfg.DefaultRawPayloadDays},
+		{repository.RetentionTargetSnapshots, s.cfg.DefaultSnapshotDays},
+	}
+
+	var policies []repository.RetentionPolicy
+	for _, d := range defaults {
+		if d.days <= 0 {
+			continue
+		}
+		policies = append(policies, repository.RetentionPolicy{
+			Name:       "default_" + d.target,
+			Target:     d.target,
+			RetainDays: d.days,
+			Priority:   math.MinInt32,
+			Enabled:    true,
+		})
+	}
+	return policies
+}
+
+func sortRetentionPolicies(policies []repository.RetentionPolicy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		a, b := policies[i], policies[j]
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return policySpecificity(a) > policySpecificity(b)
+	})
+}
+
+func policySpecificity(p repository.RetentionPolicy) int {
+	n := 0
+	if p.CameraID != nil {
+		n++
+	}
+	if p.PolygonID != nil {
+		n++
+	}
+	if p.ListType != nil {
+		n++
+	}
+	if p.TripLinked != nil {
+		n++
+	}
+	return n
+}
+
+func shadowedPolicies(previous []repository.RetentionPolicy, target string) []repository.RetentionPolicy {
+	var shadowed []repository.RetentionPolicy
+	for _, p := range previous {
+		if p.Target == target {
+			shadowed = append(shadowed, p)
+		}
+	}
+	return shadowed
+}
+
+func policyIDString(p repository.RetentionPolicy) string {
+	if p.ID == uuid.Nil {
+		return ""
+	}
+	return p.ID.String()
+}
+
+func (s *RetentionService) ListPolicies(ctx context.Context) ([]RetentionPolicyInfo, error) {
+	policies, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention policies: %w", err)
+	}
+	result := make([]RetentionPolicyInfo, 0, len(policies))
+	for _, p := range policies {
+		result = append(result, toRetentionPolicyInfo(p))
+	}
+	return result, nil
+}
+
+func (s *RetentionService) CreatePolicy(ctx context.Context, input RetentionPolicyInput) (*RetentionPolicyInfo, error) {
+	policy := repository.RetentionPolicy{}
+	if err := applyRetentionInput(&policy, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePolicy(ctx, &policy); err != nil {
+		return nil, err
+	}
+	info := toRetentionPolicyInfo(policy)
+	return &info, nil
+}
+
+func (s *RetentionService) UpdatePolicy(ctx context.Context, id uuid.UUID, input RetentionPolicyInput) (*RetentionPolicyInfo, error) {
+	policy, err := s.repo.GetPolicy(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("%w: retention policy", ErrNotFound)
+	}
+	if err := applyRetentionInput(policy, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	info := toRetentionPolicyInfo(*policy)
+	return &info, nil
+}
+
+func (s *RetentionService) DeletePolicy(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeletePolicy(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: retention policy", ErrNotFound)
+	}
+	return nil
+}
+
+// LinkTripEvents связывает события с поездкой, чтобы политики с trip_linked отличали их от прочих
+func (s *RetentionService) LinkTripEvents(ctx context.Context, tripID string, input TripEventsInput) (int64, error) {
+	tripID = strings.TrimSpace(tripID)
+	if tripID == "" {
+		return 0, fmt.Errorf("%w: trip_id is required", ErrInvalidInput)
+	}
+	if len(input.EventIDs) == 0 || len(input.EventIDs) > maxTripEvents {
+		return 0, fmt.Errorf("%w: event_ids must contain 1..%d ids", ErrInvalidInput, maxTripEvents)
+	}
+	ids := make([]uuid.UUID, 0, len(input.EventIDs))
+	for _, raw := range input.EventIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid event id %q", ErrInvalidInput, raw)
+		}
+		ids = append(ids, id)
+	}
+	return s.repo.LinkTripEvents(ctx, tripID, ids)
+}
+
+// UnlinkTrip снимает связи событий с поездкой
+func (s *RetentionService) UnlinkTrip(ctx context.Context, tripID string) (int64, error) {
+	tripID = strings.TrimSpace(tripID)
+	if tripID == "" {
+		return 0, fmt.Errorf("%w: trip_id is required", ErrInvalidInput)
+	}
+	return s.repo.UnlinkTrip(ctx, tripID)
+}
+
+func (s *RetentionService) ListRuns(ctx context.Context, limit, offset int) ([]RetentionRunInfo, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	runs, err := s.repo.ListRuns(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention runs: %w", err)
+	}
+	result := make([]RetentionRunInfo, 0, len(runs))
+	for _, r := range runs {
+		info := toRetentionRunInfo(r)
+		if len(r.Results) > 0 {
+			var stored retentionRunResults
+			if err := json.Unmarshal(r.Results, &stored); err == nil {
+				info.Results = stored.Policies
+				info.Partitions = stored.Partitions
+			}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+func applyRetentionInput(policy *repository.RetentionPolicy, input RetentionPolicyInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	switch input.Target {
+	case repository.RetentionTargetEvents, repository.RetentionTargetRawPayloads, repository.RetentionTargetSnapshots:
+	default:
+		return fmt.Errorf("%w: target must be one of events, raw_payloads, snapshots", ErrInvalidInput)
+	}
+	if input.RetainDays < 1 {
+		return fmt.Errorf("%w: retain_days must be >= 1", ErrInvalidInput)
+	}
+
+	policy.Name = name
+	policy.Target = input.Target
+	policy.RetainDays = input.RetainDays
+	policy.Priority = input.Priority
+	policy.Enabled = input.Enabled == nil || *input.Enabled
+	policy.Description = nonEmpty(input.Description)
+	policy.CameraID = nonEmpty(input.CameraID)
+	policy.PolygonID = nil
+	policy.ListType = nil
+	policy.TripLinked = input.TripLinked
+	policy.RetainUntil = nil
+
+	if input.RetainUntil != nil && *input.RetainUntil != "" {
+		until, err := time.Parse(time.RFC3339, *input.RetainUntil)
+		if err != nil {
+			return fmt.Errorf("%w: retain_until must be RFC3339", ErrInvalidInput)
+		}
+		policy.RetainUntil = &until
+	}
+
+	if input.PolygonID != nil && *input.PolygonID != "" {
+		id, err := uuid.Parse(*input.PolygonID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		policy.PolygonID = &id
+	}
+	if input.ListType != nil && *input.ListType != "" {
+		listType := strings.ToUpper(*input.ListType)
+		switch listType {
+		case "WHITELIST", "BLACKLIST", repository.RetentionListTypeNone:
+		default:
+			return fmt.Errorf("%w: list_type must be one of WHITELIST, BLACKLIST, NONE", ErrInvalidInput)
+		}
+		policy.ListType = &listType
+	}
+	return nil
+}
+
+func toRetentionPolicyInfo(p repository.RetentionPolicy) RetentionPolicyInfo {
+	info := RetentionPolicyInfo{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Target:      p.Target,
+		CameraID:    p.CameraID,
+		ListType:    p.ListType,
+		RetainDays:  p.RetainDays,
+		Priority:    p.Priority,
+		Enabled:     p.Enabled,
+		Description: p.Description,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		TripLinked:  p.TripLinked,
+		RetainUntil: p.RetainUntil,
+	}
+	if p.PolygonID != nil {
+		id := p.PolygonID.String()
+		info.PolygonID = &id
+	}
+	return info
+}
+
+func toRetentionRunInfo(r repository.RetentionRun) RetentionRunInfo {
+	return RetentionRunInfo{
+		ID:         r.ID.String(),
+		Trigger:    r.Trigger,
+		DryRun:     r.DryRun,
+		Status:     r.Status,
+		Error:      r.Error,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+}
+
+type RetentionPolicyInput struct {
+	Name        string  `json:"name"`
+	Target      string  `json:"target"`
+	CameraID    *string `json:"camera_id"`
+	PolygonID   *string `json:"polygon_id"`
+	ListType    *string `json:"list_type"`
+	RetainDays  int     `json:"retain_days"`
+	Priority    int     `json:"priority"`
+	Enabled     *bool   `json:"enabled"`
+	Description *string `json:"description"`
+
+	// TripLinked - отбор по связи с поездкой (POST /api/v1/trips/:trip_id/events)
+	TripLinked *bool `json:"trip_linked"`
+	// RetainUntil - RFC3339; до этого момента политика ничего не удаляет
+	RetainUntil *string `json:"retain_until"`
+}
+
+type RetentionPolicyInfo struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Target      string    `json:"target"`
+	CameraID    *string   `json:"camera_id,omitempty"`
+	PolygonID   *string   `json:"polygon_id,omitempty"`
+	ListType    *string   `json:"list_type,omitempty"`
+	RetainDays  int       `json:"retain_days"`
+	Priority    int       `json:"priority"`
+	Enabled     bool      `json:"enabled"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	TripLinked  *bool      `json:"trip_linked,omitempty"`
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+}
+
+// maxTripEvents - предел событий в одном запросе связи с поездкой
+const maxTripEvents = 1000
+
+type TripEventsInput struct {
+	EventIDs []string `json:"event_ids"`
+}
+
+type RetentionPolicyResult struct {
+	PolicyID   string    `json:"policy_id,omitempty"`
+	PolicyName string    `json:"policy_name"`
+	Target     string    `json:"target"`
+	RetainDays int       `json:"retain_days"`
+	Cutoff     time.Time `json:"cutoff"`
+	Affected   int64     `json:"affected"`
+	ArchiveID  string    `json:"archive_id,omitempty"`
+}
+
+type retentionRunResults struct {
+	Policies   []RetentionPolicyResult `json:"policies"`
+	Partitions []PartitionExpiry       `json:"partitions,omitempty"`
+}
+
+type RetentionRunInfo struct {
+	ID         string                  `json:"id"`
+	Trigger    string                  `json:"trigger"`
+	DryRun     bool                    `json:"dry_run"`
+	Status     string                  `json:"status"`
+	Error      *string                 `json:"error,omitempty"`
+	Results    []RetentionPolicyResult `json:"results"`
+	Partitions []PartitionExpiry       `json:"partitions,omitempty"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"anpr-service/internal/repository"
+)
+
+func TestSortRetentionPolicies(t *testing.T) {
+	camera := "cam-1"
+	blacklist := "BLACKLIST"
+
+	policies := []repository.RetentionPolicy{
+		{Name: "default_events", Target: repository.RetentionTargetEvents, Priority: math.MinInt32},
+		{Name: "camera", Target: repository.RetentionTargetEvents, CameraID: &camera},
+		{Name: "raw", Target: repository.RetentionTargetRawPayloads},
+		{Name: "blacklist", Target: repository.RetentionTargetEvents, ListType: &blacklist, Priority: 10},
+		{Name: "camera_blacklist", Target: repository.RetentionTargetEvents, CameraID: &camera, ListType: &blacklist},
+	}
+
+	sortRetentionPolicies(policies)
+
+	expected := []string{"blacklist", "camera_blacklist", "camera", "default_events", "raw"}
+	for i, name := range expected {
+		if policies[i].Name != name {
+			t.Fatalf("position %d: got %q, want %q", i, policies[i].Name, name)
+		}
+	}
+
+	shadowed := shadowedPolicies(policies[:3], repository.RetentionTargetEvents)
+	if len(shadowed) != 3 {
+		t.Fatalf("shadowed policies: got %d, want 3", len(shadowed))
+	}
+}
+
+func TestPartitionHorizonKeepsPartitionsDuringSeason(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	linked := true
+	seasonEnd := now.AddDate(0, 6, 0)
+	plan := []repository.RetentionPolicy{
+		{Name: "season", Target: repository.RetentionTargetEvents, RetainDays: 1, TripLinked: &linked, RetainUntil: &seasonEnd},
+		{Name: "default_events", Target: repository.RetentionTargetEvents, RetainDays: 90, Priority: math.MinInt32},
+	}
+
+	if _, ok := partitionHorizon(plan, now); ok {
+		t.Error("partitions must not be dropped before retain_until")
+	}
+
+	horizon, ok := partitionHorizon(plan, seasonEnd.Add(time.Hour))
+	if !ok || !horizon.Equal(seasonEnd.Add(time.Hour).AddDate(0, 0, -90)) {
+		t.Errorf("after the season: horizon %v, %v", horizon, ok)
+	}
+}
+
+func TestApplyRetentionInputSeason(t *testing.T) {
+	linked := true
+	until := "2027-04-15T00:00:00+05:00"
+	var policy repository.RetentionPolicy
+	err := applyRetentionInput(&policy, RetentionPolicyInput{Name: "season", Target: "events", RetainDays: 7, TripLinked: &linked, RetainUntil: &until})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.TripLinked == nil || !*policy.TripLinked || policy.RetainUntil == nil {
+		t.Fatalf("season criteria not applied: %+v", policy)
+	}
+
+	bad := "15.04.2027"
+	if err := applyRetentionInput(&policy, RetentionPolicyInput{Name: "season", Target: "events", RetainDays: 7, RetainUntil: &bad}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("err = %v, want invalid input", err)
+	}
+}
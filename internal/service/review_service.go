@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/metrics"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/utils"
+)
+
+// reviewPolicyCacheTTL - как долго приём событий использует закэшированные политики полигонов
+const reviewPolicyCacheTTL = 30 * time.Second
+
+// ReviewService ведёт очередь проверки номеров, не состоящих ни в одном списке:
+// незарегистрированная машина на полигоне - вопрос для оператора, а не просто ещё одно событие
+type ReviewService struct {
+	repo   *repository.ReviewRepository
+	plates *repository.ANPRRepository
+	cfg    config.ReviewConfig
+	log    zerolog.Logger
+
+	mu       sync.RWMutex
+	policies map[uuid.UUID]repository.ReviewPolicy
+	loadedAt time.Time
+}
+
+func NewReviewService(repo *repository.ReviewRepository, plates *repository.ANPRRepository, cfg config.ReviewConfig, log zerolog.Logger) *ReviewService {
+	return &ReviewService{
+		repo:   repo,
+		plates: plates,
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+type ReviewPolicyInput struct {
+	Enabled   *bool   `json:"enabled"`
+	Direction *string `json:"direction"`
+}
+
+type ReviewPolicyInfo struct {
+	PolygonID string    `json:"polygon_id"`
+	Enabled   bool      `json:"enabled"`
+	Direction *string   `json:"direction,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewQuery - фильтры GET /review/items
+type ReviewQuery struct {
+	Status    *string
+	PolygonID *string
+	CameraID  *string
+	Plate     *string
+}
+
+// ReviewDecision - решение оператора: approved (ListID), misread (Plate - настоящий номер) или dismissed
+type ReviewDecision struct {
+	Status string  `json:"status" binding:"required"`
+	ListID *string `json:"list_id"`
+	Plate  *string `json:"plate"`
+	Note   *string `json:"note"`
+}
+
+type ReviewItemInfo struct {
+	ID               string     `json:"id"`
+	Status           string     `json:"status"`
+	PlateID          string     `json:"plate_id"`
+	NormalizedPlate  string     `json:"normalized_plate"`
+	PolygonID        string     `json:"polygon_id"`
+	CameraID         string     `json:"camera_id"`
+	FirstEventID     string     `json:"first_event_id"`
+	LastEventID      string     `json:"last_event_id"`
+	FirstSeen        time.Time  `json:"first_seen"`
+	LastSeen         time.Time  `json:"last_seen"`
+	Occurrences      int        `json:"occurrences"`
+	SuggestedPlateID *string    `json:"suggested_plate_id,omitempty"`
+	ListID           *string    `json:"list_id,omitempty"`
+	CorrectedPlateID *string    `json:"corrected_plate_id,omitempty"`
+	ResolvedBy       *string    `json:"resolved_by,omitempty"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	ResolutionNote   *string    `json:"resolution_note,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Enqueue ставит номер вне списков на проверку, если этого требует политика полигона события.
+// Возвращает id записи очереди; nil - событие не требует проверки.
+func (s *ReviewService) Enqueue(ctx context.Context, event *anpr.Event) (*uuid.UUID, error) {
+	if event.PolygonID == nil {
+		return nil, nil
+	}
+	policy, err := s.policy(ctx, *event.PolygonID)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewPolicyApplies(policy, s.cfg.DefaultEnabled, event.Direction) {
+		return nil, nil
+	}
+
+	item := &repository.ReviewItem{
+		PlateID:         event.PlateID,
+		NormalizedPlate: event.NormalizedPlate,
+		PolygonID:       *event.PolygonID,
+		CameraID:        event.CameraID,
+		FirstEventID:    event.ID,
+		LastEventID:     event.ID,
+		FirstSeen:       event.EventTime,
+		LastSeen:        event.EventTime,
+	}
+	// Подсказка оператору: номер, которым эта строка уже оказывалась после исправлений
+	correction, err := s.repo.FindCorrection(ctx, event.NormalizedPlate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find plate correction: %w", err)
+	}
+	if correction != nil {
+		item.SuggestedPlateID = &correction.PlateID
+	}
+
+	id, err := s.repo.EnqueueItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	metrics.UnknownPlates.WithLabelValues(event.PolygonID.String()).Inc()
+	return &id, nil
+}
+
+// policy возвращает политику полигона из кэша; nil - у полигона нет своей политики
+func (s *ReviewService) policy(ctx context.Context, polygonID uuid.UUID) (*repository.ReviewPolicy, error) {
+	s.mu.RLock()
+	fresh := s.policies != nil && time.Since(s.loadedAt) < reviewPolicyCacheTTL
+	policy, ok := s.policies[polygonID]
+	s.mu.RUnlock()
+	if fresh {
+		if !ok {
+			return nil, nil
+		}
+		return &policy, nil
+	}
+
+	policies, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load review policies: %w", err)
+	}
+	cache := make(map[uuid.UUID]repository.ReviewPolicy, len(policies))
+	for _, p := range policies {
+		cache[p.PolygonID] = p
+	}
+
+	s.mu.Lock()
+	s.policies = cache
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	if policy, ok := cache[polygonID]; ok {
+		return &policy, nil
+	}
+	return nil, nil
+}
+
+// reviewPolicyApplies решает, ставить ли событие направления direction на проверку
+func reviewPolicyApplies(policy *repository.ReviewPolicy, defaultEnabled bool, direction string) bool {
+	if policy == nil {
+		return defaultEnabled
+	}
+	if !policy.Enabled {
+		return false
+	}
+	return policy.Direction == nil || strings.EqualFold(*policy.Direction, direction)
+}
+
+func (s *ReviewService) ListPolicies(ctx context.Context) ([]ReviewPolicyInfo, error) {
+	policies, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review policies: %w", err)
+	}
+	result := make([]ReviewPolicyInfo, 0, len(policies))
+	for _, p := range policies {
+		result = append(result, reviewPolicyInfo(p))
+	}
+	return result, nil
+}
+
+func (s *ReviewService) UpsertPolicy(ctx context.Context, polygonID string, input ReviewPolicyInput) (*ReviewPolicyInfo, error) {
+	id, err := uuid.Parse(polygonID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+	}
+	policy := &repository.ReviewPolicy{PolygonID: id, Enabled: true}
+	if input.Enabled != nil {
+		policy.Enabled = *input.Enabled
+	}
+	if input.Direction != nil {
+		if direction := strings.TrimSpace(*input.Direction); direction != "" {
+			policy.Direction = &direction
+		}
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	info := reviewPolicyInfo(*policy)
+	return &info, nil
+}
+
+func (s *ReviewService) DeletePolicy(ctx context.Context, polygonID string) error {
+	id, err := uuid.Parse(polygonID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+	}
+	deleted, err := s.repo.DeletePolicy(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete review policy: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: review policy not found", ErrNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *ReviewService) invalidate() {
+	s.mu.Lock()
+	s.policies = nil
+	s.mu.Unlock()
+}
+
+func (s *ReviewService) FindItems(ctx context.Context, q ReviewQuery, limit, offset int) ([]ReviewItemInfo, error) {
+	var filter repository.ReviewFilter
+	if q.Status != nil {
+		if !validReviewStatus(*q.Status) {
+			return nil, fmt.Errorf("%w: unknown review status", ErrInvalidInput)
+		}
+		filter.Status = q.Status
+	}
+	if q.PolygonID != nil {
+		id, err := uuid.Parse(*q.PolygonID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid polygon_id", ErrInvalidInput)
+		}
+		filter.PolygonID = &id
+	}
+	if q.Plate != nil {
+		if normalized := utils.NormalizePlate(*q.Plate); normalized != "" {
+			filter.NormalizedPlate = &normalized
+		}
+	}
+	filter.CameraID = q.CameraID
+
+	items, err := s.repo.FindItems(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find review items: %w", err)
+	}
+	result := make([]ReviewItemInfo, 0, len(items))
+	for _, item := range items {
+		result = append(result, reviewItemInfo(item))
+	}
+	return result, nil
+}
+
+func (s *ReviewService) GetItem(ctx context.Context, id string) (*ReviewItemInfo, error) {
+	item, err := s.getItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info := reviewItemInfo(*item)
+	return &info, nil
+}
+
+func (s *ReviewService) getItem(ctx context.Context, id string) (*repository.ReviewItem, error) {
+	itemID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid review item id", ErrInvalidInput)
+	}
+	item, err := s.repo.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review item: %w", err)
+	}
+	if item == nil {
+		return nil, fmt.Errorf("%w: review item not found", ErrNotFound)
+	}
+	return item, nil
+}
+
+// ResolveItem применяет решение оператора по открытой записи. Одобрение и исправление
+// пополняют данные о распознавании: из них строятся подсказки для следующих записей.
+func (s *ReviewService) ResolveItem(ctx context.Context, id string, decision ReviewDecision, resolvedBy *uuid.UUID) (*ReviewItemInfo, error) {
+	item, err := s.getItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != repository.ReviewStatusPending {
+		return nil, fmt.Errorf("%w: review item is already %s", ErrInvalidInput, item.Status)
+	}
+
+	resolution := repository.ReviewResolution{
+		Status:     decision.Status,
+		ResolvedBy: resolvedBy,
+		Note:       decision.Note,
+	}
+	switch decision.Status {
+	case repository.ReviewStatusApproved:
+		if decision.ListID == nil {
+			return nil, fmt.Errorf("%w: list_id is required to approve", ErrInvalidInput)
+		}
+		listID, err := uuid.Parse(*decision.ListID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid list_id", ErrInvalidInput)
+		}
+		exists, err := s.repo.ListExists(ctx, listID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check list: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: list not found", ErrInvalidInput)
+		}
+		resolution.ListID = &listID
+	case repository.ReviewStatusMisread:
+		if decision.Plate == nil {
+			return nil, fmt.Errorf("%w: plate is required for misread", ErrInvalidInput)
+		}
+		normalized := utils.NormalizePlate(*decision.Plate)
+		if normalized == "" || normalized == item.NormalizedPlate {
+			return nil, fmt.Errorf("%w: plate must differ from the read plate", ErrInvalidInput)
+		}
+		plates, err := s.plates.FindPlatesByNormalized(ctx, normalized)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find plates: %w", err)
+		}
+		if len(plates) == 0 {
+			return nil, fmt.Errorf("%w: plate %s is not known", ErrInvalidInput, normalized)
+		}
+		resolution.CorrectedPlateID = &plates[0].ID
+	case repository.ReviewStatusDismissed:
+	default:
+		return nil, fmt.Errorf("%w: status must be approved, misread or dismissed", ErrInvalidInput)
+	}
+
+	resolved, err := s.repo.ResolveItem(ctx, item, resolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve review item: %w", err)
+	}
+	if !resolved {
+		return nil, fmt.Errorf("%w: review item is no longer pending", ErrInvalidInput)
+	}
+
+	s.log.Info().
+		Str("review_item_id", item.ID.String()).
+		Str("plate", item.NormalizedPlate).
+		Str("status", decision.Status).
+		Msg("review item resolved")
+	return s.GetItem(ctx, id)
+}
+
+func validReviewStatus(status string) bool {
+	switch status {
+	case repository.ReviewStatusPending, repository.ReviewStatusApproved,
+		repository.ReviewStatusMisread, repository.ReviewStatusDismissed:
+		return true
+	}
+	return false
+}
+
+func reviewPolicyInfo(p repository.ReviewPolicy) ReviewPolicyInfo {
+	return ReviewPolicyInfo{
+		PolygonID: p.PolygonID.String(),
+		Enabled:   p.Enabled,
+		Direction: p.Direction,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func reviewItemInfo(item repository.ReviewItem) ReviewItemInfo {
+	return ReviewItemInfo{
+		ID:               item.ID.String(),
+		Status:           item.Status,
+		PlateID:          item.PlateID.String(),
+		NormalizedPlate:  item.NormalizedPlate,
+		PolygonID:        item.PolygonID.String(),
+		CameraID:         item.CameraID,
+		FirstEventID:     item.FirstEventID.String(),
+		LastEventID:      item.LastEventID.String(),
+		FirstSeen:        item.FirstSeen,
+		LastSeen:         item.LastSeen,
+		Occurrences:      item.Occurrences,
+		SuggestedPlateID: uuidString(item.SuggestedPlateID),
+		ListID:           uuidString(item.ListID),
+		CorrectedPlateID: uuidString(item.CorrectedPlateID),
+		ResolvedBy:       uuidString(item.ResolvedBy),
+		ResolvedAt:       item.ResolvedAt,
+		ResolutionNote:   item.ResolutionNote,
+		CreatedAt:        item.CreatedAt,
+	}
+}
+
+func uuidString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+)
+
+func TestReviewPolicyApplies(t *testing.T) {
+	entry := "entry"
+	cases := []struct {
+		name           string
+		policy         *repository.ReviewPolicy
+		defaultEnabled bool
+		direction      string
+		want           bool
+	}{
+		{"no policy, default off", nil, false, "entry", false},
+		{"no policy, default on", nil, true, "exit", true},
+		{"disabled policy overrides default", &repository.ReviewPolicy{PolygonID: uuid.New()}, true, "entry", false},
+		{"enabled, any direction", &repository.ReviewPolicy{Enabled: true}, false, "exit", true},
+		{"enabled, matching direction", &repository.ReviewPolicy{Enabled: true, Direction: &entry}, false, "ENTRY", true},
+		{"enabled, other direction", &repository.ReviewPolicy{Enabled: true, Direction: &entry}, false, "exit", false},
+	}
+	for _, tc := range cases {
+		if got := reviewPolicyApplies(tc.policy, tc.defaultEnabled, tc.direction); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"anpr-service/internal/config"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/roles"
+	"anpr-service/internal/utils"
+)
+
+// VehicleService ведёт зеркало реестра ТС сервиса roles и через него - членство номеров в whitelist
+type VehicleService struct {
+	repo *repository.VehicleRepository
+	// client - если задан, зеркало периодически сверяется с roles
+	client *roles.Client
+	cfg    config.VehicleSyncConfig
+	log    zerolog.Logger
+
+	// mu сериализует сверки: плановую и из API
+	mu sync.Mutex
+}
+
+func NewVehicleService(
+	repo *repository.VehicleRepository,
+	client *roles.Client,
+	cfg config.VehicleSyncConfig,
+	log zerolog.Logger,
+) *VehicleService {
+	return &VehicleService{
+		repo:   repo,
+		client: client,
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+// VehicleSyncRequest - полное состояние реестра (или одной организации, если задан OrgID)
+type VehicleSyncRequest struct {
+	OrgID    *uuid.UUID      `json:"organization_id"`
+	Vehicles []roles.Vehicle `json:"vehicles"`
+	DryRun   bool            `json:"dry_run"`
+	// Force - применить, даже если сверка удаляет больше VEHICLE_SYNC_MAX_REMOVE_SHARE зеркала
+	Force bool `json:"force"`
+}
+
+type VehicleChange struct {
+	VehicleID     uuid.UUID `json:"vehicle_id"`
+	OrgID         uuid.UUID `json:"organization_id"`
+	PlateNumber   string    `json:"plate_number"`
+	PreviousPlate *string   `json:"previous_plate,omitempty"`
+	Fields        []string  `json:"fields,omitempty"`
+}
+
+type VehicleSkip struct {
+	VehicleID uuid.UUID `json:"vehicle_id"`
+	Reason    string    `json:"reason"`
+}
+
+type VehicleSyncResult struct {
+	DryRun    bool                         `json:"dry_run"`
+	Added     []VehicleChange              `json:"added"`
+	Updated   []VehicleChange              `json:"updated"`
+	Removed   []VehicleChange              `json:"removed"`
+	Unchanged int                          `json:"unchanged"`
+	Skipped   []VehicleSkip                `json:"skipped,omitempty"`
+	Whitelist *repository.WhitelistChanges `json:"whitelist,omitempty"`
+}
+
+type VehicleInfo struct {
+	VehicleID       uuid.UUID  `json:"vehicle_id"`
+	OrgID           uuid.UUID  `json:"organization_id"`
+	ContractorID    *uuid.UUID `json:"contractor_id,omitempty"`
+	ContractorName  *string    `json:"contractor_name,omitempty"`
+	PlateNumber     string     `json:"plate_number"`
+	NormalizedPlate string     `json:"normalized_plate"`
+	PlateID         *uuid.UUID `json:"plate_id,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	SourceUpdatedAt *time.Time `json:"source_updated_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Start запускает периодическую сверку с сервисом roles до отмены ctx
+func (s *VehicleService) Start(ctx context.Context) {
+	if s.client == nil {
+		s.log.Info().Msg("vehicle sync disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error().Err(err).Msg("scheduled vehicle sync failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *VehicleService) poll(ctx context.Context) error {
+	vehicles, err := s.client.ListVehicles(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = s.Sync(ctx, VehicleSyncRequest{Vehicles: vehicles})
+	return err
+}
+
+// Sync сверяет зеркало с переданным полным состоянием реестра: новые и изменённые ТС
+// сохраняются, отсутствующие удаляются, whitelist приводится в соответствие
+func (s *VehicleService) Sync(ctx context.Context, req VehicleSyncRequest) (*VehicleSyncResult, error) {
+	desired, skipped, err := desiredVehicles(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.repo.FindVehicles(ctx, repository.VehicleFilter{OrgID: req.OrgID}, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vehicles: %w", err)
+	}
+
+	result, changes := diffVehicles(current, desired)
+	result.DryRun = req.DryRun
+	result.Skipped = skipped
+
+	if limit := s.cfg.MaxRemoveShare; limit > 0 && !req.Force && len(current) > 0 &&
+		float64(len(result.Removed))/float64(len(current)) > limit {
+		return nil, fmt.Errorf("%w: sync would remove %d of %d vehicles, pass force to apply",
+			ErrInvalidInput, len(result.Removed), len(current))
+	}
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	// Полная сверка без ограничения по организации заодно убирает из whitelist
+	// номера синхронизации, оставшиеся без активного ТС
+	changes.PruneOrphans = req.OrgID == nil
+	result.Whitelist, err = s.repo.ApplyVehicleChanges(ctx, changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply vehicle changes: %w", err)
+	}
+
+	s.log.Info().
+		Int("added", len(result.Added)).
+		Int("updated", len(result.Updated)).
+		Int("removed", len(result.Removed)).
+		Int("skipped", len(result.Skipped)).
+		Int64("whitelist_added", result.Whitelist.Added).
+		Int64("whitelist_removed", result.Whitelist.Removed).
+		Msg("vehicle registry synced")
+
+	return result, nil
+}
+
+type VehicleQuery struct {
+	OrgID        *string
+	ContractorID *string
+	Plate        *string
+}
+
+func (s *VehicleService) FindVehicles(ctx context.Context, query VehicleQuery, limit, offset int) ([]VehicleInfo, error) {
+	var filter repository.VehicleFilter
+	var err error
+	if filter.OrgID, err = parseOptionalUUID(query.OrgID, "organization_id"); err != nil {
+		return nil, err
+	}
+	if filter.ContractorID, err = parseOptionalUUID(query.ContractorID, "contractor_id"); err != nil {
+		return nil, err
+	}
+	if query.Plate != nil {
+		normalized := utils.NormalizePlate(*query.Plate)
+		filter.NormalizedPlate = &normalized
+	}
+
+	vehicles, err := s.repo.FindVehicles(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find vehicles: %w", err)
+	}
+	result := make([]VehicleInfo, 0, len(vehicles))
+	for _, v := range vehicles {
+		result = append(result, VehicleInfo{
+			VehicleID:       v.VehicleID,
+			OrgID:           v.OrgID,
+			ContractorID:    v.ContractorID,
+			ContractorName:  v.ContractorName,
+			PlateNumber:     v.PlateNumber,
+			NormalizedPlate: v.NormalizedPlate,
+			PlateID:         v.PlateID,
+			IsActive:        v.IsActive,
+			SourceUpdatedAt: v.SourceUpdatedAt,
+			UpdatedAt:       v.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+// desiredVehicles проверяет запрос и приводит записи roles к строкам зеркала.
+// ТС без распознаваемого номера пропускаются: в зеркале и whitelist им делать нечего.
+func desiredVehicles(req VehicleSyncRequest) ([]repository.Vehicle, []VehicleSkip, error) {
+	seen := make(map[uuid.UUID]struct{}, len(req.Vehicles))
+	desired := make([]repository.Vehicle, 0, len(req.Vehicles))
+	var skipped []VehicleSkip
+	for _, v := range req.Vehicles {
+		if v.ID == uuid.Nil {
+			return nil, nil, fmt.Errorf("%w: vehicle id is required", ErrInvalidInput)
+		}
+		if _, ok := seen[v.ID]; ok {
+			return nil, nil, fmt.Errorf("%w: duplicate vehicle %s", ErrInvalidInput, v.ID)
+		}
+		seen[v.ID] = struct{}{}
+		if v.OrgID == uuid.Nil {
+			return nil, nil, fmt.Errorf("%w: vehicle %s has no organization_id", ErrInvalidInput, v.ID)
+		}
+		if req.OrgID != nil && v.OrgID != *req.OrgID {
+			return nil, nil, fmt.Errorf("%w: vehicle %s belongs to another organization", ErrInvalidInput, v.ID)
+		}
+
+		normalized := utils.NormalizePlate(v.PlateNumber)
+		if normalized == "" {
+			skipped = append(skipped, VehicleSkip{VehicleID: v.ID, Reason: "empty plate number"})
+			continue
+		}
+		desired = append(desired, repository.Vehicle{
+			VehicleID:       v.ID,
+			OrgID:           v.OrgID,
+			ContractorID:    v.ContractorID,
+			ContractorName:  v.ContractorName,
+			PlateNumber:     v.PlateNumber,
+			NormalizedPlate: normalized,
+			IsActive:        v.Active(),
+			SourceUpdatedAt: v.UpdatedAt,
+		})
+	}
+	return desired, skipped, nil
+}
+
+// diffVehicles сравнивает зеркало с желаемым состоянием
+func diffVehicles(current, desired []repository.Vehicle) (*VehicleSyncResult, repository.VehicleChanges) {
+	result := &VehicleSyncResult{
+		Added:   []VehicleChange{},
+		Updated: []VehicleChange{},
+		Removed: []VehicleChange{},
+	}
+	var changes repository.VehicleChanges
+
+	existing := make(map[uuid.UUID]repository.Vehicle, len(current))
+	for _, v := range current {
+		existing[v.VehicleID] = v
+	}
+
+	for _, v := range desired {
+		old, ok := existing[v.VehicleID]
+		if !ok {
+			result.Added = append(result.Added, VehicleChange{VehicleID: v.VehicleID, OrgID: v.OrgID, PlateNumber: v.PlateNumber})
+			changes.Upserts = append(changes.Upserts, v)
+			continue
+		}
+		delete(existing, v.VehicleID)
+
+		fields := changedVehicleFields(old, v)
+		if len(fields) == 0 {
+			result.Unchanged++
+			continue
+		}
+		change := VehicleChange{VehicleID: v.VehicleID, OrgID: v.OrgID, PlateNumber: v.PlateNumber, Fields: fields}
+		if old.NormalizedPlate != v.NormalizedPlate {
+			previous := old.PlateNumber
+			change.PreviousPlate = &previous
+		}
+		v.CreatedAt = old.CreatedAt
+		result.Updated = append(result.Updated, change)
+		changes.Upserts = append(changes.Upserts, v)
+	}
+
+	for _, v := range current {
+		if _, ok := existing[v.VehicleID]; !ok {
+			continue
+		}
+		result.Removed = append(result.Removed, VehicleChange{VehicleID: v.VehicleID, OrgID: v.OrgID, PlateNumber: v.PlateNumber})
+		changes.Deletes = append(changes.Deletes, v.VehicleID)
+	}
+
+	return result, changes
+}
+
+func changedVehicleFields(old, v repository.Vehicle) []string {
+	var fields []string
+	if old.NormalizedPlate != v.NormalizedPlate || old.PlateNumber != v.PlateNumber {
+		fields = append(fields, "plate_number")
+	}
+	if old.OrgID != v.OrgID {
+		fields = append(fields, "organization_id")
+	}
+	if !equalUUIDPtr(old.ContractorID, v.ContractorID) {
+		fields = append(fields, "contractor_id")
+	}
+	if !equalStringPtr(old.ContractorName, v.ContractorName) {
+		fields = append(fields, "contractor_name")
+	}
+	if old.IsActive != v.IsActive {
+		fields = append(fields, "is_active")
+	}
+	return fields
+}
+
+func parseOptionalUUID(value *string, name string) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidInput, name)
+	}
+	return &id, nil
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"anpr-service/internal/repository"
+	"anpr-service/internal/roles"
+)
+
+func TestDiffVehicles(t *testing.T) {
+	org := uuid.New()
+	kept, moved, deactivated, gone, added := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	current := []repository.Vehicle{
+		{VehicleID: kept, OrgID: org, PlateNumber: "123ABC02", NormalizedPlate: "123ABC02", IsActive: true},
+		{VehicleID: moved, OrgID: org, PlateNumber: "456DEF02", NormalizedPlate: "456DEF02", IsActive: true},
+		{VehicleID: deactivated, OrgID: org, PlateNumber: "789GHI02", NormalizedPlate: "789GHI02", IsActive: true},
+		{VehicleID: gone, OrgID: org, PlateNumber: "111AAA02", NormalizedPlate: "111AAA02", IsActive: true},
+	}
+	desired := []repository.Vehicle{
+		{VehicleID: kept, OrgID: org, PlateNumber: "123ABC02", NormalizedPlate: "123ABC02", IsActive: true},
+		{VehicleID: moved, OrgID: org, PlateNumber: "654FED02", NormalizedPlate: "654FED02", IsActive: true},
+		{VehicleID: deactivated, OrgID: org, PlateNumber: "789GHI02", NormalizedPlate: "789GHI02", IsActive: false},
+		{VehicleID: added, OrgID: org, PlateNumber: "222BBB02", NormalizedPlate: "222BBB02", IsActive: true},
+	}
+
+	result, changes := diffVehicles(current, desired)
+
+	if result.Unchanged != 1 || len(result.Added) != 1 || len(result.Updated) != 2 || len(result.Removed) != 1 {
+		t.Fatalf("unexpected diff: %+v", result)
+	}
+	if result.Added[0].VehicleID != added || result.Removed[0].VehicleID != gone {
+		t.Fatalf("wrong added/removed: %+v", result)
+	}
+	plateChange := result.Updated[0]
+	if plateChange.VehicleID != moved || plateChange.PreviousPlate == nil || *plateChange.PreviousPlate != "456DEF02" {
+		t.Fatalf("plate change not reported: %+v", plateChange)
+	}
+	if fields := result.Updated[1].Fields; len(fields) != 1 || fields[0] != "is_active" {
+		t.Fatalf("expected is_active change, got %v", fields)
+	}
+	if len(changes.Upserts) != 3 || len(changes.Deletes) != 1 || changes.Deletes[0] != gone {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestDesiredVehicles(t *testing.T) {
+	org := uuid.New()
+	withPlate := roles.Vehicle{ID: uuid.New(), OrgID: org, PlateNumber: "123 abc 02"}
+	noPlate := roles.Vehicle{ID: uuid.New(), OrgID: org, PlateNumber: " - "}
+
+	desired, skipped, err := desiredVehicles(VehicleSyncRequest{Vehicles: []roles.Vehicle{withPlate, noPlate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desired) != 1 || desired[0].NormalizedPlate != "123ABC02" || !desired[0].IsActive {
+		t.Fatalf("unexpected desired: %+v", desired)
+	}
+	if len(skipped) != 1 || skipped[0].VehicleID != noPlate.ID {
+		t.Fatalf("unexpected skipped: %+v", skipped)
+	}
+
+	other := uuid.New()
+	_, _, err = desiredVehicles(VehicleSyncRequest{OrgID: &other, Vehicles: []roles.Vehicle{withPlate}})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for foreign organization, got %v", err)
+	}
+	_, _, err = desiredVehicles(VehicleSyncRequest{Vehicles: []roles.Vehicle{withPlate, withPlate}})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for duplicate vehicle, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"mime/multipart"
+	"net/textproto"
+	"time"
+)
+
+// Camera - эмулируемая камера
+type Camera struct {
+	DeviceID  string
+	ChannelID string
+	IPAddress string
+	// Direction - enter или exit
+	Direction string
+	Lane      int
+}
+
+// Passage - проезд, который камера отправляет как EventNotificationAlert
+type Passage struct {
+	Camera       Camera
+	Plate        string
+	Confidence   float64
+	Time         time.Time
+	VehicleType  string
+	VehicleColor string
+	Speed        float64
+}
+
+var (
+	vehicleTypes  = []string{"truck", "car", "SUVMPV", "van", "bus"}
+	vehicleColors = []string{"white", "black", "gray", "orange", "yellow", "blue", "red"}
+)
+
+// RandomPassage заполняет случайные атрибуты ТС
+func RandomPassage(rng *rand.Rand, cam Camera, plate string, at time.Time) Passage {
+	return Passage{
+		Camera:       cam,
+		Plate:        plate,
+		Confidence:   70 + rng.Float64()*30,
+		Time:         at,
+		VehicleType:  vehicleTypes[rng.Intn(len(vehicleTypes))],
+		VehicleColor: vehicleColors[rng.Intn(len(vehicleColors))],
+		Speed:        5 + rng.Float64()*40,
+	}
+}
+
+type alertXML struct {
+	XMLName      xml.Name `xml:"EventNotificationAlert"`
+	Version      string   `xml:"version,attr"`
+	IPAddress    string   `xml:"ipAddress"`
+	PortNo       int      `xml:"portNo"`
+	ProtocolType string   `xml:"protocolType"`
+	ChannelID    string   `xml:"channelID"`
+	DateTime     string   `xml:"dateTime"`
+	EventType    string   `xml:"eventType"`
+	EventState   string   `xml:"eventState"`
+	DeviceID     string   `xml:"deviceID"`
+	ANPR         struct {
+		LicensePlate    string `xml:"licensePlate"`
+		ConfidenceLevel string `xml:"confidenceLevel"`
+		VehicleType     string `xml:"vehicleType"`
+		Direction       string `xml:"direction"`
+		LaneNo          int    `xml:"laneNo"`
+	} `xml:"ANPR"`
+	VehicleInfo struct {
+		VehicleType string `xml:"vehicleType"`
+		Color       string `xml:"color"`
+		Speed       string `xml:"speed"`
+	} `xml:"vehicleInfo"`
+}
+
+// BuildXML формирует EventNotificationAlert в формате камер Hikvision
+func BuildXML(p Passage) ([]byte, error) {
+	var a alertXML
+	a.Version = "2.0"
+	a.IPAddress = p.Camera.IPAddress
+	a.PortNo = 80
+	a.ProtocolType = "HTTP"
+	a.ChannelID = p.Camera.ChannelID
+	a.DateTime = p.Time.Format("2006-01-02T15:04:05-07:00")
+	a.EventType = "ANPR"
+	a.EventState = "active"
+	a.DeviceID = p.Camera.DeviceID
+	a.ANPR.LicensePlate = p.Plate
+	a.ANPR.ConfidenceLevel = fmt.Sprintf("%.0f", p.Confidence)
+	a.ANPR.VehicleType = p.VehicleType
+	a.ANPR.Direction = p.Camera.Direction
+	a.ANPR.LaneNo = p.Camera.Lane
+	a.VehicleInfo.VehicleType = p.VehicleType
+	a.VehicleInfo.Color = p.VehicleColor
+	a.VehicleInfo.Speed = fmt.Sprintf("%.1f", p.Speed)
+
+	body, err := xml.MarshalIndent(a, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// BuildMultipart собирает multipart-тело как у камеры: XML и снимки JPEG
+func BuildMultipart(xmlBody []byte, pictures map[string][]byte) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="anpr.xml"; filename="anpr.xml"`)
+	h.Set("Content-Type", "application/xml")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(xmlBody); err != nil {
+		return nil, "", err
+	}
+
+	for name, data := range pictures {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, name, name))
+		h.Set("Content-Type", "image/jpeg")
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// NoiseJPEG генерирует JPEG заданных размеров; шум не сжимается, поэтому размер файла реалистичный
+func NoiseJPEG(rng *rand.Rand, width, height int) ([]byte, error) {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(rng.Intn(256))})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 75}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Символы, которые камеры чаще всего путают при распознавании
+var lookalikes = map[byte][]byte{
+	'0': {'O', 'D'},
+	'O': {'0', 'D'},
+	'D': {'0', 'O'},
+	'1': {'I', '7'},
+	'I': {'1'},
+	'7': {'1'},
+	'2': {'Z'},
+	'Z': {'2'},
+	'5': {'S'},
+	'S': {'5'},
+	'8': {'B'},
+	'B': {'8'},
+	'6': {'G'},
+	'G': {'6'},
+}
+
+const plateLetters = "ABCDEFHKLMNOPRSTUXYZ"
+
+// PlatePool - набор номеров для генерации; номера из списков выбираются с долей ListShare
+type PlatePool struct {
+	Regular   []string
+	Listed    []string
+	ListShare float64
+}
+
+// GeneratePlates создаёт n номеров казахстанского формата 123ABC02
+func GeneratePlates(rng *rand.Rand, n int) []string {
+	plates := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for len(plates) < n {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%03d", rng.Intn(1000))
+		for i := 0; i < 3; i++ {
+			b.WriteByte(plateLetters[rng.Intn(len(plateLetters))])
+		}
+		fmt.Fprintf(&b, "%02d", 1+rng.Intn(18))
+		plate := b.String()
+		if !seen[plate] {
+			seen[plate] = true
+			plates = append(plates, plate)
+		}
+	}
+	return plates
+}
+
+// Pick выбирает номер из пула
+func (p *PlatePool) Pick(rng *rand.Rand) string {
+	if len(p.Listed) > 0 && (len(p.Regular) == 0 || rng.Float64() < p.ListShare) {
+		return p.Listed[rng.Intn(len(p.Listed))]
+	}
+	return p.Regular[rng.Intn(len(p.Regular))]
+}
+
+// Misread заменяет один символ номера на похожий, как это делает камера при ошибке
+func Misread(rng *rand.Rand, plate string) string {
+	var candidates []int
+	for i := 0; i < len(plate); i++ {
+		if _, ok := lookalikes[plate[i]]; ok {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return plate
+	}
+	pos := candidates[rng.Intn(len(candidates))]
+	options := lookalikes[plate[pos]]
+	b := []byte(plate)
+	b[pos] = options[rng.Intn(len(options))]
+	return string(b)
+}
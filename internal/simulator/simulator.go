@@ -0,0 +1,333 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"anpr-service/internal/service"
+)
+
+const (
+	DirectionEnter = "enter"
+	DirectionExit  = "exit"
+
+	hikvisionPath = "/api/v1/anpr/hikvision"
+	burstGap      = 100 * time.Millisecond
+)
+
+// Config - параметры эмуляции трафика камер
+type Config struct {
+	Target   string
+	Cameras  int
+	Rate     float64 // событий в секунду на камеру
+	Duration time.Duration
+	Pool     PlatePool
+	// MisreadProbability - вероятность замены символа номера на похожий
+	MisreadProbability float64
+	// DuplicateProbability - вероятность того, что камера отправит проезд серией из BurstSize копий
+	DuplicateProbability float64
+	BurstSize            int
+	// ExitProbability - доля въездов, за которыми через ~Dwell следует выезд на парной камере
+	ExitProbability float64
+	Dwell           time.Duration
+	Concurrency     int
+	Pictures        map[string][]byte
+	Seed            int64
+	// APIKey - ключ API камеры (X-API-Key), HMACSecret - секрет подписи тела, как у
+	// пограничного ретранслятора (X-Signature). Пустые - запросы без аутентификации
+	APIKey     string
+	HMACSecret string
+}
+
+// Report - итог прогона
+type Report struct {
+	Sent       int
+	Succeeded  int
+	Failed     int
+	Statuses   map[int]int
+	Errors     map[string]int
+	Misreads   int
+	Duplicates int
+	Exits      int
+	Elapsed    time.Duration
+	Throughput float64
+	Latency    map[string]time.Duration
+}
+
+type job struct {
+	passage Passage
+	copies  int
+}
+
+type collector struct {
+	mu         sync.Mutex
+	latencies  []time.Duration
+	statuses   map[int]int
+	errors     map[string]int
+	misreads   int
+	duplicates int
+	exits      int
+}
+
+// Run эмулирует cfg.Cameras камер, отправляющих события в cfg.Target в течение cfg.Duration
+func Run(ctx context.Context, cfg Config, client *http.Client) (*Report, error) {
+	if cfg.Cameras < 1 || cfg.Rate <= 0 || cfg.Duration <= 0 {
+		return nil, errors.New("cameras, rate and duration must be positive")
+	}
+	if len(cfg.Pool.Regular) == 0 && len(cfg.Pool.Listed) == 0 {
+		return nil, errors.New("plate pool is empty")
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.BurstSize < 2 {
+		cfg.BurstSize = 2
+	}
+
+	cameras := makeCameras(cfg.Cameras)
+	genCtx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	stats := &collector{statuses: make(map[int]int), errors: make(map[string]int)}
+	jobs := make(chan job, cfg.Concurrency*2)
+
+	var workers sync.WaitGroup
+	for i := 0; i < cfg.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				send(ctx, client, cfg, j, stats)
+			}
+		}()
+	}
+
+	start := time.Now()
+	var producers sync.WaitGroup
+	for i, cam := range cameras {
+		producers.Add(1)
+		partner := cameras[i]
+		if cam.Direction == DirectionEnter && i+1 < len(cameras) {
+			partner = cameras[i+1]
+		}
+		go func(cam, partner Camera, seed int64) {
+			defer producers.Done()
+			generate(genCtx, cfg, cam, partner, rand.New(rand.NewSource(seed)), jobs, stats, &producers)
+		}(cam, partner, cfg.Seed+int64(i))
+	}
+
+	producers.Wait()
+	close(jobs)
+	workers.Wait()
+
+	return stats.report(time.Since(start)), nil
+}
+
+// makeCameras: чётные камеры на въезд, нечётные - парные им на выезд
+func makeCameras(n int) []Camera {
+	cameras := make([]Camera, n)
+	for i := range cameras {
+		direction := DirectionEnter
+		if i%2 == 1 {
+			direction = DirectionExit
+		}
+		cameras[i] = Camera{
+			DeviceID:  fmt.Sprintf("SIM-%03d", i+1),
+			ChannelID: "1",
+			IPAddress: fmt.Sprintf("10.77.0.%d", i+1),
+			Direction: direction,
+			Lane:      1,
+		}
+	}
+	return cameras
+}
+
+func generate(ctx context.Context, cfg Config, cam, partner Camera, rng *rand.Rand, jobs chan<- job, stats *collector, producers *sync.WaitGroup) {
+	for {
+		// Пуассоновский поток: экспоненциальные интервалы между проездами
+		wait := time.Duration(rng.ExpFloat64() / cfg.Rate * float64(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		plate := cfg.Pool.Pick(rng)
+		enqueue(ctx, cfg, rng, cam, plate, jobs, stats)
+
+		if cam.Direction == DirectionEnter && partner.Direction == DirectionExit && rng.Float64() < cfg.ExitProbability {
+			dwell := time.Duration(rng.ExpFloat64() * float64(cfg.Dwell))
+			exitRng := rand.New(rand.NewSource(rng.Int63()))
+			producers.Add(1)
+			go func() {
+				defer producers.Done()
+				t := time.NewTimer(dwell)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+				case <-t.C:
+					stats.add(func(c *collector) { c.exits++ })
+					enqueue(ctx, cfg, exitRng, partner, plate, jobs, stats)
+				}
+			}()
+		}
+	}
+}
+
+func enqueue(ctx context.Context, cfg Config, rng *rand.Rand, cam Camera, plate string, jobs chan<- job, stats *collector) {
+	if rng.Float64() < cfg.MisreadProbability {
+		plate = Misread(rng, plate)
+		stats.add(func(c *collector) { c.misreads++ })
+	}
+	j := job{passage: RandomPassage(rng, cam, plate, time.Now()), copies: 1}
+	if rng.Float64() < cfg.DuplicateProbability {
+		j.copies = cfg.BurstSize
+		stats.add(func(c *collector) { c.duplicates++ })
+	}
+
+	select {
+	case jobs <- j:
+	case <-ctx.Done():
+	}
+}
+
+func send(ctx context.Context, client *http.Client, cfg Config, j job, stats *collector) {
+	for i := 0; i < j.copies; i++ {
+		if i > 0 {
+			time.Sleep(burstGap)
+		}
+		err := post(ctx, client, cfg, j.passage, stats)
+		if err != nil {
+			stats.add(func(c *collector) { c.errors[errorKind(err)]++ })
+		}
+	}
+}
+
+func post(ctx context.Context, client *http.Client, cfg Config, p Passage, stats *collector) error {
+	xmlBody, err := BuildXML(p)
+	if err != nil {
+		return err
+	}
+	body, contentType, err := BuildMultipart(xmlBody, cfg.Pictures)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(cfg.Target, "/")+hikvisionPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	Authenticate(req, body, cfg.APIKey, cfg.HMACSecret)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	stats.add(func(c *collector) {
+		c.latencies = append(c.latencies, latency)
+		c.statuses[resp.StatusCode]++
+	})
+	return nil
+}
+
+// Authenticate добавляет к запросу ключ API камеры и подпись тела body секретом
+// hmacSecret по схеме service.VerifySignature; пустые значения пропускаются
+func Authenticate(req *http.Request, body []byte, apiKey, hmacSecret string) {
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	if hmacSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Signature-Timestamp", timestamp)
+		req.Header.Set("X-Signature", service.SignBody(hmacSecret, timestamp, body))
+	}
+}
+
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return err.Error()
+	}
+}
+
+func (c *collector) add(fn func(*collector)) {
+	c.mu.Lock()
+	fn(c)
+	c.mu.Unlock()
+}
+
+func (c *collector) report(elapsed time.Duration) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &Report{
+		Statuses:   c.statuses,
+		Errors:     c.errors,
+		Misreads:   c.misreads,
+		Duplicates: c.duplicates,
+		Exits:      c.exits,
+		Elapsed:    elapsed,
+	}
+	for status, n := range c.statuses {
+		r.Sent += n
+		if status < 300 {
+			r.Succeeded += n
+		} else {
+			r.Failed += n
+		}
+	}
+	for _, n := range c.errors {
+		r.Sent += n
+		r.Failed += n
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Sent) / elapsed.Seconds()
+	}
+
+	sort.Slice(c.latencies, func(i, j int) bool { return c.latencies[i] < c.latencies[j] })
+	r.Latency = map[string]time.Duration{
+		"p50": Percentile(c.latencies, 50),
+		"p90": Percentile(c.latencies, 90),
+		"p95": Percentile(c.latencies, 95),
+		"p99": Percentile(c.latencies, 99),
+		"max": Percentile(c.latencies, 100),
+	}
+	return r
+}
+
+// Percentile возвращает перцентиль p (0-100) по отсортированной выборке (nearest-rank)
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted)) + 0.999999)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,94 @@
+package simulator
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"anpr-service/internal/hikvision"
+	"anpr-service/internal/service"
+)
+
+func TestPercentile(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	cases := map[float64]time.Duration{50: 50 * time.Millisecond, 95: 95 * time.Millisecond, 100: 100 * time.Millisecond, 0: time.Millisecond}
+	for p, want := range cases {
+		if got := Percentile(samples, p); got != want {
+			t.Errorf("p%.0f = %v, want %v", p, got, want)
+		}
+	}
+	if Percentile(nil, 50) != 0 {
+		t.Error("empty sample must give 0")
+	}
+}
+
+func TestMisreadChangesOneLookalike(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	plate := "080ABC02"
+	got := Misread(rng, plate)
+
+	diff := 0
+	for i := range plate {
+		if plate[i] != got[i] {
+			diff++
+		}
+	}
+	if diff != 1 {
+		t.Fatalf("expected exactly one changed character, got %q", got)
+	}
+	if Misread(rng, "AAA") != "AAA" {
+		t.Fatal("plate without lookalikes must stay unchanged")
+	}
+}
+
+func TestBuildXMLParsesWithVendorParser(t *testing.T) {
+	cam := Camera{DeviceID: "SIM-001", ChannelID: "1", IPAddress: "10.77.0.1", Direction: DirectionEnter, Lane: 2}
+	at := time.Date(2025, 1, 21, 12, 34, 56, 0, time.UTC)
+	body, err := BuildXML(RandomPassage(rand.New(rand.NewSource(1)), cam, "123ABC02", at))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := hikvision.Parse(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := event.ToEventPayload(body, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Plate != "123ABC02" || payload.Lane != 2 || payload.Direction != DirectionEnter {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if !payload.EventTime.Equal(at) {
+		t.Fatalf("event time %v, want %v", payload.EventTime, at)
+	}
+}
+
+func TestAuthenticateSignsBody(t *testing.T) {
+	body := []byte("<EventNotificationAlert/>")
+	req := httptest.NewRequest(http.MethodPost, hikvisionPath, strings.NewReader(string(body)))
+	Authenticate(req, body, "camera-key", "relay-secret")
+
+	if got := req.Header.Get("X-API-Key"); got != "camera-key" {
+		t.Errorf("X-API-Key = %q", got)
+	}
+	err := service.VerifySignature([]string{"relay-secret"}, req.Header.Get("X-Signature-Timestamp"),
+		req.Header.Get("X-Signature"), body, time.Now(), time.Minute)
+	if err != nil {
+		t.Errorf("signature rejected: %v", err)
+	}
+
+	unsigned := httptest.NewRequest(http.MethodPost, hikvisionPath, nil)
+	Authenticate(unsigned, body, "", "")
+	if len(unsigned.Header) != 0 {
+		t.Errorf("empty credentials must add no headers: %v", unsigned.Header)
+	}
+}
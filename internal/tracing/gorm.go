@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"anpr-service/internal/gormhook"
+)
+
+const spanKey = "tracing:span"
+
+// InstrumentGorm регистрирует callbacks gorm, открывающие спан на каждый SQL-запрос
+func InstrumentGorm(db *gorm.DB) error {
+	return gormhook.Register(db, "tracing",
+		func(operation string, tx *gorm.DB) {
+			ctx, span := Start(tx.Statement.Context, "db."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.String("db.system", "postgresql")),
+			)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanKey, span)
+		},
+		func(_ string, tx *gorm.DB) {
+			value, ok := tx.InstanceGet(spanKey)
+			if !ok {
+				return
+			}
+			span, ok := value.(trace.Span)
+			if !ok {
+				return
+			}
+			span.SetAttributes(
+				attribute.String("db.statement", tx.Statement.SQL.String()),
+				attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+			)
+			if tx.Statement.Table != "" {
+				span.SetAttributes(attribute.String("db.sql.table", tx.Statement.Table))
+			}
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+			span.End()
+		},
+	)
+}
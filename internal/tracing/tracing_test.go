@@ -0,0 +1,145 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"anpr-service/internal/domain/anpr"
+	"anpr-service/internal/repository"
+	"anpr-service/internal/service"
+	"anpr-service/internal/tracing"
+)
+
+// recordSpans подменяет глобальный TracerProvider провайдером с записью спанов в память
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestEndSpanRecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, failed := tracing.Start(context.Background(), "failed")
+	tracing.EndSpan(failed, errors.New("boom"))
+	_, succeeded := tracing.Start(context.Background(), "succeeded")
+	tracing.EndSpan(succeeded, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %d, want 2", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "boom" || len(spans[0].Events()) != 1 {
+		t.Errorf("failed span: status %+v, events %d", spans[0].Status(), len(spans[0].Events()))
+	}
+	if spans[1].Status().Code == codes.Error || len(spans[1].Events()) != 0 {
+		t.Errorf("succeeded span: status %+v", spans[1].Status())
+	}
+}
+
+// TestIngestTrace проверяет, что приём события - один трейс: серверный спан обработчика,
+// ANPRService.ProcessIncomingEvent и спан SQL-запроса из callback gorm. База недоступна,
+// поэтому ошибка запроса проходит через все уровни и каждый спан её записывает.
+func TestIngestTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	// Подключение ленивое: соединение открывается первым запросом и сразу получает отказ
+	database, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=anpr dbname=anpr sslmode=disable connect_timeout=1"), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracing.InstrumentGorm(database); err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewANPRService(repository.NewANPRRepository(database), nil, nil, nil, nil, nil, zerolog.Nop())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(otelgin.Middleware("anpr-service"))
+	r.POST("/api/v1/anpr/events", func(c *gin.Context) {
+		var payload anpr.EventPayload
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		if _, err := svc.ProcessIncomingEvent(c.Request.Context(), payload, ""); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	body, _ := json.Marshal(anpr.EventPayload{CameraID: "cam-1", Plate: "123ABC02", EventTime: time.Now()})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/anpr/events", bytes.NewReader(body)))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500 with the database down", w.Code)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, ok := spans["/api/v1/anpr/events"]
+	if !ok {
+		t.Fatalf("no handler span among %v", spanNames(recorder.Ended()))
+	}
+	ingest, ok := spans["ANPRService.ProcessIncomingEvent"]
+	if !ok {
+		t.Fatalf("no service span among %v", spanNames(recorder.Ended()))
+	}
+	query, ok := spans["db.query"]
+	if !ok {
+		t.Fatalf("no gorm span among %v", spanNames(recorder.Ended()))
+	}
+
+	traceID := server.SpanContext().TraceID()
+	for _, span := range []sdktrace.ReadOnlySpan{ingest, query} {
+		if span.SpanContext().TraceID() != traceID {
+			t.Errorf("%s is in trace %s, want %s", span.Name(), span.SpanContext().TraceID(), traceID)
+		}
+	}
+	if ingest.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("service span must be a child of the handler span")
+	}
+	for _, span := range []sdktrace.ReadOnlySpan{ingest, query} {
+		if span.Status().Code != codes.Error {
+			t.Errorf("%s: status %+v, want error", span.Name(), span.Status())
+		}
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
@@ -0,0 +1,31 @@
+package utils
+
+// ConfidenceFromPercent переводит уверенность в процентах (0–100, как её передают камеры
+// Hikvision) в долю 0–1. Результат ограничен диапазоном [0, 1].
+func ConfidenceFromPercent(percent float64) float64 {
+	confidence := percent / 100
+	if confidence < 0 {
+		return 0
+	}
+	if confidence > 1 {
+		return 1
+	}
+	return confidence
+}
+
+// ValidConfidence - уверенность задана долей 0–1
+func ValidConfidence(confidence float64) bool {
+	return confidence >= 0 && confidence <= 1
+}
+
+// NormalizeConfidence приводит уверенность из JSON и gRPC к доле 0–1: значения до 1 - уже доля,
+// значения в (1, 100] - проценты. false - значение вне 0–100.
+func NormalizeConfidence(confidence float64) (float64, bool) {
+	if confidence < 0 || confidence > 100 {
+		return 0, false
+	}
+	if confidence > 1 {
+		return confidence / 100, true
+	}
+	return confidence, true
+}
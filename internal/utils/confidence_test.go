@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestConfidenceFromPercent(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    float64
+		expected float64
+	}{
+		{name: "percent", input: 87, expected: 0.87},
+		{name: "hundred percent", input: 100, expected: 1},
+		{name: "below one percent", input: 0.5, expected: 0.005},
+		{name: "zero", input: 0, expected: 0},
+		{name: "negative", input: -5, expected: 0},
+		{name: "above hundred", input: 250, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ConfidenceFromPercent(tt.input)
+			if result != tt.expected {
+				t.Errorf("ConfidenceFromPercent(%v) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeConfidence(t *testing.T) {
+	tests := []struct {
+		input    float64
+		expected float64
+		ok       bool
+	}{
+		{input: 0, expected: 0, ok: true},
+		{input: 0.87, expected: 0.87, ok: true},
+		{input: 1, expected: 1, ok: true},
+		{input: 1.5, expected: 0.015, ok: true},
+		{input: 87, expected: 0.87, ok: true},
+		{input: 100, expected: 1, ok: true},
+		{input: 100.5, ok: false},
+		{input: -0.1, ok: false},
+	}
+
+	for _, tt := range tests {
+		result, ok := NormalizeConfidence(tt.input)
+		if ok != tt.ok || (ok && result != tt.expected) {
+			t.Errorf("NormalizeConfidence(%v) = %v, %v; want %v, %v", tt.input, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestValidConfidence(t *testing.T) {
+	for _, v := range []float64{0, 0.5, 1} {
+		if !ValidConfidence(v) {
+			t.Errorf("ValidConfidence(%v) = false, want true", v)
+		}
+	}
+	for _, v := range []float64{-0.1, 1.01, 87} {
+		if ValidConfidence(v) {
+			t.Errorf("ValidConfidence(%v) = true, want false", v)
+		}
+	}
+}
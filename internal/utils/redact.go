@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RedactQuery заменяет значения секретных параметров строки запроса на REDACTED.
+// Остальные параметры и их порядок сохраняются как есть.
+func RedactQuery(rawQuery string, params ...string) string {
+	if rawQuery == "" || len(params) == 0 {
+		return rawQuery
+	}
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		for _, param := range params {
+			if key == param {
+				parts[i] = url.QueryEscape(param) + "=REDACTED"
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "empty", input: "", expected: ""},
+		{name: "no secret", input: "camera_id=cam-1&limit=10", expected: "camera_id=cam-1&limit=10"},
+		{name: "secret", input: "api_key=abc123", expected: "api_key=REDACTED"},
+		{name: "order kept", input: "camera_id=cam-1&api_key=abc&limit=10", expected: "camera_id=cam-1&api_key=REDACTED&limit=10"},
+		{name: "repeated", input: "api_key=a&api_key=b", expected: "api_key=REDACTED&api_key=REDACTED"},
+		{name: "escaped key", input: "api%5Fkey=abc", expected: "api_key=REDACTED"},
+		{name: "without value", input: "api_key", expected: "api_key=REDACTED"},
+		{name: "prefix only", input: "api_key_id=7", expected: "api_key_id=7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RedactQuery(tt.input, "api_key")
+			if result != tt.expected {
+				t.Errorf("RedactQuery(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}